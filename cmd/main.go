@@ -3,18 +3,32 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	authdb "greddit/internal/infra/db/postgres/auth"
+	forumdb "greddit/internal/infra/db/postgres/forum"
 
 	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+	servicesjobs "greddit/internal/services/jobs"
+	servicesmedia "greddit/internal/services/media"
+	servicespurge "greddit/internal/services/purge"
+	servicesrender "greddit/internal/services/render"
+	servicesstream "greddit/internal/services/stream"
+	servicesunfurl "greddit/internal/services/unfurl"
 
 	"greddit/internal/infra/auth/local/hs256"
+	"greddit/internal/infra/blob/localfs"
+	"greddit/internal/infra/blob/s3"
+
+	blobports "greddit/internal/ports/blob"
 
 	httpserver "greddit/internal/infra/http/server"
 
@@ -32,6 +46,29 @@ var (
 	keyFilePath    = env.GetStringEnvDef("KEY_FILE", "./key")
 
 	pgConnStr = env.GetStringEnvOrFatal("PGSQL_CONN_STR")
+
+	purgeRetention = env.GetDurationEnvDef("PURGE_RETENTION", 30*24*time.Hour)
+	purgeInterval  = env.GetDurationEnvDef("PURGE_INTERVAL", time.Hour)
+	purgeBatchSize = env.GetIntEnvDef("PURGE_BATCH_SIZE", 500)
+
+	streamRetention = env.GetDurationEnvDef("STREAM_RETENTION", 24*time.Hour)
+
+	unfurlInterval = env.GetDurationEnvDef("UNFURL_INTERVAL", 10*time.Second)
+	unfurlTimeout  = env.GetDurationEnvDef("UNFURL_TIMEOUT", 5*time.Second)
+	unfurlMaxSize  = env.GetIntEnvDef("UNFURL_MAX_SIZE", 1024*1024)
+	unfurlTtl      = env.GetDurationEnvDef("UNFURL_TTL", 24*time.Hour)
+
+	blobStore   = env.GetStringEnvDef("BLOB_STORE", "local")
+	blobDir     = env.GetStringEnvDef("BLOB_DIR", "./blobs")
+	s3Endpoint  = env.GetStringEnvDef("S3_ENDPOINT", "")
+	s3Bucket    = env.GetStringEnvDef("S3_BUCKET", "")
+	s3Region    = env.GetStringEnvDef("S3_REGION", "us-east-1")
+	s3AccessKey = env.GetStringEnvDef("S3_ACCESS_KEY", "")
+	s3SecretKey = env.GetStringEnvDef("S3_SECRET_KEY", "")
+
+	jobsInterval    = env.GetDurationEnvDef("JOBS_INTERVAL", 5*time.Second)
+	jobsConcurrency = env.GetIntEnvDef("JOBS_CONCURRENCY", 2)
+	thumbnailSize   = env.GetIntEnvDef("THUMBNAIL_SIZE", 320)
 )
 
 func main() {
@@ -104,6 +141,69 @@ func main() {
 		routingParam.AuthSer = &ser
 	}
 
+	var blobs blobports.BlobStore
+	{
+		switch blobStore {
+		case "local":
+			blobs, err = localfs.NewStore(blobDir)
+		case "s3":
+			blobs, err = s3.NewStore(s3.Config{
+				Endpoint:  s3Endpoint,
+				Bucket:    s3Bucket,
+				Region:    s3Region,
+				AccessKey: s3AccessKey,
+				SecretKey: s3SecretKey,
+			})
+		default:
+			err = fmt.Errorf("unknown blob store %q", blobStore)
+		}
+		if err != nil {
+			logger.Error("Error creating blob store",
+				"error", err,
+			)
+			os.Exit(exitBlobStoreFailure)
+		}
+	}
+
+	{
+		txs := postgres.NewTransactional(pool)
+		renderer := servicesrender.NewService()
+		ser := servicesforum.NewService(logger, txs, renderer, blobs, servicesforum.Repos{
+			Communities:   forumdb.NewCommunitiesRepo(pool),
+			Posts:         forumdb.NewPostsRepo(pool),
+			Comments:      forumdb.NewCommentsRepo(pool),
+			Links:         forumdb.NewLinksRepo(pool),
+			Revisions:     forumdb.NewRevisionsRepo(pool),
+			Subscriptions: forumdb.NewSubscriptionsRepo(pool),
+			SavedItems:    forumdb.NewSavedItemsRepo(pool),
+			Reads:         forumdb.NewReadsRepo(pool),
+			Notifications: forumdb.NewNotificationsRepo(pool),
+			Events:        forumdb.NewEventsRepo(pool),
+			Moderation:    forumdb.NewModerationRepo(pool),
+			Reports:       forumdb.NewReportsRepo(pool),
+			Bans:          forumdb.NewBansRepo(pool),
+			Members:       forumdb.NewMembersRepo(pool),
+			Flairs:        forumdb.NewFlairsRepo(pool),
+			Tags:          forumdb.NewTagsRepo(pool),
+			LinkPreviews:  forumdb.NewLinkPreviewsRepo(pool),
+			Attachments:   forumdb.NewAttachmentsRepo(pool),
+			Polls:         forumdb.NewPollsRepo(pool),
+			Users:         authdb.NewUsersRepo(pool),
+			Jobs:          postgres.NewJobsRepo(pool),
+		})
+		routingParam.ForumSer = &ser
+	}
+
+	{
+		ser := servicesstream.NewService(logger, servicesstream.Repos{
+			Events:        forumdb.NewEventsRepo(pool),
+			Subscriptions: forumdb.NewSubscriptionsRepo(pool),
+			Posts:         forumdb.NewPostsRepo(pool),
+			Presence:      forumdb.NewPresenceRepo(pool),
+		}, servicesstream.WithRetention(streamRetention))
+		routingParam.StreamSer = &ser
+	}
+
 	err = routingParam.Validate()
 	if err != nil {
 		logger.Error("Error validating router params",
@@ -128,6 +228,43 @@ func main() {
 		errCh <- svr.Start(ctx)
 	})
 
+	wg.Go(func() {
+		ser := servicespurge.NewService(logger, postgres.NewTransactional(pool), postgres.NewPurgeRepo(pool), blobs,
+			servicespurge.WithRetention(purgeRetention),
+			servicespurge.WithInterval(purgeInterval),
+			servicespurge.WithBatchSize(purgeBatchSize),
+		)
+		errCh <- ser.Start(ctx)
+	})
+
+	wg.Go(func() {
+		errCh <- routingParam.StreamSer.Start(ctx)
+	})
+
+	wg.Go(func() {
+		ser := servicesunfurl.NewService(logger, forumdb.NewLinkPreviewsRepo(pool),
+			servicesunfurl.WithInterval(unfurlInterval),
+			servicesunfurl.WithTimeout(unfurlTimeout),
+			servicesunfurl.WithMaxSize(int64(unfurlMaxSize)),
+			servicesunfurl.WithTtl(unfurlTtl),
+		)
+		errCh <- ser.Start(ctx)
+	})
+
+	wg.Go(func() {
+		mediaSer := servicesmedia.NewService(logger, postgres.NewTransactional(pool),
+			forumdb.NewAttachmentsRepo(pool), blobs,
+			servicesmedia.WithThumbnailSize(thumbnailSize),
+		)
+		ser := servicesjobs.NewService(logger, postgres.NewJobsRepo(pool), map[string]servicesjobs.Handler{
+			servicesmedia.JobKindProcessImage: mediaSer,
+		},
+			servicesjobs.WithInterval(jobsInterval),
+			servicesjobs.WithConcurrency(jobsConcurrency),
+		)
+		errCh <- ser.Start(ctx)
+	})
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -151,4 +288,5 @@ const (
 	exitDbFailure
 	exitRoutingParamValidationFailure
 	exitKeyFailure
+	exitBlobStoreFailure
 )
@@ -1,6 +1,9 @@
 package auth
 
-import "greddit/internal/util/set"
+import (
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+)
 
 // Role represents a user role.
 type Role string
@@ -25,6 +28,19 @@ func (e InvalidRoleError) Error() string {
 	return "invalid role value: " + e.value
 }
 
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidRoleError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidRoleError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  "role",
+		Reason: "invalid role value: " + e.value,
+	}}
+}
+
 // Validate checks that the role is valid.
 func (r Role) Validate() (err error) {
 	if !allowedRoles.Contains(r) {
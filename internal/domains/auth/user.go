@@ -12,6 +12,16 @@ import (
 
 type UserId = uuid.UUID
 
+// DeletedUserId is the ID of the placeholder user which the posts, comments,
+// revisions and attachments of purged users are reassigned to, so that
+// threads outlive their authors. It is the nil UUID, like the authors of
+// tombstones.
+var DeletedUserId = UserId(uuid.Nil)
+
+// DeletedUsername is the username of the placeholder user, which is not a
+// valid username so that it cannot be taken.
+const DeletedUsername = "[deleted]"
+
 // User represents a user in the system. Should be reused across
 // all sub applications.
 type User struct {
@@ -42,16 +52,19 @@ func ValidateUsername(username string) error {
 	username = strings.TrimSpace(username)
 	if username == "" {
 		return InvalidUserParamsError{
+			field:  "username",
 			reason: "username cannot be empty",
 		}
 	} else if len(username) < nameMinLength || len(username) > nameMaxLength {
 		return InvalidUserParamsError{
+			field:  "username",
 			reason: fmt.Sprintf("username must be between %d and %d characters", nameMinLength, nameMaxLength),
 		}
 	}
 	for _, r := range username {
 		if !unicode.IsOneOf(allowedUsernameChars, r) {
 			return InvalidUserParamsError{
+				field:  "username",
 				reason: "username contains invalid characters",
 			}
 		}
@@ -73,10 +86,12 @@ func (v UserValue) Validate() (err error) {
 		displayName = strings.TrimSpace(displayName)
 		if displayName == "" {
 			return InvalidUserParamsError{
+				field:  "display_name",
 				reason: "display name cannot be empty",
 			}
 		} else if len(displayName) > nameMaxLength {
 			return InvalidUserParamsError{
+				field:  "display_name",
 				reason: fmt.Sprintf("display name must be less than %d characters", nameMaxLength),
 			}
 		}
@@ -100,6 +115,7 @@ type UserMetadata struct {
 
 // InvalidUserParamsError represents an error when creating a user with invalid parameters.
 type InvalidUserParamsError struct {
+	field  string
 	reason string
 }
 
@@ -108,6 +124,19 @@ func (e InvalidUserParamsError) Error() string {
 	return "invalid user params: " + e.reason
 }
 
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidUserParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidUserParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
 // NewUser creates a new user.
 func NewUser(value UserValue, metadata UserMetadata, base shared.Base) (user *User, err error) {
 	err = value.Validate()
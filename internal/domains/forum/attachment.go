@@ -0,0 +1,176 @@
+package forum
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AttachmentMaxSize is the maximum size of an attachment in bytes.
+	AttachmentMaxSize = 10 * 1024 * 1024
+
+	attachmentMaxFilenameLength = 255
+)
+
+// allowedAttachmentTypes are the media types attachments may have, as sniffed
+// from their content.
+var allowedAttachmentTypes = set.New[string](set.WithSlice([]string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}))
+
+type AttachmentId = uuid.UUID
+
+// Attachment represents a file attached to a post or a comment. Its content is
+// a blob, which is shared by the attachments with the same content.
+type Attachment struct {
+	Id         AttachmentId `json:"id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UploaderId auth.UserId  `json:"uploader_id"`
+
+	AttachmentValue
+	Blob
+}
+
+// AttachmentValue represents the value of an attachment. Exactly one of the
+// post and comment IDs is set.
+type AttachmentValue struct {
+	PostId    *PostId    `json:"post_id"`
+	CommentId *CommentId `json:"comment_id"`
+	Filename  string     `json:"filename"`
+}
+
+// BlobStatus represents whether the content of an attachment is ready to be
+// served. Images are processed in the background before being served, to
+// strip their metadata and generate their thumbnail. Blobs no longer used by
+// any attachment are purging while their content is deleted from the blob
+// store.
+type BlobStatus string
+
+const (
+	BlobStatusPending BlobStatus = "pending"
+	BlobStatusReady   BlobStatus = "ready"
+	BlobStatusFailed  BlobStatus = "failed"
+	BlobStatusPurging BlobStatus = "purging"
+)
+
+// Blob represents the content of an attachment, identified by the hex encoded
+// SHA-256 hash of its bytes as uploaded. The dimensions and thumbnail of
+// images are set once processed, and their size then is the size of their
+// content without metadata.
+type Blob struct {
+	Sha256      string     `json:"sha256"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Status      BlobStatus `json:"status"`
+	Width       int        `json:"width,omitempty"`
+	Height      int        `json:"height,omitempty"`
+	Thumbnail   *Thumbnail `json:"thumbnail"`
+}
+
+// Thumbnail represents the resized copy of an image blob.
+type Thumbnail struct {
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// Validate checks that the blob may be attached.
+func (b Blob) Validate() error {
+	if b.Size <= 0 {
+		return InvalidAttachmentParamsError{
+			field:  "file",
+			reason: "file cannot be empty",
+		}
+	} else if b.Size > AttachmentMaxSize {
+		return InvalidAttachmentParamsError{
+			field:  "file",
+			reason: fmt.Sprintf("file must be at most %d bytes", AttachmentMaxSize),
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(b.ContentType)
+	if err != nil || !allowedAttachmentTypes.Contains(mediaType) {
+		return InvalidAttachmentParamsError{
+			field:  "file",
+			reason: "file must be a png, jpeg, gif or webp image, a pdf or plain text",
+		}
+	}
+
+	return nil
+}
+
+// IsImage returns whether the blob is an image.
+func (b Blob) IsImage() bool {
+	return strings.HasPrefix(b.ContentType, "image/")
+}
+
+// Key returns the key the content of the blob is stored under.
+func (b Blob) Key() string {
+	return b.Sha256
+}
+
+// ThumbnailKey returns the key the thumbnail of the blob is stored under,
+// alongside its content.
+func (b Blob) ThumbnailKey() string {
+	return b.Sha256 + ".thumb"
+}
+
+// SanitizeFilename returns the base name of the filename given by a client,
+// without control characters and shortened to the maximum length. Empty names
+// are replaced with "file".
+func SanitizeFilename(filename string) string {
+	filename = strings.ReplaceAll(filename, `\`, "/")
+	filename = path.Base(filename)
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+
+	if filename == "" || filename == "." || filename == "/" || filename == ".." {
+		return "file"
+	}
+	return truncate(filename, attachmentMaxFilenameLength)
+}
+
+// InvalidAttachmentParamsError is returned when an attachment is created with
+// invalid parameters.
+type InvalidAttachmentParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidAttachmentParamsError) Error() string {
+	return "invalid attachment params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidAttachmentParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidAttachmentParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
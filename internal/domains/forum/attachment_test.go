@@ -0,0 +1,69 @@
+package forum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestBlob_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		data := []string{"image/png", "image/jpeg", "application/pdf", "text/plain; charset=utf-8"}
+
+		for _, contentType := range data {
+			t.Run(contentType, func(t *testing.T) {
+				t.Parallel()
+
+				test.NilErr(t, Blob{ContentType: contentType, Size: 1024}.Validate())
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name string
+			blob Blob
+		}{
+			{name: "empty", blob: Blob{ContentType: "image/png", Size: 0}},
+			{name: "too large", blob: Blob{ContentType: "image/png", Size: AttachmentMaxSize + 1}},
+			{name: "html", blob: Blob{ContentType: "text/html; charset=utf-8", Size: 1024}},
+			{name: "svg", blob: Blob{ContentType: "image/svg+xml", Size: 1024}},
+			{name: "unknown", blob: Blob{ContentType: "application/octet-stream", Size: 1024}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.blob.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	data := []struct {
+		filename string
+		expected string
+	}{
+		{filename: "photo.png", expected: "photo.png"},
+		{filename: "../../etc/passwd", expected: "passwd"},
+		{filename: `C:\Users\me\report.pdf`, expected: "report.pdf"},
+		{filename: "line\nbreak\".txt", expected: "linebreak.txt"},
+		{filename: "", expected: "file"},
+		{filename: "..", expected: "file"},
+		{filename: strings.Repeat("a", 300), expected: strings.Repeat("a", attachmentMaxFilenameLength)},
+	}
+
+	for _, d := range data {
+		t.Run(d.filename, func(t *testing.T) {
+			t.Parallel()
+
+			test.AssertEqual(t, "Filename not as expected", d.expected, SanitizeFilename(d.filename))
+		})
+	}
+}
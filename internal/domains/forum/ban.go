@@ -0,0 +1,94 @@
+package forum
+
+import (
+	"fmt"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+)
+
+const banMaxReasonLength = 512
+
+// Ban represents a user banned from posting and commenting in a community,
+// until it expires or is lifted. The moderator ID is nil once the moderator
+// who issued the ban is purged.
+type Ban struct {
+	CommunityId CommunityId  `json:"community_id"`
+	UserId      auth.UserId  `json:"user_id"`
+	ModeratorId *auth.UserId `json:"moderator_id"`
+	CreatedAt   time.Time    `json:"created_at"`
+
+	BanValue
+}
+
+// Suspension represents a user suspended by an admin from posting and
+// commenting in every community, until it expires or is lifted. The admin ID
+// is nil once the admin who issued the suspension is purged.
+type Suspension struct {
+	UserId    auth.UserId  `json:"user_id"`
+	AdminId   *auth.UserId `json:"admin_id"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	BanValue
+}
+
+// BanValue represents the value of a ban or suspension. The expiry is nil for
+// permanent bans.
+type BanValue struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Validate checks that the ban value is valid. Temporary bans must expire in
+// the future.
+func (v BanValue) Validate() error {
+	if v.Reason == "" {
+		return InvalidBanParamsError{
+			field:  "reason",
+			reason: "reason cannot be empty",
+		}
+	} else if len(v.Reason) > banMaxReasonLength {
+		return InvalidBanParamsError{
+			field:  "reason",
+			reason: fmt.Sprintf("reason must be less than %d characters", banMaxReasonLength),
+		}
+	} else if v.ExpiresAt != nil && !v.ExpiresAt.After(time.Now()) {
+		return InvalidBanParamsError{
+			field:  "expires_at",
+			reason: "expires_at must be in the future",
+		}
+	}
+
+	return nil
+}
+
+// IsPermanent returns whether the ban never expires.
+func (v BanValue) IsPermanent() bool {
+	return v.ExpiresAt == nil
+}
+
+// InvalidBanParamsError is returned when a user is banned or suspended with
+// invalid parameters.
+type InvalidBanParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidBanParamsError) Error() string {
+	return "invalid ban params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidBanParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidBanParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
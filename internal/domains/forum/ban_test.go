@@ -0,0 +1,43 @@
+package forum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestBanValue_Validate(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, BanValue{Reason: "Spam"}.Validate())
+		test.NilErr(t, BanValue{Reason: "Spam", ExpiresAt: &future}.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value BanValue
+		}{
+			{name: "empty reason", value: BanValue{}},
+			{name: "reason too long", value: BanValue{Reason: strings.Repeat("a", banMaxReasonLength+1)}},
+			{name: "expired", value: BanValue{Reason: "Spam", ExpiresAt: &past}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.value.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
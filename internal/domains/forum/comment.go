@@ -3,6 +3,7 @@ package forum
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"greddit/internal/domains/auth"
 	"greddit/internal/domains/shared"
@@ -22,6 +23,7 @@ type Comment struct {
 
 	CommentValue
 	CommentMetadata
+	Removal
 }
 
 // CommentValue represents the value of a comment.
@@ -35,10 +37,12 @@ func (v CommentValue) Validate() error {
 	body = strings.TrimSpace(body)
 	if body == "" {
 		return InvalidCommentParamsError{
+			field:  "body",
 			reason: "body cannot be empty",
 		}
 	} else if len(body) > commentMaxBodyLength {
 		return InvalidCommentParamsError{
+			field:  "body",
 			reason: fmt.Sprintf("body must be less than %d characters", commentMaxBodyLength),
 		}
 	}
@@ -54,8 +58,29 @@ type CommentMetadata struct {
 	ParentId    *CommentId  `json:"parent_id"`
 }
 
+// IsUnreadBy returns whether the comment is new to the user, i.e. written by
+// someone else after the user last saw its post. Nil last seen times mean the
+// post has never been seen. Soft-deleted comments are never unread.
+func (c Comment) IsUnreadBy(userId auth.UserId, lastSeenAt *time.Time) bool {
+	if c.DeletedAt != nil || c.CommenterId == userId {
+		return false
+	}
+
+	return lastSeenAt == nil || c.CreatedAt.After(*lastSeenAt)
+}
+
+// Tombstone returns the comment with its body and commenter removed, standing
+// in for a soft-deleted or removed comment so that replies to it keep their
+// place in the thread.
+func (c Comment) Tombstone() Comment {
+	c.Body = ""
+	c.CommenterId = auth.UserId{}
+	return c
+}
+
 // InvalidCommentParamsError represents an error when creating a comment with invalid parameters.
 type InvalidCommentParamsError struct {
+	field  string
 	reason string
 }
 
@@ -64,6 +89,19 @@ func (e InvalidCommentParamsError) Error() string {
 	return "invalid comment params: " + e.reason
 }
 
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidCommentParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidCommentParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
 // NewComment creates a new comment.
 func NewComment(value CommentValue, metadata CommentMetadata, base shared.Base) (comment *Comment, err error) {
 	err = value.Validate()
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"greddit/internal/domains/auth"
 	"greddit/internal/domains/shared"
 	"greddit/internal/test"
 
@@ -97,3 +98,57 @@ func TestNewComment(t *testing.T) {
 		}
 	})
 }
+
+func TestComment_Tombstone(t *testing.T) {
+	t.Parallel()
+
+	parentId := uuid.New()
+	comment := Comment{
+		CommentValue: CommentValue{
+			Body: "Deleted body",
+		},
+		CommentMetadata: CommentMetadata{
+			Id:          uuid.New(),
+			CommenterId: uuid.New(),
+			PostId:      uuid.New(),
+			ParentId:    &parentId,
+		},
+	}
+
+	tombstone := comment.Tombstone()
+	test.AssertEqual(t, "Expected empty body", "", tombstone.Body)
+	test.AssertEqual(t, "Expected no commenter", auth.UserId{}, tombstone.CommenterId)
+	test.AssertEqual(t, "Expected ID to be kept", comment.Id, tombstone.Id)
+	test.Assert(t, "Expected parent to be kept", tombstone.ParentId == &parentId)
+	test.AssertEqual(t, "Expected original to be unchanged", "Deleted body", comment.Body)
+}
+
+func TestComment_IsUnreadBy(t *testing.T) {
+	t.Parallel()
+
+	reader := uuid.New()
+	createdAt := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	before := createdAt.Add(-time.Minute)
+	after := createdAt.Add(time.Minute)
+	deletedAt := after
+
+	comment := Comment{
+		Base: shared.Base{
+			CreatedAt: createdAt,
+		},
+		CommentMetadata: CommentMetadata{
+			Id:          uuid.New(),
+			CommenterId: uuid.New(),
+		},
+	}
+	own := comment
+	own.CommenterId = reader
+	deleted := comment
+	deleted.DeletedAt = &deletedAt
+
+	test.Assert(t, "Expected unread if never seen", comment.IsUnreadBy(reader, nil))
+	test.Assert(t, "Expected unread if written after last seen", comment.IsUnreadBy(reader, &before))
+	test.Assert(t, "Expected read if written before last seen", !comment.IsUnreadBy(reader, &after))
+	test.Assert(t, "Expected own comments to be read", !own.IsUnreadBy(reader, nil))
+	test.Assert(t, "Expected deleted comments to be read", !deleted.IsUnreadBy(reader, nil))
+}
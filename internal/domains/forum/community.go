@@ -2,10 +2,12 @@ package forum
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"unicode"
 
 	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
 
 	"github.com/google/uuid"
 )
@@ -13,6 +15,10 @@ import (
 const (
 	communityMaxNameLength        = 64
 	communityMaxDescriptionLength = 2048
+
+	communityMaxRules                 = 15
+	communityMaxRuleTitleLength       = 100
+	communityMaxRuleDescriptionLength = 500
 )
 
 type CommunityId = uuid.UUID
@@ -23,6 +29,8 @@ type Community struct {
 
 	CommunityValue
 	CommunityMetadata
+
+	Settings CommunitySettings `json:"settings"`
 }
 
 // CommunityValue represents the value of a community.
@@ -38,10 +46,12 @@ func (v CommunityValue) Validate() error {
 		name = strings.TrimSpace(name)
 		if name == "" {
 			return InvalidCommunityParamsError{
+				field:  "name",
 				reason: "name cannot be empty",
 			}
 		} else if len(name) > communityMaxNameLength {
 			return InvalidCommunityParamsError{
+				field:  "name",
 				reason: fmt.Sprintf("name must be less than %d characters", communityMaxNameLength),
 			}
 		}
@@ -49,6 +59,7 @@ func (v CommunityValue) Validate() error {
 		r := []rune(name)[0]
 		if !unicode.IsLetter(r) {
 			return InvalidCommunityParamsError{
+				field:  "name",
 				reason: "name must start with a letter",
 			}
 		}
@@ -60,6 +71,7 @@ func (v CommunityValue) Validate() error {
 		description = strings.TrimSpace(description)
 		if len(description) > communityMaxDescriptionLength {
 			return InvalidCommunityParamsError{
+				field:  "description",
 				reason: fmt.Sprintf("description must be less than %d characters", communityMaxDescriptionLength),
 			}
 		}
@@ -73,8 +85,163 @@ type CommunityMetadata struct {
 	Id CommunityId `json:"id"`
 }
 
+// CommunityVisibility is who may read and post in a community.
+type CommunityVisibility string
+
+const (
+	// CommunityVisibilityPublic lets anyone read and post.
+	CommunityVisibilityPublic CommunityVisibility = "public"
+	// CommunityVisibilityRestricted lets anyone read, but only members post
+	// and comment.
+	CommunityVisibilityRestricted CommunityVisibility = "restricted"
+	// CommunityVisibilityPrivate lets only members read and post.
+	CommunityVisibilityPrivate CommunityVisibility = "private"
+)
+
+var allowedCommunityVisibilities = set.New[CommunityVisibility](set.WithSlice([]CommunityVisibility{
+	CommunityVisibilityPublic,
+	CommunityVisibilityRestricted,
+	CommunityVisibilityPrivate,
+}))
+
+// CommunityRule represents a rule of a community, which reports and removals
+// may refer to.
+type CommunityRule struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// CommunitySettings represents the settings of a community, managed by its
+// moderators.
+type CommunitySettings struct {
+	Rules            []CommunityRule     `json:"rules"`
+	AllowedPostTypes []PostType          `json:"allowed_post_types"`
+	RequireFlair     bool                `json:"require_flair"`
+	Visibility       CommunityVisibility `json:"visibility"`
+}
+
+// DefaultCommunitySettings returns the settings of new communities, which are
+// public, have no rules and allow every post type.
+func DefaultCommunitySettings() CommunitySettings {
+	return CommunitySettings{
+		Rules:            []CommunityRule{},
+		AllowedPostTypes: []PostType{PostTypeText, PostTypeLink, PostTypeImage, PostTypePoll},
+		Visibility:       CommunityVisibilityPublic,
+	}
+}
+
+// Validate checks that the community settings are valid.
+func (s CommunitySettings) Validate() error {
+	if len(s.Rules) > communityMaxRules {
+		return InvalidCommunityParamsError{
+			field:  "rules",
+			reason: fmt.Sprintf("a community cannot have more than %d rules", communityMaxRules),
+		}
+	}
+	for _, rule := range s.Rules {
+		title := strings.TrimSpace(rule.Title)
+		if title == "" {
+			return InvalidCommunityParamsError{
+				field:  "rules",
+				reason: "rule title cannot be empty",
+			}
+		} else if len(title) > communityMaxRuleTitleLength {
+			return InvalidCommunityParamsError{
+				field:  "rules",
+				reason: fmt.Sprintf("rule title must be less than %d characters", communityMaxRuleTitleLength),
+			}
+		} else if len(rule.Description) > communityMaxRuleDescriptionLength {
+			return InvalidCommunityParamsError{
+				field: "rules",
+				reason: fmt.Sprintf("rule description must be less than %d characters",
+					communityMaxRuleDescriptionLength),
+			}
+		}
+	}
+
+	if len(s.AllowedPostTypes) == 0 {
+		return InvalidCommunityParamsError{
+			field:  "allowed_post_types",
+			reason: "at least one post type must be allowed",
+		}
+	}
+	seen := set.New[PostType]()
+	for _, postType := range s.AllowedPostTypes {
+		if !allowedPostTypes.Contains(postType) {
+			return InvalidCommunityParamsError{
+				field:  "allowed_post_types",
+				reason: "post types must be text, link, image or poll",
+			}
+		} else if seen.Contains(postType) {
+			return InvalidCommunityParamsError{
+				field:  "allowed_post_types",
+				reason: "post types cannot be repeated",
+			}
+		}
+		seen.Add(postType)
+	}
+
+	if !allowedCommunityVisibilities.Contains(s.Visibility) {
+		return InvalidCommunityParamsError{
+			field:  "visibility",
+			reason: "visibility must be one of public, restricted or private",
+		}
+	}
+
+	return nil
+}
+
+// MembersOnlyPosting returns whether only the members and moderators of the
+// community may post and comment in it.
+func (s CommunitySettings) MembersOnlyPosting() bool {
+	return s.Visibility != CommunityVisibilityPublic
+}
+
+// AllowsPostType returns whether posts of the type may be created in the
+// community.
+func (s CommunitySettings) AllowsPostType(postType PostType) bool {
+	return slices.Contains(s.AllowedPostTypes, postType)
+}
+
+// ValidatePost checks that the post may be created in the community.
+func (s CommunitySettings) ValidatePost(value PostValue) error {
+	if !s.AllowsPostType(value.Type) {
+		return InvalidPostParamsError{
+			field:  "type",
+			reason: fmt.Sprintf("%s posts are not allowed in the community", value.Type),
+		}
+	}
+
+	return nil
+}
+
+// ValidateFlair checks that the flair may be carried by a post in the
+// community. The flair must belong to the community, and may only be nil if
+// the community does not require flairs.
+func (c Community) ValidateFlair(flair *Flair) error {
+	if flair == nil {
+		if c.Settings.RequireFlair {
+			return InvalidPostParamsError{
+				field:  "flair_id",
+				reason: "posts in the community require a flair",
+			}
+		}
+		return nil
+	}
+
+	if flair.CommunityId != c.Id {
+		return InvalidPostParamsError{
+			field:  "flair_id",
+			reason: "flair does not belong to the community",
+		}
+	}
+
+	return nil
+}
+
 // InvalidCommunityParamsError represents an error when creating a community with invalid parameters.
 type InvalidCommunityParamsError struct {
+	field  string
 	reason string
 }
 
@@ -83,6 +250,19 @@ func (e InvalidCommunityParamsError) Error() string {
 	return "invalid community params: " + e.reason
 }
 
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidCommunityParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidCommunityParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
 // NewCommunity creates a new community.
 func NewCommunity(value CommunityValue, metadata CommunityMetadata, base shared.Base) (community *Community, err error) {
 	err = value.Validate()
@@ -1,6 +1,8 @@
 package forum
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -127,8 +129,121 @@ func TestNewCommunity(t *testing.T) {
 
 				c, err := NewCommunity(d.value, metadata, base)
 				test.Assert(t, "Expected non-nil error", err != nil)
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
 				test.Assert(t, "Expected community to be nil", c == nil)
 			})
 		}
 	})
 }
+
+func TestCommunitySettings_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, DefaultCommunitySettings().Validate())
+
+		settings := CommunitySettings{
+			Rules:            []CommunityRule{{Title: "Be civil"}},
+			AllowedPostTypes: []PostType{PostTypeText},
+			RequireFlair:     true,
+			Visibility:       CommunityVisibilityPrivate,
+		}
+		test.NilErr(t, settings.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tooManyRules := make([]CommunityRule, communityMaxRules+1)
+		for i := range tooManyRules {
+			tooManyRules[i] = CommunityRule{Title: "Rule"}
+		}
+
+		data := []struct {
+			name   string
+			modify func(s *CommunitySettings)
+			field  string
+		}{
+			{
+				name:   "too many rules",
+				modify: func(s *CommunitySettings) { s.Rules = tooManyRules },
+				field:  "rules",
+			},
+			{
+				name:   "rule without title",
+				modify: func(s *CommunitySettings) { s.Rules = []CommunityRule{{Title: "  "}} },
+				field:  "rules",
+			},
+			{
+				name: "rule title too long",
+				modify: func(s *CommunitySettings) {
+					s.Rules = []CommunityRule{{Title: strings.Repeat("a", communityMaxRuleTitleLength+1)}}
+				},
+				field: "rules",
+			},
+			{
+				name:   "no allowed post types",
+				modify: func(s *CommunitySettings) { s.AllowedPostTypes = nil },
+				field:  "allowed_post_types",
+			},
+			{
+				name:   "repeated post type",
+				modify: func(s *CommunitySettings) { s.AllowedPostTypes = []PostType{PostTypeText, PostTypeText} },
+				field:  "allowed_post_types",
+			},
+			{
+				name:   "unknown post type",
+				modify: func(s *CommunitySettings) { s.AllowedPostTypes = []PostType{"video"} },
+				field:  "allowed_post_types",
+			},
+			{
+				name:   "unknown visibility",
+				modify: func(s *CommunitySettings) { s.Visibility = "hidden" },
+				field:  "visibility",
+			},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				settings := DefaultCommunitySettings()
+				d.modify(&settings)
+
+				err := settings.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+
+				var validationErr shared.ValidationError
+				test.Assert(t, "Expected shared.ValidationError", errors.As(err, &validationErr))
+				test.AssertEqual(t, "Field not as expected", d.field, validationErr.FieldErrors()[0].Field)
+			})
+		}
+	})
+}
+
+func TestCommunitySettings_ValidatePost(t *testing.T) {
+	t.Parallel()
+
+	settings := DefaultCommunitySettings()
+	settings.AllowedPostTypes = []PostType{PostTypeText}
+
+	test.NilErr(t, settings.ValidatePost(PostValue{Type: PostTypeText, Title: "Title"}))
+
+	err := settings.ValidatePost(PostValue{Type: PostTypeLink, Title: "Title"})
+	test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+}
+
+func TestCommunitySettings_MembersOnlyPosting(t *testing.T) {
+	t.Parallel()
+
+	data := map[CommunityVisibility]bool{
+		CommunityVisibilityPublic:     false,
+		CommunityVisibilityRestricted: true,
+		CommunityVisibilityPrivate:    true,
+	}
+
+	for visibility, expected := range data {
+		settings := DefaultCommunitySettings()
+		settings.Visibility = visibility
+		test.AssertEqual(t, "Members only posting not as expected for "+string(visibility),
+			expected, settings.MembersOnlyPosting())
+	}
+}
@@ -0,0 +1,184 @@
+package forum
+
+import (
+	"encoding/json"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/util/set"
+)
+
+// EventId orders events by when they were published. IDs of concurrent
+// transactions may become visible out of order.
+type EventId = int64
+
+// EventKind is the kind of change an event reports.
+type EventKind string
+
+const (
+	// EventKindPostCreated reports a new post, streamed to the subscribers of
+	// its community.
+	EventKindPostCreated EventKind = "post_created"
+	// EventKindCommentCreated reports a new comment, streamed to the users
+	// viewing its post.
+	EventKindCommentCreated EventKind = "comment_created"
+	// EventKindCommentUpdated reports an edited or restored comment,
+	// streamed to the users viewing its post.
+	EventKindCommentUpdated EventKind = "comment_updated"
+	// EventKindCommentDeleted reports a soft-deleted comment as a tombstone,
+	// streamed to the users viewing its post.
+	EventKindCommentDeleted EventKind = "comment_deleted"
+	// EventKindNotification reports a new notification, streamed to its
+	// recipient.
+	EventKindNotification EventKind = "notification"
+	// EventKindPresence reports how many users are viewing a post, streamed
+	// to the users viewing it. Presence events are not stored, and so have
+	// no ID and are never replayed.
+	EventKindPresence EventKind = "presence"
+	// EventKindAccessRevoked reports that a user may no longer see a
+	// community, or that it became private if no recipient is set. It is
+	// never streamed, but closes the streams of the recipient, or every
+	// stream, so that clients reconnect with their access checked again.
+	EventKindAccessRevoked EventKind = "access_revoked"
+)
+
+// Event represents a change streamed to clients in real time.
+type Event struct {
+	Id        EventId   `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	EventValue
+}
+
+// EventValue represents the value of an event. The community, post and
+// recipient IDs decide who receives the event, and the data is the JSON
+// encoded entity which changed.
+type EventValue struct {
+	Kind        EventKind       `json:"kind"`
+	CommunityId *CommunityId    `json:"community_id"`
+	PostId      *PostId         `json:"post_id"`
+	RecipientId *auth.UserId    `json:"recipient_id"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// NewPostCreatedEvent returns the event reporting the new post.
+func NewPostCreatedEvent(post Post) (value EventValue, err error) {
+	data, err := json.Marshal(post)
+	if err != nil {
+		return EventValue{}, err
+	}
+
+	return EventValue{
+		Kind:        EventKindPostCreated,
+		CommunityId: &post.CommunityId,
+		PostId:      &post.Id,
+		Data:        data,
+	}, nil
+}
+
+// NewCommentEvent returns the event of the kind reporting a change to the
+// comment.
+func NewCommentEvent(kind EventKind, comment Comment) (value EventValue, err error) {
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return EventValue{}, err
+	}
+
+	return EventValue{
+		Kind:   kind,
+		PostId: &comment.PostId,
+		Data:   data,
+	}, nil
+}
+
+// NewNotificationEvent returns the event reporting the new notification.
+func NewNotificationEvent(notification Notification) (value EventValue, err error) {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return EventValue{}, err
+	}
+
+	return EventValue{
+		Kind:        EventKindNotification,
+		PostId:      &notification.PostId,
+		RecipientId: &notification.RecipientId,
+		Data:        data,
+	}, nil
+}
+
+// AccessRevocation represents a user who may no longer see a community, or
+// a community which became private if the user ID is nil.
+type AccessRevocation struct {
+	CommunityId CommunityId  `json:"community_id"`
+	UserId      *auth.UserId `json:"user_id"`
+}
+
+// NewAccessRevokedEvent returns the event reporting the revoked access.
+func NewAccessRevokedEvent(revocation AccessRevocation) (value EventValue, err error) {
+	data, err := json.Marshal(revocation)
+	if err != nil {
+		return EventValue{}, err
+	}
+
+	return EventValue{
+		Kind:        EventKindAccessRevoked,
+		CommunityId: &revocation.CommunityId,
+		RecipientId: revocation.UserId,
+		Data:        data,
+	}, nil
+}
+
+// Revokes returns whether the event revokes the access of the user, closing
+// their streams.
+func (e Event) Revokes(userId auth.UserId) bool {
+	return e.Kind == EventKindAccessRevoked && (e.RecipientId == nil || *e.RecipientId == userId)
+}
+
+// Presence represents how many distinct users are viewing a post.
+type Presence struct {
+	PostId  PostId `json:"post_id"`
+	Viewers int    `json:"viewers"`
+}
+
+// NewPresenceEvent returns the event reporting the presence of viewers of a
+// post.
+func NewPresenceEvent(presence Presence) (value EventValue, err error) {
+	data, err := json.Marshal(presence)
+	if err != nil {
+		return EventValue{}, err
+	}
+
+	return EventValue{
+		Kind:   EventKindPresence,
+		PostId: &presence.PostId,
+		Data:   data,
+	}, nil
+}
+
+// IsCommentEventKind returns whether the kind reports a change to a comment.
+func IsCommentEventKind(kind EventKind) bool {
+	return kind == EventKindCommentCreated || kind == EventKindCommentUpdated || kind == EventKindCommentDeleted
+}
+
+// EventFilter selects the events streamed to a client: new posts in the
+// communities, changes to the comments and presence of viewers of the posts,
+// and the notifications of the recipient, if any.
+type EventFilter struct {
+	RecipientId  *auth.UserId
+	CommunityIds set.Set[CommunityId]
+	PostIds      set.Set[PostId]
+}
+
+// Matches returns whether the event is streamed to the client of the filter.
+func (f EventFilter) Matches(event Event) bool {
+	switch {
+	case event.Kind == EventKindPostCreated:
+		return event.CommunityId != nil && f.CommunityIds.Contains(*event.CommunityId)
+	case IsCommentEventKind(event.Kind), event.Kind == EventKindPresence:
+		return event.PostId != nil && f.PostIds.Contains(*event.PostId)
+	case event.Kind == EventKindNotification:
+		return f.RecipientId != nil && event.RecipientId != nil && *f.RecipientId == *event.RecipientId
+	default:
+		return false
+	}
+}
@@ -0,0 +1,167 @@
+package forum
+
+import (
+	"encoding/json"
+	"testing"
+
+	"greddit/internal/test"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+func TestEventFilter_Matches(t *testing.T) {
+	userId := uuid.New()
+	subscribed := Post{PostMetadata: PostMetadata{Id: uuid.New(), CommunityId: uuid.New()}}
+	other := Post{PostMetadata: PostMetadata{Id: uuid.New(), CommunityId: uuid.New()}}
+
+	filter := EventFilter{
+		RecipientId:  &userId,
+		CommunityIds: set.New[CommunityId](set.WithSlice([]CommunityId{subscribed.CommunityId})),
+		PostIds:      set.New[PostId](set.WithSlice([]PostId{other.Id})),
+	}
+
+	comment := func(post Post) Comment {
+		return Comment{CommentMetadata: CommentMetadata{Id: uuid.New(), PostId: post.Id}}
+	}
+
+	event := func(value EventValue, err error) Event {
+		test.NilErr(t, err)
+		return Event{EventValue: value}
+	}
+
+	data := []struct {
+		name     string
+		event    Event
+		expected bool
+	}{
+		{
+			name:     "post in subscribed community",
+			event:    event(NewPostCreatedEvent(subscribed)),
+			expected: true,
+		},
+		{
+			name:     "post in other community",
+			event:    event(NewPostCreatedEvent(other)),
+			expected: false,
+		},
+		{
+			name:     "comment on open post",
+			event:    event(NewCommentEvent(EventKindCommentCreated, comment(other))),
+			expected: true,
+		},
+		{
+			name:     "deleted comment on open post",
+			event:    event(NewCommentEvent(EventKindCommentDeleted, comment(other).Tombstone())),
+			expected: true,
+		},
+		{
+			name:     "comment on other post",
+			event:    event(NewCommentEvent(EventKindCommentUpdated, comment(subscribed))),
+			expected: false,
+		},
+		{
+			name:     "presence on open post",
+			event:    event(NewPresenceEvent(Presence{PostId: other.Id, Viewers: 3})),
+			expected: true,
+		},
+		{
+			name: "own notification",
+			event: event(NewNotificationEvent(Notification{
+				NotificationValue: NotificationValue{RecipientId: userId},
+			})),
+			expected: true,
+		},
+		{
+			name: "notification of other user",
+			event: event(NewNotificationEvent(Notification{
+				NotificationValue: NotificationValue{RecipientId: uuid.New()},
+			})),
+			expected: false,
+		},
+		{
+			name: "revoked access to subscribed community",
+			event: event(NewAccessRevokedEvent(AccessRevocation{
+				CommunityId: subscribed.CommunityId,
+				UserId:      &userId,
+			})),
+			expected: false,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.AssertEqual(t, "Unexpected match", d.expected, filter.Matches(d.event))
+		})
+	}
+
+	t.Run("data is the entity", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := NewPostCreatedEvent(subscribed)
+		test.NilErr(t, err)
+
+		var got Post
+		test.NilErr(t, json.Unmarshal(value.Data, &got))
+		test.AssertEqual(t, "Unexpected post", subscribed.Id, got.Id)
+	})
+
+	t.Run("filters without a recipient match no notifications", func(t *testing.T) {
+		t.Parallel()
+
+		notification := event(NewNotificationEvent(Notification{
+			NotificationValue: NotificationValue{RecipientId: userId},
+		}))
+		test.Assert(t, "Unexpected match", !EventFilter{}.Matches(notification))
+	})
+}
+
+func TestEvent_Revokes(t *testing.T) {
+	userId := uuid.New()
+	otherId := uuid.New()
+	communityId := uuid.New()
+
+	event := func(value EventValue, err error) Event {
+		test.NilErr(t, err)
+		return Event{EventValue: value}
+	}
+
+	data := []struct {
+		name     string
+		event    Event
+		expected bool
+	}{
+		{
+			name:     "access of user revoked",
+			event:    event(NewAccessRevokedEvent(AccessRevocation{CommunityId: communityId, UserId: &userId})),
+			expected: true,
+		},
+		{
+			name:     "access of other user revoked",
+			event:    event(NewAccessRevokedEvent(AccessRevocation{CommunityId: communityId, UserId: &otherId})),
+			expected: false,
+		},
+		{
+			name:     "community became private",
+			event:    event(NewAccessRevokedEvent(AccessRevocation{CommunityId: communityId})),
+			expected: true,
+		},
+		{
+			name: "notification of user",
+			event: event(NewNotificationEvent(Notification{
+				NotificationValue: NotificationValue{RecipientId: userId},
+			})),
+			expected: false,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.AssertEqual(t, "Unexpected revocation", d.expected, d.event.Revokes(userId))
+		})
+	}
+}
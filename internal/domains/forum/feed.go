@@ -0,0 +1,139 @@
+package forum
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+// Subscription represents a user following a community, so that its posts
+// appear in the home feed of the user.
+type Subscription struct {
+	UserId      auth.UserId `json:"user_id"`
+	CommunityId CommunityId `json:"community_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// FeedSort is the order of the posts in a feed.
+type FeedSort string
+
+const (
+	// FeedSortNew orders posts newest first.
+	FeedSortNew FeedSort = "new"
+	// FeedSortOld orders posts oldest first.
+	FeedSortOld FeedSort = "old"
+	// FeedSortActive orders posts most recently updated first.
+	FeedSortActive FeedSort = "active"
+)
+
+var allowedFeedSorts = set.New[FeedSort](set.WithSlice([]FeedSort{
+	FeedSortNew,
+	FeedSortOld,
+	FeedSortActive,
+}))
+
+// Validate checks that the sort is valid.
+func (s FeedSort) Validate() error {
+	if !allowedFeedSorts.Contains(s) {
+		return InvalidFeedParamsError{
+			field:  "sort",
+			reason: "sort must be one of new, old or active",
+		}
+	}
+	return nil
+}
+
+// Ascending returns whether the sort orders posts oldest first.
+func (s FeedSort) Ascending() bool {
+	return s == FeedSortOld
+}
+
+// CursorOf returns the cursor positioned after the post.
+func (s FeedSort) CursorOf(post Post) FeedCursor {
+	at := post.CreatedAt
+	if s == FeedSortActive {
+		at = post.UpdatedAt
+	}
+
+	return FeedCursor{
+		At: at,
+		Id: post.Id,
+	}
+}
+
+// FeedCursor marks the position after the last post of a page of a feed, by
+// the sorted timestamp and ID of the post. The ID breaks ties between posts
+// with the same timestamp.
+type FeedCursor struct {
+	At time.Time
+	Id PostId
+}
+
+// String encodes the cursor as an opaque token for clients.
+func (c FeedCursor) String() string {
+	raw := strconv.FormatInt(c.At.UnixMicro(), 10) + ":" + c.Id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseFeedCursor decodes a cursor token created by FeedCursor.String.
+func ParseFeedCursor(token string) (cursor FeedCursor, err error) {
+	invalid := InvalidFeedParamsError{
+		field:  "cursor",
+		reason: "cursor is malformed",
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return FeedCursor{}, invalid
+	}
+
+	at, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return FeedCursor{}, invalid
+	}
+
+	micros, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return FeedCursor{}, invalid
+	}
+
+	cursor.Id, err = uuid.Parse(id)
+	if err != nil {
+		return FeedCursor{}, invalid
+	}
+	cursor.At = time.UnixMicro(micros).UTC()
+
+	return cursor, nil
+}
+
+// InvalidFeedParamsError is returned when a feed is requested with invalid
+// parameters.
+type InvalidFeedParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidFeedParamsError) Error() string {
+	return "invalid feed params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidFeedParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidFeedParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
@@ -0,0 +1,85 @@
+package forum
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestFeedSort(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, sort := range []FeedSort{FeedSortNew, FeedSortOld, FeedSortActive} {
+			test.NilErr(t, sort.Validate())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		err := FeedSort("top").Validate()
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+
+	t.Run("cursor uses the sorted timestamp", func(t *testing.T) {
+		t.Parallel()
+
+		createdAt := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+		updatedAt := createdAt.Add(time.Hour)
+		post := Post{
+			Base: shared.Base{
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+			},
+			PostMetadata: PostMetadata{
+				Id: uuid.New(),
+			},
+		}
+
+		test.AssertEqual(t, "New cursor not as expected", createdAt, FeedSortNew.CursorOf(post).At)
+		test.AssertEqual(t, "Active cursor not as expected", updatedAt, FeedSortActive.CursorOf(post).At)
+		test.AssertEqual(t, "Cursor ID not as expected", post.Id, FeedSortOld.CursorOf(post).Id)
+	})
+}
+
+func TestParseFeedCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		cursor := FeedCursor{
+			At: time.Date(2025, 10, 10, 12, 0, 0, 123456000, time.UTC),
+			Id: uuid.New(),
+		}
+
+		parsed, err := ParseFeedCursor(cursor.String())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Cursor not as expected", cursor, parsed)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		data := []struct {
+			name  string
+			token string
+		}{
+			{name: "not base64", token: "!!!"},
+			{name: "missing separator", token: "MTIz"},
+			{name: "invalid timestamp", token: "YWJjOjEyMw"},
+			{name: "invalid id", token: "MTIzOmFiYw"},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := ParseFeedCursor(d.token)
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package forum
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"greddit/internal/domains/shared"
+
+	"github.com/google/uuid"
+)
+
+const (
+	flairMaxTextLength = 32
+
+	tagMaxLength = 32
+	postMaxTags  = 10
+)
+
+type FlairId = uuid.UUID
+
+// Flair represents a label defined by the moderators of a community, which
+// posts in the community may carry.
+type Flair struct {
+	Id          FlairId     `json:"id"`
+	CommunityId CommunityId `json:"community_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+
+	FlairValue
+}
+
+// FlairValue represents the value of a flair. The color is a hex color of the
+// form #rrggbb.
+type FlairValue struct {
+	Text  string `json:"text"`
+	Color string `json:"color"`
+}
+
+// Validate checks that the flair value is valid.
+func (v FlairValue) Validate() error {
+	text := v.Text
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return InvalidFlairParamsError{
+			field:  "text",
+			reason: "text cannot be empty",
+		}
+	} else if len(text) > flairMaxTextLength {
+		return InvalidFlairParamsError{
+			field:  "text",
+			reason: fmt.Sprintf("text must be less than %d characters", flairMaxTextLength),
+		}
+	}
+
+	if !isHexColor(v.Color) {
+		return InvalidFlairParamsError{
+			field:  "color",
+			reason: "color must be a hex color such as #ff4500",
+		}
+	}
+
+	return nil
+}
+
+// isHexColor returns whether the string is a hex color of the form #rrggbb.
+func isHexColor(color string) bool {
+	hex, ok := strings.CutPrefix(color, "#")
+	if !ok || len(hex) != 6 {
+		return false
+	}
+
+	for _, r := range hex {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InvalidFlairParamsError is returned when a flair is created with invalid
+// parameters.
+type InvalidFlairParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidFlairParamsError) Error() string {
+	return "invalid flair params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidFlairParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidFlairParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
+// Tag represents a free-form label shared by posts across communities, along
+// with the number of posts carrying it.
+type Tag struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"`
+}
+
+// NormalizeTag returns the canonical form of a tag, which is lower case with
+// runs of spaces replaced by dashes. Tags may only contain letters, digits and
+// the characters -_.+#.
+func NormalizeTag(tag string) (normalized string, err error) {
+	normalized = strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+	if normalized == "" {
+		return "", InvalidTagParamsError{
+			reason: "tag cannot be empty",
+		}
+	} else if len(normalized) > tagMaxLength {
+		return "", InvalidTagParamsError{
+			reason: fmt.Sprintf("tag must be less than %d characters", tagMaxLength),
+		}
+	}
+
+	for _, r := range normalized {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.+#", r) {
+			return "", InvalidTagParamsError{
+				reason: "tag may only contain letters, digits and -_.+#",
+			}
+		}
+	}
+
+	return normalized, nil
+}
+
+// NormalizeTags returns the distinct canonical forms of the tags, in the order
+// they first appear. See NormalizeTag.
+func NormalizeTags(tags []string) (normalized []string, err error) {
+	normalized = make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err = NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) > postMaxTags {
+		return nil, InvalidTagParamsError{
+			reason: fmt.Sprintf("a post cannot have more than %d tags", postMaxTags),
+		}
+	}
+
+	return normalized, nil
+}
+
+// InvalidTagParamsError is returned when a tag is invalid.
+type InvalidTagParamsError struct {
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidTagParamsError) Error() string {
+	return "invalid tag params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidTagParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidTagParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  "tags",
+		Reason: e.reason,
+	}}
+}
+
+// PostLabels represents the flair and tags of a post, which organize the posts
+// of a community. The flair must belong to the community of the post.
+type PostLabels struct {
+	FlairId *FlairId `json:"flair_id"`
+	Tags    []string `json:"tags"`
+}
+
+// Normalize returns the labels with their tags in canonical form, see
+// NormalizeTags.
+func (l PostLabels) Normalize() (labels PostLabels, err error) {
+	tags, err := NormalizeTags(l.Tags)
+	if err != nil {
+		return PostLabels{}, err
+	}
+
+	return PostLabels{
+		FlairId: l.FlairId,
+		Tags:    tags,
+	}, nil
+}
+
+// PostFilter narrows down listings of posts to those carrying a tag or a
+// flair. Zero fields match all posts.
+type PostFilter struct {
+	Tag     string   `json:"tag"`
+	FlairId *FlairId `json:"flair_id"`
+}
+
+// Normalize returns the filter with its tag in canonical form, see
+// NormalizeTag.
+func (f PostFilter) Normalize() (filter PostFilter, err error) {
+	filter = f
+	if filter.Tag != "" {
+		filter.Tag, err = NormalizeTag(filter.Tag)
+		if err != nil {
+			return PostFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,165 @@
+package forum
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestFlairValue_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, FlairValue{Text: "Question", Color: "#ff4500"}.Validate())
+		test.NilErr(t, FlairValue{Text: "Answered", Color: "#00AA00"}.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value FlairValue
+		}{
+			{name: "empty text", value: FlairValue{Text: " ", Color: "#ff4500"}},
+			{name: "text too long", value: FlairValue{Text: strings.Repeat("a", flairMaxTextLength+1), Color: "#ff4500"}},
+			{name: "empty color", value: FlairValue{Text: "Question"}},
+			{name: "color without hash", value: FlairValue{Text: "Question", Color: "ff4500"}},
+			{name: "short color", value: FlairValue{Text: "Question", Color: "#f40"}},
+			{name: "color not hex", value: FlairValue{Text: "Question", Color: "#gg4500"}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.value.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestNormalizeTag(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		data := []struct {
+			tag      string
+			expected string
+		}{
+			{tag: "golang", expected: "golang"},
+			{tag: "  GoLang ", expected: "golang"},
+			{tag: "error  handling", expected: "error-handling"},
+			{tag: "c++", expected: "c++"},
+			{tag: "c#", expected: "c#"},
+			{tag: "go1.25", expected: "go1.25"},
+		}
+
+		for _, d := range data {
+			t.Run(d.tag, func(t *testing.T) {
+				t.Parallel()
+
+				got, err := NormalizeTag(d.tag)
+				test.NilErr(t, err)
+				test.AssertEqual(t, "Normalized tag not as expected", d.expected, got)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name string
+			tag  string
+		}{
+			{name: "empty", tag: ""},
+			{name: "only spaces", tag: "   "},
+			{name: "too long", tag: strings.Repeat("a", tagMaxLength+1)},
+			{name: "punctuation", tag: "what?"},
+			{name: "wildcard", tag: "go%"},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := NormalizeTag(d.tag)
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("removes duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := NormalizeTags([]string{"Go", "databases", "go", " GO "})
+		test.NilErr(t, err)
+		test.Assert(t, "Tags not as expected", slices.Equal([]string{"go", "databases"}, got))
+	})
+
+	t.Run("too many tags", func(t *testing.T) {
+		t.Parallel()
+
+		tags := make([]string, 0, postMaxTags+1)
+		for i := range postMaxTags + 1 {
+			tags = append(tags, strings.Repeat("a", i+1))
+		}
+
+		_, err := NormalizeTags(tags)
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+}
+
+func TestPostFilter_Normalize(t *testing.T) {
+	t.Parallel()
+
+	filter, err := PostFilter{}.Normalize()
+	test.NilErr(t, err)
+	test.AssertEqual(t, "Empty filter should match all posts", "", filter.Tag)
+
+	filter, err = PostFilter{Tag: "Error Handling"}.Normalize()
+	test.NilErr(t, err)
+	test.AssertEqual(t, "Tag not as expected", "error-handling", filter.Tag)
+
+	_, err = PostFilter{Tag: "what?"}.Normalize()
+	test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+}
+
+func TestCommunity_ValidateFlair(t *testing.T) {
+	community := Community{
+		CommunityMetadata: CommunityMetadata{Id: uuid.New()},
+		Settings:          DefaultCommunitySettings(),
+	}
+	own := &Flair{Id: uuid.New(), CommunityId: community.Id}
+	other := &Flair{Id: uuid.New(), CommunityId: uuid.New()}
+
+	t.Run("optional flair", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, community.ValidateFlair(nil))
+		test.NilErr(t, community.ValidateFlair(own))
+	})
+
+	t.Run("required flair", func(t *testing.T) {
+		t.Parallel()
+
+		required := community
+		required.Settings.RequireFlair = true
+
+		test.NilErr(t, required.ValidateFlair(own))
+
+		err := required.ValidateFlair(nil)
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+
+	t.Run("flair of another community", func(t *testing.T) {
+		t.Parallel()
+
+		err := community.ValidateFlair(other)
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+}
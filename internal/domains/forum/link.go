@@ -0,0 +1,104 @@
+package forum
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	linkMaxTargetLength = 255
+)
+
+// wikiLinkPattern matches wiki-style links, i.e. [[Post Title]] or
+// [[community/Post Title]].
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// WikiLink represents a wiki-style link to a post by its title, optionally
+// qualified by the name of the community of the post.
+type WikiLink struct {
+	Community string `json:"community"`
+	Title     string `json:"title"`
+}
+
+// NewWikiLink parses the text between the brackets of a wiki-style link. The
+// text before the first slash is taken as the community name. Returns false if
+// the text is not a valid link.
+func NewWikiLink(text string) (link WikiLink, ok bool) {
+	text = strings.TrimSpace(text)
+
+	community, title, found := strings.Cut(text, "/")
+	if found {
+		link = WikiLink{
+			Community: strings.TrimSpace(community),
+			Title:     strings.TrimSpace(title),
+		}
+	} else {
+		link = WikiLink{
+			Title: text,
+		}
+	}
+
+	if link.Title == "" || (found && link.Community == "") {
+		return WikiLink{}, false
+	} else if len(link.Title) > linkMaxTargetLength || len(link.Community) > linkMaxTargetLength {
+		return WikiLink{}, false
+	}
+
+	return link, true
+}
+
+// Key returns the case-insensitive key identifying the target of the link.
+func (l WikiLink) Key() string {
+	return strings.ToLower(l.Community) + "/" + strings.ToLower(l.Title)
+}
+
+// String returns the link as written in a body.
+func (l WikiLink) String() string {
+	if l.Community == "" {
+		return l.Title
+	}
+	return l.Community + "/" + l.Title
+}
+
+// ParseWikiLinks returns the distinct wiki-style links within the body, in
+// order of first appearance.
+func ParseWikiLinks(body string) (links []WikiLink) {
+	seen := map[string]struct{}{}
+
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(body, -1) {
+		link, ok := NewWikiLink(match[1])
+		if !ok {
+			continue
+		}
+
+		key := link.Key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		links = append(links, link)
+	}
+
+	return links
+}
+
+// Link represents a stored wiki-style link from the body of a post or comment.
+// The target post is nil when the link is dangling.
+type Link struct {
+	WikiLink
+
+	SourcePostId    PostId     `json:"source_post_id"`
+	SourceCommentId *CommentId `json:"source_comment_id"`
+	TargetPostId    *PostId    `json:"target_post_id"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Backlink represents a link to a post, along with the title of the post the
+// link is from.
+type Backlink struct {
+	Link
+
+	SourceTitle string `json:"source_title"`
+}
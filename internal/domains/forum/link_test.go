@@ -0,0 +1,90 @@
+package forum
+
+import (
+	"strings"
+	"testing"
+
+	"greddit/internal/test"
+)
+
+func TestParseWikiLinks(t *testing.T) {
+	data := []struct {
+		name     string
+		body     string
+		expected []WikiLink
+	}{
+		{
+			name:     "no links",
+			body:     "Just a plain body with [a markdown](link)",
+			expected: nil,
+		},
+		{
+			name: "title link",
+			body: "See [[Post Title]] for details",
+			expected: []WikiLink{
+				{Title: "Post Title"},
+			},
+		},
+		{
+			name: "community link",
+			body: "See [[golang/Why Go is awesome]]",
+			expected: []WikiLink{
+				{Community: "golang", Title: "Why Go is awesome"},
+			},
+		},
+		{
+			name: "slashes after the community are part of the title",
+			body: "[[golang/Input/Output]]",
+			expected: []WikiLink{
+				{Community: "golang", Title: "Input/Output"},
+			},
+		},
+		{
+			name: "whitespace is trimmed",
+			body: "[[  golang / Spaced  ]]",
+			expected: []WikiLink{
+				{Community: "golang", Title: "Spaced"},
+			},
+		},
+		{
+			name: "duplicates are removed case-insensitively",
+			body: "[[First]] [[Second]] [[first]] [[FIRST]]",
+			expected: []WikiLink{
+				{Title: "First"},
+				{Title: "Second"},
+			},
+		},
+		{
+			name:     "empty and invalid links are ignored",
+			body:     "[[]] [[   ]] [[/title]] [[community/]] [[multi\nline]]",
+			expected: nil,
+		},
+		{
+			name:     "title too long",
+			body:     "[[" + strings.Repeat("a", linkMaxTargetLength+1) + "]]",
+			expected: nil,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ParseWikiLinks(d.body)
+			test.AssertEqual(t, "Links not as expected", d.expected, got)
+		})
+	}
+}
+
+func TestWikiLink_Key(t *testing.T) {
+	t.Parallel()
+
+	a := WikiLink{Community: "Golang", Title: "Post"}
+	b := WikiLink{Community: "golang", Title: "POST"}
+	c := WikiLink{Title: "post"}
+
+	test.AssertEqual(t, "Expected keys to match", a.Key(), b.Key())
+	test.Assert(t, "Expected keys to differ", a.Key() != c.Key())
+	test.AssertEqual(t, "Unexpected string", "Golang/Post", a.String())
+	test.AssertEqual(t, "Unexpected string", "post", c.String())
+}
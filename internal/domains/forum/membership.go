@@ -0,0 +1,24 @@
+package forum
+
+import (
+	"time"
+
+	"greddit/internal/domains/auth"
+)
+
+// Member represents a user who is a member of a community. Only members and
+// moderators may read private communities and post in restricted ones.
+type Member struct {
+	CommunityId CommunityId `json:"community_id"`
+	UserId      auth.UserId `json:"user_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// Invite represents a pending invitation of a user to become a member of a
+// community.
+type Invite struct {
+	CommunityId CommunityId `json:"community_id"`
+	InviteeId   auth.UserId `json:"invitee_id"`
+	InviterId   auth.UserId `json:"inviter_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
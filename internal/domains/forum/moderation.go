@@ -0,0 +1,182 @@
+package forum
+
+import (
+	"fmt"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+const (
+	moderationMaxReasonLength = 512
+
+	// MaxPinnedPosts is the maximum number of posts pinned in a community at
+	// once.
+	MaxPinnedPosts = 2
+)
+
+// ModeratorRole is the role of a moderator within a community, distinct from
+// the global auth.Role.
+type ModeratorRole string
+
+const (
+	// ModeratorRoleOwner moderates the community and manages its moderators.
+	// A community has at most one owner.
+	ModeratorRoleOwner ModeratorRole = "owner"
+	// ModeratorRoleModerator moderates the posts and comments of the
+	// community.
+	ModeratorRoleModerator ModeratorRole = "moderator"
+)
+
+var allowedModeratorRoles = set.New[ModeratorRole](set.WithSlice([]ModeratorRole{
+	ModeratorRoleOwner,
+	ModeratorRoleModerator,
+}))
+
+// Validate checks that the role is valid.
+func (r ModeratorRole) Validate() error {
+	if !allowedModeratorRoles.Contains(r) {
+		return InvalidModerationParamsError{
+			field:  "role",
+			reason: "role must be either owner or moderator",
+		}
+	}
+	return nil
+}
+
+// Moderator represents a user moderating a community.
+type Moderator struct {
+	CommunityId CommunityId   `json:"community_id"`
+	UserId      auth.UserId   `json:"user_id"`
+	Role        ModeratorRole `json:"role"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Removal represents the removal of a post or comment by a moderator.
+// Removed content stays in place, but is hidden from users who cannot
+// moderate it.
+type Removal struct {
+	RemovedAt     *time.Time `json:"removed_at"`
+	RemovalReason *string    `json:"removal_reason"`
+}
+
+// IsRemoved returns whether the content has been removed.
+func (r Removal) IsRemoved() bool {
+	return r.RemovedAt != nil
+}
+
+// ModerationAction is an action taken by a moderator, as recorded in the
+// moderation log.
+type ModerationAction string
+
+const (
+	ModerationActionRemovePost        ModerationAction = "remove_post"
+	ModerationActionApprovePost       ModerationAction = "approve_post"
+	ModerationActionRemoveComment     ModerationAction = "remove_comment"
+	ModerationActionApproveComment    ModerationAction = "approve_comment"
+	ModerationActionLockPost          ModerationAction = "lock_post"
+	ModerationActionUnlockPost        ModerationAction = "unlock_post"
+	ModerationActionPinPost           ModerationAction = "pin_post"
+	ModerationActionUnpinPost         ModerationAction = "unpin_post"
+	ModerationActionAddModerator      ModerationAction = "add_moderator"
+	ModerationActionRemoveModerator   ModerationAction = "remove_moderator"
+	ModerationActionTransferOwnership ModerationAction = "transfer_ownership"
+	ModerationActionDismissReports    ModerationAction = "dismiss_reports"
+	ModerationActionBanUser           ModerationAction = "ban_user"
+	ModerationActionUnbanUser         ModerationAction = "unban_user"
+	ModerationActionUpdateSettings    ModerationAction = "update_settings"
+	ModerationActionInviteMember      ModerationAction = "invite_member"
+	ModerationActionRevokeInvite      ModerationAction = "revoke_invite"
+	ModerationActionRemoveMember      ModerationAction = "remove_member"
+	ModerationActionUpdateFlairs      ModerationAction = "update_flairs"
+)
+
+// RequiresReason returns whether the action must be justified with a reason.
+func (a ModerationAction) RequiresReason() bool {
+	return a == ModerationActionRemovePost || a == ModerationActionRemoveComment || a == ModerationActionBanUser
+}
+
+type ModerationLogEntryId = uuid.UUID
+
+// ModerationLogEntry represents an action taken by a moderator in a
+// community.
+type ModerationLogEntry struct {
+	Id        ModerationLogEntryId `json:"id"`
+	CreatedAt time.Time            `json:"created_at"`
+
+	ModerationLogValue
+}
+
+// ModerationLogValue represents the value of a moderation log entry, i.e.
+// which moderator took which action on which post, comment or user, and why.
+// The target user is the author of moderated posts and comments. Entries
+// outlive what they refer to, whose IDs are nil once purged.
+type ModerationLogValue struct {
+	CommunityId  CommunityId      `json:"community_id"`
+	ModeratorId  *auth.UserId     `json:"moderator_id"`
+	Action       ModerationAction `json:"action"`
+	PostId       *PostId          `json:"post_id"`
+	CommentId    *CommentId       `json:"comment_id"`
+	TargetUserId *auth.UserId     `json:"target_user_id"`
+	Reason       string           `json:"reason"`
+}
+
+// ValidateModerationReason checks that the reason for the action is valid.
+func ValidateModerationReason(action ModerationAction, reason string) error {
+	if action.RequiresReason() && reason == "" {
+		return InvalidModerationParamsError{
+			field:  "reason",
+			reason: "reason cannot be empty",
+		}
+	} else if len(reason) > moderationMaxReasonLength {
+		return InvalidModerationParamsError{
+			field:  "reason",
+			reason: fmt.Sprintf("reason must be less than %d characters", moderationMaxReasonLength),
+		}
+	}
+
+	return nil
+}
+
+// InvalidModerationParamsError is returned when a moderation action is taken
+// with invalid parameters.
+type InvalidModerationParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidModerationParamsError) Error() string {
+	return "invalid moderation params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidModerationParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidModerationParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
+// PinLimitError is returned when a post is pinned in a community which
+// already has the maximum number of pinned posts.
+type PinLimitError struct{}
+
+// Error implements the error interface.
+func (e PinLimitError) Error() string {
+	return fmt.Sprintf("a community cannot have more than %d pinned posts", MaxPinnedPosts)
+}
+
+// Is reports whether the error matches shared.ErrConflict.
+func (e PinLimitError) Is(target error) bool {
+	return target == shared.ErrConflict
+}
@@ -0,0 +1,63 @@
+package forum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestModeratorRole_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, ModeratorRoleOwner.Validate())
+		test.NilErr(t, ModeratorRoleModerator.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, role := range []ModeratorRole{"", "admin", "Owner"} {
+			err := role.Validate()
+			test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+		}
+	})
+}
+
+func TestValidateModerationReason(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, ValidateModerationReason(ModerationActionRemovePost, "Spam"))
+		test.NilErr(t, ValidateModerationReason(ModerationActionLockPost, ""))
+		test.NilErr(t, ValidateModerationReason(ModerationActionApproveComment, ""))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name   string
+			action ModerationAction
+			reason string
+		}{
+			{name: "removing post without reason", action: ModerationActionRemovePost, reason: ""},
+			{name: "removing comment without reason", action: ModerationActionRemoveComment, reason: ""},
+			{
+				name:   "reason too long",
+				action: ModerationActionPinPost,
+				reason: strings.Repeat("a", moderationMaxReasonLength+1),
+			},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := ValidateModerationReason(d.action, d.reason)
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
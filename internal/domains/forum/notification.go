@@ -0,0 +1,136 @@
+package forum
+
+import (
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"greddit/internal/domains/auth"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// mentionsMaxPerComment is the maximum number of distinct users notified
+	// of mentions in a single comment.
+	mentionsMaxPerComment = 10
+
+	mentionMinLength = 3
+	mentionMaxLength = 32
+)
+
+// mentionPattern matches @username mentions which are not part of a longer
+// word, such as an email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}@])@([\p{L}\p{N}]+)`)
+
+type NotificationId = uuid.UUID
+
+// NotificationKind is the event a notification informs about.
+type NotificationKind string
+
+const (
+	// NotificationKindPostReply is a top level comment on a post of the
+	// recipient.
+	NotificationKindPostReply NotificationKind = "post_reply"
+	// NotificationKindCommentReply is a reply to a comment of the recipient.
+	NotificationKindCommentReply NotificationKind = "comment_reply"
+	// NotificationKindMention is a comment mentioning the recipient.
+	NotificationKindMention NotificationKind = "mention"
+)
+
+// Notification represents an event in the inbox of a user.
+type Notification struct {
+	Id        NotificationId `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	ReadAt    *time.Time     `json:"read_at"`
+
+	NotificationValue
+}
+
+// NotificationValue represents the value of a notification, i.e. who caused
+// which event on which comment.
+type NotificationValue struct {
+	RecipientId auth.UserId      `json:"recipient_id"`
+	ActorId     auth.UserId      `json:"actor_id"`
+	Kind        NotificationKind `json:"kind"`
+	PostId      PostId           `json:"post_id"`
+	CommentId   CommentId        `json:"comment_id"`
+}
+
+// NotificationPreferences represents which kinds of notifications a user
+// receives.
+type NotificationPreferences struct {
+	PostReplies    bool `json:"post_replies"`
+	CommentReplies bool `json:"comment_replies"`
+	Mentions       bool `json:"mentions"`
+}
+
+// DefaultNotificationPreferences returns the preferences of users who have
+// not changed them, receiving every kind of notification.
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		PostReplies:    true,
+		CommentReplies: true,
+		Mentions:       true,
+	}
+}
+
+// NotificationPreferencesFromMuted returns the preferences receiving every
+// kind of notification except the muted ones.
+func NotificationPreferencesFromMuted(muted []NotificationKind) NotificationPreferences {
+	p := DefaultNotificationPreferences()
+	for _, kind := range muted {
+		switch kind {
+		case NotificationKindPostReply:
+			p.PostReplies = false
+		case NotificationKindCommentReply:
+			p.CommentReplies = false
+		case NotificationKindMention:
+			p.Mentions = false
+		}
+	}
+	return p
+}
+
+// MutedKinds returns the kinds of notifications the preferences do not
+// receive.
+func (p NotificationPreferences) MutedKinds() []NotificationKind {
+	muted := []NotificationKind{}
+	if !p.PostReplies {
+		muted = append(muted, NotificationKindPostReply)
+	}
+	if !p.CommentReplies {
+		muted = append(muted, NotificationKindCommentReply)
+	}
+	if !p.Mentions {
+		muted = append(muted, NotificationKindMention)
+	}
+	return muted
+}
+
+// ParseMentions returns the distinct usernames mentioned in the body in order
+// of appearance, up to mentionsMaxPerComment. Mentions which cannot be valid
+// usernames are ignored.
+func ParseMentions(body string) []string {
+	usernames := []string{}
+	seen := make(map[string]struct{})
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := match[1]
+		length := utf8.RuneCountInString(username)
+		if length < mentionMinLength || length > mentionMaxLength {
+			continue
+		}
+		if _, ok := seen[username]; ok {
+			continue
+		}
+
+		seen[username] = struct{}{}
+		usernames = append(usernames, username)
+		if len(usernames) == mentionsMaxPerComment {
+			break
+		}
+	}
+
+	return usernames
+}
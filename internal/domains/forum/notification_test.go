@@ -0,0 +1,99 @@
+package forum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"greddit/internal/test"
+)
+
+func TestParseMentions(t *testing.T) {
+	data := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "no mentions",
+			body:     "Just a plain body",
+			expected: []string{},
+		},
+		{
+			name:     "single mention",
+			body:     "Thanks @alice for the tip",
+			expected: []string{"alice"},
+		},
+		{
+			name:     "mention at the start",
+			body:     "@bob123: agreed",
+			expected: []string{"bob123"},
+		},
+		{
+			name:     "duplicates are removed",
+			body:     "@alice and @carol, cc @alice",
+			expected: []string{"alice", "carol"},
+		},
+		{
+			name:     "email addresses are not mentions",
+			body:     "Mail me at me@example.com",
+			expected: []string{},
+		},
+		{
+			name:     "double at signs are not mentions",
+			body:     "@@alice",
+			expected: []string{},
+		},
+		{
+			name:     "too short",
+			body:     "@al",
+			expected: []string{},
+		},
+		{
+			name:     "too long",
+			body:     "@" + strings.Repeat("a", mentionMaxLength+1),
+			expected: []string{},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.AssertEqual(t, "Unexpected mentions", d.expected, ParseMentions(d.body))
+		})
+	}
+
+	t.Run("capped per comment", func(t *testing.T) {
+		t.Parallel()
+
+		body := ""
+		for i := range mentionsMaxPerComment + 5 {
+			body += fmt.Sprintf("@user%d ", i)
+		}
+
+		test.AssertEqual(t, "Unexpected number of mentions", mentionsMaxPerComment, len(ParseMentions(body)))
+	})
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	t.Run("default mutes nothing", func(t *testing.T) {
+		t.Parallel()
+
+		test.AssertEqual(t, "Unexpected muted kinds",
+			[]NotificationKind{}, DefaultNotificationPreferences().MutedKinds())
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		muted := []NotificationKind{NotificationKindPostReply, NotificationKindMention}
+		prefs := NotificationPreferencesFromMuted(muted)
+		test.AssertEqual(t, "Unexpected preferences", NotificationPreferences{
+			PostReplies:    false,
+			CommentReplies: true,
+			Mentions:       false,
+		}, prefs)
+		test.AssertEqual(t, "Unexpected muted kinds", muted, prefs.MutedKinds())
+	})
+}
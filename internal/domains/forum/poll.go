@@ -0,0 +1,193 @@
+package forum
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pollMinOptions         = 2
+	pollMaxOptions         = 20
+	pollMaxOptionLength    = 128
+	pollMaxClosingDuration = 366 * 24 * time.Hour
+)
+
+type PollOptionId = uuid.UUID
+
+// Poll represents the poll of a poll post. Each user votes once, for a single
+// option or, for multiple choice polls, for any number of options. Votes are
+// no longer accepted once the poll has closed, if it has a closing time.
+type Poll struct {
+	PostId         PostId       `json:"post_id"`
+	MultipleChoice bool         `json:"multiple_choice"`
+	ClosesAt       *time.Time   `json:"closes_at"`
+	Options        []PollOption `json:"options"`
+	VoterCount     int          `json:"voter_count"`
+}
+
+// PollOption represents an option of a poll, with the number of votes it
+// received.
+type PollOption struct {
+	Id        PollOptionId `json:"id"`
+	Text      string       `json:"text"`
+	VoteCount int          `json:"vote_count"`
+}
+
+// PollValue represents the value of a poll, set when its post is created. The
+// closing time is nil for polls which never close.
+type PollValue struct {
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	ClosesAt       *time.Time `json:"closes_at"`
+}
+
+// Normalize returns the poll value with the whitespace around its options
+// trimmed, checking that it is valid. Polls have between 2 and 20 distinct
+// options, and must close in the future, within a year.
+func (v PollValue) Normalize() (value PollValue, err error) {
+	if len(v.Options) < pollMinOptions || len(v.Options) > pollMaxOptions {
+		return PollValue{}, InvalidPollParamsError{
+			field:  "options",
+			reason: fmt.Sprintf("a poll must have between %d and %d options", pollMinOptions, pollMaxOptions),
+		}
+	}
+
+	options := make([]string, 0, len(v.Options))
+	seen := set.New[string]()
+	for _, option := range v.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return PollValue{}, InvalidPollParamsError{
+				field:  "options",
+				reason: "option cannot be empty",
+			}
+		} else if len(option) > pollMaxOptionLength {
+			return PollValue{}, InvalidPollParamsError{
+				field:  "options",
+				reason: fmt.Sprintf("option must be less than %d characters", pollMaxOptionLength),
+			}
+		} else if seen.Contains(option) {
+			return PollValue{}, InvalidPollParamsError{
+				field:  "options",
+				reason: "options cannot be repeated",
+			}
+		}
+		seen.Add(option)
+		options = append(options, option)
+	}
+
+	if v.ClosesAt != nil {
+		now := time.Now()
+		if !v.ClosesAt.After(now) {
+			return PollValue{}, InvalidPollParamsError{
+				field:  "closes_at",
+				reason: "closes_at must be in the future",
+			}
+		} else if v.ClosesAt.After(now.Add(pollMaxClosingDuration)) {
+			return PollValue{}, InvalidPollParamsError{
+				field:  "closes_at",
+				reason: "closes_at must be within a year",
+			}
+		}
+	}
+
+	return PollValue{
+		Options:        options,
+		MultipleChoice: v.MultipleChoice,
+		ClosesAt:       v.ClosesAt,
+	}, nil
+}
+
+// ValidatePoll checks that the post carries a poll if and only if it is a
+// poll post.
+func (v PostValue) ValidatePoll(poll *PollValue) error {
+	if v.Type == PostTypePoll && poll == nil {
+		return InvalidPostParamsError{
+			field:  "poll",
+			reason: "poll posts must have a poll",
+		}
+	} else if v.Type != PostTypePoll && poll != nil {
+		return InvalidPostParamsError{
+			field:  "poll",
+			reason: "only poll posts may have a poll",
+		}
+	}
+
+	return nil
+}
+
+// IsClosed returns whether the poll has closed at the given time.
+func (p Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !p.ClosesAt.After(now)
+}
+
+// ValidateVote checks that the options may be voted for together. Single
+// choice polls take exactly one option, and multiple choice polls at least
+// one, each option of the poll at most once.
+func (p Poll) ValidateVote(optionIds []PollOptionId) error {
+	if len(optionIds) == 0 {
+		return InvalidPollParamsError{
+			field:  "option_ids",
+			reason: "at least one option must be chosen",
+		}
+	} else if !p.MultipleChoice && len(optionIds) > 1 {
+		return InvalidPollParamsError{
+			field:  "option_ids",
+			reason: "only one option may be chosen",
+		}
+	}
+
+	options := set.New[PollOptionId]()
+	for _, option := range p.Options {
+		options.Add(option.Id)
+	}
+
+	seen := set.New[PollOptionId]()
+	for _, id := range optionIds {
+		if !options.Contains(id) {
+			return InvalidPollParamsError{
+				field:  "option_ids",
+				reason: "options must belong to the poll",
+			}
+		} else if seen.Contains(id) {
+			return InvalidPollParamsError{
+				field:  "option_ids",
+				reason: "options cannot be repeated",
+			}
+		}
+		seen.Add(id)
+	}
+
+	return nil
+}
+
+// InvalidPollParamsError is returned when a poll is created or voted on with
+// invalid parameters.
+type InvalidPollParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidPollParamsError) Error() string {
+	return "invalid poll params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidPollParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidPollParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
@@ -0,0 +1,115 @@
+package forum
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestPollValue_Normalize(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	tooFar := time.Now().Add(2 * pollMaxClosingDuration)
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := PollValue{Options: []string{" Yes ", "No"}, ClosesAt: &future}.Normalize()
+		test.NilErr(t, err)
+		test.Assert(t, "Options should be trimmed", slices.Equal([]string{"Yes", "No"}, value.Options))
+		test.AssertEqual(t, "Closing time not as expected", &future, value.ClosesAt)
+
+		_, err = PollValue{Options: []string{"Go", "Rust", "Zig"}, MultipleChoice: true}.Normalize()
+		test.NilErr(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value PollValue
+		}{
+			{name: "no options", value: PollValue{}},
+			{name: "single option", value: PollValue{Options: []string{"Yes"}}},
+			{name: "too many options", value: PollValue{Options: slices.Repeat([]string{"a"}, pollMaxOptions+1)}},
+			{name: "empty option", value: PollValue{Options: []string{"Yes", " "}}},
+			{name: "option too long", value: PollValue{Options: []string{"Yes", strings.Repeat("a", pollMaxOptionLength+1)}}},
+			{name: "repeated option", value: PollValue{Options: []string{"Yes", " Yes"}}},
+			{name: "closed", value: PollValue{Options: []string{"Yes", "No"}, ClosesAt: &past}},
+			{name: "closing too late", value: PollValue{Options: []string{"Yes", "No"}, ClosesAt: &tooFar}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := d.value.Normalize()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestPostValue_ValidatePoll(t *testing.T) {
+	poll := &PollValue{Options: []string{"Yes", "No"}}
+
+	test.NilErr(t, PostValue{Type: PostTypePoll}.ValidatePoll(poll))
+	test.NilErr(t, PostValue{Type: PostTypeText}.ValidatePoll(nil))
+
+	err := PostValue{Type: PostTypePoll}.ValidatePoll(nil)
+	test.Assert(t, "Poll posts should require a poll", errors.Is(err, shared.ErrValidation))
+	err = PostValue{Type: PostTypeText}.ValidatePoll(poll)
+	test.Assert(t, "Text posts should not have a poll", errors.Is(err, shared.ErrValidation))
+}
+
+func TestPoll_IsClosed(t *testing.T) {
+	now := time.Now()
+	closesAt := now.Add(time.Hour)
+
+	test.Assert(t, "Poll without closing time should be open", !Poll{}.IsClosed(now))
+	test.Assert(t, "Poll should be open before its closing time", !Poll{ClosesAt: &closesAt}.IsClosed(now))
+	test.Assert(t, "Poll should be closed at its closing time", Poll{ClosesAt: &closesAt}.IsClosed(closesAt))
+}
+
+func TestPoll_ValidateVote(t *testing.T) {
+	first, second, other := uuid.New(), uuid.New(), uuid.New()
+	options := []PollOption{{Id: first, Text: "Yes"}, {Id: second, Text: "No"}}
+	single := Poll{Options: options}
+	multiple := Poll{Options: options, MultipleChoice: true}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, single.ValidateVote([]PollOptionId{second}))
+		test.NilErr(t, multiple.ValidateVote([]PollOptionId{first}))
+		test.NilErr(t, multiple.ValidateVote([]PollOptionId{first, second}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name      string
+			poll      Poll
+			optionIds []PollOptionId
+		}{
+			{name: "no option", poll: multiple, optionIds: []PollOptionId{}},
+			{name: "several options for single choice", poll: single, optionIds: []PollOptionId{first, second}},
+			{name: "option of another poll", poll: multiple, optionIds: []PollOptionId{first, other}},
+			{name: "repeated option", poll: multiple, optionIds: []PollOptionId{first, first}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.poll.ValidateVote(d.optionIds)
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
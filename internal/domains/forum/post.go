@@ -2,10 +2,13 @@ package forum
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"greddit/internal/domains/auth"
 	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
 
 	"github.com/google/uuid"
 )
@@ -13,16 +16,57 @@ import (
 const (
 	postMaxTitleLength = 128
 	postMaxBodyLength  = 1024 * 1024
+	postMaxUrlLength   = 2048
 )
 
 type PostId = uuid.UUID
 
+// PostType is the type of a post, which communities may restrict.
+type PostType string
+
+const (
+	PostTypeText  PostType = "text"
+	PostTypeLink  PostType = "link"
+	PostTypeImage PostType = "image"
+	PostTypePoll  PostType = "poll"
+)
+
+var allowedPostTypes = set.New[PostType](set.WithSlice([]PostType{
+	PostTypeText,
+	PostTypeLink,
+	PostTypeImage,
+	PostTypePoll,
+}))
+
+// Validate checks that the post type is valid.
+func (t PostType) Validate() error {
+	if !allowedPostTypes.Contains(t) {
+		return InvalidPostParamsError{
+			field:  "type",
+			reason: "type must be one of text, link, image or poll",
+		}
+	}
+	return nil
+}
+
 // Post represents a post in a community.
 type Post struct {
 	shared.Base
 
 	PostMetadata
 	PostValue
+	PostModeration
+}
+
+// PostModeration represents the moderation state of a post.
+type PostModeration struct {
+	Removal
+
+	// LockedAt is set while the post is locked against new comments, except
+	// from moderators.
+	LockedAt *time.Time `json:"locked_at"`
+	// PinnedAt is set while the post is pinned to the top of its community.
+	PinnedAt *time.Time `json:"pinned_at"`
 }
 
 // PostMetadata represents metadata about a post.
@@ -31,37 +75,93 @@ type PostMetadata struct {
 
 	PosterId    auth.UserId `json:"poster_id"`
 	CommunityId CommunityId `json:"community_id"`
+	FlairId     *FlairId    `json:"flair_id"`
 }
 
-// PostValue represents the value of a post.
+// PostValue represents the value of a post. The type and URL are set when the
+// post is created, and an empty type is taken as text. Link posts carry the
+// URL they link to, and their body is optional, as is the body of polls.
 type PostValue struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	Type  PostType `json:"type"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Url   string   `json:"url"`
 }
 
 // Validate checks that the post value is valid.
 func (v PostValue) Validate() error {
+	if v.Type != "" {
+		err := v.Type.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
 	title := v.Title
 	title = strings.TrimSpace(title)
 	if title == "" {
 		return InvalidPostParamsError{
+			field:  "title",
 			reason: "title cannot be empty",
 		}
 	} else if len(title) > postMaxTitleLength {
 		return InvalidPostParamsError{
+			field:  "title",
 			reason: fmt.Sprintf("title must be less than %d characters", postMaxTitleLength),
 		}
 	}
 
 	body := v.Body
 	body = strings.TrimSpace(body)
-	if body == "" {
+	if body == "" && v.Type != PostTypeLink && v.Type != PostTypePoll {
 		return InvalidPostParamsError{
+			field:  "body",
 			reason: "body cannot be empty",
 		}
 	} else if len(body) > postMaxBodyLength {
 		return InvalidPostParamsError{
-			fmt.Sprintf("body must be less than %d characters", postMaxBodyLength),
+			field:  "body",
+			reason: fmt.Sprintf("body must be less than %d characters", postMaxBodyLength),
+		}
+	}
+
+	if v.Type == PostTypeLink {
+		return validateUrl(v.Url)
+	} else if v.Url != "" {
+		return InvalidPostParamsError{
+			field:  "url",
+			reason: "only link posts may have a url",
+		}
+	}
+
+	return nil
+}
+
+// validateUrl checks that the URL of a link post is an absolute http or https
+// URL without credentials.
+func validateUrl(rawUrl string) error {
+	if rawUrl == "" {
+		return InvalidPostParamsError{
+			field:  "url",
+			reason: "url cannot be empty",
+		}
+	} else if len(rawUrl) > postMaxUrlLength {
+		return InvalidPostParamsError{
+			field:  "url",
+			reason: fmt.Sprintf("url must be less than %d characters", postMaxUrlLength),
+		}
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return InvalidPostParamsError{
+			field:  "url",
+			reason: "url must be an absolute http or https url",
+		}
+	} else if u.User != nil {
+		return InvalidPostParamsError{
+			field:  "url",
+			reason: "url cannot contain credentials",
 		}
 	}
 
@@ -70,6 +170,7 @@ func (v PostValue) Validate() error {
 
 // InvalidPostParamsError is returned when a post is created with invalid parameters.
 type InvalidPostParamsError struct {
+	field  string
 	reason string
 }
 
@@ -78,6 +179,19 @@ func (e InvalidPostParamsError) Error() string {
 	return "invalid post params: " + e.reason
 }
 
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidPostParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidPostParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
 // NewPost creates a new post.
 func NewPost(value PostValue, metadata PostMetadata, base shared.Base) (
 	post *Post, err error,
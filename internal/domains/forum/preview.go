@@ -0,0 +1,85 @@
+package forum
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	linkPreviewMaxTitleLength       = 256
+	linkPreviewMaxDescriptionLength = 1024
+)
+
+// LinkPreviewStatus is the state of the preview of a URL.
+type LinkPreviewStatus string
+
+const (
+	// LinkPreviewStatusPending is the status of a preview waiting to be
+	// fetched.
+	LinkPreviewStatusPending LinkPreviewStatus = "pending"
+	// LinkPreviewStatusReady is the status of a preview which was fetched.
+	LinkPreviewStatusReady LinkPreviewStatus = "ready"
+	// LinkPreviewStatusFailed is the status of a preview whose page could not
+	// be fetched or was not a web page.
+	LinkPreviewStatusFailed LinkPreviewStatus = "failed"
+)
+
+// LinkPreview represents the metadata of the page a link post points to, as
+// found in its title and description and OpenGraph tags. Previews are shared
+// by the posts linking to the same URL.
+type LinkPreview struct {
+	Url    string            `json:"url"`
+	Status LinkPreviewStatus `json:"status"`
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageUrl    string `json:"image_url"`
+
+	FetchedAt *time.Time `json:"fetched_at"`
+}
+
+// NewLinkPreview creates a ready preview of the URL fetched at the given time.
+// Whitespace in the title and description is collapsed and long values are
+// truncated, while an image URL which is not an absolute http or https URL is
+// dropped.
+func NewLinkPreview(url string, title string, description string, imageUrl string, fetchedAt time.Time) LinkPreview {
+	if validateUrl(imageUrl) != nil {
+		imageUrl = ""
+	}
+
+	return LinkPreview{
+		Url:    url,
+		Status: LinkPreviewStatusReady,
+
+		Title:       truncate(strings.Join(strings.Fields(title), " "), linkPreviewMaxTitleLength),
+		Description: truncate(strings.Join(strings.Fields(description), " "), linkPreviewMaxDescriptionLength),
+		ImageUrl:    imageUrl,
+
+		FetchedAt: &fetchedAt,
+	}
+}
+
+// NewFailedLinkPreview creates a failed preview of the URL attempted at the
+// given time.
+func NewFailedLinkPreview(url string, fetchedAt time.Time) LinkPreview {
+	return LinkPreview{
+		Url:       url,
+		Status:    LinkPreviewStatusFailed,
+		FetchedAt: &fetchedAt,
+	}
+}
+
+// truncate shortens the string to at most limit bytes without splitting a
+// character.
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+
+	s = s[:limit]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package forum
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"greddit/internal/test"
+)
+
+func TestNewLinkPreview(t *testing.T) {
+	fetchedAt := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("collapses whitespace", func(t *testing.T) {
+		t.Parallel()
+
+		preview := NewLinkPreview("https://go.dev/blog", "  The Go\n  Blog ", "News\tand\narticles", "https://go.dev/images/go-logo.png", fetchedAt)
+		test.AssertEqual(t, "Status not as expected", LinkPreviewStatusReady, preview.Status)
+		test.AssertEqual(t, "Title not as expected", "The Go Blog", preview.Title)
+		test.AssertEqual(t, "Description not as expected", "News and articles", preview.Description)
+		test.AssertEqual(t, "Image URL not as expected", "https://go.dev/images/go-logo.png", preview.ImageUrl)
+	})
+
+	t.Run("truncates long values", func(t *testing.T) {
+		t.Parallel()
+
+		title := strings.Repeat("é", linkPreviewMaxTitleLength)
+		preview := NewLinkPreview("https://go.dev/blog", title, "", "", fetchedAt)
+		test.Assert(t, "Title should be truncated", len(preview.Title) <= linkPreviewMaxTitleLength)
+		test.Assert(t, "Title should be valid UTF-8", utf8.ValidString(preview.Title))
+	})
+
+	t.Run("drops invalid image urls", func(t *testing.T) {
+		data := []string{"/images/logo.png", "javascript:alert(1)", "data:image/png;base64,AAAA"}
+
+		for _, imageUrl := range data {
+			t.Run(imageUrl, func(t *testing.T) {
+				t.Parallel()
+
+				preview := NewLinkPreview("https://go.dev/blog", "The Go Blog", "", imageUrl, fetchedAt)
+				test.AssertEqual(t, "Image URL should be dropped", "", preview.ImageUrl)
+			})
+		}
+	})
+}
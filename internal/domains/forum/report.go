@@ -0,0 +1,193 @@
+package forum
+
+import (
+	"fmt"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+const reportMaxDetailsLength = 1024
+
+// ReportReason is the category of a report.
+type ReportReason string
+
+const (
+	ReportReasonSpam           ReportReason = "spam"
+	ReportReasonHarassment     ReportReason = "harassment"
+	ReportReasonHate           ReportReason = "hate"
+	ReportReasonMisinformation ReportReason = "misinformation"
+	ReportReasonOffTopic       ReportReason = "off_topic"
+	// ReportReasonOther requires the report to be explained in its details.
+	ReportReasonOther ReportReason = "other"
+)
+
+var allowedReportReasons = set.New[ReportReason](set.WithSlice([]ReportReason{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHate,
+	ReportReasonMisinformation,
+	ReportReasonOffTopic,
+	ReportReasonOther,
+}))
+
+type ReportId = uuid.UUID
+
+// Report represents a user flagging a post or comment to the moderators of
+// its community. The comment ID is nil for reports of posts.
+type Report struct {
+	Id         ReportId    `json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	ReporterId auth.UserId `json:"reporter_id"`
+	PostId     PostId      `json:"post_id"`
+	CommentId  *CommentId  `json:"comment_id"`
+
+	ReportValue
+}
+
+// ReportValue represents the value of a report.
+type ReportValue struct {
+	Reason  ReportReason `json:"reason"`
+	Details string       `json:"details"`
+}
+
+// Validate checks that the report value is valid.
+func (v ReportValue) Validate() error {
+	if !allowedReportReasons.Contains(v.Reason) {
+		return InvalidReportParamsError{
+			field:  "reason",
+			reason: "reason must be one of spam, harassment, hate, misinformation, off_topic or other",
+		}
+	} else if v.Reason == ReportReasonOther && v.Details == "" {
+		return InvalidReportParamsError{
+			field:  "details",
+			reason: "details cannot be empty for other reasons",
+		}
+	} else if len(v.Details) > reportMaxDetailsLength {
+		return InvalidReportParamsError{
+			field:  "details",
+			reason: fmt.Sprintf("details must be less than %d characters", reportMaxDetailsLength),
+		}
+	}
+
+	return nil
+}
+
+// ReportedItem represents the open reports of a post or comment, aggregated
+// for the moderation queue. The comment ID is nil for posts.
+type ReportedItem struct {
+	PostId          PostId               `json:"post_id"`
+	CommentId       *CommentId           `json:"comment_id"`
+	Reports         int                  `json:"reports"`
+	Reasons         map[ReportReason]int `json:"reasons"`
+	FirstReportedAt time.Time            `json:"first_reported_at"`
+	LastReportedAt  time.Time            `json:"last_reported_at"`
+}
+
+// ReportQueueSort is the order of the items in the moderation queue.
+type ReportQueueSort string
+
+const (
+	// ReportQueueSortCount orders items most reported first, then oldest
+	// first.
+	ReportQueueSortCount ReportQueueSort = "count"
+	// ReportQueueSortAge orders items by their first report, oldest first.
+	ReportQueueSortAge ReportQueueSort = "age"
+)
+
+// Validate checks that the sort is valid.
+func (s ReportQueueSort) Validate() error {
+	if s != ReportQueueSortCount && s != ReportQueueSortAge {
+		return InvalidReportParamsError{
+			field:  "sort",
+			reason: "sort must be either count or age",
+		}
+	}
+	return nil
+}
+
+// ReportAction is how a moderator resolves the reports of an item.
+type ReportAction string
+
+const (
+	// ReportActionDismiss keeps the item.
+	ReportActionDismiss ReportAction = "dismiss"
+	// ReportActionRemove removes the item.
+	ReportActionRemove ReportAction = "remove"
+	// ReportActionBan removes the item and bans its author from the
+	// community.
+	ReportActionBan ReportAction = "ban"
+)
+
+// ResolutionValue represents how a moderator resolves the reports of an item,
+// and why. The expiry applies to bans, which are permanent when it is nil.
+type ResolutionValue struct {
+	Action    ReportAction `json:"action"`
+	Reason    string       `json:"reason"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+// Ban returns the value of the ban of the author of the item.
+func (v ResolutionValue) Ban() BanValue {
+	return BanValue{
+		Reason:    v.Reason,
+		ExpiresAt: v.ExpiresAt,
+	}
+}
+
+// Validate checks that the resolution value is valid.
+func (v ResolutionValue) Validate() error {
+	var action ModerationAction
+	switch v.Action {
+	case ReportActionDismiss:
+		action = ModerationActionDismissReports
+	case ReportActionRemove:
+		action = ModerationActionRemovePost
+	case ReportActionBan:
+		return v.Ban().Validate()
+	default:
+		return InvalidReportParamsError{
+			field:  "action",
+			reason: "action must be one of dismiss, remove or ban",
+		}
+	}
+
+	return ValidateModerationReason(action, v.Reason)
+}
+
+// Resolution represents the reports of an item resolved by a moderator, with
+// the moderation log entries of the actions taken.
+type Resolution struct {
+	Action   ReportAction         `json:"action"`
+	Resolved int                  `json:"resolved"`
+	Entries  []ModerationLogEntry `json:"entries"`
+}
+
+// InvalidReportParamsError is returned when a report is created or resolved
+// with invalid parameters.
+type InvalidReportParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidReportParamsError) Error() string {
+	return "invalid report params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidReportParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidReportParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
@@ -0,0 +1,90 @@
+package forum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestReportValue_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, ReportValue{Reason: ReportReasonSpam}.Validate())
+		test.NilErr(t, ReportValue{Reason: ReportReasonOther, Details: "Copied from another site"}.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value ReportValue
+		}{
+			{name: "empty reason", value: ReportValue{}},
+			{name: "unknown reason", value: ReportValue{Reason: "boring"}},
+			{name: "other without details", value: ReportValue{Reason: ReportReasonOther}},
+			{
+				name:  "details too long",
+				value: ReportValue{Reason: ReportReasonSpam, Details: strings.Repeat("a", reportMaxDetailsLength+1)},
+			},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.value.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestReportQueueSort_Validate(t *testing.T) {
+	t.Parallel()
+
+	test.NilErr(t, ReportQueueSortCount.Validate())
+	test.NilErr(t, ReportQueueSortAge.Validate())
+
+	err := ReportQueueSort("new").Validate()
+	test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+}
+
+func TestResolutionValue_Validate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, ResolutionValue{Action: ReportActionDismiss}.Validate())
+		test.NilErr(t, ResolutionValue{Action: ReportActionRemove, Reason: "Spam"}.Validate())
+		test.NilErr(t, ResolutionValue{Action: ReportActionBan, Reason: "Repeated spam"}.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value ResolutionValue
+		}{
+			{name: "unknown action", value: ResolutionValue{Action: "warn"}},
+			{name: "removing without reason", value: ResolutionValue{Action: ReportActionRemove}},
+			{name: "banning without reason", value: ResolutionValue{Action: ReportActionBan}},
+			{
+				name:  "banning until the past",
+				value: ResolutionValue{Action: ReportActionBan, Reason: "Spam", ExpiresAt: &past},
+			},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.value.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package forum
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"greddit/internal/domains/auth"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// revisionDiffContext is the number of context lines around changes in a
+	// revision diff.
+	revisionDiffContext = 3
+)
+
+type RevisionId = uuid.UUID
+
+// RevisionMetadata represents metadata about a revision of a post or comment.
+// Revisions are numbered from 1 in order of creation.
+type RevisionMetadata struct {
+	Id        RevisionId  `json:"id"`
+	Revision  int         `json:"revision"`
+	EditorId  auth.UserId `json:"editor_id"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// PostRevision represents a stored version of a post.
+type PostRevision struct {
+	RevisionMetadata
+	PostValue
+
+	PostId PostId `json:"post_id"`
+}
+
+// CommentRevision represents a stored version of a comment.
+type CommentRevision struct {
+	RevisionMetadata
+	CommentValue
+
+	CommentId CommentId `json:"comment_id"`
+}
+
+// RevisionDiff represents the unified diff between two revisions.
+type RevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
+
+// NewRevisionDiff returns the unified diff between the bodies of two
+// revisions.
+func NewRevisionDiff(from RevisionMetadata, fromBody string, to RevisionMetadata, toBody string) (
+	diff *RevisionDiff, err error,
+) {
+	str, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(fromBody),
+		B:        splitLines(toBody),
+		FromFile: fmt.Sprintf("revision %d", from.Revision),
+		FromDate: from.CreatedAt.UTC().Format(time.RFC3339),
+		ToFile:   fmt.Sprintf("revision %d", to.Revision),
+		ToDate:   to.CreatedAt.UTC().Format(time.RFC3339),
+		Context:  revisionDiffContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionDiff{
+		From: from.Revision,
+		To:   to.Revision,
+		Diff: str,
+	}, nil
+}
+
+// splitLines splits the body into lines for diffing, each ending with a
+// newline.
+func splitLines(body string) []string {
+	lines := strings.SplitAfter(body, "\n")
+
+	last := len(lines) - 1
+	if lines[last] == "" {
+		return lines[:last]
+	}
+	lines[last] += "\n"
+
+	return lines
+}
@@ -0,0 +1,48 @@
+package forum
+
+import (
+	"testing"
+	"time"
+
+	"greddit/internal/test"
+)
+
+func TestNewRevisionDiff(t *testing.T) {
+	tt := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+	from := RevisionMetadata{
+		Revision:  1,
+		CreatedAt: tt,
+	}
+	to := RevisionMetadata{
+		Revision:  3,
+		CreatedAt: tt.Add(time.Hour),
+	}
+
+	t.Run("changed lines", func(t *testing.T) {
+		t.Parallel()
+
+		diff, err := NewRevisionDiff(from, "first\nsecond\nthird\n", to, "first\nchanged\nthird\nfourth\n")
+		test.NilErr(t, err)
+
+		expected := "--- revision 1\t2025-10-10T12:00:00Z\n" +
+			"+++ revision 3\t2025-10-10T13:00:00Z\n" +
+			"@@ -1,3 +1,4 @@\n" +
+			" first\n" +
+			"-second\n" +
+			"+changed\n" +
+			" third\n" +
+			"+fourth\n"
+
+		test.AssertEqual(t, "Diff not as expected", expected, diff.Diff)
+		test.AssertEqual(t, "Unexpected from revision", 1, diff.From)
+		test.AssertEqual(t, "Unexpected to revision", 3, diff.To)
+	})
+
+	t.Run("identical bodies", func(t *testing.T) {
+		t.Parallel()
+
+		diff, err := NewRevisionDiff(from, "same\n", to, "same\n")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected empty diff", "", diff.Diff)
+	})
+}
@@ -0,0 +1,162 @@
+package forum
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+
+	"github.com/google/uuid"
+)
+
+const (
+	collectionMaxNameLength = 64
+	savedItemMaxNoteLength  = 1024
+)
+
+type CollectionId = uuid.UUID
+
+// Collection represents a named group of saved items of a user.
+type Collection struct {
+	Id        CollectionId `json:"id"`
+	UserId    auth.UserId  `json:"user_id"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	CollectionValue
+}
+
+// CollectionValue represents the value of a collection.
+type CollectionValue struct {
+	Name string `json:"name"`
+}
+
+// Validate checks that the collection value is valid.
+func (v CollectionValue) Validate() error {
+	name := v.Name
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return InvalidCollectionParamsError{
+			field:  "name",
+			reason: "name cannot be empty",
+		}
+	} else if len(name) > collectionMaxNameLength {
+		return InvalidCollectionParamsError{
+			field:  "name",
+			reason: fmt.Sprintf("name must be less than %d characters", collectionMaxNameLength),
+		}
+	}
+
+	return nil
+}
+
+// InvalidCollectionParamsError is returned when a collection is created with
+// invalid parameters.
+type InvalidCollectionParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidCollectionParamsError) Error() string {
+	return "invalid collection params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidCollectionParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidCollectionParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
+
+type SavedItemId = uuid.UUID
+
+// SavedItem represents a post or comment bookmarked by a user. Exactly one
+// of the post and comment IDs is set.
+type SavedItem struct {
+	Id        SavedItemId `json:"id"`
+	UserId    auth.UserId `json:"user_id"`
+	PostId    *PostId     `json:"post_id"`
+	CommentId *CommentId  `json:"comment_id"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	SavedItemValue
+}
+
+// SavedItemValue represents the value of a saved item.
+type SavedItemValue struct {
+	CollectionId *CollectionId `json:"collection_id"`
+	Note         string        `json:"note"`
+}
+
+// Validate checks that the saved item value is valid.
+func (v SavedItemValue) Validate() error {
+	if len(v.Note) > savedItemMaxNoteLength {
+		return InvalidSavedItemParamsError{
+			field:  "note",
+			reason: fmt.Sprintf("note must be less than %d characters", savedItemMaxNoteLength),
+		}
+	}
+
+	return nil
+}
+
+// SavedItemKind is the kind of content of a saved item.
+type SavedItemKind string
+
+const (
+	SavedItemKindPost    SavedItemKind = "post"
+	SavedItemKindComment SavedItemKind = "comment"
+)
+
+// SavedItemFilter narrows down the saved items of a user. Zero fields match
+// all saved items.
+type SavedItemFilter struct {
+	Kind         SavedItemKind
+	CollectionId *CollectionId
+}
+
+// Validate checks that the filter is valid.
+func (f SavedItemFilter) Validate() error {
+	switch f.Kind {
+	case "", SavedItemKindPost, SavedItemKindComment:
+		return nil
+	default:
+		return InvalidSavedItemParamsError{
+			field:  "kind",
+			reason: "kind must be either post or comment",
+		}
+	}
+}
+
+// InvalidSavedItemParamsError is returned when an item is saved or listed with
+// invalid parameters.
+type InvalidSavedItemParamsError struct {
+	field  string
+	reason string
+}
+
+// Error implements the error interface.
+func (e InvalidSavedItemParamsError) Error() string {
+	return "invalid saved item params: " + e.reason
+}
+
+// Is reports whether the error matches shared.ErrValidation.
+func (e InvalidSavedItemParamsError) Is(target error) bool {
+	return target == shared.ErrValidation
+}
+
+// FieldErrors implements shared.ValidationError.
+func (e InvalidSavedItemParamsError) FieldErrors() []shared.FieldError {
+	return []shared.FieldError{{
+		Field:  e.field,
+		Reason: e.reason,
+	}}
+}
@@ -0,0 +1,71 @@
+package forum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestCollectionValue_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, CollectionValue{Name: "Read later"}.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := []struct {
+			name  string
+			value CollectionValue
+		}{
+			{name: "empty name", value: CollectionValue{Name: ""}},
+			{name: "name with only spaces", value: CollectionValue{Name: "   "}},
+			{name: "name too long", value: CollectionValue{Name: strings.Repeat("a", collectionMaxNameLength+1)}},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				err := d.value.Validate()
+				test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+			})
+		}
+	})
+}
+
+func TestSavedItemValue_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		test.NilErr(t, SavedItemValue{}.Validate())
+		test.NilErr(t, SavedItemValue{Note: "Check the benchmarks"}.Validate())
+	})
+
+	t.Run("note too long", func(t *testing.T) {
+		t.Parallel()
+
+		err := SavedItemValue{Note: strings.Repeat("a", savedItemMaxNoteLength+1)}.Validate()
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+}
+
+func TestSavedItemFilter_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, kind := range []SavedItemKind{"", SavedItemKindPost, SavedItemKindComment} {
+			test.NilErr(t, SavedItemFilter{Kind: kind}.Validate())
+		}
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		t.Parallel()
+
+		err := SavedItemFilter{Kind: "community"}.Validate()
+		test.Assert(t, "Expected validation error", errors.Is(err, shared.ErrValidation))
+	})
+}
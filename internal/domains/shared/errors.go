@@ -0,0 +1,89 @@
+package shared
+
+import "errors"
+
+// Sentinel errors classifying domain failures. Concrete error types match
+// them through errors.Is, so callers can branch on the kind of failure
+// without knowing which entity or layer produced it.
+var (
+	// ErrNotFound classifies errors for entities which do not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict classifies errors for writes which clash with an
+	// existing entity, such as a duplicate unique value.
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidReference classifies errors for writes which refer to an
+	// entity which does not exist.
+	ErrInvalidReference = errors.New("invalid reference")
+	// ErrValidation classifies errors for values which fail domain
+	// validation.
+	ErrValidation = errors.New("validation failed")
+)
+
+// NotFoundError is returned when an entity does not exist, or has been soft
+// deleted and deleted entities were not requested.
+type NotFoundError struct {
+	Entity string
+}
+
+// Error implements the error interface.
+func (e NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
+
+// Is reports whether the error matches ErrNotFound.
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError is returned when a write clashes with an existing entity on
+// a field which must be unique.
+type ConflictError struct {
+	Entity string
+	Field  string
+}
+
+// Error implements the error interface.
+func (e ConflictError) Error() string {
+	if e.Field == "" {
+		return e.Entity + " already exists"
+	}
+	return e.Entity + " with this " + e.Field + " already exists"
+}
+
+// Is reports whether the error matches ErrConflict.
+func (e ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// InvalidReferenceError is returned when a write refers through a field to
+// an entity which does not exist.
+type InvalidReferenceError struct {
+	Entity string
+	Field  string
+}
+
+// Error implements the error interface.
+func (e InvalidReferenceError) Error() string {
+	if e.Field == "" {
+		return e.Entity + " references a missing entity"
+	}
+	return e.Entity + " " + e.Field + " references a missing entity"
+}
+
+// Is reports whether the error matches ErrInvalidReference.
+func (e InvalidReferenceError) Is(target error) bool {
+	return target == ErrInvalidReference
+}
+
+// FieldError describes why a single field failed validation.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError is implemented by errors matching ErrValidation which can
+// describe the fields that failed.
+type ValidationError interface {
+	error
+	FieldErrors() []FieldError
+}
@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"greddit/internal/test"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("errors match their kind", func(t *testing.T) {
+		t.Parallel()
+
+		test.Assert(t, "Expected not found",
+			errors.Is(NotFoundError{Entity: "post"}, ErrNotFound))
+		test.Assert(t, "Expected conflict",
+			errors.Is(ConflictError{Entity: "user", Field: "username"}, ErrConflict))
+		test.Assert(t, "Expected invalid reference",
+			errors.Is(InvalidReferenceError{Entity: "post", Field: "community_id"}, ErrInvalidReference))
+	})
+
+	t.Run("errors do not match other kinds", func(t *testing.T) {
+		t.Parallel()
+
+		err := NotFoundError{Entity: "post"}
+		test.Assert(t, "Unexpected conflict", !errors.Is(err, ErrConflict))
+		test.Assert(t, "Unexpected invalid reference", !errors.Is(err, ErrInvalidReference))
+		test.Assert(t, "Unexpected validation", !errors.Is(err, ErrValidation))
+	})
+
+	t.Run("wrapped errors match their kind", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("creating user: %w", ConflictError{Entity: "user", Field: "username"})
+		test.Assert(t, "Expected conflict", errors.Is(err, ErrConflict))
+		test.AssertEqual(t, "Message not as expected",
+			"creating user: user with this username already exists", err.Error())
+	})
+}
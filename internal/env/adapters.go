@@ -3,6 +3,7 @@ package env
 import (
 	"strconv"
 	"strings"
+	"time"
 )
 
 /*
@@ -32,3 +33,8 @@ func StrAdapter(str string) (string, error) {
 func BoolAdapter(str string) (bool, error) {
 	return strconv.ParseBool(str)
 }
+
+// DurationAdapter parses a time.Duration from a string.
+func DurationAdapter(str string) (time.Duration, error) {
+	return time.ParseDuration(strings.TrimSpace(str))
+}
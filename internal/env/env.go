@@ -2,6 +2,7 @@ package env
 
 import (
 	"os"
+	"time"
 
 	"greddit/internal/infra/log"
 )
@@ -89,3 +90,8 @@ func GetBoolEnvOrFatal(key string) bool {
 func GetBoolEnvDef(key string, defV bool) bool {
 	return GetEnvOrDef(key, defV, BoolAdapter)
 }
+
+// GetDurationEnvDef is GetEnvOrDef for time.Duration values.
+func GetDurationEnvDef(key string, defV time.Duration) time.Duration {
+	return GetEnvOrDef(key, defV, DurationAdapter)
+}
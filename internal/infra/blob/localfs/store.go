@@ -0,0 +1,118 @@
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"greddit/internal/domains/shared"
+
+	blobports "greddit/internal/ports/blob"
+)
+
+// Store implements the blobports.BlobStore interface on the local filesystem.
+// Blobs are spread over subdirectories named after the first characters of
+// their keys, to keep directories small.
+type Store struct {
+	root string
+}
+
+// NewStore creates a new Store keeping blobs under the root directory, which
+// is created if it does not exist.
+func NewStore(root string) (store Store, err error) {
+	err = os.MkdirAll(root, 0o750)
+	if err != nil {
+		return Store{}, err
+	}
+
+	return Store{
+		root: root,
+	}, nil
+}
+
+// path returns the path of the blob stored under the key.
+func (s Store) path(key string) (path string, err error) {
+	if !blobports.ValidKey(key) {
+		return "", fmt.Errorf("localfs: invalid key %q", key)
+	}
+
+	dir := "_"
+	if len(key) >= 2 {
+		dir = key[:2]
+	}
+	return filepath.Join(s.root, dir, key), nil
+}
+
+func (s Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return err
+	}
+
+	// The blob is written to a temporary file which is renamed into place, so
+	// that readers never see a partial blob.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+key+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, io.LimitReader(r, size))
+	if err != nil {
+		return err
+	} else if n != size {
+		return fmt.Errorf("localfs: read %d bytes of %d", n, size)
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s Store) Open(ctx context.Context, key string) (blob io.ReadSeekCloser, err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, shared.NotFoundError{
+			Entity: "blob",
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (s Store) Delete(ctx context.Context, key string) (err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
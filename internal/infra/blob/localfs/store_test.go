@@ -0,0 +1,64 @@
+package localfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+func TestStore(t *testing.T) {
+	ctx := t.Context()
+
+	store, err := NewStore(t.TempDir())
+	test.NilErr(t, err)
+
+	t.Run("put, open and delete", func(t *testing.T) {
+		const content = "hello, world"
+
+		err := store.Put(ctx, "abc123", strings.NewReader(content), int64(len(content)), "text/plain")
+		test.NilErr(t, err)
+
+		blob, err := store.Open(ctx, "abc123")
+		test.NilErr(t, err)
+
+		_, err = blob.Seek(7, io.SeekStart)
+		test.NilErr(t, err)
+		got, err := io.ReadAll(blob)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Content not as expected", "world", string(got))
+		test.NilErr(t, blob.Close())
+
+		err = store.Delete(ctx, "abc123")
+		test.NilErr(t, err)
+
+		_, err = store.Open(ctx, "abc123")
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		err = store.Delete(ctx, "abc123")
+		test.NilErr(t, err)
+	})
+
+	t.Run("short reads leave no blob", func(t *testing.T) {
+		err := store.Put(ctx, "short", strings.NewReader("abc"), 10, "text/plain")
+		test.Assert(t, "Expected error", err != nil)
+
+		_, err = store.Open(ctx, "short")
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		entries, err := os.ReadDir(store.root + "/sh")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Temporary files should be removed", 0, len(entries))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "../escape", ".hidden", "a/b"} {
+			err := store.Put(ctx, key, strings.NewReader("abc"), 3, "text/plain")
+			test.Assert(t, "Expected error for key "+key, err != nil)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"greddit/internal/domains/shared"
+
+	blobports "greddit/internal/ports/blob"
+)
+
+// Config represents the configuration of a Store.
+type Config struct {
+	// Endpoint is the base URL of the S3-compatible service, such as
+	// https://s3.eu-west-1.amazonaws.com or http://localhost:9000.
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+// Store implements the blobports.BlobStore interface on an S3-compatible
+// object storage service. Objects are addressed in path style, which every
+// S3-compatible service supports.
+type Store struct {
+	endpoint *url.URL
+	bucket   string
+	creds    credentials
+	client   *http.Client
+}
+
+// NewStore creates a new Store storing blobs in the bucket.
+func NewStore(config Config) (store Store, err error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return Store{}, err
+	} else if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return Store{}, fmt.Errorf("s3: invalid endpoint %q", config.Endpoint)
+	} else if config.Bucket == "" {
+		return Store{}, errors.New("s3: missing bucket")
+	}
+
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return Store{
+		endpoint: endpoint,
+		bucket:   config.Bucket,
+		creds: credentials{
+			accessKey: config.AccessKey,
+			secretKey: config.SecretKey,
+			region:    region,
+		},
+		client: &http.Client{},
+	}, nil
+}
+
+// objectUrl returns the URL of the object stored under the key.
+func (s Store) objectUrl(key string) (u string, err error) {
+	if !blobports.ValidKey(key) {
+		return "", fmt.Errorf("s3: invalid key %q", key)
+	}
+
+	return s.endpoint.JoinPath(s.bucket, key).String(), nil
+}
+
+// do signs and sends a request for the object stored under the key.
+func (s Store) do(ctx context.Context, method string, key string, body io.Reader, size int64,
+	header http.Header,
+) (resp *http.Response, err error) {
+	u, err := s.objectUrl(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := emptyPayload
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.creds.sign(req, payloadHash, time.Now())
+
+	return s.client.Do(req)
+}
+
+// statusError returns the error matching an unexpected response status.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return shared.NotFoundError{
+			Entity: "blob",
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3: unexpected status %d: %s", resp.StatusCode, body)
+}
+
+func (s Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	resp, err := s.do(ctx, http.MethodPut, key, io.LimitReader(r, size), size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	return nil
+}
+
+func (s Store) Open(ctx context.Context, key string) (blob io.ReadSeekCloser, err error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+
+	return &object{
+		ctx:   ctx,
+		store: s,
+		key:   key,
+		size:  resp.ContentLength,
+	}, nil
+}
+
+func (s Store) Delete(ctx context.Context, key string) (err error) {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusNotFound {
+		return statusError(resp)
+	}
+
+	return nil
+}
+
+// object is an object opened for reading. The object is read lazily with a
+// ranged request from the current offset, which is sent again after seeking,
+// so that serving a range only downloads that range.
+type object struct {
+	ctx   context.Context
+	store Store
+	key   string
+	size  int64
+
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *object) Read(p []byte) (n int, err error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+
+	if o.body == nil {
+		header := http.Header{}
+		header.Set("Range", "bytes="+strconv.FormatInt(o.offset, 10)+"-")
+
+		resp, err := o.store.do(o.ctx, http.MethodGet, o.key, nil, 0, header)
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return 0, statusError(resp)
+		}
+		o.body = resp.Body
+	}
+
+	n, err = o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, errors.New("s3: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("s3: negative position")
+	}
+
+	if offset != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+
+	return offset, nil
+}
+
+func (o *object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
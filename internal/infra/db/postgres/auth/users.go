@@ -6,6 +6,7 @@ import (
 
 	"greddit/internal/domains/auth"
 	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -34,7 +35,7 @@ func (r UsersRepo) CreateUser(ctx context.Context, value auth.UserValue) (user *
 		&user.Base.CreatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "user")
 	}
 
 	user.Base.UpdatedAt = user.Base.CreatedAt
@@ -42,9 +43,12 @@ func (r UsersRepo) CreateUser(ctx context.Context, value auth.UserValue) (user *
 	return user, nil
 }
 
-func (r UsersRepo) GetUserById(ctx context.Context, id auth.UserId) (user *auth.User, err error) {
-	const stmt = "SELECT username, display_name, role, created_at, updated_at, deleted_at FROM auth_users WHERE id = $1"
-	args := []any{id}
+func (r UsersRepo) GetUserById(ctx context.Context, id auth.UserId, opts ...dbports.ReadOption) (
+	user *auth.User, err error,
+) {
+	const stmt = "SELECT username, display_name, role, created_at, updated_at, deleted_at FROM auth_users WHERE id = $1 AND ($2 OR deleted_at IS NULL)"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{id, options.IncludeDeleted}
 
 	user = &auth.User{
 		UserMetadata: auth.UserMetadata{
@@ -56,15 +60,18 @@ func (r UsersRepo) GetUserById(ctx context.Context, id auth.UserId) (user *auth.
 		&user.Username, &user.DisplayName, &user.Role, &user.Base.CreatedAt, &user.Base.UpdatedAt, &user.Base.DeletedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "user")
 	}
 
 	return user, nil
 }
 
-func (r UsersRepo) GetUserByUsername(ctx context.Context, username string) (user *auth.User, err error) {
-	const stmt = "SELECT id, display_name, role, created_at, updated_at, deleted_at FROM auth_users WHERE username = $1"
-	args := []any{username}
+func (r UsersRepo) GetUserByUsername(ctx context.Context, username string, opts ...dbports.ReadOption) (
+	user *auth.User, err error,
+) {
+	const stmt = "SELECT id, display_name, role, created_at, updated_at, deleted_at FROM auth_users WHERE username = $1 AND ($2 OR deleted_at IS NULL)"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{username, options.IncludeDeleted}
 	user = &auth.User{
 		UserValue: auth.UserValue{
 			Username: username,
@@ -75,7 +82,7 @@ func (r UsersRepo) GetUserByUsername(ctx context.Context, username string) (user
 		&user.UserMetadata.Id, &user.DisplayName, &user.Role, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "user")
 	}
 
 	return user, nil
@@ -88,21 +95,60 @@ func (r UsersRepo) UpdateDisplayName(ctx context.Context, id auth.UserId, displa
 	updatedAt = &time.Time{}
 	err = r.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "user")
 	}
 
 	return updatedAt, nil
 }
 
 func (r UsersRepo) DeleteUser(ctx context.Context, id auth.UserId) (deletedAt *time.Time, err error) {
-	const stmt = "UPDATE auth_users SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at"
+	const stmt = "UPDATE auth_users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at"
 	args := []any{id}
 
 	deletedAt = &time.Time{}
 	err = r.QueryRow(ctx, stmt, args...).Scan(&deletedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "user")
 	}
 
 	return deletedAt, nil
 }
+
+func (r UsersRepo) GetDeletedUsers(ctx context.Context, limit int, offset int) (users []auth.User, err error) {
+	const stmt = "SELECT id, username, display_name, role, created_at, updated_at, deleted_at FROM auth_users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT $1 OFFSET $2"
+	args := []any{limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users = make([]auth.User, 0, limit)
+	for rows.Next() {
+		user := auth.User{}
+		err = rows.Scan(
+			&user.UserMetadata.Id, &user.Username, &user.DisplayName, &user.Role,
+			&user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+func (r UsersRepo) RestoreUser(ctx context.Context, id auth.UserId) (updatedAt *time.Time, err error) {
+	const stmt = "UPDATE auth_users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING updated_at"
+	args := []any{id}
+
+	updatedAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "user")
+	}
+
+	return updatedAt, nil
+}
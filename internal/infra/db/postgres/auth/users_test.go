@@ -1,13 +1,17 @@
 package authdb
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"greddit/internal/test"
 
 	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
+
+	dbports "greddit/internal/ports/db"
 )
 
 func TestUsersRepo_CreateUser(t *testing.T) {
@@ -73,6 +77,10 @@ func TestUsersRepo_CreateUser(t *testing.T) {
 		// Try to create another user with the same username
 		_, err = repo.CreateUser(ctx, value)
 		test.Assert(t, "Expected error for duplicate username", err != nil)
+
+		var conflictErr shared.ConflictError
+		test.Assert(t, "Expected conflict error", errors.As(err, &conflictErr))
+		test.AssertEqual(t, "Conflicting field not as expected", "username", conflictErr.Field)
 	})
 }
 
@@ -226,7 +234,7 @@ func TestUsersRepo_DeleteUser(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify that the user is soft-deleted
-		deletedUser, err := repo.GetUserById(ctx, user.Id)
+		deletedUser, err := repo.GetUserById(ctx, user.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected username", user.Username, deletedUser.Username)
@@ -268,7 +276,7 @@ func TestUsersRepo_DeleteUser(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify user1 is deleted but user2 is not
-		retrieved1, err := repo.GetUserById(ctx, user1.Id)
+		retrieved1, err := repo.GetUserById(ctx, user1.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		retrieved2, err := repo.GetUserById(ctx, user2.Id)
@@ -278,6 +286,31 @@ func TestUsersRepo_DeleteUser(t *testing.T) {
 		test.Assert(t, "user2 should not be deleted", retrieved2.DeletedAt == nil)
 	})
 
+	t.Run("deleted user hidden by default", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		user, err := repo.CreateUser(ctx, auth.UserValue{
+			Username:    "hidden",
+			DisplayName: "Hidden",
+			Role:        "user",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.DeleteUser(ctx, user.Id)
+		test.NilErr(t, err)
+
+		var notFoundErr shared.NotFoundError
+
+		_, err = repo.GetUserById(ctx, user.Id)
+		test.Assert(t, "Expected not found error by ID", errors.As(err, &notFoundErr))
+
+		_, err = repo.GetUserByUsername(ctx, user.Username)
+		test.Assert(t, "Expected not found error by username", errors.As(err, &notFoundErr))
+
+		_, err = repo.GetUserByUsername(ctx, user.Username, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+	})
+
 	t.Run("user data still accessible after soft delete", func(t *testing.T) {
 		postgres.ClearAllTables(t, pool)
 
@@ -296,7 +329,7 @@ func TestUsersRepo_DeleteUser(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// User should still be retrievable
-		deletedUser, err := repo.GetUserById(ctx, user.Id)
+		deletedUser, err := repo.GetUserById(ctx, user.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected username", user.Username, deletedUser.Username)
@@ -305,6 +338,62 @@ func TestUsersRepo_DeleteUser(t *testing.T) {
 	})
 }
 
+func TestUsersRepo_RestoreUser(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewUsersRepo(pool)
+	ctx := t.Context()
+
+	t.Run("restore a soft-deleted user", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		user, err := repo.CreateUser(ctx, auth.UserValue{
+			Username:    "restoreuser",
+			DisplayName: "Restore User",
+			Role:        "user",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.DeleteUser(ctx, user.Id)
+		test.NilErr(t, err)
+
+		users, err := repo.GetDeletedUsers(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 deleted user", 1, len(users))
+		test.AssertEqual(t, "Unexpected deleted user", user.Id, users[0].Id)
+
+		updatedAt, err := repo.RestoreUser(ctx, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected a non-nil updated timestamp", updatedAt != nil)
+
+		restoredUser, err := repo.GetUserById(ctx, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected user to be restored", restoredUser.DeletedAt == nil)
+
+		users, err = repo.GetDeletedUsers(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no deleted users", 0, len(users))
+	})
+
+	t.Run("live user", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		user, err := repo.CreateUser(ctx, auth.UserValue{
+			Username:    "liveuser",
+			DisplayName: "Live User",
+			Role:        "user",
+		})
+		test.NilErr(t, err)
+
+		updatedAt, err := repo.RestoreUser(ctx, user.Id)
+		test.Assert(t, "Expected error for live user", err != nil)
+		test.Assert(t, "Expected updated timestamp to be nil", updatedAt == nil)
+	})
+}
+
 func TestUsersRepo_Integration(t *testing.T) {
 	t.Parallel()
 
@@ -345,7 +434,7 @@ func TestUsersRepo_Integration(t *testing.T) {
 		test.NilErr(t, err)
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
-		deleted, err := repo.GetUserById(ctx, user.Id)
+		deleted, err := repo.GetUserById(ctx, user.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 		test.Assert(t, "Expected user to be soft-deleted", deleted.DeletedAt != nil)
 	})
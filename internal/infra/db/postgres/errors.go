@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"greddit/internal/domains/shared"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+// TranslateError translates driver errors into the shared domain errors for
+// the entity, returning other errors as they are:
+//   - pgx.ErrNoRows becomes a shared.NotFoundError.
+//   - Unique violations become a shared.ConflictError.
+//   - Foreign key violations become a shared.InvalidReferenceError.
+func TranslateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return shared.NotFoundError{
+			Entity: entity,
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolationCode:
+		return shared.ConflictError{
+			Entity: entity,
+			Field:  constraintField(pgErr, "_key"),
+		}
+	case foreignKeyViolationCode:
+		return shared.InvalidReferenceError{
+			Entity: entity,
+			Field:  constraintField(pgErr, "_fkey"),
+		}
+	}
+
+	return err
+}
+
+// constraintField recovers the column names from a constraint following the
+// default postgres naming of <table>_<columns>_<suffix>.
+func constraintField(pgErr *pgconn.PgError, suffix string) string {
+	name, ok := strings.CutSuffix(pgErr.ConstraintName, suffix)
+	if !ok {
+		return ""
+	}
+	name, ok = strings.CutPrefix(name, pgErr.TableName+"_")
+	if !ok {
+		return ""
+	}
+
+	return name
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	t.Run("no rows", func(t *testing.T) {
+		t.Parallel()
+
+		err := TranslateError(pgx.ErrNoRows, "post")
+		test.AssertEqual(t, "Error not as expected", error(shared.NotFoundError{Entity: "post"}), err)
+	})
+
+	t.Run("unique violation", func(t *testing.T) {
+		t.Parallel()
+
+		err := TranslateError(&pgconn.PgError{
+			Code:           uniqueViolationCode,
+			TableName:      "auth_users",
+			ConstraintName: "auth_users_username_key",
+		}, "user")
+		test.AssertEqual(t, "Error not as expected",
+			error(shared.ConflictError{Entity: "user", Field: "username"}), err)
+	})
+
+	t.Run("foreign key violation", func(t *testing.T) {
+		t.Parallel()
+
+		err := TranslateError(&pgconn.PgError{
+			Code:           foreignKeyViolationCode,
+			TableName:      "forum_posts",
+			ConstraintName: "forum_posts_community_id_fkey",
+		}, "post")
+		test.AssertEqual(t, "Error not as expected",
+			error(shared.InvalidReferenceError{Entity: "post", Field: "community_id"}), err)
+	})
+
+	t.Run("unconventional constraint name", func(t *testing.T) {
+		t.Parallel()
+
+		err := TranslateError(&pgconn.PgError{
+			Code:           uniqueViolationCode,
+			TableName:      "forum_posts",
+			ConstraintName: "posts_title_unique",
+		}, "post")
+		test.AssertEqual(t, "Error not as expected", error(shared.ConflictError{Entity: "post"}), err)
+	})
+
+	t.Run("other errors pass through", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("boom")
+		test.AssertEqual(t, "Error not as expected", cause, TranslateError(cause, "post"))
+		test.NilErr(t, TranslateError(nil, "post"))
+	})
+}
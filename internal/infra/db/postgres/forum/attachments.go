@@ -0,0 +1,273 @@
+package forumdb
+
+import (
+	"context"
+	"errors"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AttachmentsRepo implements the dbportsforum.AttachmentsRepo interface.
+type AttachmentsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewAttachmentsRepo creates a new AttachmentsRepo.
+func NewAttachmentsRepo(pool *pgxpool.Pool) AttachmentsRepo {
+	return AttachmentsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+const blobColumns = "b.sha256, b.content_type, b.size, b.status, b.width, b.height, " +
+	"b.thumbnail_content_type, b.thumbnail_width, b.thumbnail_height"
+
+// blobDest returns the scan destinations of the blob columns, and a function
+// setting the thumbnail of the blob once scanned.
+func blobDest(blob *forum.Blob) (dest []any, finish func()) {
+	var thumbnailContentType *string
+	var thumbnailWidth, thumbnailHeight *int
+
+	dest = []any{
+		&blob.Sha256,
+		&blob.ContentType,
+		&blob.Size,
+		&blob.Status,
+		&blob.Width,
+		&blob.Height,
+		&thumbnailContentType,
+		&thumbnailWidth,
+		&thumbnailHeight,
+	}
+	finish = func() {
+		blob.Thumbnail = nil
+		if thumbnailContentType != nil && thumbnailWidth != nil && thumbnailHeight != nil {
+			blob.Thumbnail = &forum.Thumbnail{
+				ContentType: *thumbnailContentType,
+				Width:       *thumbnailWidth,
+				Height:      *thumbnailHeight,
+			}
+		}
+	}
+
+	return dest, finish
+}
+
+func scanBlob(row pgx.Row, blob *forum.Blob) error {
+	dest, finish := blobDest(blob)
+	err := row.Scan(dest...)
+	if err != nil {
+		return err
+	}
+
+	finish()
+	return nil
+}
+
+const attachmentColumns = "a.id, a.created_at, a.uploader_id, a.post_id, a.comment_id, a.filename, " + blobColumns
+
+func scanAttachment(row pgx.Row, attachment *forum.Attachment) error {
+	dest, finish := blobDest(&attachment.Blob)
+	dest = append([]any{
+		&attachment.Id,
+		&attachment.CreatedAt,
+		&attachment.UploaderId,
+		&attachment.PostId,
+		&attachment.CommentId,
+		&attachment.Filename,
+	}, dest...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return err
+	}
+
+	finish()
+	return nil
+}
+
+func (r AttachmentsRepo) CreateBlob(ctx context.Context, blob forum.Blob) (created bool, err error) {
+	const insertStmt = `INSERT INTO forum_blobs (sha256, content_type, size, status) VALUES ($1, $2, $3, $4)
+ON CONFLICT (sha256) DO NOTHING RETURNING TRUE`
+	args := []any{blob.Sha256, blob.ContentType, blob.Size, blob.Status}
+
+	rows, err := r.Query(ctx, insertStmt, args...)
+	if err != nil {
+		return false, postgres.TranslateError(err, "blob")
+	}
+	created = rows.Next()
+	rows.Close()
+	if rows.Err() != nil {
+		return false, postgres.TranslateError(rows.Err(), "blob")
+	} else if created {
+		return true, nil
+	}
+
+	locked, err := r.LockBlob(ctx, blob.Sha256)
+	if errors.Is(err, shared.ErrNotFound) {
+		// The blob was purged while waiting for the lock.
+		return r.CreateBlob(ctx, blob)
+	} else if err != nil {
+		return false, err
+	}
+
+	// The content of purging blobs may already be deleted, so it is stored
+	// again.
+	if locked.Status == forum.BlobStatusPurging {
+		return true, r.UpdateBlob(ctx, blob)
+	}
+
+	return false, nil
+}
+
+func (r AttachmentsRepo) LockBlob(ctx context.Context, sha256 string) (blob *forum.Blob, err error) {
+	const stmt = "SELECT " + blobColumns + " FROM forum_blobs b WHERE b.sha256 = $1 FOR UPDATE"
+	args := []any{sha256}
+
+	blob = &forum.Blob{}
+	err = scanBlob(r.QueryRow(ctx, stmt, args...), blob)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "blob")
+	}
+
+	return blob, nil
+}
+
+func (r AttachmentsRepo) UpdateBlob(ctx context.Context, blob forum.Blob) (err error) {
+	const stmt = `UPDATE forum_blobs SET size = $2, status = $3, width = $4, height = $5,
+    thumbnail_content_type = $6, thumbnail_width = $7, thumbnail_height = $8
+WHERE sha256 = $1`
+	args := []any{blob.Sha256, blob.Size, blob.Status, blob.Width, blob.Height, nil, nil, nil}
+	if blob.Thumbnail != nil {
+		args[5], args[6], args[7] = blob.Thumbnail.ContentType, blob.Thumbnail.Width, blob.Thumbnail.Height
+	}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "blob")
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "blob"}
+	}
+
+	return nil
+}
+
+func (r AttachmentsRepo) DeleteUnusedBlob(ctx context.Context, sha256 string) (deleted bool, err error) {
+	const lockStmt = "SELECT sha256 FROM forum_blobs WHERE sha256 = $1 FOR UPDATE"
+	args := []any{sha256}
+
+	rows, err := r.Query(ctx, lockStmt, args...)
+	if err != nil {
+		return false, err
+	}
+	found := rows.Next()
+	rows.Close()
+	if rows.Err() != nil || !found {
+		return false, rows.Err()
+	}
+
+	// Once the row is locked, the statement sees the attachments committed
+	// while waiting for the lock.
+	const deleteStmt = `DELETE FROM forum_blobs b WHERE sha256 = $1
+AND NOT EXISTS (SELECT 1 FROM forum_attachments a WHERE a.sha256 = b.sha256)`
+
+	tag, err := r.Exec(ctx, deleteStmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r AttachmentsRepo) CreateAttachment(ctx context.Context, uploaderId auth.UserId, value forum.AttachmentValue,
+	blob forum.Blob,
+) (attachment *forum.Attachment, err error) {
+	const stmt = `INSERT INTO forum_attachments (uploader_id, post_id, comment_id, filename, sha256)
+VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	args := []any{uploaderId, value.PostId, value.CommentId, value.Filename, blob.Sha256}
+
+	attachment = &forum.Attachment{
+		UploaderId:      uploaderId,
+		AttachmentValue: value,
+		Blob:            blob,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&attachment.Id, &attachment.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "attachment")
+	}
+
+	return attachment, nil
+}
+
+func (r AttachmentsRepo) GetAttachmentById(ctx context.Context, id forum.AttachmentId) (
+	attachment *forum.Attachment, err error,
+) {
+	const stmt = "SELECT " + attachmentColumns + " FROM forum_attachments a JOIN forum_blobs b ON b.sha256 = a.sha256 WHERE a.id = $1"
+	args := []any{id}
+
+	attachment = &forum.Attachment{}
+	err = scanAttachment(r.QueryRow(ctx, stmt, args...), attachment)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "attachment")
+	}
+
+	return attachment, nil
+}
+
+func (r AttachmentsRepo) GetAttachmentsByPosts(ctx context.Context, postIds []forum.PostId) (
+	attachments []forum.Attachment, err error,
+) {
+	const stmt = "SELECT " + attachmentColumns + " FROM forum_attachments a JOIN forum_blobs b ON b.sha256 = a.sha256 WHERE a.post_id = ANY($1) ORDER BY a.created_at, a.id"
+	args := []any{postIds}
+
+	return r.getAttachmentsAux(ctx, stmt, args)
+}
+
+func (r AttachmentsRepo) GetAttachmentsByComments(ctx context.Context, commentIds []forum.CommentId) (
+	attachments []forum.Attachment, err error,
+) {
+	const stmt = "SELECT " + attachmentColumns + " FROM forum_attachments a JOIN forum_blobs b ON b.sha256 = a.sha256 WHERE a.comment_id = ANY($1) ORDER BY a.created_at, a.id"
+	args := []any{commentIds}
+
+	return r.getAttachmentsAux(ctx, stmt, args)
+}
+
+func (r AttachmentsRepo) getAttachmentsAux(ctx context.Context, stmt string, args []any) (
+	attachments []forum.Attachment, err error,
+) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments = []forum.Attachment{}
+	for rows.Next() {
+		attachment := forum.Attachment{}
+		err = scanAttachment(rows, &attachment)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (r AttachmentsRepo) DeleteAttachment(ctx context.Context, id forum.AttachmentId) (err error) {
+	const stmt = "DELETE FROM forum_attachments WHERE id = $1 RETURNING id"
+	args := []any{id}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&id)
+	if err != nil {
+		return postgres.TranslateError(err, "attachment")
+	}
+
+	return nil
+}
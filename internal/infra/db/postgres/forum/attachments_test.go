@@ -0,0 +1,205 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestAttachmentsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewAttachmentsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	blob := forum.Blob{
+		Sha256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		ContentType: "text/plain; charset=utf-8",
+		Size:        4,
+		Status:      forum.BlobStatusReady,
+	}
+
+	setup := func(t *testing.T) (user *auth.User, post *forum.Post, comment *forum.Comment) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		comment, err = commentsRepo.CreateComment(ctx, post.Id, user.Id, forum.CommentValue{
+			Body: "Great post",
+		}, nil)
+		test.NilErr(t, err)
+
+		return user, post, comment
+	}
+
+	t.Run("blobs are created once", func(t *testing.T) {
+		setup(t)
+
+		created, err := repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+		test.Assert(t, "Blob should be created", created)
+
+		created, err = repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+		test.Assert(t, "Blob should not be created again", !created)
+	})
+
+	t.Run("purging blobs are created again", func(t *testing.T) {
+		setup(t)
+
+		_, err := repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+		_, err = pool.Exec(ctx, "UPDATE forum_blobs SET status = 'purging' WHERE sha256 = $1", blob.Sha256)
+		test.NilErr(t, err)
+
+		created, err := repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+		test.Assert(t, "Purging blob should be created again", created)
+
+		got, err := repo.LockBlob(ctx, blob.Sha256)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Status not as expected", forum.BlobStatusReady, got.Status)
+	})
+
+	t.Run("processed images keep their thumbnail", func(t *testing.T) {
+		setup(t)
+
+		image := forum.Blob{
+			Sha256:      "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752",
+			ContentType: "image/jpeg",
+			Size:        2048,
+			Status:      forum.BlobStatusPending,
+		}
+		_, err := repo.CreateBlob(ctx, image)
+		test.NilErr(t, err)
+
+		image.Size = 1024
+		image.Status = forum.BlobStatusReady
+		image.Width, image.Height = 640, 480
+		image.Thumbnail = &forum.Thumbnail{ContentType: "image/jpeg", Width: 320, Height: 240}
+		err = repo.UpdateBlob(ctx, image)
+		test.NilErr(t, err)
+
+		got, err := repo.LockBlob(ctx, image.Sha256)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Size not as expected", image.Size, got.Size)
+		test.AssertEqual(t, "Status not as expected", forum.BlobStatusReady, got.Status)
+		test.AssertEqual(t, "Width not as expected", 640, got.Width)
+		test.Assert(t, "Thumbnail should be set", got.Thumbnail != nil)
+		test.AssertEqual(t, "Thumbnail not as expected", *image.Thumbnail, *got.Thumbnail)
+
+		_, err = repo.LockBlob(ctx, blob.Sha256)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+		err = repo.UpdateBlob(ctx, blob)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("attachments share their blob", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		_, err := repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+
+		postAttachment, err := repo.CreateAttachment(ctx, user.Id, forum.AttachmentValue{
+			PostId:   &post.Id,
+			Filename: "test.txt",
+		}, blob)
+		test.NilErr(t, err)
+
+		commentAttachment, err := repo.CreateAttachment(ctx, user.Id, forum.AttachmentValue{
+			CommentId: &comment.Id,
+			Filename:  "copy.txt",
+		}, blob)
+		test.NilErr(t, err)
+
+		got, err := repo.GetAttachmentById(ctx, postAttachment.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Filename not as expected", "test.txt", got.Filename)
+		test.AssertEqual(t, "Blob not as expected", blob, got.Blob)
+		test.AssertEqual(t, "Post not as expected", post.Id, *got.PostId)
+		test.Assert(t, "Comment should not be set", got.CommentId == nil)
+
+		attachments, err := repo.GetAttachmentsByPosts(ctx, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of post attachments not as expected", 1, len(attachments))
+
+		attachments, err = repo.GetAttachmentsByComments(ctx, []forum.CommentId{comment.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of comment attachments not as expected", 1, len(attachments))
+		test.AssertEqual(t, "Comment attachment not as expected", commentAttachment.Id, attachments[0].Id)
+
+		test.NilErr(t, repo.DeleteAttachment(ctx, postAttachment.Id))
+		deleted, err := repo.DeleteUnusedBlob(ctx, blob.Sha256)
+		test.NilErr(t, err)
+		test.Assert(t, "Used blob should not be deleted", !deleted)
+
+		test.NilErr(t, repo.DeleteAttachment(ctx, commentAttachment.Id))
+		deleted, err = repo.DeleteUnusedBlob(ctx, blob.Sha256)
+		test.NilErr(t, err)
+		test.Assert(t, "Unused blob should be deleted", deleted)
+
+		deleted, err = repo.DeleteUnusedBlob(ctx, blob.Sha256)
+		test.NilErr(t, err)
+		test.Assert(t, "Missing blob should not be deleted", !deleted)
+	})
+
+	t.Run("attachments need exactly one parent", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		_, err := repo.CreateBlob(ctx, blob)
+		test.NilErr(t, err)
+
+		_, err = repo.CreateAttachment(ctx, user.Id, forum.AttachmentValue{Filename: "test.txt"}, blob)
+		test.Assert(t, "Expected error without parent", err != nil)
+
+		_, err = repo.CreateAttachment(ctx, user.Id, forum.AttachmentValue{
+			PostId:    &post.Id,
+			CommentId: &comment.Id,
+			Filename:  "test.txt",
+		}, blob)
+		test.Assert(t, "Expected error with both parents", err != nil)
+	})
+
+	t.Run("missing attachments are not found", func(t *testing.T) {
+		user, _, _ := setup(t)
+
+		_, err := repo.GetAttachmentById(ctx, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		err = repo.DeleteAttachment(ctx, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
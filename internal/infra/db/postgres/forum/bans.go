@@ -0,0 +1,164 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BansRepo implements the dbportsforum.BansRepo interface.
+type BansRepo struct {
+	postgres.BaseRepo
+}
+
+// NewBansRepo creates a new BansRepo.
+func NewBansRepo(pool *pgxpool.Pool) BansRepo {
+	return BansRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r BansRepo) BanUser(ctx context.Context, communityId forum.CommunityId, userId auth.UserId,
+	moderatorId auth.UserId, value forum.BanValue,
+) (ban *forum.Ban, err error) {
+	const stmt = `INSERT INTO forum_bans (community_id, user_id, moderator_id, reason, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (community_id, user_id) DO UPDATE
+    SET moderator_id = EXCLUDED.moderator_id, reason = EXCLUDED.reason, expires_at = EXCLUDED.expires_at,
+        created_at = NOW()
+RETURNING created_at`
+	args := []any{communityId, userId, moderatorId, value.Reason, value.ExpiresAt}
+
+	ban = &forum.Ban{
+		CommunityId: communityId,
+		UserId:      userId,
+		ModeratorId: &moderatorId,
+		BanValue:    value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&ban.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "ban")
+	}
+
+	return ban, nil
+}
+
+func (r BansRepo) UnbanUser(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	removed bool, err error,
+) {
+	const stmt = `DELETE FROM forum_bans WHERE community_id = $1 AND user_id = $2
+AND (expires_at IS NULL OR expires_at > NOW())`
+	args := []any{communityId, userId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r BansRepo) GetBan(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	ban *forum.Ban, err error,
+) {
+	const stmt = `SELECT moderator_id, reason, expires_at, created_at FROM forum_bans
+WHERE community_id = $1 AND user_id = $2 AND (expires_at IS NULL OR expires_at > NOW())`
+	args := []any{communityId, userId}
+
+	ban = &forum.Ban{
+		CommunityId: communityId,
+		UserId:      userId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&ban.ModeratorId, &ban.Reason, &ban.ExpiresAt, &ban.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "ban")
+	}
+
+	return ban, nil
+}
+
+func (r BansRepo) GetBans(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+	bans []forum.Ban, err error,
+) {
+	const stmt = `SELECT user_id, moderator_id, reason, expires_at, created_at FROM forum_bans
+WHERE community_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC, user_id LIMIT $2 OFFSET $3`
+	args := []any{communityId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans = make([]forum.Ban, 0, limit)
+	for rows.Next() {
+		ban := forum.Ban{
+			CommunityId: communityId,
+		}
+		err = rows.Scan(&ban.UserId, &ban.ModeratorId, &ban.Reason, &ban.ExpiresAt, &ban.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+func (r BansRepo) SuspendUser(ctx context.Context, userId auth.UserId, adminId auth.UserId, value forum.BanValue) (
+	suspension *forum.Suspension, err error,
+) {
+	const stmt = `INSERT INTO forum_suspensions (user_id, admin_id, reason, expires_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+    SET admin_id = EXCLUDED.admin_id, reason = EXCLUDED.reason, expires_at = EXCLUDED.expires_at,
+        created_at = NOW()
+RETURNING created_at`
+	args := []any{userId, adminId, value.Reason, value.ExpiresAt}
+
+	suspension = &forum.Suspension{
+		UserId:   userId,
+		AdminId:  &adminId,
+		BanValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&suspension.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "suspension")
+	}
+
+	return suspension, nil
+}
+
+func (r BansRepo) LiftSuspension(ctx context.Context, userId auth.UserId) (removed bool, err error) {
+	const stmt = `DELETE FROM forum_suspensions WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())`
+	args := []any{userId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r BansRepo) GetSuspension(ctx context.Context, userId auth.UserId) (suspension *forum.Suspension, err error) {
+	const stmt = `SELECT admin_id, reason, expires_at, created_at FROM forum_suspensions
+WHERE user_id = $1 AND (expires_at IS NULL OR expires_at > NOW())`
+	args := []any{userId}
+
+	suspension = &forum.Suspension{
+		UserId: userId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(
+		&suspension.AdminId, &suspension.Reason, &suspension.ExpiresAt, &suspension.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "suspension")
+	}
+
+	return suspension, nil
+}
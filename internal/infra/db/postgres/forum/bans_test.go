@@ -0,0 +1,139 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestBansRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewBansRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (moderator *auth.User, user *auth.User, community *forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		moderator, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "moderator",
+			DisplayName: "moderator",
+			Role:        auth.RoleAdmin,
+		})
+		test.NilErr(t, err)
+
+		user, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		return moderator, user, community
+	}
+
+	t.Run("banning again replaces the ban", func(t *testing.T) {
+		moderator, user, community := setup(t)
+
+		_, err := repo.GetBan(ctx, community.Id, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		expiresAt := time.Now().Add(time.Hour)
+		_, err = repo.BanUser(ctx, community.Id, user.Id, moderator.Id, forum.BanValue{
+			Reason:    "Spam",
+			ExpiresAt: &expiresAt,
+		})
+		test.NilErr(t, err)
+		_, err = repo.BanUser(ctx, community.Id, user.Id, moderator.Id, forum.BanValue{Reason: "Repeated spam"})
+		test.NilErr(t, err)
+
+		ban, err := repo.GetBan(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Reason not as expected", "Repeated spam", ban.Reason)
+		test.Assert(t, "Ban should be permanent", ban.IsPermanent())
+
+		bans, err := repo.GetBans(ctx, community.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of bans not as expected", 1, len(bans))
+
+		removed, err := repo.UnbanUser(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Ban should be lifted", removed)
+
+		_, err = repo.GetBan(ctx, community.Id, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("ignores expired bans", func(t *testing.T) {
+		moderator, user, community := setup(t)
+
+		expiresAt := time.Now().Add(-time.Minute)
+		_, err := repo.BanUser(ctx, community.Id, user.Id, moderator.Id, forum.BanValue{
+			Reason:    "Spam",
+			ExpiresAt: &expiresAt,
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.GetBan(ctx, community.Id, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		bans, err := repo.GetBans(ctx, community.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expired ban should not be listed", 0, len(bans))
+
+		removed, err := repo.UnbanUser(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expired ban should not be lifted", !removed)
+	})
+
+	t.Run("suspends users until expiry", func(t *testing.T) {
+		admin, user, _ := setup(t)
+
+		expiresAt := time.Now().Add(time.Hour)
+		_, err := repo.SuspendUser(ctx, user.Id, admin.Id, forum.BanValue{
+			Reason:    "Harassment",
+			ExpiresAt: &expiresAt,
+		})
+		test.NilErr(t, err)
+
+		suspension, err := repo.GetSuspension(ctx, user.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Reason not as expected", "Harassment", suspension.Reason)
+		test.Assert(t, "Suspension should be temporary", !suspension.IsPermanent())
+
+		expiresAt = time.Now().Add(-time.Minute)
+		_, err = repo.SuspendUser(ctx, user.Id, admin.Id, forum.BanValue{
+			Reason:    "Harassment",
+			ExpiresAt: &expiresAt,
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.GetSuspension(ctx, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		removed, err := repo.LiftSuspension(ctx, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expired suspension should not be lifted", !removed)
+	})
+}
@@ -6,7 +6,9 @@ import (
 
 	"greddit/internal/domains/auth"
 	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -39,7 +41,7 @@ func (c CommentsRepo) CreateComment(ctx context.Context, postId forum.PostId, co
 	}
 	err = c.QueryRow(ctx, stmt, args...).Scan(&comment.Id, &comment.CreatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "comment")
 	}
 
 	comment.UpdatedAt = comment.CreatedAt
@@ -47,36 +49,51 @@ func (c CommentsRepo) CreateComment(ctx context.Context, postId forum.PostId, co
 	return comment, nil
 }
 
-func (c CommentsRepo) GetCommentById(ctx context.Context, id forum.CommentId) (comment *forum.Comment, err error) {
-	const stmt = "SELECT body, created_at, updated_at, deleted_at, parent_id FROM forum_comments WHERE id = $1"
-	args := []any{id}
+func (c CommentsRepo) GetCommentById(ctx context.Context, id forum.CommentId, opts ...dbports.ReadOption) (
+	comment *forum.Comment, err error,
+) {
+	const stmt = `SELECT c.body, c.created_at, c.updated_at, c.deleted_at, c.post_id, c.commenter_id, c.parent_id, c.removed_at, c.removal_reason FROM forum_comments c
+JOIN forum_posts p ON p.id = c.post_id
+WHERE c.id = $1 AND ($2 OR c.deleted_at IS NULL) AND forum_community_visible(p.community_id, $3, $4)`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{id, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	comment = &forum.Comment{}
 	comment.Id = id
 	err = c.QueryRow(ctx, stmt, args...).Scan(
-		&comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt, &comment.ParentId,
+		&comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt,
+		&comment.PostId, &comment.CommenterId, &comment.ParentId,
+		&comment.RemovedAt, &comment.RemovalReason,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "comment")
 	}
 
 	return comment, nil
 }
 
 func (c CommentsRepo) GetCommentsByPostSortedCreatedAt(ctx context.Context, postId forum.PostId, limit int,
-	offset int,
+	offset int, opts ...dbports.ReadOption,
 ) (comments []forum.Comment, err error) {
-	const stmt = "SELECT id, body, created_at, updated_at, deleted_at, post_id, commenter_id, parent_id FROM forum_comments WHERE post_id = $1 ORDER BY created_at LIMIT $2 OFFSET $3"
-	args := []any{postId, limit, offset}
+	const stmt = `SELECT id, body, created_at, updated_at, deleted_at, post_id, commenter_id, parent_id, removed_at, removal_reason FROM forum_comments
+WHERE post_id = $1 AND ($4 OR deleted_at IS NULL)
+  AND forum_community_visible((SELECT community_id FROM forum_posts WHERE id = $1), $5, $6)
+ORDER BY created_at LIMIT $2 OFFSET $3`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{postId, limit, offset, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	return c.getCommentsAux(ctx, stmt, args, limit)
 }
 
 func (c CommentsRepo) GetCommentsByCommenterSortedCreatedAt(ctx context.Context, commenterId auth.UserId, limit int,
-	offset int,
+	offset int, opts ...dbports.ReadOption,
 ) (comments []forum.Comment, err error) {
-	const stmt = "SELECT id, body, created_at, updated_at, deleted_at, post_id, commenter_id, parent_id FROM forum_comments WHERE commenter_id = $1 ORDER BY created_at LIMIT $2 OFFSET $3"
-	args := []any{commenterId, limit, offset}
+	const stmt = `SELECT c.id, c.body, c.created_at, c.updated_at, c.deleted_at, c.post_id, c.commenter_id, c.parent_id, c.removed_at, c.removal_reason FROM forum_comments c
+JOIN forum_posts p ON p.id = c.post_id
+WHERE c.commenter_id = $1 AND ($4 OR c.deleted_at IS NULL) AND forum_community_visible(p.community_id, $5, $6)
+ORDER BY c.created_at LIMIT $2 OFFSET $3`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{commenterId, limit, offset, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	return c.getCommentsAux(ctx, stmt, args, limit)
 }
@@ -97,6 +114,7 @@ func (c CommentsRepo) getCommentsAux(ctx context.Context, stmt string, args []an
 			&comment.Id, &comment.Body,
 			&comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt,
 			&comment.PostId, &comment.CommenterId, &comment.ParentId,
+			&comment.RemovedAt, &comment.RemovalReason,
 		)
 		if err != nil {
 			return nil, err
@@ -116,21 +134,74 @@ func (c CommentsRepo) UpdateCommentBody(ctx context.Context, id forum.CommentId,
 	updatedAt = &time.Time{}
 	err = c.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "comment")
 	}
 
 	return updatedAt, nil
 }
 
 func (c CommentsRepo) DeleteComment(ctx context.Context, id forum.CommentId) (deletedAt *time.Time, err error) {
-	const stmt = "UPDATE forum_comments SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at"
+	const stmt = "UPDATE forum_comments SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at"
 	args := []any{id}
 
 	deletedAt = &time.Time{}
 	err = c.QueryRow(ctx, stmt, args...).Scan(&deletedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "comment")
 	}
 
 	return deletedAt, nil
 }
+
+func (c CommentsRepo) GetDeletedComments(ctx context.Context, commenterId *auth.UserId, limit int, offset int) (
+	comments []forum.Comment, err error,
+) {
+	const stmt = `SELECT id, body, created_at, updated_at, deleted_at, post_id, commenter_id, parent_id, removed_at, removal_reason FROM forum_comments
+WHERE deleted_at IS NOT NULL AND ($1::uuid IS NULL OR commenter_id = $1::uuid)
+ORDER BY deleted_at DESC LIMIT $2 OFFSET $3`
+	args := []any{commenterId, limit, offset}
+
+	return c.getCommentsAux(ctx, stmt, args, limit)
+}
+
+func (c CommentsRepo) RestoreComment(ctx context.Context, id forum.CommentId) (updatedAt *time.Time, err error) {
+	const stmt = "UPDATE forum_comments SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING updated_at"
+	args := []any{id}
+
+	updatedAt = &time.Time{}
+	err = c.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "comment")
+	}
+
+	return updatedAt, nil
+}
+
+func (c CommentsRepo) RemoveComment(ctx context.Context, id forum.CommentId, reason string) (
+	removedAt *time.Time, err error,
+) {
+	const stmt = "UPDATE forum_comments SET removed_at = NOW(), removal_reason = $2 WHERE id = $1 RETURNING removed_at"
+	args := []any{id, reason}
+
+	removedAt = &time.Time{}
+	err = c.QueryRow(ctx, stmt, args...).Scan(&removedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "comment")
+	}
+
+	return removedAt, nil
+}
+
+func (c CommentsRepo) ApproveComment(ctx context.Context, id forum.CommentId) (err error) {
+	const stmt = "UPDATE forum_comments SET removed_at = NULL, removal_reason = NULL WHERE id = $1"
+	args := []any{id}
+
+	tag, err := c.Exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "comment"}
+	}
+
+	return nil
+}
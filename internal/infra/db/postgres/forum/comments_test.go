@@ -1,15 +1,19 @@
 package forumdb
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"greddit/internal/domains/auth"
 	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
 	"greddit/internal/test"
 
 	authdb "greddit/internal/infra/db/postgres/auth"
+
+	dbports "greddit/internal/ports/db"
 )
 
 func TestCommentsRepo_CreateComment(t *testing.T) {
@@ -185,6 +189,8 @@ func TestCommentsRepo_GetCommentById(t *testing.T) {
 		test.NilErr(t, err)
 		test.AssertEqual(t, "Body not as expected", createdComment.Body, comment.Body)
 		test.AssertEqual(t, "ID not as expected", createdComment.Id, comment.Id)
+		test.AssertEqual(t, "PostId not as expected", createdComment.PostId, comment.PostId)
+		test.AssertEqual(t, "CommenterId not as expected", createdComment.CommenterId, comment.CommenterId)
 	})
 
 	t.Run("non-existent comment", func(t *testing.T) {
@@ -622,7 +628,7 @@ func TestCommentsRepo_DeleteComment(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify that the comment is soft-deleted
-		deletedComment, err := repo.GetCommentById(ctx, comment.Id)
+		deletedComment, err := repo.GetCommentById(ctx, comment.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected body", comment.Body, deletedComment.Body)
@@ -677,7 +683,7 @@ func TestCommentsRepo_DeleteComment(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify comment1 is deleted but comment2 is not
-		retrieved1, err := repo.GetCommentById(ctx, comment1.Id)
+		retrieved1, err := repo.GetCommentById(ctx, comment1.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		retrieved2, err := repo.GetCommentById(ctx, comment2.Id)
@@ -687,6 +693,53 @@ func TestCommentsRepo_DeleteComment(t *testing.T) {
 		test.Assert(t, "comment2 should not be deleted", retrieved2.DeletedAt == nil)
 	})
 
+	t.Run("deleted comment hidden by default", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		commenter, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "commenter",
+			DisplayName: "commenter",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "hidden",
+			Description: "Hidden",
+		})
+		test.NilErr(t, err)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, commenter.Id, forum.PostValue{
+			Title: "Hidden",
+			Body:  "Hidden",
+		})
+		test.NilErr(t, err)
+
+		comment, err := repo.CreateComment(ctx, post.Id, commenter.Id, forum.CommentValue{
+			Body: "Hidden",
+		}, nil)
+		test.NilErr(t, err)
+
+		_, err = repo.DeleteComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		var notFoundErr shared.NotFoundError
+		_, err = repo.GetCommentById(ctx, comment.Id)
+		test.Assert(t, "Expected not found error", errors.As(err, &notFoundErr))
+
+		comments, err := repo.GetCommentsByPostSortedCreatedAt(ctx, post.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no comments on post by default", 0, len(comments))
+
+		comments, err = repo.GetCommentsByCommenterSortedCreatedAt(ctx, commenter.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no comments by commenter by default", 0, len(comments))
+
+		comments, err = repo.GetCommentsByPostSortedCreatedAt(ctx, post.Id, 10, 0, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected deleted comment to be included", 1, len(comments))
+	})
+
 	t.Run("comment data still accessible after soft delete", func(t *testing.T) {
 		postgres.ClearAllTables(t, pool)
 
@@ -721,7 +774,7 @@ func TestCommentsRepo_DeleteComment(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Comment should still be retrievable
-		deletedComment, err := repo.GetCommentById(ctx, comment.Id)
+		deletedComment, err := repo.GetCommentById(ctx, comment.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected body", comment.Body, deletedComment.Body)
@@ -792,7 +845,7 @@ func TestCommentsRepo_Integration(t *testing.T) {
 		test.NilErr(t, err)
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
-		deleted, err := repo.GetCommentById(ctx, comment.Id)
+		deleted, err := repo.GetCommentById(ctx, comment.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 		test.Assert(t, "Expected comment to be soft-deleted", deleted.DeletedAt != nil)
 	})
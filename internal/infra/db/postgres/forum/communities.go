@@ -6,6 +6,7 @@ import (
 
 	"greddit/internal/domains/forum"
 	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -22,15 +23,19 @@ func NewCommunitiesRepo(pool *pgxpool.Pool) CommunitiesRepo {
 }
 
 func (r CommunitiesRepo) CreateCommunity(ctx context.Context, value forum.CommunityValue) (community *forum.Community, err error) {
-	const stmt = "INSERT INTO forum_communities (name, description) VALUES ($1, $2) RETURNING id, created_at"
+	const stmt = `INSERT INTO forum_communities (name, description) VALUES ($1, $2)
+RETURNING id, created_at, rules, allowed_post_types, require_flair, visibility`
 	args := []any{value.Name, value.Description}
 
 	community = &forum.Community{
 		CommunityValue: value,
 	}
-	err = r.QueryRow(ctx, stmt, args...).Scan(&community.Id, &community.CreatedAt)
+	err = r.QueryRow(ctx, stmt, args...).Scan(
+		&community.Id, &community.CreatedAt, &community.Settings.Rules, &community.Settings.AllowedPostTypes,
+		&community.Settings.RequireFlair, &community.Settings.Visibility,
+	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "community")
 	}
 
 	community.UpdatedAt = community.CreatedAt
@@ -38,45 +43,54 @@ func (r CommunitiesRepo) CreateCommunity(ctx context.Context, value forum.Commun
 	return community, nil
 }
 
-func (r CommunitiesRepo) GetCommunityById(ctx context.Context, id forum.CommunityId) (community *forum.Community, err error) {
-	const stmt = "SELECT name, description, created_at, updated_at, deleted_at FROM forum_communities WHERE id = $1"
-	args := []any{id}
+func (r CommunitiesRepo) GetCommunityById(ctx context.Context, id forum.CommunityId, opts ...dbports.ReadOption) (
+	community *forum.Community, err error,
+) {
+	const stmt = "SELECT name, description, created_at, updated_at, deleted_at, rules, allowed_post_types, require_flair, visibility FROM forum_communities WHERE id = $1 AND ($2 OR deleted_at IS NULL) AND forum_community_visible(id, $3, $4)"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{id, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	community = &forum.Community{}
+	community.Id = id
 
 	err = r.QueryRow(ctx, stmt, args...).Scan(
 		&community.Name, &community.Description, &community.CreatedAt, &community.UpdatedAt, &community.DeletedAt,
+		&community.Settings.Rules, &community.Settings.AllowedPostTypes, &community.Settings.RequireFlair,
+		&community.Settings.Visibility,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "community")
 	}
 
 	return community, nil
 }
 
-func (r CommunitiesRepo) GetAllCommunitiesSortedByName(ctx context.Context, limit int, offset int) (
-	communities []forum.Community, err error,
-) {
-	const stmt = "SELECT id, name, description, created_at, updated_at FROM forum_communities WHERE deleted_at IS NULL ORDER BY name LIMIT $1 OFFSET $2"
-	args := []any{limit, offset}
+func (r CommunitiesRepo) GetAllCommunitiesSortedByName(ctx context.Context, limit int, offset int,
+	opts ...dbports.ReadOption,
+) (communities []forum.Community, err error) {
+	const stmt = "SELECT id, name, description, created_at, updated_at, deleted_at, rules, allowed_post_types, require_flair, visibility FROM forum_communities WHERE ($3 OR deleted_at IS NULL) AND forum_community_visible(id, $4, $5) ORDER BY name LIMIT $1 OFFSET $2"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{limit, offset, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	return r.getAllCommunitiesAux(ctx, stmt, args, limit)
 }
 
-func (r CommunitiesRepo) GetAllCommunitiesSortedByCreatedAt(ctx context.Context, limit int, offset int) (
-	communities []forum.Community, err error,
-) {
-	const stmt = "SELECT id, name, description, created_at, updated_at FROM forum_communities WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2"
-	args := []any{limit, offset}
+func (r CommunitiesRepo) GetAllCommunitiesSortedByCreatedAt(ctx context.Context, limit int, offset int,
+	opts ...dbports.ReadOption,
+) (communities []forum.Community, err error) {
+	const stmt = "SELECT id, name, description, created_at, updated_at, deleted_at, rules, allowed_post_types, require_flair, visibility FROM forum_communities WHERE ($3 OR deleted_at IS NULL) AND forum_community_visible(id, $4, $5) ORDER BY created_at DESC LIMIT $1 OFFSET $2"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{limit, offset, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	return r.getAllCommunitiesAux(ctx, stmt, args, limit)
 }
 
-func (r CommunitiesRepo) GetAllCommunitiesSortedByUpdatedAt(ctx context.Context, limit int, offset int) (
-	communities []forum.Community, err error,
-) {
-	const stmt = "SELECT id, name, description, created_at, updated_at FROM forum_communities WHERE deleted_at IS NULL ORDER BY updated_at DESC LIMIT $1 OFFSET $2"
-	args := []any{limit, offset}
+func (r CommunitiesRepo) GetAllCommunitiesSortedByUpdatedAt(ctx context.Context, limit int, offset int,
+	opts ...dbports.ReadOption,
+) (communities []forum.Community, err error) {
+	const stmt = "SELECT id, name, description, created_at, updated_at, deleted_at, rules, allowed_post_types, require_flair, visibility FROM forum_communities WHERE ($3 OR deleted_at IS NULL) AND forum_community_visible(id, $4, $5) ORDER BY updated_at DESC LIMIT $1 OFFSET $2"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{limit, offset, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	return r.getAllCommunitiesAux(ctx, stmt, args, limit)
 }
@@ -92,7 +106,10 @@ func (r CommunitiesRepo) getAllCommunitiesAux(ctx context.Context, stmt string,
 
 	for rows.Next() {
 		c := forum.Community{}
-		err = rows.Scan(&c.Id, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt)
+		err = rows.Scan(
+			&c.Id, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
+			&c.Settings.Rules, &c.Settings.AllowedPostTypes, &c.Settings.RequireFlair, &c.Settings.Visibility,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -108,20 +125,71 @@ func (r CommunitiesRepo) UpdateCommunityDescription(ctx context.Context, id foru
 	updatedAt = &time.Time{}
 	err = r.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "community")
+	}
+
+	return updatedAt, nil
+}
+
+func (r CommunitiesRepo) UpdateCommunitySettings(ctx context.Context, id forum.CommunityId,
+	settings forum.CommunitySettings,
+) (updatedAt *time.Time, err error) {
+	const stmt = `UPDATE forum_communities
+SET rules = $2, allowed_post_types = $3, require_flair = $4, visibility = $5, updated_at = NOW()
+WHERE id = $1 RETURNING updated_at`
+	args := []any{id, settings.Rules, settings.AllowedPostTypes, settings.RequireFlair, settings.Visibility}
+
+	updatedAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "community")
 	}
 
 	return updatedAt, nil
 }
 
 func (r CommunitiesRepo) DeleteCommunity(ctx context.Context, id forum.CommunityId) (deletedAt *time.Time, err error) {
-	const stmt = "UPDATE forum_communities SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at"
+	const stmt = `WITH community AS (
+    UPDATE forum_communities SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at
+), posts AS (
+    UPDATE forum_posts p SET deleted_at = community.deleted_at, deleted_with_community = TRUE
+    FROM community WHERE p.community_id = $1 AND p.deleted_at IS NULL
+)
+SELECT deleted_at FROM community`
 	args := []any{id}
 
 	deletedAt = &time.Time{}
 	err = r.QueryRow(ctx, stmt, args...).Scan(&deletedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "community")
 	}
 	return deletedAt, nil
 }
+
+func (r CommunitiesRepo) GetDeletedCommunities(ctx context.Context, limit int, offset int) (
+	communities []forum.Community, err error,
+) {
+	const stmt = "SELECT id, name, description, created_at, updated_at, deleted_at, rules, allowed_post_types, require_flair, visibility FROM forum_communities WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT $1 OFFSET $2"
+	args := []any{limit, offset}
+
+	return r.getAllCommunitiesAux(ctx, stmt, args, limit)
+}
+
+func (r CommunitiesRepo) RestoreCommunity(ctx context.Context, id forum.CommunityId) (updatedAt *time.Time, err error) {
+	const stmt = `WITH community AS (
+    UPDATE forum_communities SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+    RETURNING updated_at
+), posts AS (
+    UPDATE forum_posts p SET deleted_at = NULL, deleted_with_community = FALSE
+    FROM community WHERE p.community_id = $1 AND p.deleted_with_community
+)
+SELECT updated_at FROM community`
+	args := []any{id}
+
+	updatedAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "community")
+	}
+	return updatedAt, nil
+}
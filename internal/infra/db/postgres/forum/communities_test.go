@@ -1,13 +1,18 @@
 package forumdb
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
 	"greddit/internal/test"
+
+	dbports "greddit/internal/ports/db"
 )
 
 func TestCommunitiesRepo_CreateCommunity(t *testing.T) {
@@ -68,6 +73,10 @@ func TestCommunitiesRepo_CreateCommunity(t *testing.T) {
 		// Try to create another community with the same name
 		_, err = repo.CreateCommunity(ctx, value)
 		test.Assert(t, "Expected error for duplicate community name", err != nil)
+
+		var conflictErr shared.ConflictError
+		test.Assert(t, "Expected conflict error", errors.As(err, &conflictErr))
+		test.AssertEqual(t, "Conflicting field not as expected", "name", conflictErr.Field)
 	})
 }
 
@@ -338,6 +347,61 @@ func TestCommunitiesRepo_UpdateCommunityDescription(t *testing.T) {
 	})
 }
 
+func TestCommunitiesRepo_UpdateCommunitySettings(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewCommunitiesRepo(pool)
+	ctx := t.Context()
+
+	t.Run("new communities have the default settings", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		community, err := repo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "defaults",
+			Description: strings.Repeat("a", 2048),
+		})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Settings not as expected", forum.DefaultCommunitySettings(), community.Settings)
+	})
+
+	t.Run("update settings", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		community, err := repo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "settings",
+			Description: "Settings community",
+		})
+		test.NilErr(t, err)
+
+		settings := forum.CommunitySettings{
+			Rules: []forum.CommunityRule{
+				{Title: "Be civil", Description: "No personal attacks"},
+				{Title: "Stay on topic"},
+			},
+			AllowedPostTypes: []forum.PostType{forum.PostTypeText, forum.PostTypeLink},
+			RequireFlair:     true,
+			Visibility:       forum.CommunityVisibilityRestricted,
+		}
+		updatedAt, err := repo.UpdateCommunitySettings(ctx, community.Id, settings)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected a non-nil updated timestamp", updatedAt != nil)
+
+		updatedCommunity, err := repo.GetCommunityById(ctx, community.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Settings not as expected", settings, updatedCommunity.Settings)
+	})
+
+	t.Run("non-existent community", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		_, err := repo.UpdateCommunitySettings(ctx, forum.CommunityId{}, forum.DefaultCommunitySettings())
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
+
 func TestCommunitiesRepo_DeleteCommunity(t *testing.T) {
 	t.Parallel()
 
@@ -365,7 +429,7 @@ func TestCommunitiesRepo_DeleteCommunity(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify that the community is soft-deleted
-		deletedCommunity, err := repo.GetCommunityById(ctx, community.Id)
+		deletedCommunity, err := repo.GetCommunityById(ctx, community.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected name", community.Name, deletedCommunity.Name)
@@ -405,7 +469,7 @@ func TestCommunitiesRepo_DeleteCommunity(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify community1 is deleted but community2 is not
-		retrieved1, err := repo.GetCommunityById(ctx, community1.Id)
+		retrieved1, err := repo.GetCommunityById(ctx, community1.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		retrieved2, err := repo.GetCommunityById(ctx, community2.Id)
@@ -415,6 +479,31 @@ func TestCommunitiesRepo_DeleteCommunity(t *testing.T) {
 		test.Assert(t, "community2 should not be deleted", retrieved2.DeletedAt == nil)
 	})
 
+	t.Run("deleted community hidden by default", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		community, err := repo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "hidden",
+			Description: "Hidden",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.DeleteCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+
+		var notFoundErr shared.NotFoundError
+		_, err = repo.GetCommunityById(ctx, community.Id)
+		test.Assert(t, "Expected not found error", errors.As(err, &notFoundErr))
+
+		communities, err := repo.GetAllCommunitiesSortedByName(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no communities by default", 0, len(communities))
+
+		communities, err = repo.GetAllCommunitiesSortedByName(ctx, 10, 0, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected deleted community to be included", 1, len(communities))
+	})
+
 	t.Run("community data still accessible after soft delete", func(t *testing.T) {
 		postgres.ClearAllTables(t, pool)
 
@@ -432,7 +521,7 @@ func TestCommunitiesRepo_DeleteCommunity(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Community should still be retrievable
-		deletedCommunity, err := repo.GetCommunityById(ctx, community.Id)
+		deletedCommunity, err := repo.GetCommunityById(ctx, community.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected name", community.Name, deletedCommunity.Name)
@@ -483,7 +572,7 @@ func TestCommunitiesRepo_Integration(t *testing.T) {
 		test.NilErr(t, err)
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
-		deleted, err := repo.GetCommunityById(ctx, community.Id)
+		deleted, err := repo.GetCommunityById(ctx, community.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 		test.Assert(t, "Expected community to be soft-deleted", deleted.DeletedAt != nil)
 	})
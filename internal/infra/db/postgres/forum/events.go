@@ -0,0 +1,157 @@
+package forumdb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// eventsChannel is the channel on which published events are announced.
+const eventsChannel = "forum_events"
+
+// EventsRepo implements the dbportsforum.EventsRepo interface.
+type EventsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewEventsRepo creates a new EventsRepo.
+func NewEventsRepo(pool *pgxpool.Pool) EventsRepo {
+	return EventsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+const eventColumns = "id, created_at, kind, community_id, post_id, recipient_id, data"
+
+func scanEvent(row pgx.Row, event *forum.Event) error {
+	return row.Scan(
+		&event.Id,
+		&event.CreatedAt,
+		&event.Kind,
+		&event.CommunityId,
+		&event.PostId,
+		&event.RecipientId,
+		&event.Data,
+	)
+}
+
+func (r EventsRepo) PublishEvent(ctx context.Context, value forum.EventValue) (event *forum.Event, err error) {
+	// Notifications are only delivered once the transaction commits, so
+	// listeners never see events which are rolled back.
+	const stmt = `WITH e AS (
+    INSERT INTO forum_events (kind, community_id, post_id, recipient_id, data)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING id, created_at
+)
+SELECT e.id, e.created_at FROM e, LATERAL pg_notify($6, e.id::TEXT)`
+	args := []any{string(value.Kind), value.CommunityId, value.PostId, value.RecipientId, value.Data, eventsChannel}
+
+	event = &forum.Event{
+		EventValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&event.Id, &event.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "event")
+	}
+
+	return event, nil
+}
+
+func (r EventsRepo) GetEventById(ctx context.Context, id forum.EventId) (event *forum.Event, err error) {
+	const stmt = "SELECT " + eventColumns + " FROM forum_events WHERE id = $1"
+	args := []any{id}
+
+	event = &forum.Event{}
+	err = scanEvent(r.QueryRow(ctx, stmt, args...), event)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "event")
+	}
+
+	return event, nil
+}
+
+func (r EventsRepo) GetEventsAfter(ctx context.Context, afterId forum.EventId, limit int) (
+	events []forum.Event, err error,
+) {
+	const stmt = "SELECT " + eventColumns + " FROM forum_events WHERE id > $1 ORDER BY id LIMIT $2"
+	args := []any{afterId, limit}
+
+	return r.getEvents(ctx, stmt, args, limit)
+}
+
+func (r EventsRepo) GetMatchingEventsAfter(ctx context.Context, afterId forum.EventId, filter forum.EventFilter,
+	limit int,
+) (events []forum.Event, err error) {
+	// Mirrors forum.EventFilter.Matches.
+	const stmt = "SELECT " + eventColumns + ` FROM forum_events
+WHERE id > $1 AND (
+    (kind = $2 AND community_id = ANY ($3)) OR
+    (kind = ANY ($4) AND post_id = ANY ($5)) OR
+    (kind = $6 AND recipient_id = $7)
+)
+ORDER BY id LIMIT $8`
+	args := []any{
+		afterId,
+		string(forum.EventKindPostCreated), filter.CommunityIds.Keys(),
+		[]string{
+			string(forum.EventKindCommentCreated),
+			string(forum.EventKindCommentUpdated),
+			string(forum.EventKindCommentDeleted),
+		}, filter.PostIds.Keys(),
+		string(forum.EventKindNotification), filter.RecipientId,
+		limit,
+	}
+
+	return r.getEvents(ctx, stmt, args, limit)
+}
+
+// getEvents returns the events selected by the statement.
+func (r EventsRepo) getEvents(ctx context.Context, stmt string, args []any, limit int) (
+	events []forum.Event, err error,
+) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events = make([]forum.Event, 0, limit)
+	for rows.Next() {
+		var event forum.Event
+		err = scanEvent(rows, &event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r EventsRepo) DeleteEventsBefore(ctx context.Context, before time.Time) (count int64, err error) {
+	const stmt = "DELETE FROM forum_events WHERE created_at < $1"
+	args := []any{before}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r EventsRepo) Listen(ctx context.Context, listening func(), handle func(id forum.EventId)) (err error) {
+	return r.BaseRepo.Listen(ctx, eventsChannel, listening, func(payload string) {
+		id, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return
+		}
+		handle(id)
+	})
+}
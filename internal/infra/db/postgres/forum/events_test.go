@@ -0,0 +1,161 @@
+package forumdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+	"greddit/internal/util/set"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestEventsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewEventsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	txs := postgres.NewTransactional(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) *forum.Post {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		return post
+	}
+
+	publish := func(t *testing.T, ctx context.Context, post *forum.Post) *forum.Event {
+		t.Helper()
+
+		value, err := forum.NewPostCreatedEvent(*post)
+		test.NilErr(t, err)
+
+		event, err := repo.PublishEvent(ctx, value)
+		test.NilErr(t, err)
+
+		return event
+	}
+
+	t.Run("publish and read", func(t *testing.T) {
+		post := setup(t)
+
+		first := publish(t, ctx, post)
+		second := publish(t, ctx, post)
+		test.Assert(t, "Expected increasing event IDs", second.Id > first.Id)
+
+		found, err := repo.GetEventById(ctx, first.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Event kind not as expected", forum.EventKindPostCreated, found.Kind)
+		test.AssertEqual(t, "Event community not as expected", post.CommunityId, *found.CommunityId)
+		var data forum.Post
+		test.NilErr(t, json.Unmarshal(found.Data, &data))
+		test.AssertEqual(t, "Event data not as expected", post.Id, data.Id)
+
+		events, err := repo.GetEventsAfter(ctx, first.Id, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of events not as expected", 1, len(events))
+		test.AssertEqual(t, "Event not as expected", second.Id, events[0].Id)
+
+		matching, err := repo.GetMatchingEventsAfter(ctx, 0, forum.EventFilter{
+			CommunityIds: set.New[forum.CommunityId](set.WithSlice([]forum.CommunityId{post.CommunityId})),
+		}, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of matching events not as expected", 2, len(matching))
+
+		matching, err = repo.GetMatchingEventsAfter(ctx, 0, forum.EventFilter{
+			CommunityIds: set.New[forum.CommunityId](),
+			PostIds:      set.New[forum.PostId](set.WithSlice([]forum.PostId{post.Id})),
+		}, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no matching events", 0, len(matching))
+
+		_, err = repo.GetEventById(ctx, second.Id+1)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("delete old events", func(t *testing.T) {
+		post := setup(t)
+
+		publish(t, ctx, post)
+		count, err := repo.DeleteEventsBefore(ctx, time.Now().Add(-time.Hour))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected recent events to be kept", int64(0), count)
+
+		count, err = repo.DeleteEventsBefore(ctx, time.Now().Add(time.Hour))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of deleted events not as expected", int64(1), count)
+	})
+
+	t.Run("listeners are notified on commit", func(t *testing.T) {
+		post := setup(t)
+
+		listenCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		listening := make(chan struct{})
+		ids := make(chan forum.EventId, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- repo.Listen(listenCtx, func() {
+				close(listening)
+			}, func(id forum.EventId) {
+				ids <- id
+			})
+		}()
+		<-listening
+
+		txCtx, err := txs.CtxTx(ctx)
+		test.NilErr(t, err)
+		publish(t, txCtx, post)
+		test.NilErr(t, txs.TxRollback(txCtx))
+
+		txCtx, err = txs.CtxTx(ctx)
+		test.NilErr(t, err)
+		committed := publish(t, txCtx, post)
+		test.NilErr(t, txs.TxCommit(txCtx))
+
+		select {
+		case id := <-ids:
+			test.AssertEqual(t, "Announced event not as expected", committed.Id, id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for event announcement")
+		}
+
+		cancel()
+		err = <-done
+		test.Assert(t, "Expected listening to stop with the context", errors.Is(err, context.Canceled))
+	})
+}
@@ -0,0 +1,118 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FlairsRepo implements the dbportsforum.FlairsRepo interface.
+type FlairsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewFlairsRepo creates a new FlairsRepo.
+func NewFlairsRepo(pool *pgxpool.Pool) FlairsRepo {
+	return FlairsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r FlairsRepo) CreateFlair(ctx context.Context, communityId forum.CommunityId, value forum.FlairValue) (
+	flair *forum.Flair, err error,
+) {
+	const stmt = "INSERT INTO forum_flairs (community_id, text, color) VALUES ($1, $2, $3) RETURNING id, created_at"
+	args := []any{communityId, value.Text, value.Color}
+
+	flair = &forum.Flair{
+		CommunityId: communityId,
+		FlairValue:  value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&flair.Id, &flair.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "flair")
+	}
+
+	return flair, nil
+}
+
+func (r FlairsRepo) GetFlairById(ctx context.Context, id forum.FlairId) (flair *forum.Flair, err error) {
+	const stmt = "SELECT community_id, text, color, created_at FROM forum_flairs WHERE id = $1"
+	args := []any{id}
+
+	flair = &forum.Flair{
+		Id: id,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&flair.CommunityId, &flair.Text, &flair.Color, &flair.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "flair")
+	}
+
+	return flair, nil
+}
+
+func (r FlairsRepo) GetFlairsByIds(ctx context.Context, ids []forum.FlairId) (flairs []forum.Flair, err error) {
+	const stmt = "SELECT id, community_id, text, color, created_at FROM forum_flairs WHERE id = ANY($1)"
+	args := []any{ids}
+
+	return r.getFlairsAux(ctx, stmt, args)
+}
+
+func (r FlairsRepo) GetFlairsByCommunity(ctx context.Context, communityId forum.CommunityId) (
+	flairs []forum.Flair, err error,
+) {
+	const stmt = "SELECT id, community_id, text, color, created_at FROM forum_flairs WHERE community_id = $1 ORDER BY text"
+	args := []any{communityId}
+
+	return r.getFlairsAux(ctx, stmt, args)
+}
+
+func (r FlairsRepo) getFlairsAux(ctx context.Context, stmt string, args []any) (flairs []forum.Flair, err error) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flairs = []forum.Flair{}
+	for rows.Next() {
+		flair := forum.Flair{}
+		err = rows.Scan(&flair.Id, &flair.CommunityId, &flair.Text, &flair.Color, &flair.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		flairs = append(flairs, flair)
+	}
+
+	return flairs, rows.Err()
+}
+
+func (r FlairsRepo) UpdateFlair(ctx context.Context, id forum.FlairId, value forum.FlairValue) (err error) {
+	const stmt = "UPDATE forum_flairs SET text = $2, color = $3 WHERE id = $1"
+	args := []any{id, value.Text, value.Color}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "flair")
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "flair"}
+	}
+
+	return nil
+}
+
+func (r FlairsRepo) DeleteFlair(ctx context.Context, id forum.FlairId) (err error) {
+	const stmt = "DELETE FROM forum_flairs WHERE id = $1 RETURNING id"
+	args := []any{id}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&id)
+	if err != nil {
+		return postgres.TranslateError(err, "flair")
+	}
+
+	return nil
+}
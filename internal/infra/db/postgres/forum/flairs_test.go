@@ -0,0 +1,136 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestFlairsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewFlairsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (community *forum.Community, post *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		return community, post
+	}
+
+	t.Run("flairs are unique per community and sorted by text", func(t *testing.T) {
+		community, _ := setup(t)
+
+		_, err := repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Question", Color: "#0000ff"})
+		test.NilErr(t, err)
+		_, err = repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Answered", Color: "#00ff00"})
+		test.NilErr(t, err)
+
+		_, err = repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Question", Color: "#ff0000"})
+		test.Assert(t, "Expected conflict error", errors.Is(err, shared.ErrConflict))
+
+		flairs, err := repo.GetFlairsByCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of flairs not as expected", 2, len(flairs))
+		test.AssertEqual(t, "First flair not as expected", "Answered", flairs[0].Text)
+		test.AssertEqual(t, "Second flair not as expected", "Question", flairs[1].Text)
+	})
+
+	t.Run("updating a flair replaces its value", func(t *testing.T) {
+		community, _ := setup(t)
+
+		flair, err := repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Question", Color: "#0000ff"})
+		test.NilErr(t, err)
+
+		err = repo.UpdateFlair(ctx, flair.Id, forum.FlairValue{Text: "Help", Color: "#ff0000"})
+		test.NilErr(t, err)
+
+		got, err := repo.GetFlairById(ctx, flair.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Text not as expected", "Help", got.Text)
+		test.AssertEqual(t, "Color not as expected", "#ff0000", got.Color)
+	})
+
+	t.Run("deleting a flair removes it from posts", func(t *testing.T) {
+		community, post := setup(t)
+
+		flair, err := repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Question", Color: "#0000ff"})
+		test.NilErr(t, err)
+
+		err = postsRepo.SetPostFlair(ctx, post.Id, &flair.Id)
+		test.NilErr(t, err)
+
+		got, err := postsRepo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Post should carry the flair", got.FlairId != nil && *got.FlairId == flair.Id)
+
+		err = repo.DeleteFlair(ctx, flair.Id)
+		test.NilErr(t, err)
+
+		got, err = postsRepo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Post should not carry a flair", got.FlairId == nil)
+
+		err = repo.DeleteFlair(ctx, flair.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("community posts are filtered by flair", func(t *testing.T) {
+		community, post := setup(t)
+
+		flair, err := repo.CreateFlair(ctx, community.Id, forum.FlairValue{Text: "Question", Color: "#0000ff"})
+		test.NilErr(t, err)
+
+		_, err = postsRepo.CreatePost(ctx, community.Id, post.PosterId, forum.PostValue{
+			Title: "Iterators",
+			Body:  "Range over functions",
+		})
+		test.NilErr(t, err)
+
+		err = postsRepo.SetPostFlair(ctx, post.Id, &flair.Id)
+		test.NilErr(t, err)
+
+		posts, err := postsRepo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{FlairId: &flair.Id}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of posts not as expected", 1, len(posts))
+		test.AssertEqual(t, "Post not as expected", post.Id, posts[0].Id)
+
+		posts, err = postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortNew, forum.PostFilter{FlairId: &flair.Id}, nil, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of feed posts not as expected", 1, len(posts))
+	})
+}
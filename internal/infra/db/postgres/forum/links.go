@@ -0,0 +1,202 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LinksRepo implements the dbportsforum.LinksRepo interface.
+type LinksRepo struct {
+	postgres.BaseRepo
+}
+
+// NewLinksRepo creates a new LinksRepo.
+func NewLinksRepo(pool *pgxpool.Pool) LinksRepo {
+	return LinksRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+// insertLinkStmt inserts a link, resolving it to the oldest live post with a
+// matching title and, if given, community name. Failing that, it resolves to
+// the live post most recently redirected from a matching title and community.
+const insertLinkStmt = `INSERT INTO forum_links (source_post_id, source_comment_id, target_community, target_title, target_post_id)
+SELECT $1::uuid, $2::uuid, $3::text, $4::text, COALESCE((
+    SELECT p.id FROM forum_posts p JOIN forum_communities c ON c.id = p.community_id
+    WHERE LOWER(p.title) = LOWER($4::text) AND ($3::text = '' OR LOWER(c.name) = LOWER($3::text)) AND p.deleted_at IS NULL
+    ORDER BY p.created_at LIMIT 1
+), (
+    SELECT p.id FROM forum_post_redirects r
+    JOIN forum_communities c ON c.id = r.community_id
+    JOIN forum_posts p ON p.id = r.post_id
+    WHERE LOWER(r.title) = LOWER($4::text) AND ($3::text = '' OR LOWER(c.name) = LOWER($3::text)) AND p.deleted_at IS NULL
+    ORDER BY r.created_at DESC LIMIT 1
+))
+ON CONFLICT DO NOTHING`
+
+// linkColumns are the columns selected for a link, with links to soft-deleted
+// posts treated as dangling. Expects the links table as l and the target post
+// table as t, left joined only if the target is visible to the viewer so that
+// links to posts in hidden communities are dangling too.
+const linkColumns = "l.target_community, l.target_title, l.source_post_id, l.source_comment_id, CASE WHEN t.deleted_at IS NULL THEN t.id END, l.created_at"
+
+func (r LinksRepo) ReplacePostLinks(ctx context.Context, postId forum.PostId, links []forum.WikiLink) (err error) {
+	const stmt = "DELETE FROM forum_links WHERE source_post_id = $1 AND source_comment_id IS NULL"
+	args := []any{postId}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	return r.insertLinks(ctx, postId, nil, links)
+}
+
+func (r LinksRepo) ReplaceCommentLinks(ctx context.Context, postId forum.PostId, commentId forum.CommentId,
+	links []forum.WikiLink,
+) (err error) {
+	const stmt = "DELETE FROM forum_links WHERE source_comment_id = $1"
+	args := []any{commentId}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	return r.insertLinks(ctx, postId, &commentId, links)
+}
+
+func (r LinksRepo) insertLinks(ctx context.Context, postId forum.PostId, commentId *forum.CommentId,
+	links []forum.WikiLink,
+) (err error) {
+	for _, link := range links {
+		args := []any{postId, commentId, link.Community, link.Title}
+
+		_, err = r.Exec(ctx, insertLinkStmt, args...)
+		if err != nil {
+			return postgres.TranslateError(err, "link")
+		}
+	}
+
+	return nil
+}
+
+func (r LinksRepo) ResolveDanglingLinks(ctx context.Context, postId forum.PostId) (err error) {
+	const stmt = `UPDATE forum_links l SET target_post_id = p.id
+FROM forum_posts p JOIN forum_communities c ON c.id = p.community_id
+WHERE p.id = $1 AND l.target_post_id IS NULL AND LOWER(l.target_title) = LOWER(p.title)
+  AND (l.target_community = '' OR LOWER(l.target_community) = LOWER(c.name))`
+	args := []any{postId}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	return err
+}
+
+func (r LinksRepo) GetLinksByPosts(ctx context.Context, postIds []forum.PostId, opts ...dbports.ReadOption) (
+	links []forum.Link, err error,
+) {
+	const stmt = `SELECT ` + linkColumns + ` FROM forum_links l
+LEFT JOIN forum_posts t ON t.id = l.target_post_id AND forum_community_visible(t.community_id, $2, $3)
+WHERE l.source_post_id = ANY($1) AND l.source_comment_id IS NULL ORDER BY l.created_at`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{postIds, options.ViewerId, options.IncludePrivate}
+
+	return r.getLinksAux(ctx, stmt, args)
+}
+
+func (r LinksRepo) GetLinksByComments(ctx context.Context, commentIds []forum.CommentId, opts ...dbports.ReadOption) (
+	links []forum.Link, err error,
+) {
+	const stmt = `SELECT ` + linkColumns + ` FROM forum_links l
+LEFT JOIN forum_posts t ON t.id = l.target_post_id AND forum_community_visible(t.community_id, $2, $3)
+WHERE l.source_comment_id = ANY($1) ORDER BY l.created_at`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{commentIds, options.ViewerId, options.IncludePrivate}
+
+	return r.getLinksAux(ctx, stmt, args)
+}
+
+func (r LinksRepo) GetDanglingLinks(ctx context.Context, limit int, offset int, opts ...dbports.ReadOption) (
+	links []forum.Link, err error,
+) {
+	const stmt = `SELECT ` + linkColumns + ` FROM forum_links l
+LEFT JOIN forum_posts t ON t.id = l.target_post_id AND forum_community_visible(t.community_id, $3, $4)
+JOIN forum_posts s ON s.id = l.source_post_id
+LEFT JOIN forum_comments c ON c.id = l.source_comment_id
+WHERE (t.id IS NULL OR t.deleted_at IS NOT NULL) AND s.deleted_at IS NULL AND c.deleted_at IS NULL
+  AND ($5 OR (s.removed_at IS NULL AND c.removed_at IS NULL)) AND forum_community_visible(s.community_id, $3, $4)
+ORDER BY l.created_at LIMIT $1 OFFSET $2`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{limit, offset, options.ViewerId, options.IncludePrivate, options.IncludeRemoved}
+
+	return r.getLinksAux(ctx, stmt, args)
+}
+
+func (r LinksRepo) getLinksAux(ctx context.Context, stmt string, args []any) (links []forum.Link, err error) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links = []forum.Link{}
+	for rows.Next() {
+		link := forum.Link{}
+		err = scanLink(rows, &link)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r LinksRepo) GetBacklinks(ctx context.Context, postId forum.PostId, opts ...dbports.ReadOption) (
+	backlinks []forum.Backlink, err error,
+) {
+	const stmt = `SELECT ` + linkColumns + `, s.title FROM forum_links l
+JOIN forum_posts t ON t.id = l.target_post_id
+JOIN forum_posts s ON s.id = l.source_post_id
+LEFT JOIN forum_comments c ON c.id = l.source_comment_id
+WHERE l.target_post_id = $1 AND NOT (l.source_post_id = $1 AND l.source_comment_id IS NULL)
+  AND s.deleted_at IS NULL AND c.deleted_at IS NULL AND ($4 OR (s.removed_at IS NULL AND c.removed_at IS NULL))
+  AND forum_community_visible(s.community_id, $2, $3)
+ORDER BY l.created_at`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{postId, options.ViewerId, options.IncludePrivate, options.IncludeRemoved}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backlinks = []forum.Backlink{}
+	for rows.Next() {
+		backlink := forum.Backlink{}
+		err = scanLink(rows, &backlink.Link, &backlink.SourceTitle)
+		if err != nil {
+			return nil, err
+		}
+		backlinks = append(backlinks, backlink)
+	}
+
+	return backlinks, rows.Err()
+}
+
+// scanLink scans the link columns into the link, followed by any extra
+// destinations.
+func scanLink(rows pgx.Rows, link *forum.Link, extra ...any) error {
+	dest := append([]any{
+		&link.Community, &link.Title, &link.SourcePostId, &link.SourceCommentId, &link.TargetPostId, &link.CreatedAt,
+	}, extra...)
+
+	return rows.Scan(dest...)
+}
@@ -0,0 +1,281 @@
+package forumdb
+
+import (
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	dbports "greddit/internal/ports/db"
+)
+
+func TestLinksRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewLinksRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (poster *auth.User, community *forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		return poster, community
+	}
+
+	t.Run("links resolve to existing posts", func(t *testing.T) {
+		poster, community := setup(t)
+
+		target, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Target Post",
+			Body:  "Target",
+		})
+		test.NilErr(t, err)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[target post]] [[golang/Target Post]] [[other/Target Post]] [[Missing]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByPosts(ctx, []forum.PostId{source.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 4 links", 4, len(links))
+
+		test.Assert(t, "Expected title link to resolve", links[0].TargetPostId != nil && *links[0].TargetPostId == target.Id)
+		test.Assert(t, "Expected community link to resolve", links[1].TargetPostId != nil && *links[1].TargetPostId == target.Id)
+		test.Assert(t, "Expected link to other community to dangle", links[2].TargetPostId == nil)
+		test.Assert(t, "Expected missing link to dangle", links[3].TargetPostId == nil)
+
+		backlinks, err := repo.GetBacklinks(ctx, target.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 2 backlinks", 2, len(backlinks))
+		test.AssertEqual(t, "Unexpected source title", source.Title, backlinks[0].SourceTitle)
+
+		dangling, err := repo.GetDanglingLinks(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 2 dangling links", 2, len(dangling))
+	})
+
+	t.Run("replacing links removes old links", func(t *testing.T) {
+		poster, community := setup(t)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[First]] [[Second]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks("[[Third]]"))
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByPosts(ctx, []forum.PostId{source.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 link", 1, len(links))
+		test.AssertEqual(t, "Unexpected link title", "Third", links[0].Title)
+	})
+
+	t.Run("dangling links resolve once the post is created", func(t *testing.T) {
+		poster, community := setup(t)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[Later Post]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		later, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Later Post",
+			Body:  "Later",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ResolveDanglingLinks(ctx, later.Id)
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByPosts(ctx, []forum.PostId{source.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 link", 1, len(links))
+		test.Assert(t, "Expected link to resolve", links[0].TargetPostId != nil && *links[0].TargetPostId == later.Id)
+	})
+
+	t.Run("links from comments", func(t *testing.T) {
+		poster, community := setup(t)
+
+		target, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Target Post",
+			Body:  "Target",
+		})
+		test.NilErr(t, err)
+
+		comment, err := commentsRepo.CreateComment(ctx, target.Id, poster.Id, forum.CommentValue{
+			Body: "Self reference to [[Target Post]]",
+		}, nil)
+		test.NilErr(t, err)
+
+		err = repo.ReplaceCommentLinks(ctx, target.Id, comment.Id, forum.ParseWikiLinks(comment.Body))
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByComments(ctx, []forum.CommentId{comment.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 link", 1, len(links))
+		test.Assert(t, "Expected comment source", links[0].SourceCommentId != nil && *links[0].SourceCommentId == comment.Id)
+
+		backlinks, err := repo.GetBacklinks(ctx, target.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected backlink from comment", 1, len(backlinks))
+
+		_, err = commentsRepo.DeleteComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		backlinks, err = repo.GetBacklinks(ctx, target.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no backlinks from deleted comment", 0, len(backlinks))
+	})
+
+	t.Run("links from removed content are hidden", func(t *testing.T) {
+		poster, community := setup(t)
+
+		target, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Target Post",
+			Body:  "Target",
+		})
+		test.NilErr(t, err)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[Target Post]] and [[Missing Post]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		comment, err := commentsRepo.CreateComment(ctx, target.Id, poster.Id, forum.CommentValue{
+			Body: "Self reference to [[Target Post]]",
+		}, nil)
+		test.NilErr(t, err)
+
+		err = repo.ReplaceCommentLinks(ctx, target.Id, comment.Id, forum.ParseWikiLinks(comment.Body))
+		test.NilErr(t, err)
+
+		_, err = postsRepo.RemovePost(ctx, source.Id, "Spam")
+		test.NilErr(t, err)
+		_, err = commentsRepo.RemoveComment(ctx, comment.Id, "Spam")
+		test.NilErr(t, err)
+
+		backlinks, err := repo.GetBacklinks(ctx, target.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no backlinks from removed content", 0, len(backlinks))
+
+		backlinks, err = repo.GetBacklinks(ctx, target.Id, dbports.IncludeRemoved())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected backlinks from removed content to be included", 2, len(backlinks))
+
+		dangling, err := repo.GetDanglingLinks(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no dangling links from removed content", 0, len(dangling))
+
+		dangling, err = repo.GetDanglingLinks(ctx, 10, 0, dbports.IncludeRemoved())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected dangling links from removed content to be included", 1, len(dangling))
+	})
+
+	t.Run("links to deleted posts dangle", func(t *testing.T) {
+		poster, community := setup(t)
+
+		target, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Target Post",
+			Body:  "Target",
+		})
+		test.NilErr(t, err)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[Target Post]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		_, err = postsRepo.DeletePost(ctx, target.Id)
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByPosts(ctx, []forum.PostId{source.Id})
+		test.NilErr(t, err)
+		test.Assert(t, "Expected link to deleted post to dangle", links[0].TargetPostId == nil)
+	})
+
+	t.Run("links resolve through redirects", func(t *testing.T) {
+		poster, community := setup(t)
+
+		other, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "rust",
+			Description: "Rust programming",
+		})
+		test.NilErr(t, err)
+
+		target, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Old Title",
+			Body:  "Target",
+		})
+		test.NilErr(t, err)
+
+		_, err = postsRepo.UpdatePostTitle(ctx, target.Id, "New Title")
+		test.NilErr(t, err)
+
+		_, err = postsRepo.MovePost(ctx, target.Id, other.Id)
+		test.NilErr(t, err)
+
+		source, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Source Post",
+			Body:  "[[Old Title]] [[golang/New Title]] [[rust/New Title]]",
+		})
+		test.NilErr(t, err)
+
+		err = repo.ReplacePostLinks(ctx, source.Id, forum.ParseWikiLinks(source.Body))
+		test.NilErr(t, err)
+
+		links, err := repo.GetLinksByPosts(ctx, []forum.PostId{source.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 3 links", 3, len(links))
+
+		for _, link := range links {
+			test.Assert(t, "Expected link to resolve to the renamed post: "+link.String(),
+				link.TargetPostId != nil && *link.TargetPostId == target.Id)
+		}
+	})
+}
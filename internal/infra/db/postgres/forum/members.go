@@ -0,0 +1,188 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MembersRepo implements the dbportsforum.MembersRepo interface.
+type MembersRepo struct {
+	postgres.BaseRepo
+}
+
+// NewMembersRepo creates a new MembersRepo.
+func NewMembersRepo(pool *pgxpool.Pool) MembersRepo {
+	return MembersRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r MembersRepo) AddMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	member *forum.Member, err error,
+) {
+	// As with subscriptions, exactly one row is returned.
+	const stmt = `WITH inserted AS (
+    INSERT INTO forum_members (community_id, user_id) VALUES ($1, $2)
+    ON CONFLICT (community_id, user_id) DO NOTHING
+    RETURNING created_at
+)
+SELECT created_at FROM inserted
+UNION ALL
+SELECT created_at FROM forum_members WHERE community_id = $1 AND user_id = $2`
+	args := []any{communityId, userId}
+
+	member = &forum.Member{
+		CommunityId: communityId,
+		UserId:      userId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&member.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "member")
+	}
+
+	return member, nil
+}
+
+func (r MembersRepo) RemoveMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_members WHERE community_id = $1 AND user_id = $2"
+	args := []any{communityId, userId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r MembersRepo) IsMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	ok bool, err error,
+) {
+	const stmt = "SELECT EXISTS (SELECT 1 FROM forum_members WHERE community_id = $1 AND user_id = $2)"
+	args := []any{communityId, userId}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&ok)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (r MembersRepo) GetMembers(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+	members []forum.Member, err error,
+) {
+	const stmt = `SELECT user_id, created_at FROM forum_members WHERE community_id = $1
+ORDER BY created_at, user_id LIMIT $2 OFFSET $3`
+	args := []any{communityId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members = make([]forum.Member, 0, limit)
+	for rows.Next() {
+		member := forum.Member{
+			CommunityId: communityId,
+		}
+		err = rows.Scan(&member.UserId, &member.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (r MembersRepo) CreateInvite(ctx context.Context, communityId forum.CommunityId, inviteeId auth.UserId,
+	inviterId auth.UserId,
+) (invite *forum.Invite, err error) {
+	const stmt = `WITH inserted AS (
+    INSERT INTO forum_invites (community_id, invitee_id, inviter_id) VALUES ($1, $2, $3)
+    ON CONFLICT (community_id, invitee_id) DO NOTHING
+    RETURNING inviter_id, created_at
+)
+SELECT inviter_id, created_at FROM inserted
+UNION ALL
+SELECT inviter_id, created_at FROM forum_invites WHERE community_id = $1 AND invitee_id = $2`
+	args := []any{communityId, inviteeId, inviterId}
+
+	invite = &forum.Invite{
+		CommunityId: communityId,
+		InviteeId:   inviteeId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&invite.InviterId, &invite.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "invite")
+	}
+
+	return invite, nil
+}
+
+func (r MembersRepo) DeleteInvite(ctx context.Context, communityId forum.CommunityId, inviteeId auth.UserId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_invites WHERE community_id = $1 AND invitee_id = $2"
+	args := []any{communityId, inviteeId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r MembersRepo) GetInvitesByCommunity(ctx context.Context, communityId forum.CommunityId, limit int,
+	offset int,
+) (invites []forum.Invite, err error) {
+	const stmt = `SELECT community_id, invitee_id, inviter_id, created_at FROM forum_invites WHERE community_id = $1
+ORDER BY created_at DESC, invitee_id LIMIT $2 OFFSET $3`
+	args := []any{communityId, limit, offset}
+
+	return r.getInvitesAux(ctx, stmt, args, limit)
+}
+
+func (r MembersRepo) GetInvitesByInvitee(ctx context.Context, inviteeId auth.UserId, limit int, offset int) (
+	invites []forum.Invite, err error,
+) {
+	const stmt = `SELECT i.community_id, i.invitee_id, i.inviter_id, i.created_at FROM forum_invites i
+JOIN forum_communities c ON c.id = i.community_id AND c.deleted_at IS NULL
+WHERE i.invitee_id = $1
+ORDER BY i.created_at DESC, i.community_id LIMIT $2 OFFSET $3`
+	args := []any{inviteeId, limit, offset}
+
+	return r.getInvitesAux(ctx, stmt, args, limit)
+}
+
+func (r MembersRepo) getInvitesAux(ctx context.Context, stmt string, args []any, limit int) (
+	invites []forum.Invite, err error,
+) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites = make([]forum.Invite, 0, limit)
+	for rows.Next() {
+		invite := forum.Invite{}
+		err = rows.Scan(&invite.CommunityId, &invite.InviteeId, &invite.InviterId, &invite.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
@@ -0,0 +1,119 @@
+package forumdb
+
+import (
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestMembersRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewMembersRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (moderator *auth.User, user *auth.User, community *forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		moderator, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "moderator",
+			DisplayName: "moderator",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		user, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		return moderator, user, community
+	}
+
+	t.Run("adding members is idempotent", func(t *testing.T) {
+		_, user, community := setup(t)
+
+		ok, err := repo.IsMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "User should not be a member yet", !ok)
+
+		first, err := repo.AddMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+
+		second, err := repo.AddMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Membership time should not change", first.CreatedAt, second.CreatedAt)
+
+		ok, err = repo.IsMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "User should be a member", ok)
+
+		members, err := repo.GetMembers(ctx, community.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of members not as expected", 1, len(members))
+		test.AssertEqual(t, "Member not as expected", user.Id, members[0].UserId)
+
+		removed, err := repo.RemoveMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Member should be removed", removed)
+
+		removed, err = repo.RemoveMember(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Member should not be removed twice", !removed)
+	})
+
+	t.Run("invites are listed for the community and the invitee", func(t *testing.T) {
+		moderator, user, community := setup(t)
+
+		first, err := repo.CreateInvite(ctx, community.Id, user.Id, moderator.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Inviter not as expected", moderator.Id, first.InviterId)
+
+		second, err := repo.CreateInvite(ctx, community.Id, user.Id, moderator.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Invite time should not change", first.CreatedAt, second.CreatedAt)
+
+		invites, err := repo.GetInvitesByCommunity(ctx, community.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of community invites not as expected", 1, len(invites))
+
+		invites, err = repo.GetInvitesByInvitee(ctx, user.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of invitee invites not as expected", 1, len(invites))
+
+		_, err = communitiesRepo.DeleteCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+
+		invites, err = repo.GetInvitesByInvitee(ctx, user.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Invites to deleted communities should not be listed", 0, len(invites))
+
+		removed, err := repo.DeleteInvite(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Invite should be deleted", removed)
+
+		removed, err = repo.DeleteInvite(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Invite should not be deleted twice", !removed)
+	})
+}
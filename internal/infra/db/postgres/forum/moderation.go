@@ -0,0 +1,159 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ModerationRepo implements the dbportsforum.ModerationRepo interface.
+type ModerationRepo struct {
+	postgres.BaseRepo
+}
+
+// NewModerationRepo creates a new ModerationRepo.
+func NewModerationRepo(pool *pgxpool.Pool) ModerationRepo {
+	return ModerationRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r ModerationRepo) SetModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId,
+	role forum.ModeratorRole,
+) (moderator *forum.Moderator, err error) {
+	const stmt = `INSERT INTO forum_moderators (community_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (community_id, user_id) DO UPDATE SET role = EXCLUDED.role
+RETURNING created_at`
+	args := []any{communityId, userId, role}
+
+	moderator = &forum.Moderator{
+		CommunityId: communityId,
+		UserId:      userId,
+		Role:        role,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&moderator.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "moderator")
+	}
+
+	return moderator, nil
+}
+
+func (r ModerationRepo) GetModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	moderator *forum.Moderator, err error,
+) {
+	const stmt = "SELECT role, created_at FROM forum_moderators WHERE community_id = $1 AND user_id = $2"
+	args := []any{communityId, userId}
+
+	moderator = &forum.Moderator{
+		CommunityId: communityId,
+		UserId:      userId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&moderator.Role, &moderator.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "moderator")
+	}
+
+	return moderator, nil
+}
+
+func (r ModerationRepo) GetModerators(ctx context.Context, communityId forum.CommunityId) (
+	moderators []forum.Moderator, err error,
+) {
+	const stmt = `SELECT user_id, role, created_at FROM forum_moderators WHERE community_id = $1
+ORDER BY role = 'owner' DESC, created_at, user_id`
+	args := []any{communityId}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moderators = []forum.Moderator{}
+	for rows.Next() {
+		moderator := forum.Moderator{
+			CommunityId: communityId,
+		}
+		err = rows.Scan(&moderator.UserId, &moderator.Role, &moderator.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		moderators = append(moderators, moderator)
+	}
+
+	return moderators, rows.Err()
+}
+
+func (r ModerationRepo) RemoveModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_moderators WHERE community_id = $1 AND user_id = $2"
+	args := []any{communityId, userId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r ModerationRepo) LogAction(ctx context.Context, value forum.ModerationLogValue) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	const stmt = `INSERT INTO forum_moderation_log (community_id, moderator_id, action, post_id, comment_id, target_user_id, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	args := []any{
+		value.CommunityId, value.ModeratorId, value.Action, value.PostId, value.CommentId, value.TargetUserId,
+		value.Reason,
+	}
+
+	entry = &forum.ModerationLogEntry{
+		ModerationLogValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&entry.Id, &entry.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "moderation log entry")
+	}
+
+	return entry, nil
+}
+
+func (r ModerationRepo) GetModerationLog(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+	entries []forum.ModerationLogEntry, err error,
+) {
+	const stmt = `SELECT id, created_at, moderator_id, action, post_id, comment_id, target_user_id, reason
+FROM forum_moderation_log WHERE community_id = $1
+ORDER BY created_at DESC, id LIMIT $2 OFFSET $3`
+	args := []any{communityId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries = make([]forum.ModerationLogEntry, 0, limit)
+	for rows.Next() {
+		entry := forum.ModerationLogEntry{
+			ModerationLogValue: forum.ModerationLogValue{
+				CommunityId: communityId,
+			},
+		}
+		err = rows.Scan(
+			&entry.Id, &entry.CreatedAt, &entry.ModeratorId, &entry.Action, &entry.PostId, &entry.CommentId,
+			&entry.TargetUserId, &entry.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,212 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	dbports "greddit/internal/ports/db"
+)
+
+func TestModerationRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewModerationRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (owner *auth.User, user *auth.User, community *forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		owner, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "owner",
+			DisplayName: "owner",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		user, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.SetModerator(ctx, community.Id, owner.Id, forum.ModeratorRoleOwner)
+		test.NilErr(t, err)
+
+		return owner, user, community
+	}
+
+	t.Run("lists the owner first and changes roles", func(t *testing.T) {
+		owner, user, community := setup(t)
+
+		moderator, err := repo.SetModerator(ctx, community.Id, user.Id, forum.ModeratorRoleModerator)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Role not as expected", forum.ModeratorRoleModerator, moderator.Role)
+
+		moderators, err := repo.GetModerators(ctx, community.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of moderators not as expected", 2, len(moderators))
+		test.AssertEqual(t, "Owner should be listed first", owner.Id, moderators[0].UserId)
+
+		_, err = repo.SetModerator(ctx, community.Id, owner.Id, forum.ModeratorRoleModerator)
+		test.NilErr(t, err)
+		_, err = repo.SetModerator(ctx, community.Id, user.Id, forum.ModeratorRoleOwner)
+		test.NilErr(t, err)
+
+		moderator, err = repo.GetModerator(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Role not as expected", forum.ModeratorRoleOwner, moderator.Role)
+	})
+
+	t.Run("allows a single owner", func(t *testing.T) {
+		_, user, community := setup(t)
+
+		_, err := repo.SetModerator(ctx, community.Id, user.Id, forum.ModeratorRoleOwner)
+		test.Assert(t, "Expected conflict error", errors.Is(err, shared.ErrConflict))
+	})
+
+	t.Run("removes moderators", func(t *testing.T) {
+		_, user, community := setup(t)
+
+		_, err := repo.SetModerator(ctx, community.Id, user.Id, forum.ModeratorRoleModerator)
+		test.NilErr(t, err)
+
+		removed, err := repo.RemoveModerator(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Moderator should be removed", removed)
+
+		removed, err = repo.RemoveModerator(ctx, community.Id, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Moderator should not be removed twice", !removed)
+
+		_, err = repo.GetModerator(ctx, community.Id, user.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("logs actions most recent first", func(t *testing.T) {
+		owner, user, community := setup(t)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: community.Id,
+			ModeratorId: &owner.Id,
+			Action:      forum.ModerationActionRemovePost,
+			PostId:      &post.Id,
+			Reason:      "Spam",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: community.Id,
+			ModeratorId: &owner.Id,
+			Action:      forum.ModerationActionApprovePost,
+			PostId:      &post.Id,
+		})
+		test.NilErr(t, err)
+
+		entries, err := repo.GetModerationLog(ctx, community.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of entries not as expected", 2, len(entries))
+		test.AssertEqual(t, "Action not as expected", forum.ModerationActionApprovePost, entries[0].Action)
+		test.AssertEqual(t, "Reason not as expected", "Spam", entries[1].Reason)
+		test.AssertEqual(t, "Post not as expected", post.Id, *entries[1].PostId)
+	})
+
+	t.Run("removes, locks and pins posts", func(t *testing.T) {
+		_, user, community := setup(t)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		pinnedAt, err := postsRepo.SetPostPinned(ctx, post.Id, true)
+		test.NilErr(t, err)
+		test.Assert(t, "Post should be pinned", pinnedAt != nil)
+
+		count, err := postsRepo.CountPinnedPosts(ctx, community.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of pinned posts not as expected", 1, count)
+
+		lockedAt, err := postsRepo.SetPostLocked(ctx, post.Id, true)
+		test.NilErr(t, err)
+		test.Assert(t, "Post should be locked", lockedAt != nil)
+
+		_, err = postsRepo.RemovePost(ctx, post.Id, "Spam")
+		test.NilErr(t, err)
+
+		posts, err := postsRepo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Removed post should not be listed", 0, len(posts))
+
+		posts, err = postsRepo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 10, 0, dbports.IncludeRemoved())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Removed post should be listed to moderators", 1, len(posts))
+		test.AssertEqual(t, "Removal reason not as expected", "Spam", *posts[0].RemovalReason)
+
+		err = postsRepo.ApprovePost(ctx, post.Id)
+		test.NilErr(t, err)
+
+		got, err := postsRepo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Post should not be removed", !got.IsRemoved())
+		test.Assert(t, "Post should still be locked", got.LockedAt != nil)
+	})
+
+	t.Run("removes and approves comments", func(t *testing.T) {
+		_, user, community := setup(t)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		comment, err := commentsRepo.CreateComment(ctx, post.Id, user.Id, forum.CommentValue{
+			Body: "Buy now",
+		}, nil)
+		test.NilErr(t, err)
+
+		_, err = commentsRepo.RemoveComment(ctx, comment.Id, "Spam")
+		test.NilErr(t, err)
+
+		got, err := commentsRepo.GetCommentById(ctx, comment.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Comment should be removed", got.IsRemoved())
+
+		err = commentsRepo.ApproveComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		got, err = commentsRepo.GetCommentById(ctx, comment.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Comment should not be removed", !got.IsRemoved())
+	})
+}
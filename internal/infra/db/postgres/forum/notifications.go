@@ -0,0 +1,199 @@
+package forumdb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NotificationsRepo implements the dbportsforum.NotificationsRepo interface.
+type NotificationsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewNotificationsRepo creates a new NotificationsRepo.
+func NewNotificationsRepo(pool *pgxpool.Pool) NotificationsRepo {
+	return NotificationsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+const notificationColumns = "n.id, n.created_at, n.read_at, n.recipient_id, n.actor_id, n.kind, n.post_id, n.comment_id"
+
+func scanNotification(row pgx.Row, notification *forum.Notification) error {
+	return row.Scan(
+		&notification.Id,
+		&notification.CreatedAt,
+		&notification.ReadAt,
+		&notification.RecipientId,
+		&notification.ActorId,
+		&notification.Kind,
+		&notification.PostId,
+		&notification.CommentId,
+	)
+}
+
+func (r NotificationsRepo) Notify(ctx context.Context, value forum.NotificationValue) (
+	notification *forum.Notification, err error,
+) {
+	const stmt = `INSERT INTO notifications (recipient_id, actor_id, kind, post_id, comment_id)
+SELECT $1, $2, $3, $4, $5
+WHERE NOT EXISTS (SELECT 1 FROM notification_preferences WHERE user_id = $1 AND $3 = ANY (muted_kinds))
+  AND forum_community_visible((SELECT community_id FROM forum_posts WHERE id = $4), $1, FALSE)
+ON CONFLICT (recipient_id, comment_id) DO NOTHING
+RETURNING id, created_at`
+	args := []any{value.RecipientId, value.ActorId, string(value.Kind), value.PostId, value.CommentId}
+
+	notification = &forum.Notification{
+		NotificationValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&notification.Id, &notification.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, postgres.TranslateError(err, "notification")
+	}
+
+	return notification, nil
+}
+
+func (r NotificationsRepo) GetNotificationById(ctx context.Context, id forum.NotificationId) (
+	notification *forum.Notification, err error,
+) {
+	const stmt = "SELECT " + notificationColumns + " FROM notifications n WHERE n.id = $1"
+	args := []any{id}
+
+	notification = &forum.Notification{}
+	err = scanNotification(r.QueryRow(ctx, stmt, args...), notification)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "notification")
+	}
+
+	return notification, nil
+}
+
+func (r NotificationsRepo) GetNotifications(ctx context.Context, recipientId auth.UserId, unreadOnly bool,
+	limit int, offset int, opts ...dbports.ReadOption,
+) (notifications []forum.Notification, err error) {
+	const stmt = "SELECT " + notificationColumns + ` FROM notifications n
+JOIN forum_comments c ON c.id = n.comment_id
+JOIN forum_posts p ON p.id = n.post_id
+WHERE n.recipient_id = $1 AND c.deleted_at IS NULL AND (NOT $2 OR n.read_at IS NULL)
+  AND forum_community_visible(p.community_id, $5, $6)
+ORDER BY n.created_at DESC, n.id
+LIMIT $3 OFFSET $4`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{recipientId, unreadOnly, limit, offset, options.ViewerId, options.IncludePrivate}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications = make([]forum.Notification, 0, limit)
+	for rows.Next() {
+		var notification forum.Notification
+		err = scanNotification(rows, &notification)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r NotificationsRepo) CountUnread(ctx context.Context, recipientId auth.UserId, opts ...dbports.ReadOption) (
+	count int, err error,
+) {
+	const stmt = `SELECT COUNT(*) FROM notifications n
+JOIN forum_comments c ON c.id = n.comment_id
+JOIN forum_posts p ON p.id = n.post_id
+WHERE n.recipient_id = $1 AND n.read_at IS NULL AND c.deleted_at IS NULL
+  AND forum_community_visible(p.community_id, $2, $3)`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{recipientId, options.ViewerId, options.IncludePrivate}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r NotificationsRepo) MarkRead(ctx context.Context, id forum.NotificationId) (readAt *time.Time, err error) {
+	const stmt = "UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 RETURNING read_at"
+	args := []any{id}
+
+	readAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(readAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "notification")
+	}
+
+	return readAt, nil
+}
+
+func (r NotificationsRepo) MarkAllRead(ctx context.Context, recipientId auth.UserId) (count int64, err error) {
+	const stmt = "UPDATE notifications SET read_at = NOW() WHERE recipient_id = $1 AND read_at IS NULL"
+	args := []any{recipientId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r NotificationsRepo) GetPreferences(ctx context.Context, userId auth.UserId) (
+	preferences forum.NotificationPreferences, err error,
+) {
+	const stmt = "SELECT muted_kinds FROM notification_preferences WHERE user_id = $1"
+	args := []any{userId}
+
+	var muted []string
+	err = r.QueryRow(ctx, stmt, args...).Scan(&muted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return forum.DefaultNotificationPreferences(), nil
+	} else if err != nil {
+		return forum.NotificationPreferences{}, err
+	}
+
+	kinds := make([]forum.NotificationKind, 0, len(muted))
+	for _, kind := range muted {
+		kinds = append(kinds, forum.NotificationKind(kind))
+	}
+
+	return forum.NotificationPreferencesFromMuted(kinds), nil
+}
+
+func (r NotificationsRepo) UpdatePreferences(ctx context.Context, userId auth.UserId,
+	preferences forum.NotificationPreferences,
+) (err error) {
+	const stmt = `INSERT INTO notification_preferences (user_id, muted_kinds) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET muted_kinds = EXCLUDED.muted_kinds`
+
+	muted := []string{}
+	for _, kind := range preferences.MutedKinds() {
+		muted = append(muted, string(kind))
+	}
+	args := []any{userId, muted}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "notification preferences")
+	}
+
+	return nil
+}
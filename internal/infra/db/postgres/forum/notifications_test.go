@@ -0,0 +1,179 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+
+	"github.com/google/uuid"
+)
+
+func TestNotificationsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewNotificationsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (recipient *auth.User, actor *auth.User, post *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		recipient, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "recipient",
+			DisplayName: "recipient",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		actor, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "actor",
+			DisplayName: "actor",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, recipient.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		return recipient, actor, post
+	}
+
+	notify := func(t *testing.T, recipient *auth.User, actor *auth.User, post *forum.Post,
+		kind forum.NotificationKind,
+	) (*forum.Comment, *forum.Notification) {
+		t.Helper()
+
+		comment, err := commentsRepo.CreateComment(ctx, post.Id, actor.Id, forum.CommentValue{
+			Body: "Hello @" + recipient.Username,
+		}, nil)
+		test.NilErr(t, err)
+
+		notification, err := repo.Notify(ctx, forum.NotificationValue{
+			RecipientId: recipient.Id,
+			ActorId:     actor.Id,
+			Kind:        kind,
+			PostId:      post.Id,
+			CommentId:   comment.Id,
+		})
+		test.NilErr(t, err)
+
+		return comment, notification
+	}
+
+	t.Run("notify and list", func(t *testing.T) {
+		recipient, actor, post := setup(t)
+
+		comment, notification := notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+		test.Assert(t, "Expected notification to be created", notification != nil)
+		test.Assert(t, "Expected notification to be unread", notification.ReadAt == nil)
+
+		found, err := repo.GetNotificationById(ctx, notification.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Notification value not as expected", notification.NotificationValue, found.NotificationValue)
+
+		notifications, err := repo.GetNotifications(ctx, recipient.Id, false, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of notifications not as expected", 1, len(notifications))
+		test.AssertEqual(t, "Notification not as expected", comment.Id, notifications[0].CommentId)
+
+		notifications, err = repo.GetNotifications(ctx, actor.Id, false, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no notifications for the actor", 0, len(notifications))
+	})
+
+	t.Run("a comment notifies a user once", func(t *testing.T) {
+		recipient, actor, post := setup(t)
+
+		comment, _ := notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+
+		notification, err := repo.Notify(ctx, forum.NotificationValue{
+			RecipientId: recipient.Id,
+			ActorId:     actor.Id,
+			Kind:        forum.NotificationKindMention,
+			PostId:      post.Id,
+			CommentId:   comment.Id,
+		})
+		test.NilErr(t, err)
+		test.Assert(t, "Expected duplicate notification to be skipped", notification == nil)
+	})
+
+	t.Run("muted kinds are skipped", func(t *testing.T) {
+		recipient, actor, post := setup(t)
+
+		prefs, err := repo.GetPreferences(ctx, recipient.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected default preferences", forum.DefaultNotificationPreferences(), prefs)
+
+		prefs.Mentions = false
+		test.NilErr(t, repo.UpdatePreferences(ctx, recipient.Id, prefs))
+
+		found, err := repo.GetPreferences(ctx, recipient.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Preferences not as expected", prefs, found)
+
+		_, notification := notify(t, recipient, actor, post, forum.NotificationKindMention)
+		test.Assert(t, "Expected muted notification to be skipped", notification == nil)
+
+		_, notification = notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+		test.Assert(t, "Expected unmuted notification to be created", notification != nil)
+	})
+
+	t.Run("mark read", func(t *testing.T) {
+		recipient, actor, post := setup(t)
+
+		_, first := notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+		notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+		deleted, _ := notify(t, recipient, actor, post, forum.NotificationKindPostReply)
+		_, err := commentsRepo.DeleteComment(ctx, deleted.Id)
+		test.NilErr(t, err)
+
+		count, err := repo.CountUnread(ctx, recipient.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unread count not as expected", 2, count)
+
+		readAt, err := repo.MarkRead(ctx, first.Id)
+		test.NilErr(t, err)
+		again, err := repo.MarkRead(ctx, first.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected first read time to be kept", readAt.UTC(), again.UTC())
+
+		unread, err := repo.GetNotifications(ctx, recipient.Id, true, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of unread notifications not as expected", 1, len(unread))
+
+		marked, err := repo.MarkAllRead(ctx, recipient.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of marked notifications not as expected", int64(2), marked)
+
+		count, err = repo.CountUnread(ctx, recipient.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unread count after marking all not as expected", 0, count)
+
+		_, err = repo.MarkRead(ctx, uuid.New())
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
@@ -0,0 +1,181 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PollsRepo implements the dbportsforum.PollsRepo interface.
+type PollsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewPollsRepo creates a new PollsRepo.
+func NewPollsRepo(pool *pgxpool.Pool) PollsRepo {
+	return PollsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r PollsRepo) CreatePoll(ctx context.Context, postId forum.PostId, value forum.PollValue) (
+	poll *forum.Poll, err error,
+) {
+	const stmt = `WITH poll AS (
+    INSERT INTO forum_polls (post_id, multiple_choice, closes_at) VALUES ($1, $2, $3) RETURNING post_id
+)
+INSERT INTO forum_poll_options (post_id, position, text)
+SELECT poll.post_id, o.position - 1, o.text FROM poll, UNNEST($4::text[]) WITH ORDINALITY AS o(text, position)
+RETURNING id, position`
+	args := []any{postId, value.MultipleChoice, value.ClosesAt, value.Options}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "poll")
+	}
+	defer rows.Close()
+
+	poll = &forum.Poll{
+		PostId:         postId,
+		MultipleChoice: value.MultipleChoice,
+		ClosesAt:       value.ClosesAt,
+		Options:        make([]forum.PollOption, len(value.Options)),
+	}
+	for rows.Next() {
+		var (
+			id       forum.PollOptionId
+			position int
+		)
+		err = rows.Scan(&id, &position)
+		if err != nil {
+			return nil, err
+		}
+		poll.Options[position] = forum.PollOption{
+			Id:   id,
+			Text: value.Options[position],
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, postgres.TranslateError(err, "poll")
+	}
+
+	return poll, nil
+}
+
+func (r PollsRepo) GetPollByPost(ctx context.Context, postId forum.PostId) (poll *forum.Poll, err error) {
+	polls, err := r.GetPollsByPosts(ctx, []forum.PostId{postId})
+	if err != nil {
+		return nil, err
+	} else if len(polls) == 0 {
+		return nil, shared.NotFoundError{
+			Entity: "poll",
+		}
+	}
+
+	return &polls[0], nil
+}
+
+func (r PollsRepo) GetPollsByPosts(ctx context.Context, postIds []forum.PostId) (polls []forum.Poll, err error) {
+	const stmt = `SELECT p.post_id, p.multiple_choice, p.closes_at, p.voter_count, o.id, o.text, o.vote_count
+FROM forum_polls p JOIN forum_poll_options o ON o.post_id = p.post_id
+WHERE p.post_id = ANY($1) ORDER BY p.post_id, o.position`
+	args := []any{postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	polls = make([]forum.Poll, 0, len(postIds))
+	for rows.Next() {
+		poll := forum.Poll{}
+		option := forum.PollOption{}
+		err = rows.Scan(&poll.PostId, &poll.MultipleChoice, &poll.ClosesAt, &poll.VoterCount, &option.Id,
+			&option.Text, &option.VoteCount)
+		if err != nil {
+			return nil, err
+		}
+
+		// The options of a poll are on consecutive rows.
+		if len(polls) == 0 || polls[len(polls)-1].PostId != poll.PostId {
+			polls = append(polls, poll)
+		}
+		last := &polls[len(polls)-1]
+		last.Options = append(last.Options, option)
+	}
+
+	return polls, rows.Err()
+}
+
+func (r PollsRepo) GetPollVotes(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+	votes map[forum.PostId][]forum.PollOptionId, err error,
+) {
+	const stmt = "SELECT post_id, option_ids FROM forum_poll_votes WHERE user_id = $1 AND post_id = ANY($2)"
+	args := []any{userId, postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes = make(map[forum.PostId][]forum.PollOptionId, len(postIds))
+	for rows.Next() {
+		var (
+			postId    forum.PostId
+			optionIds []forum.PollOptionId
+		)
+		err = rows.Scan(&postId, &optionIds)
+		if err != nil {
+			return nil, err
+		}
+		votes[postId] = optionIds
+	}
+
+	return votes, rows.Err()
+}
+
+func (r PollsRepo) CastPollVote(ctx context.Context, postId forum.PostId, userId auth.UserId,
+	optionIds []forum.PollOptionId,
+) (err error) {
+	// Concurrent votes of the same user wait for the first one to commit, and
+	// then conflict with it, so that a user is only counted once.
+	const voteStmt = `INSERT INTO forum_poll_votes (post_id, user_id, option_ids) VALUES ($1, $2, $3)
+ON CONFLICT (post_id, user_id) DO NOTHING`
+	tag, err := r.Exec(ctx, voteStmt, postId, userId, optionIds)
+	if err != nil {
+		return postgres.TranslateError(err, "poll vote")
+	} else if tag.RowsAffected() == 0 {
+		return shared.ConflictError{
+			Entity: "poll vote",
+		}
+	}
+
+	// The counts are incremented in place, which locks the rows until the
+	// transaction commits, so that concurrent votes are all counted.
+	const optionsStmt = `UPDATE forum_poll_options SET vote_count = vote_count + 1
+WHERE post_id = $1 AND id = ANY($2)`
+	tag, err = r.Exec(ctx, optionsStmt, postId, optionIds)
+	if err != nil {
+		return postgres.TranslateError(err, "poll vote")
+	} else if tag.RowsAffected() != int64(len(optionIds)) {
+		return shared.InvalidReferenceError{
+			Entity: "poll vote",
+			Field:  "option_ids",
+		}
+	}
+
+	const pollStmt = "UPDATE forum_polls SET voter_count = voter_count + 1 WHERE post_id = $1"
+	_, err = r.Exec(ctx, pollStmt, postId)
+	if err != nil {
+		return postgres.TranslateError(err, "poll vote")
+	}
+
+	return nil
+}
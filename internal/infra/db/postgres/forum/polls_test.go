@@ -0,0 +1,159 @@
+package forumdb
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestPollsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewPollsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (user *auth.User, post *forum.Post, poll *forum.Poll) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Type:  forum.PostTypePoll,
+			Title: "Favourite language",
+		})
+		test.NilErr(t, err)
+
+		closesAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+		poll, err = repo.CreatePoll(ctx, post.Id, forum.PollValue{
+			Options:        []string{"Go", "Rust", "Zig"},
+			MultipleChoice: true,
+			ClosesAt:       &closesAt,
+		})
+		test.NilErr(t, err)
+
+		return user, post, poll
+	}
+
+	t.Run("polls are read with their options in order", func(t *testing.T) {
+		_, post, created := setup(t)
+
+		poll, err := repo.GetPollByPost(ctx, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Poll should be multiple choice", poll.MultipleChoice)
+		test.Assert(t, "Closing time not as expected", poll.ClosesAt.Equal(*created.ClosesAt))
+		test.AssertEqual(t, "Options not as expected", created.Options, poll.Options)
+		test.AssertEqual(t, "Option text not as expected", "Rust", poll.Options[1].Text)
+
+		polls, err := repo.GetPollsByPosts(ctx, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of polls not as expected", 1, len(polls))
+	})
+
+	t.Run("posts without a poll are not found", func(t *testing.T) {
+		user, post, _ := setup(t)
+
+		other, err := postsRepo.CreatePost(ctx, post.CommunityId, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.GetPollByPost(ctx, other.Id)
+		test.Assert(t, "Error should be not found", errors.Is(err, shared.ErrNotFound))
+	})
+
+	t.Run("votes are counted once per user", func(t *testing.T) {
+		user, post, poll := setup(t)
+
+		optionIds := []forum.PollOptionId{poll.Options[0].Id, poll.Options[2].Id}
+		err := repo.CastPollVote(ctx, post.Id, user.Id, optionIds)
+		test.NilErr(t, err)
+
+		err = repo.CastPollVote(ctx, post.Id, user.Id, []forum.PollOptionId{poll.Options[1].Id})
+		test.Assert(t, "Second vote should conflict", errors.Is(err, shared.ErrConflict))
+
+		poll, err = repo.GetPollByPost(ctx, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Voter count not as expected", 1, poll.VoterCount)
+		counts := []int{poll.Options[0].VoteCount, poll.Options[1].VoteCount, poll.Options[2].VoteCount}
+		test.Assert(t, "Vote counts not as expected", slices.Equal([]int{1, 0, 1}, counts))
+
+		votes, err := repo.GetPollVotes(ctx, user.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.Assert(t, "Votes not as expected", slices.Equal(optionIds, votes[post.Id]))
+	})
+
+	t.Run("concurrent votes are all counted", func(t *testing.T) {
+		_, post, poll := setup(t)
+
+		const voters = 20
+		errs := make([]error, voters)
+		wg := sync.WaitGroup{}
+		for i := range voters {
+			user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+				Username:    fmt.Sprintf("voter%d", i),
+				DisplayName: "voter",
+				Role:        auth.RoleUser,
+			})
+			test.NilErr(t, err)
+
+			wg.Go(func() {
+				errs[i] = repo.CastPollVote(ctx, post.Id, user.Id, []forum.PollOptionId{poll.Options[0].Id})
+			})
+		}
+		wg.Wait()
+		for _, err := range errs {
+			test.NilErr(t, err)
+		}
+
+		poll, err := repo.GetPollByPost(ctx, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Voter count not as expected", voters, poll.VoterCount)
+		test.AssertEqual(t, "Vote count not as expected", voters, poll.Options[0].VoteCount)
+	})
+
+	t.Run("votes for options of other polls are rejected", func(t *testing.T) {
+		user, post, _ := setup(t)
+
+		other, err := postsRepo.CreatePost(ctx, post.CommunityId, user.Id, forum.PostValue{
+			Type:  forum.PostTypePoll,
+			Title: "Favourite editor",
+		})
+		test.NilErr(t, err)
+		otherPoll, err := repo.CreatePoll(ctx, other.Id, forum.PollValue{Options: []string{"Vim", "Emacs"}})
+		test.NilErr(t, err)
+
+		err = repo.CastPollVote(ctx, post.Id, user.Id, []forum.PollOptionId{otherPoll.Options[0].Id})
+		test.Assert(t, "Error should be invalid reference", errors.Is(err, shared.ErrInvalidReference))
+	})
+}
@@ -2,12 +2,15 @@ package forumdb
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"greddit/internal/domains/auth"
 
 	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -27,16 +30,20 @@ func NewPostsRepo(pool *pgxpool.Pool) PostsRepo {
 func (p PostsRepo) CreatePost(ctx context.Context, communityId forum.CommunityId, posterId auth.UserId, value forum.PostValue) (
 	post *forum.Post, err error,
 ) {
-	const stmt = "INSERT INTO forum_posts (community_id, poster_id, title, body) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
-	args := []any{communityId, posterId, value.Title, value.Body}
+	const stmt = "INSERT INTO forum_posts (community_id, poster_id, type, title, body, url) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at"
+	args := []any{communityId, posterId, value.Type, value.Title, value.Body, value.Url}
 
 	post = &forum.Post{
+		PostMetadata: forum.PostMetadata{
+			PosterId:    posterId,
+			CommunityId: communityId,
+		},
 		PostValue: value,
 	}
 
 	err = p.QueryRow(ctx, stmt, args...).Scan(&post.Id, &post.CreatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "post")
 	}
 
 	post.UpdatedAt = post.CreatedAt
@@ -44,28 +51,122 @@ func (p PostsRepo) CreatePost(ctx context.Context, communityId forum.CommunityId
 	return post, nil
 }
 
-func (p PostsRepo) GetPostById(ctx context.Context, id forum.PostId) (post *forum.Post, err error) {
-	const stmt = "SELECT title, body, created_at, updated_at, deleted_at FROM forum_posts WHERE id = $1"
-	args := []any{id}
+func (p PostsRepo) GetPostById(ctx context.Context, id forum.PostId, opts ...dbports.ReadOption) (
+	post *forum.Post, err error,
+) {
+	const stmt = "SELECT title, body, created_at, updated_at, deleted_at, poster_id, community_id, removed_at, removal_reason, locked_at, pinned_at, type, flair_id, url FROM forum_posts WHERE id = $1 AND ($2 OR deleted_at IS NULL) AND forum_community_visible(community_id, $3, $4)"
+	options := dbports.NewReadOptions(opts...)
+	args := []any{id, options.IncludeDeleted, options.ViewerId, options.IncludePrivate}
 
 	post = &forum.Post{}
+	post.Id = id
 
 	err = p.QueryRow(ctx, stmt, args...).Scan(
-		&post.Title, &post.Body, &post.CreatedAt, &post.UpdatedAt, &post.DeletedAt,
+		&post.Title, &post.Body, &post.CreatedAt, &post.UpdatedAt, &post.DeletedAt, &post.PosterId, &post.CommunityId,
+		&post.RemovedAt, &post.RemovalReason, &post.LockedAt, &post.PinnedAt, &post.Type, &post.FlairId, &post.Url,
 	)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "post")
 	}
 
 	return post, nil
 }
 
-func (p PostsRepo) GetPostsByCommunitySortedCreatedAt(ctx context.Context, communityId forum.CommunityId, limit int,
-	offset int,
+func (p PostsRepo) GetPostsByCommunitySortedCreatedAt(ctx context.Context, communityId forum.CommunityId,
+	filter forum.PostFilter, limit int, offset int, opts ...dbports.ReadOption,
 ) (posts []forum.Post, err error) {
-	const stmt = "SELECT id, title, body, created_at, updated_at, deleted_at FROM forum_posts WHERE community_id = $1 ORDER BY created_at LIMIT $2 OFFSET $3"
-	args := []any{communityId, limit, offset}
+	const stmt = `SELECT id, title, body, created_at, updated_at, deleted_at, poster_id, community_id, removed_at, removal_reason, locked_at, pinned_at, type, flair_id, url FROM forum_posts p
+WHERE community_id = $1 AND ($4 OR deleted_at IS NULL) AND ($5 OR removed_at IS NULL)
+  AND forum_community_visible($1, $6, $9)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM forum_post_tags pt JOIN forum_tags tg ON tg.id = pt.tag_id WHERE pt.post_id = p.id AND tg.name = $7::text
+  ))
+  AND ($8::uuid IS NULL OR p.flair_id = $8::uuid)
+ORDER BY pinned_at NULLS LAST, created_at LIMIT $2 OFFSET $3`
+	options := dbports.NewReadOptions(opts...)
+	tag, flairId := postFilterArgs(filter)
+	args := []any{communityId, limit, offset, options.IncludeDeleted, options.IncludeRemoved, options.ViewerId,
+		tag, flairId, options.IncludePrivate}
+
+	return p.getPostsAux(ctx, stmt, args, limit)
+}
+
+func (p PostsRepo) GetFeedPosts(ctx context.Context, subscriberId *auth.UserId, sort forum.FeedSort,
+	filter forum.PostFilter, cursor *forum.FeedCursor, limit int, opts ...dbports.ReadOption,
+) (posts []forum.Post, err error) {
+	options := dbports.NewReadOptions(opts...)
+	tag, flairId := postFilterArgs(filter)
+	args := []any{limit, options.ViewerId, options.IncludePrivate, tag, flairId}
+	if cursor != nil {
+		args = append(args, cursor.At, cursor.Id)
+	}
+	if subscriberId != nil {
+		args = append(args, *subscriberId)
+	}
+
+	return p.getPostsAux(ctx, feedStmt(sort, cursor != nil, subscriberId != nil), args, limit)
+}
+
+// postFilterCond is the condition matching the posts of the table p against
+// a forum.PostFilter, formatted with the parameters of the tag and the flair
+// ID as given by postFilterArgs, for statements built at runtime.
+const postFilterCond = `(%[1]s::text IS NULL OR EXISTS (
+    SELECT 1 FROM forum_post_tags pt JOIN forum_tags tg ON tg.id = pt.tag_id WHERE pt.post_id = p.id AND tg.name = %[1]s::text
+)) AND (%[2]s::uuid IS NULL OR p.flair_id = %[2]s::uuid)`
+
+// postFilterArgs returns the arguments of postFilterCond for the filter, which
+// are nil for the fields matching all posts.
+func postFilterArgs(filter forum.PostFilter) (tag *string, flairId *forum.FlairId) {
+	if filter.Tag != "" {
+		tag = &filter.Tag
+	}
+
+	return tag, filter.FlairId
+}
+
+// feedStmt builds the statement reading a page of a feed, taking the limit,
+// the viewer, whether private communities are included, the tag and the flair
+// ID, then the cursor and the subscriber if given. Pages of subscribed
+// communities are read per community through the partial indexes on
+// (community_id, <column>, id) and merged, so the cost grows with the number
+// of subscriptions times the limit rather than with the number of posts.
+func feedStmt(sort forum.FeedSort, withCursor bool, subscribed bool) string {
+	column, order, cmp := "created_at", "DESC", "<"
+	if sort == forum.FeedSortActive {
+		column = "updated_at"
+	}
+	if sort.Ascending() {
+		order, cmp = "ASC", ">"
+	}
+
+	const columns = "p.id, p.title, p.body, p.created_at, p.updated_at, p.deleted_at, p.poster_id, p.community_id, " +
+		"p.removed_at, p.removal_reason, p.locked_at, p.pinned_at, p.type, p.flair_id, p.url"
+	orderBy := fmt.Sprintf("ORDER BY p.%s %s, p.id %s LIMIT $1", column, order, order)
+	where := "p.deleted_at IS NULL AND p.removed_at IS NULL AND forum_community_visible(p.community_id, $2, $3) AND " +
+		fmt.Sprintf(postFilterCond, "$4", "$5")
+	if withCursor {
+		where += fmt.Sprintf(" AND (p.%s, p.id) %s ($6, $7)", column, cmp)
+	}
+
+	if !subscribed {
+		return fmt.Sprintf("SELECT %s FROM forum_posts p WHERE %s %s", columns, where, orderBy)
+	}
 
+	subscriberParam := "$6"
+	if withCursor {
+		subscriberParam = "$8"
+	}
+
+	return fmt.Sprintf(`SELECT %s FROM forum_subscriptions s
+JOIN forum_communities c ON c.id = s.community_id AND c.deleted_at IS NULL
+CROSS JOIN LATERAL (
+    SELECT * FROM forum_posts p WHERE p.community_id = s.community_id AND %s %s
+) p
+WHERE s.user_id = %s
+%s`, columns, where, orderBy, subscriberParam, orderBy)
+}
+
+func (p PostsRepo) getPostsAux(ctx context.Context, stmt string, args []any, limit int) (posts []forum.Post, err error) {
 	rows, err := p.Query(ctx, stmt, args...)
 	if err != nil {
 		return nil, err
@@ -77,6 +178,8 @@ func (p PostsRepo) GetPostsByCommunitySortedCreatedAt(ctx context.Context, commu
 		post := forum.Post{}
 		err = rows.Scan(
 			&post.Id, &post.Title, &post.Body, &post.CreatedAt, &post.UpdatedAt, &post.DeletedAt,
+			&post.PosterId, &post.CommunityId,
+			&post.RemovedAt, &post.RemovalReason, &post.LockedAt, &post.PinnedAt, &post.Type, &post.FlairId, &post.Url,
 		)
 		if err != nil {
 			return nil, err
@@ -94,21 +197,158 @@ func (p PostsRepo) UpdatePostContent(ctx context.Context, id forum.PostId, conte
 	updatedAt = &time.Time{}
 	err = p.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return updatedAt, nil
+}
+
+func (p PostsRepo) UpdatePostTitle(ctx context.Context, id forum.PostId, title string) (updatedAt *time.Time, err error) {
+	const stmt = `WITH old AS (
+    SELECT id, community_id, title FROM forum_posts WHERE id = $2 FOR UPDATE
+), redirect AS (
+    INSERT INTO forum_post_redirects (post_id, community_id, title)
+    SELECT id, community_id, title FROM old WHERE LOWER(title) <> LOWER($1)
+)
+UPDATE forum_posts p SET title = $1, updated_at = NOW() FROM old WHERE p.id = old.id RETURNING p.updated_at`
+	args := []any{title, id}
+
+	updatedAt = &time.Time{}
+	err = p.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return updatedAt, nil
+}
+
+func (p PostsRepo) MovePost(ctx context.Context, id forum.PostId, communityId forum.CommunityId) (
+	updatedAt *time.Time, err error,
+) {
+	const stmt = `WITH old AS (
+    SELECT id, community_id, title FROM forum_posts WHERE id = $2 FOR UPDATE
+), redirect AS (
+    INSERT INTO forum_post_redirects (post_id, community_id, title)
+    SELECT id, community_id, title FROM old WHERE community_id <> $1
+)
+UPDATE forum_posts p SET community_id = $1, updated_at = NOW() FROM old WHERE p.id = old.id RETURNING p.updated_at`
+	args := []any{communityId, id}
+
+	updatedAt = &time.Time{}
+	err = p.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
 	}
 
 	return updatedAt, nil
 }
 
+func (p PostsRepo) SetPostFlair(ctx context.Context, id forum.PostId, flairId *forum.FlairId) (err error) {
+	const stmt = "UPDATE forum_posts SET flair_id = $2 WHERE id = $1"
+	args := []any{id, flairId}
+
+	tag, err := p.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "post")
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "post"}
+	}
+
+	return nil
+}
+
 func (p PostsRepo) DeletePost(ctx context.Context, id forum.PostId) (deletedAt *time.Time, err error) {
-	const stmt = "UPDATE forum_posts SET deleted_at = NOW() WHERE id = $1 RETURNING deleted_at"
+	const stmt = "UPDATE forum_posts SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING deleted_at"
 	args := []any{id}
 
 	deletedAt = &time.Time{}
 	err = p.QueryRow(ctx, stmt, args...).Scan(&deletedAt)
 	if err != nil {
-		return nil, err
+		return nil, postgres.TranslateError(err, "post")
 	}
 
 	return deletedAt, nil
 }
+
+func (p PostsRepo) GetDeletedPosts(ctx context.Context, posterId *auth.UserId, limit int, offset int) (
+	posts []forum.Post, err error,
+) {
+	const stmt = `SELECT id, title, body, created_at, updated_at, deleted_at, poster_id, community_id, removed_at, removal_reason, locked_at, pinned_at, type, flair_id, url FROM forum_posts
+WHERE deleted_at IS NOT NULL AND NOT deleted_with_community AND ($1::uuid IS NULL OR poster_id = $1::uuid)
+ORDER BY deleted_at DESC LIMIT $2 OFFSET $3`
+	args := []any{posterId, limit, offset}
+
+	return p.getPostsAux(ctx, stmt, args, limit)
+}
+
+func (p PostsRepo) RestorePost(ctx context.Context, id forum.PostId) (updatedAt *time.Time, err error) {
+	const stmt = "UPDATE forum_posts SET deleted_at = NULL, deleted_with_community = FALSE WHERE id = $1 AND deleted_at IS NOT NULL RETURNING updated_at"
+	args := []any{id}
+
+	updatedAt = &time.Time{}
+	err = p.QueryRow(ctx, stmt, args...).Scan(&updatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return updatedAt, nil
+}
+
+func (p PostsRepo) RemovePost(ctx context.Context, id forum.PostId, reason string) (removedAt *time.Time, err error) {
+	const stmt = "UPDATE forum_posts SET removed_at = NOW(), removal_reason = $2 WHERE id = $1 RETURNING removed_at"
+	args := []any{id, reason}
+
+	removedAt = &time.Time{}
+	err = p.QueryRow(ctx, stmt, args...).Scan(&removedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return removedAt, nil
+}
+
+func (p PostsRepo) ApprovePost(ctx context.Context, id forum.PostId) (err error) {
+	const stmt = "UPDATE forum_posts SET removed_at = NULL, removal_reason = NULL WHERE id = $1"
+	args := []any{id}
+
+	tag, err := p.Exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "post"}
+	}
+
+	return nil
+}
+
+func (p PostsRepo) SetPostLocked(ctx context.Context, id forum.PostId, locked bool) (lockedAt *time.Time, err error) {
+	const stmt = "UPDATE forum_posts SET locked_at = CASE WHEN $2 THEN COALESCE(locked_at, NOW()) END WHERE id = $1 RETURNING locked_at"
+	args := []any{id, locked}
+
+	err = p.QueryRow(ctx, stmt, args...).Scan(&lockedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return lockedAt, nil
+}
+
+func (p PostsRepo) SetPostPinned(ctx context.Context, id forum.PostId, pinned bool) (pinnedAt *time.Time, err error) {
+	const stmt = "UPDATE forum_posts SET pinned_at = CASE WHEN $2 THEN COALESCE(pinned_at, NOW()) END WHERE id = $1 RETURNING pinned_at"
+	args := []any{id, pinned}
+
+	err = p.QueryRow(ctx, stmt, args...).Scan(&pinnedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	return pinnedAt, nil
+}
+
+func (p PostsRepo) CountPinnedPosts(ctx context.Context, communityId forum.CommunityId) (count int, err error) {
+	const stmt = "SELECT COUNT(*) FROM forum_posts WHERE community_id = $1 AND pinned_at IS NOT NULL AND deleted_at IS NULL AND removed_at IS NULL"
+	args := []any{communityId}
+
+	err = p.QueryRow(ctx, stmt, args...).Scan(&count)
+	return count, err
+}
@@ -1,6 +1,7 @@
 package forumdb
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -9,8 +10,13 @@ import (
 	authdb "greddit/internal/infra/db/postgres/auth"
 
 	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
 	"greddit/internal/infra/db/postgres"
 	"greddit/internal/test"
+
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
 )
 
 func TestPostsRepo_CreatePost(t *testing.T) {
@@ -88,6 +94,26 @@ func TestPostsRepo_CreatePost(t *testing.T) {
 
 		test.Assert(t, "Post IDs should be different", post1.Id != post2.Id)
 	})
+
+	t.Run("non-existent community", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.CreatePost(ctx, uuid.New(), poster.Id, forum.PostValue{
+			Title: "Orphan",
+			Body:  "No community",
+		})
+
+		var referenceErr shared.InvalidReferenceError
+		test.Assert(t, "Expected invalid reference error", errors.As(err, &referenceErr))
+		test.AssertEqual(t, "Referencing field not as expected", "community_id", referenceErr.Field)
+	})
 }
 
 func TestPostsRepo_GetPostById(t *testing.T) {
@@ -131,6 +157,9 @@ func TestPostsRepo_GetPostById(t *testing.T) {
 		test.NilErr(t, err)
 		test.AssertEqual(t, "Title not as expected", createdPost.Title, post.Title)
 		test.AssertEqual(t, "Body not as expected", createdPost.Body, post.Body)
+		test.AssertEqual(t, "Id not as expected", createdPost.Id, post.Id)
+		test.AssertEqual(t, "PosterId not as expected", poster.Id, post.PosterId)
+		test.AssertEqual(t, "CommunityId not as expected", community.Id, post.CommunityId)
 	})
 
 	t.Run("non-existent post", func(t *testing.T) {
@@ -193,7 +222,7 @@ func TestPostsRepo_GetPostsByCommunitySortedCreatedAt(t *testing.T) {
 		})
 		test.NilErr(t, err)
 
-		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, 10, 0)
+		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 10, 0)
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Expected 3 posts", 3, len(posts))
@@ -232,12 +261,12 @@ func TestPostsRepo_GetPostsByCommunitySortedCreatedAt(t *testing.T) {
 		}
 
 		// Get first page
-		page1, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, 2, 0)
+		page1, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 2, 0)
 		test.NilErr(t, err)
 		test.AssertEqual(t, "Unexpected number of posts", 2, len(page1))
 
 		// Get second page
-		page2, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, 2, 2)
+		page2, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 2, 2)
 		test.NilErr(t, err)
 		test.AssertEqual(t, "Unexpected number of posts", 2, len(page2))
 	})
@@ -279,7 +308,7 @@ func TestPostsRepo_GetPostsByCommunitySortedCreatedAt(t *testing.T) {
 		test.NilErr(t, err)
 
 		// Get posts from community1
-		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community1.Id, 10, 0)
+		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community1.Id, forum.PostFilter{}, 10, 0)
 		test.NilErr(t, err)
 
 		test.Assert(t, "Expected 1 post", len(posts) == 1)
@@ -394,6 +423,137 @@ func TestPostsRepo_UpdatePostContent(t *testing.T) {
 	})
 }
 
+func TestPostsRepo_UpdatePostTitle(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	t.Run("update post title", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "titletest",
+			Description: "Title test",
+		})
+		test.NilErr(t, err)
+
+		post, err := repo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Original Title",
+			Body:  "Original content",
+		})
+		test.NilErr(t, err)
+
+		updatedAt, err := repo.UpdatePostTitle(ctx, post.Id, "Updated Title")
+		test.NilErr(t, err)
+		test.Assert(t, "Expected a non-nil updated timestamp", updatedAt != nil)
+
+		updatedPost, err := repo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected title", "Updated Title", updatedPost.Title)
+		test.AssertEqual(t, "Body should remain unchanged", post.Body, updatedPost.Body)
+
+		var redirectTitle string
+		err = pool.QueryRow(ctx, "SELECT title FROM forum_post_redirects WHERE post_id = $1", post.Id).
+			Scan(&redirectTitle)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected redirect from previous title", post.Title, redirectTitle)
+	})
+
+	t.Run("non-existent post", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		updatedAt, err := repo.UpdatePostTitle(ctx, forum.PostId{}, "New Title")
+		test.Assert(t, "Expected error for non-existent post", err != nil)
+		test.Assert(t, "Expected updated timestamp to be nil", updatedAt == nil)
+	})
+}
+
+func TestPostsRepo_MovePost(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	t.Run("move post to another community", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		source, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "source",
+			Description: "Source community",
+		})
+		test.NilErr(t, err)
+
+		target, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "target",
+			Description: "Target community",
+		})
+		test.NilErr(t, err)
+
+		post, err := repo.CreatePost(ctx, source.Id, poster.Id, forum.PostValue{
+			Title: "Misfiled Post",
+			Body:  "Content",
+		})
+		test.NilErr(t, err)
+
+		updatedAt, err := repo.MovePost(ctx, post.Id, target.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected a non-nil updated timestamp", updatedAt != nil)
+
+		movedPost, err := repo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected community", target.Id, movedPost.CommunityId)
+
+		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, source.Id, forum.PostFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no posts in source community", 0, len(posts))
+
+		var redirectCommunityId forum.CommunityId
+		err = pool.QueryRow(ctx, "SELECT community_id FROM forum_post_redirects WHERE post_id = $1", post.Id).
+			Scan(&redirectCommunityId)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected redirect from previous community", source.Id, redirectCommunityId)
+	})
+
+	t.Run("non-existent post", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "target",
+			Description: "Target community",
+		})
+		test.NilErr(t, err)
+
+		updatedAt, err := repo.MovePost(ctx, forum.PostId{}, community.Id)
+		test.Assert(t, "Expected error for non-existent post", err != nil)
+		test.Assert(t, "Expected updated timestamp to be nil", updatedAt == nil)
+	})
+}
+
 func TestPostsRepo_DeletePost(t *testing.T) {
 	t.Parallel()
 
@@ -436,7 +596,7 @@ func TestPostsRepo_DeletePost(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify that the post is soft-deleted
-		deletedPost, err := repo.GetPostById(ctx, post.Id)
+		deletedPost, err := repo.GetPostById(ctx, post.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected title", post.Title, deletedPost.Title)
@@ -489,7 +649,7 @@ func TestPostsRepo_DeletePost(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Verify post1 is deleted but post2 is not
-		retrieved1, err := repo.GetPostById(ctx, post1.Id)
+		retrieved1, err := repo.GetPostById(ctx, post1.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		retrieved2, err := repo.GetPostById(ctx, post2.Id)
@@ -499,6 +659,44 @@ func TestPostsRepo_DeletePost(t *testing.T) {
 		test.Assert(t, "post2 should not be deleted", retrieved2.DeletedAt == nil)
 	})
 
+	t.Run("deleted post hidden by default", func(t *testing.T) {
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "hidden",
+			Description: "Hidden",
+		})
+		test.NilErr(t, err)
+
+		post, err := repo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Hidden",
+			Body:  "Hidden",
+		})
+		test.NilErr(t, err)
+
+		_, err = repo.DeletePost(ctx, post.Id)
+		test.NilErr(t, err)
+
+		var notFoundErr shared.NotFoundError
+		_, err = repo.GetPostById(ctx, post.Id)
+		test.Assert(t, "Expected not found error", errors.As(err, &notFoundErr))
+
+		posts, err := repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no posts by default", 0, len(posts))
+
+		posts, err = repo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{}, 10, 0, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected deleted post to be included", 1, len(posts))
+	})
+
 	t.Run("post data still accessible after soft delete", func(t *testing.T) {
 		postgres.ClearAllTables(t, pool)
 
@@ -529,7 +727,7 @@ func TestPostsRepo_DeletePost(t *testing.T) {
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
 		// Post should still be retrievable
-		deletedPost, err := repo.GetPostById(ctx, post.Id)
+		deletedPost, err := repo.GetPostById(ctx, post.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 
 		test.AssertEqual(t, "Unexpected title", post.Title, deletedPost.Title)
@@ -597,7 +795,7 @@ func TestPostsRepo_Integration(t *testing.T) {
 		test.NilErr(t, err)
 		test.Assert(t, "Expected a non-nil deleted timestamp", deletedAt != nil)
 
-		deleted, err := repo.GetPostById(ctx, post.Id)
+		deleted, err := repo.GetPostById(ctx, post.Id, dbports.IncludeDeleted())
 		test.NilErr(t, err)
 		test.Assert(t, "Expected post to be soft-deleted", deleted.DeletedAt != nil)
 	})
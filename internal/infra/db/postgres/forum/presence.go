@@ -0,0 +1,130 @@
+package forumdb
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// presenceChannel is the channel on which changes to the viewers of posts are
+// announced.
+const presenceChannel = "forum_presence"
+
+// PresenceRepo implements the dbportsforum.PresenceRepo interface.
+type PresenceRepo struct {
+	postgres.BaseRepo
+}
+
+// NewPresenceRepo creates a new PresenceRepo.
+func NewPresenceRepo(pool *pgxpool.Pool) PresenceRepo {
+	return PresenceRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r PresenceRepo) Join(ctx context.Context, sessionId uuid.UUID, postId forum.PostId, userId auth.UserId) (
+	err error,
+) {
+	const stmt = `WITH v AS (
+    INSERT INTO forum_post_viewers (session_id, post_id, user_id) VALUES ($1, $2, $3)
+    ON CONFLICT (session_id, post_id) DO UPDATE SET seen_at = EXCLUDED.seen_at
+    RETURNING post_id
+)
+SELECT pg_notify($4, v.post_id::TEXT) FROM v`
+	args := []any{sessionId, postId, userId, presenceChannel}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "post viewer")
+	}
+
+	return nil
+}
+
+func (r PresenceRepo) Leave(ctx context.Context, sessionId uuid.UUID, postId forum.PostId) (err error) {
+	const stmt = `WITH v AS (
+    DELETE FROM forum_post_viewers WHERE session_id = $1 AND post_id = $2 RETURNING post_id
+)
+SELECT pg_notify($3, v.post_id::TEXT) FROM v`
+	args := []any{sessionId, postId, presenceChannel}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	return err
+}
+
+func (r PresenceRepo) LeaveAll(ctx context.Context, sessionId uuid.UUID) (err error) {
+	const stmt = `WITH v AS (
+    DELETE FROM forum_post_viewers WHERE session_id = $1 RETURNING post_id
+)
+SELECT pg_notify($2, v.post_id::TEXT) FROM v`
+	args := []any{sessionId, presenceChannel}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	return err
+}
+
+func (r PresenceRepo) Touch(ctx context.Context, sessionIds []uuid.UUID) (err error) {
+	const stmt = "UPDATE forum_post_viewers SET seen_at = NOW() WHERE session_id = ANY ($1)"
+	args := []any{sessionIds}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	return err
+}
+
+func (r PresenceRepo) CountViewers(ctx context.Context, postIds []forum.PostId, since time.Time) (
+	viewers map[forum.PostId]int, err error,
+) {
+	const stmt = `SELECT post_id, COUNT(DISTINCT user_id) FROM forum_post_viewers
+WHERE post_id = ANY ($1) AND seen_at >= $2
+GROUP BY post_id`
+	args := []any{postIds, since}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	viewers = make(map[forum.PostId]int, len(postIds))
+	for rows.Next() {
+		var (
+			postId forum.PostId
+			count  int
+		)
+		err = rows.Scan(&postId, &count)
+		if err != nil {
+			return nil, err
+		}
+		viewers[postId] = count
+	}
+
+	return viewers, rows.Err()
+}
+
+func (r PresenceRepo) DeleteStale(ctx context.Context, before time.Time) (count int64, err error) {
+	const stmt = "DELETE FROM forum_post_viewers WHERE seen_at < $1"
+	args := []any{before}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r PresenceRepo) Listen(ctx context.Context, listening func(), handle func(postId forum.PostId)) (err error) {
+	return r.BaseRepo.Listen(ctx, presenceChannel, listening, func(payload string) {
+		postId, err := uuid.Parse(payload)
+		if err != nil {
+			return
+		}
+		handle(postId)
+	})
+}
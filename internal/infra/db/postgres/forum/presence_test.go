@@ -0,0 +1,139 @@
+package forumdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+
+	"github.com/google/uuid"
+)
+
+func TestPresenceRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewPresenceRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (alice *auth.User, bob *auth.User, post *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		alice, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "alice",
+			DisplayName: "alice",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		bob, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "bob",
+			DisplayName: "bob",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, alice.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		return alice, bob, post
+	}
+
+	viewers := func(t *testing.T, post *forum.Post, since time.Time) int {
+		t.Helper()
+
+		counts, err := repo.CountViewers(ctx, []forum.PostId{post.Id}, since)
+		test.NilErr(t, err)
+
+		return counts[post.Id]
+	}
+
+	t.Run("viewers are counted by user", func(t *testing.T) {
+		alice, bob, post := setup(t)
+		since := time.Now().Add(-time.Minute)
+
+		first, second := uuid.New(), uuid.New()
+		test.NilErr(t, repo.Join(ctx, first, post.Id, alice.Id))
+		test.NilErr(t, repo.Join(ctx, second, post.Id, alice.Id))
+		test.AssertEqual(t, "Expected sessions of a user to count once", 1, viewers(t, post, since))
+
+		test.NilErr(t, repo.Join(ctx, uuid.New(), post.Id, bob.Id))
+		test.AssertEqual(t, "Viewers not as expected", 2, viewers(t, post, since))
+
+		test.NilErr(t, repo.Leave(ctx, first, post.Id))
+		test.AssertEqual(t, "Expected other session of the user to count", 2, viewers(t, post, since))
+
+		test.NilErr(t, repo.LeaveAll(ctx, second))
+		test.AssertEqual(t, "Viewers after leaving not as expected", 1, viewers(t, post, since))
+	})
+
+	t.Run("stale sessions expire", func(t *testing.T) {
+		alice, _, post := setup(t)
+
+		session := uuid.New()
+		test.NilErr(t, repo.Join(ctx, session, post.Id, alice.Id))
+		test.AssertEqual(t, "Expected stale session to be ignored", 0, viewers(t, post, time.Now().Add(time.Minute)))
+
+		test.NilErr(t, repo.Touch(ctx, []uuid.UUID{session}))
+		count, err := repo.DeleteStale(ctx, time.Now().Add(-time.Minute))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected fresh session to be kept", int64(0), count)
+
+		count, err = repo.DeleteStale(ctx, time.Now().Add(time.Minute))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of deleted sessions not as expected", int64(1), count)
+	})
+
+	t.Run("changes are announced", func(t *testing.T) {
+		alice, _, post := setup(t)
+
+		listenCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		listening := make(chan struct{})
+		postIds := make(chan forum.PostId, 2)
+		go func() {
+			_ = repo.Listen(listenCtx, func() {
+				close(listening)
+			}, func(postId forum.PostId) {
+				postIds <- postId
+			})
+		}()
+		<-listening
+
+		session := uuid.New()
+		test.NilErr(t, repo.Join(ctx, session, post.Id, alice.Id))
+		test.NilErr(t, repo.Leave(ctx, session, post.Id))
+
+		for range 2 {
+			select {
+			case postId := <-postIds:
+				test.AssertEqual(t, "Announced post not as expected", post.Id, postId)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for presence announcement")
+			}
+		}
+	})
+}
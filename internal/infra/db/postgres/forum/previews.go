@@ -0,0 +1,104 @@
+package forumdb
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LinkPreviewsRepo implements the dbportsforum.LinkPreviewsRepo interface.
+type LinkPreviewsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewLinkPreviewsRepo creates a new LinkPreviewsRepo.
+func NewLinkPreviewsRepo(pool *pgxpool.Pool) LinkPreviewsRepo {
+	return LinkPreviewsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r LinkPreviewsRepo) RequestLinkPreview(ctx context.Context, url string) (err error) {
+	const stmt = "INSERT INTO forum_link_previews (url) VALUES ($1) ON CONFLICT (url) DO UPDATE SET requested_at = NOW()"
+	args := []any{url}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "link preview")
+	}
+
+	return nil
+}
+
+func (r LinkPreviewsRepo) GetLinkPreviewsByUrls(ctx context.Context, urls []string) (
+	previews []forum.LinkPreview, err error,
+) {
+	const stmt = "SELECT url, status, title, description, image_url, fetched_at FROM forum_link_previews WHERE url = ANY($1)"
+	args := []any{urls}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	previews = make([]forum.LinkPreview, 0, len(urls))
+	for rows.Next() {
+		preview := forum.LinkPreview{}
+		err = rows.Scan(&preview.Url, &preview.Status, &preview.Title, &preview.Description, &preview.ImageUrl,
+			&preview.FetchedAt)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews, rows.Err()
+}
+
+func (r LinkPreviewsRepo) GetDueLinkPreviewUrls(ctx context.Context, staleBefore time.Time, limit int) (
+	urls []string, err error,
+) {
+	const stmt = `SELECT url FROM forum_link_previews
+WHERE fetched_at IS NULL OR (fetched_at < $1 AND requested_at > fetched_at)
+ORDER BY requested_at LIMIT $2`
+	args := []any{staleBefore, limit}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls = make([]string, 0, limit)
+	for rows.Next() {
+		var url string
+		err = rows.Scan(&url)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, rows.Err()
+}
+
+func (r LinkPreviewsRepo) SaveLinkPreview(ctx context.Context, preview forum.LinkPreview) (err error) {
+	const stmt = `UPDATE forum_link_previews SET status = $2, title = $3, description = $4, image_url = $5, fetched_at = $6
+WHERE url = $1`
+	args := []any{preview.Url, preview.Status, preview.Title, preview.Description, preview.ImageUrl, preview.FetchedAt}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "link preview")
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "link preview"}
+	}
+
+	return nil
+}
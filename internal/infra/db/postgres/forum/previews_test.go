@@ -0,0 +1,124 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestLinkPreviewsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewLinkPreviewsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	const url = "https://go.dev/blog"
+
+	setup := func(t *testing.T) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+	}
+
+	t.Run("link posts keep their url", func(t *testing.T) {
+		setup(t)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Type:  forum.PostTypeLink,
+			Title: "The Go Blog",
+			Url:   url,
+		})
+		test.NilErr(t, err)
+
+		got, err := postsRepo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Post value not as expected", post.PostValue, got.PostValue)
+	})
+
+	t.Run("requested previews are pending until saved", func(t *testing.T) {
+		setup(t)
+
+		err := repo.RequestLinkPreview(ctx, url)
+		test.NilErr(t, err)
+		err = repo.RequestLinkPreview(ctx, url)
+		test.NilErr(t, err)
+
+		previews, err := repo.GetLinkPreviewsByUrls(ctx, []string{url, "https://example.com"})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of previews not as expected", 1, len(previews))
+		test.AssertEqual(t, "Status not as expected", forum.LinkPreviewStatusPending, previews[0].Status)
+
+		urls, err := repo.GetDueLinkPreviewUrls(ctx, time.Now().Add(-time.Hour), 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of due previews not as expected", 1, len(urls))
+
+		preview := forum.NewLinkPreview(url, "The Go Blog", "News", "https://go.dev/logo.png", time.Now())
+		err = repo.SaveLinkPreview(ctx, preview)
+		test.NilErr(t, err)
+
+		previews, err = repo.GetLinkPreviewsByUrls(ctx, []string{url})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Status not as expected", forum.LinkPreviewStatusReady, previews[0].Status)
+		test.AssertEqual(t, "Title not as expected", "The Go Blog", previews[0].Title)
+		test.AssertEqual(t, "Image URL not as expected", "https://go.dev/logo.png", previews[0].ImageUrl)
+
+		urls, err = repo.GetDueLinkPreviewUrls(ctx, time.Now().Add(-time.Hour), 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Fresh previews should not be due", 0, len(urls))
+	})
+
+	t.Run("stale previews are due once requested again", func(t *testing.T) {
+		setup(t)
+
+		err := repo.RequestLinkPreview(ctx, url)
+		test.NilErr(t, err)
+
+		err = repo.SaveLinkPreview(ctx, forum.NewFailedLinkPreview(url, time.Now().Add(-2*time.Hour)))
+		test.NilErr(t, err)
+
+		urls, err := repo.GetDueLinkPreviewUrls(ctx, time.Now().Add(-time.Hour), 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Stale previews should not be due until requested", 0, len(urls))
+
+		err = repo.RequestLinkPreview(ctx, url)
+		test.NilErr(t, err)
+
+		urls, err = repo.GetDueLinkPreviewUrls(ctx, time.Now().Add(-time.Hour), 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Requested stale previews should be due", 1, len(urls))
+	})
+
+	t.Run("saving without a request fails", func(t *testing.T) {
+		setup(t)
+
+		err := repo.SaveLinkPreview(ctx, forum.NewFailedLinkPreview(url, time.Now()))
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
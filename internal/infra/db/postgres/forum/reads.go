@@ -0,0 +1,126 @@
+package forumdb
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReadsRepo implements the dbportsforum.ReadsRepo interface.
+type ReadsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewReadsRepo creates a new ReadsRepo.
+func NewReadsRepo(pool *pgxpool.Pool) ReadsRepo {
+	return ReadsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+// lastSeenJoins joins the post and community read times of the user in $1
+// onto the posts p.
+const lastSeenJoins = `LEFT JOIN forum_post_reads pr ON pr.post_id = p.id AND pr.user_id = $1
+LEFT JOIN forum_community_reads cr ON cr.community_id = p.community_id AND cr.user_id = $1`
+
+func (r ReadsRepo) MarkPostRead(ctx context.Context, userId auth.UserId, postId forum.PostId) (
+	lastSeenAt *time.Time, err error,
+) {
+	const stmt = `INSERT INTO forum_post_reads (user_id, post_id) VALUES ($1, $2)
+ON CONFLICT (user_id, post_id) DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at
+RETURNING last_seen_at`
+	args := []any{userId, postId}
+
+	lastSeenAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(lastSeenAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post read")
+	}
+
+	return lastSeenAt, nil
+}
+
+func (r ReadsRepo) MarkCommunityRead(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+	readAt *time.Time, err error,
+) {
+	const stmt = `INSERT INTO forum_community_reads (user_id, community_id) VALUES ($1, $2)
+ON CONFLICT (user_id, community_id) DO UPDATE SET read_at = EXCLUDED.read_at
+RETURNING read_at`
+	args := []any{userId, communityId}
+
+	readAt = &time.Time{}
+	err = r.QueryRow(ctx, stmt, args...).Scan(readAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "community read")
+	}
+
+	return readAt, nil
+}
+
+func (r ReadsRepo) GetLastSeenByPosts(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+	lastSeen map[forum.PostId]time.Time, err error,
+) {
+	const stmt = `SELECT p.id, GREATEST(pr.last_seen_at, cr.read_at) FROM forum_posts p
+` + lastSeenJoins + `
+WHERE p.id = ANY($2) AND (pr.last_seen_at IS NOT NULL OR cr.read_at IS NOT NULL)`
+	args := []any{userId, postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastSeen = make(map[forum.PostId]time.Time, len(postIds))
+	for rows.Next() {
+		var (
+			postId forum.PostId
+			at     time.Time
+		)
+		err = rows.Scan(&postId, &at)
+		if err != nil {
+			return nil, err
+		}
+		lastSeen[postId] = at
+	}
+
+	return lastSeen, rows.Err()
+}
+
+func (r ReadsRepo) GetUnreadCommentCounts(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+	counts map[forum.PostId]int, err error,
+) {
+	const stmt = `SELECT c.post_id, COUNT(*) FROM forum_comments c
+JOIN forum_posts p ON p.id = c.post_id
+` + lastSeenJoins + `
+WHERE c.post_id = ANY($2) AND c.deleted_at IS NULL AND c.commenter_id <> $1
+  AND c.created_at > COALESCE(GREATEST(pr.last_seen_at, cr.read_at), '-infinity')
+GROUP BY c.post_id`
+	args := []any{userId, postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts = make(map[forum.PostId]int, len(postIds))
+	for rows.Next() {
+		var (
+			postId forum.PostId
+			count  int
+		)
+		err = rows.Scan(&postId, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[postId] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,121 @@
+package forumdb
+
+import (
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestReadsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewReadsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (reader *auth.User, writer *auth.User, post *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		reader, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "reader",
+			DisplayName: "reader",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		writer, err = usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "writer",
+			DisplayName: "writer",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, writer.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		return reader, writer, post
+	}
+
+	comment := func(t *testing.T, post *forum.Post, commenter *auth.User) *forum.Comment {
+		t.Helper()
+
+		comment, err := commentsRepo.CreateComment(ctx, post.Id, commenter.Id, forum.CommentValue{
+			Body: "Comment by " + commenter.Username,
+		}, nil)
+		test.NilErr(t, err)
+
+		return comment
+	}
+
+	unread := func(t *testing.T, reader *auth.User, post *forum.Post) int {
+		t.Helper()
+
+		counts, err := repo.GetUnreadCommentCounts(ctx, reader.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+
+		return counts[post.Id]
+	}
+
+	t.Run("comments since the post was read are unread", func(t *testing.T) {
+		reader, writer, post := setup(t)
+
+		comment(t, post, writer)
+		comment(t, post, reader)
+		test.AssertEqual(t, "Unread count before reading not as expected", 1, unread(t, reader, post))
+
+		lastSeenAt, err := repo.MarkPostRead(ctx, reader.Id, post.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unread count after reading not as expected", 0, unread(t, reader, post))
+
+		comment(t, post, writer)
+		deleted := comment(t, post, writer)
+		_, err = commentsRepo.DeleteComment(ctx, deleted.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unread count after new comments not as expected", 1, unread(t, reader, post))
+
+		lastSeen, err := repo.GetLastSeenByPosts(ctx, reader.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Last seen time not as expected", lastSeenAt.UTC(), lastSeen[post.Id].UTC())
+	})
+
+	t.Run("marking the community as read", func(t *testing.T) {
+		reader, writer, post := setup(t)
+
+		comment(t, post, writer)
+
+		lastSeen, err := repo.GetLastSeenByPosts(ctx, reader.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		_, ok := lastSeen[post.Id]
+		test.Assert(t, "Expected post to be unseen", !ok)
+
+		readAt, err := repo.MarkCommunityRead(ctx, reader.Id, post.CommunityId)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unread count after marking community not as expected", 0, unread(t, reader, post))
+
+		lastSeen, err = repo.GetLastSeenByPosts(ctx, reader.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Last seen time not as expected", readAt.UTC(), lastSeen[post.Id].UTC())
+	})
+}
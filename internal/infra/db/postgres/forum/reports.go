@@ -0,0 +1,100 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReportsRepo implements the dbportsforum.ReportsRepo interface.
+type ReportsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewReportsRepo creates a new ReportsRepo.
+func NewReportsRepo(pool *pgxpool.Pool) ReportsRepo {
+	return ReportsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r ReportsRepo) CreateReport(ctx context.Context, reporterId auth.UserId, postId forum.PostId,
+	commentId *forum.CommentId, value forum.ReportValue,
+) (report *forum.Report, err error) {
+	const stmt = `INSERT INTO forum_reports (reporter_id, post_id, comment_id, target_type, reason, details)
+VALUES ($1, $2, $3, CASE WHEN $3::UUID IS NULL THEN 'post' ELSE 'comment' END, $4, $5) RETURNING id, created_at`
+	args := []any{reporterId, postId, commentId, value.Reason, value.Details}
+
+	report = &forum.Report{
+		ReporterId:  reporterId,
+		PostId:      postId,
+		CommentId:   commentId,
+		ReportValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&report.Id, &report.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "report")
+	}
+
+	return report, nil
+}
+
+func (r ReportsRepo) GetReportQueue(ctx context.Context, communityId forum.CommunityId, sort forum.ReportQueueSort,
+	limit int, offset int,
+) (items []forum.ReportedItem, err error) {
+	// The reports are counted per reason first, so that the counts of the
+	// reasons can be aggregated into an object.
+	const stmt = `SELECT r.post_id, r.comment_id, SUM(r.n)::INT AS reports, jsonb_object_agg(r.reason, r.n),
+	MIN(r.first_reported_at), MAX(r.last_reported_at)
+FROM (SELECT post_id, comment_id, reason, COUNT(*) AS n,
+             MIN(created_at) AS first_reported_at, MAX(created_at) AS last_reported_at
+      FROM forum_reports WHERE resolved_at IS NULL AND (comment_id IS NOT NULL OR target_type = 'post')
+      GROUP BY post_id, comment_id, reason) r
+JOIN forum_posts p ON p.id = r.post_id
+WHERE p.community_id = $1
+GROUP BY r.post_id, r.comment_id
+ORDER BY CASE WHEN $2 = 'count' THEN SUM(r.n) END DESC, MIN(r.first_reported_at), r.post_id, r.comment_id NULLS FIRST
+LIMIT $3 OFFSET $4`
+	args := []any{communityId, sort, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items = make([]forum.ReportedItem, 0, limit)
+	for rows.Next() {
+		var item forum.ReportedItem
+		err = rows.Scan(
+			&item.PostId, &item.CommentId, &item.Reports, &item.Reasons, &item.FirstReportedAt,
+			&item.LastReportedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r ReportsRepo) ResolveReports(ctx context.Context, postId forum.PostId, commentId *forum.CommentId,
+	resolverId auth.UserId, action forum.ReportAction,
+) (resolved int, err error) {
+	const stmt = `UPDATE forum_reports SET resolved_at = NOW(), resolver_id = $3, resolution = $4
+WHERE post_id = $1 AND comment_id IS NOT DISTINCT FROM $2 AND resolved_at IS NULL
+  AND (comment_id IS NOT NULL OR target_type = 'post')`
+	args := []any{postId, commentId, resolverId, action}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,128 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestReportsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewReportsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (users []auth.User, post *forum.Post, comment *forum.Comment) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		for _, username := range []string{"alice", "bob", "carol"} {
+			user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+				Username:    username,
+				DisplayName: username,
+				Role:        auth.RoleUser,
+			})
+			test.NilErr(t, err)
+			users = append(users, *user)
+		}
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, users[0].Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		comment, err = commentsRepo.CreateComment(ctx, post.Id, users[0].Id, forum.CommentValue{
+			Body: "Buy now",
+		}, nil)
+		test.NilErr(t, err)
+
+		return users, post, comment
+	}
+
+	t.Run("allows one open report per user and item", func(t *testing.T) {
+		users, post, comment := setup(t)
+
+		_, err := repo.CreateReport(ctx, users[1].Id, post.Id, nil, forum.ReportValue{Reason: forum.ReportReasonSpam})
+		test.NilErr(t, err)
+
+		_, err = repo.CreateReport(ctx, users[1].Id, post.Id, nil, forum.ReportValue{Reason: forum.ReportReasonHate})
+		test.Assert(t, "Expected conflict error", errors.Is(err, shared.ErrConflict))
+
+		_, err = repo.CreateReport(ctx, users[1].Id, post.Id, &comment.Id, forum.ReportValue{
+			Reason: forum.ReportReasonSpam,
+		})
+		test.NilErr(t, err)
+
+		resolved, err := repo.ResolveReports(ctx, post.Id, nil, users[0].Id, forum.ReportActionDismiss)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of resolved reports not as expected", 1, resolved)
+
+		_, err = repo.CreateReport(ctx, users[1].Id, post.Id, nil, forum.ReportValue{Reason: forum.ReportReasonHate})
+		test.NilErr(t, err)
+	})
+
+	t.Run("aggregates reports in the queue", func(t *testing.T) {
+		users, post, comment := setup(t)
+
+		_, err := repo.CreateReport(ctx, users[1].Id, post.Id, nil, forum.ReportValue{Reason: forum.ReportReasonSpam})
+		test.NilErr(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		for _, user := range users[1:] {
+			_, err = repo.CreateReport(ctx, user.Id, post.Id, &comment.Id, forum.ReportValue{
+				Reason: forum.ReportReasonSpam,
+			})
+			test.NilErr(t, err)
+		}
+		_, err = repo.CreateReport(ctx, users[0].Id, post.Id, &comment.Id, forum.ReportValue{
+			Reason: forum.ReportReasonHate,
+		})
+		test.NilErr(t, err)
+
+		items, err := repo.GetReportQueue(ctx, post.CommunityId, forum.ReportQueueSortCount, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of items not as expected", 2, len(items))
+		test.AssertEqual(t, "Most reported item should be first", comment.Id, *items[0].CommentId)
+		test.AssertEqual(t, "Number of reports not as expected", 3, items[0].Reports)
+		test.AssertEqual(t, "Reasons not as expected", map[forum.ReportReason]int{
+			forum.ReportReasonSpam: 2,
+			forum.ReportReasonHate: 1,
+		}, items[0].Reasons)
+
+		items, err = repo.GetReportQueue(ctx, post.CommunityId, forum.ReportQueueSortAge, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of items not as expected", 2, len(items))
+		test.Assert(t, "Oldest item should be first", items[0].CommentId == nil)
+
+		resolved, err := repo.ResolveReports(ctx, post.Id, &comment.Id, users[0].Id, forum.ReportActionRemove)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of resolved reports not as expected", 3, resolved)
+
+		items, err = repo.GetReportQueue(ctx, post.CommunityId, forum.ReportQueueSortCount, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Resolved item should leave the queue", 1, len(items))
+	})
+}
@@ -0,0 +1,249 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevisionsRepo implements the dbportsforum.RevisionsRepo interface.
+type RevisionsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewRevisionsRepo creates a new RevisionsRepo.
+func NewRevisionsRepo(pool *pgxpool.Pool) RevisionsRepo {
+	return RevisionsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r RevisionsRepo) CreatePostRevision(ctx context.Context, postId forum.PostId, editorId auth.UserId,
+	value forum.PostValue,
+) (revision *forum.PostRevision, err error) {
+	const stmt = `INSERT INTO forum_post_revisions (post_id, editor_id, title, body, revision)
+SELECT $1::uuid, $2::uuid, $3::text, $4::text, COALESCE(MAX(revision), 0) + 1 FROM forum_post_revisions WHERE post_id = $1::uuid
+RETURNING id, revision, created_at`
+	args := []any{postId, editorId, value.Title, value.Body}
+
+	// Locking the post serializes the revisions of concurrent edits, which
+	// would otherwise compute the same next number.
+	const lockStmt = "SELECT id FROM forum_posts WHERE id = $1 FOR UPDATE"
+	err = r.QueryRow(ctx, lockStmt, postId).Scan(&postId)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "post")
+	}
+
+	revision = &forum.PostRevision{
+		RevisionMetadata: forum.RevisionMetadata{
+			EditorId: editorId,
+		},
+		PostValue: value,
+		PostId:    postId,
+	}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&revision.Id, &revision.Revision, &revision.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "revision")
+	}
+
+	return revision, nil
+}
+
+func (r RevisionsRepo) GetPostRevision(ctx context.Context, postId forum.PostId, revision int) (
+	postRevision *forum.PostRevision, err error,
+) {
+	const stmt = "SELECT id, editor_id, title, body, created_at FROM forum_post_revisions WHERE post_id = $1 AND revision = $2"
+	args := []any{postId, revision}
+
+	postRevision = &forum.PostRevision{
+		RevisionMetadata: forum.RevisionMetadata{
+			Revision: revision,
+		},
+		PostId: postId,
+	}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(
+		&postRevision.Id, &postRevision.EditorId, &postRevision.Title, &postRevision.Body, &postRevision.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "revision")
+	}
+
+	return postRevision, nil
+}
+
+func (r RevisionsRepo) GetPostRevisions(ctx context.Context, postId forum.PostId, limit int, offset int) (
+	revisions []forum.PostRevision, err error,
+) {
+	const stmt = "SELECT id, revision, editor_id, title, body, created_at FROM forum_post_revisions WHERE post_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3"
+	args := []any{postId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions = make([]forum.PostRevision, 0, limit)
+	for rows.Next() {
+		revision := forum.PostRevision{
+			PostId: postId,
+		}
+		err = rows.Scan(
+			&revision.Id, &revision.Revision, &revision.EditorId, &revision.Title, &revision.Body, &revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+func (r RevisionsRepo) GetLatestPostRevisionIds(ctx context.Context, postIds []forum.PostId) (
+	ids map[forum.PostId]forum.RevisionId, err error,
+) {
+	const stmt = `SELECT DISTINCT ON (post_id) post_id, id FROM forum_post_revisions
+WHERE post_id = ANY($1) ORDER BY post_id, revision DESC`
+	args := []any{postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids = make(map[forum.PostId]forum.RevisionId, len(postIds))
+	for rows.Next() {
+		var (
+			postId     forum.PostId
+			revisionId forum.RevisionId
+		)
+		err = rows.Scan(&postId, &revisionId)
+		if err != nil {
+			return nil, err
+		}
+		ids[postId] = revisionId
+	}
+
+	return ids, rows.Err()
+}
+
+func (r RevisionsRepo) CreateCommentRevision(ctx context.Context, commentId forum.CommentId, editorId auth.UserId,
+	value forum.CommentValue,
+) (revision *forum.CommentRevision, err error) {
+	const stmt = `INSERT INTO forum_comment_revisions (comment_id, editor_id, body, revision)
+SELECT $1::uuid, $2::uuid, $3::text, COALESCE(MAX(revision), 0) + 1 FROM forum_comment_revisions WHERE comment_id = $1::uuid
+RETURNING id, revision, created_at`
+	args := []any{commentId, editorId, value.Body}
+
+	// Locking the comment serializes the revisions of concurrent edits, which
+	// would otherwise compute the same next number.
+	const lockStmt = "SELECT id FROM forum_comments WHERE id = $1 FOR UPDATE"
+	err = r.QueryRow(ctx, lockStmt, commentId).Scan(&commentId)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "comment")
+	}
+
+	revision = &forum.CommentRevision{
+		RevisionMetadata: forum.RevisionMetadata{
+			EditorId: editorId,
+		},
+		CommentValue: value,
+		CommentId:    commentId,
+	}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&revision.Id, &revision.Revision, &revision.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "revision")
+	}
+
+	return revision, nil
+}
+
+func (r RevisionsRepo) GetCommentRevision(ctx context.Context, commentId forum.CommentId, revision int) (
+	commentRevision *forum.CommentRevision, err error,
+) {
+	const stmt = "SELECT id, editor_id, body, created_at FROM forum_comment_revisions WHERE comment_id = $1 AND revision = $2"
+	args := []any{commentId, revision}
+
+	commentRevision = &forum.CommentRevision{
+		RevisionMetadata: forum.RevisionMetadata{
+			Revision: revision,
+		},
+		CommentId: commentId,
+	}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(
+		&commentRevision.Id, &commentRevision.EditorId, &commentRevision.Body, &commentRevision.CreatedAt,
+	)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "revision")
+	}
+
+	return commentRevision, nil
+}
+
+func (r RevisionsRepo) GetCommentRevisions(ctx context.Context, commentId forum.CommentId, limit int, offset int) (
+	revisions []forum.CommentRevision, err error,
+) {
+	const stmt = "SELECT id, revision, editor_id, body, created_at FROM forum_comment_revisions WHERE comment_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3"
+	args := []any{commentId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions = make([]forum.CommentRevision, 0, limit)
+	for rows.Next() {
+		revision := forum.CommentRevision{
+			CommentId: commentId,
+		}
+		err = rows.Scan(
+			&revision.Id, &revision.Revision, &revision.EditorId, &revision.Body, &revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
+func (r RevisionsRepo) GetLatestCommentRevisionIds(ctx context.Context, commentIds []forum.CommentId) (
+	ids map[forum.CommentId]forum.RevisionId, err error,
+) {
+	const stmt = `SELECT DISTINCT ON (comment_id) comment_id, id FROM forum_comment_revisions
+WHERE comment_id = ANY($1) ORDER BY comment_id, revision DESC`
+	args := []any{commentIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids = make(map[forum.CommentId]forum.RevisionId, len(commentIds))
+	for rows.Next() {
+		var (
+			commentId  forum.CommentId
+			revisionId forum.RevisionId
+		)
+		err = rows.Scan(&commentId, &revisionId)
+		if err != nil {
+			return nil, err
+		}
+		ids[commentId] = revisionId
+	}
+
+	return ids, rows.Err()
+}
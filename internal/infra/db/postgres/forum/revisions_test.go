@@ -0,0 +1,166 @@
+package forumdb
+
+import (
+	"sync"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+
+	"github.com/google/uuid"
+)
+
+func TestRevisionsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewRevisionsRepo(pool)
+	txs := postgres.NewTransactional(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (poster *auth.User, post *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Test Post",
+			Body:  "First",
+		})
+		test.NilErr(t, err)
+
+		return poster, post
+	}
+
+	t.Run("post revisions are numbered in order", func(t *testing.T) {
+		poster, post := setup(t)
+
+		for i, body := range []string{"First", "Second", "Third"} {
+			revision, err := repo.CreatePostRevision(ctx, post.Id, poster.Id, forum.PostValue{
+				Title: post.Title,
+				Body:  body,
+			})
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Unexpected revision number", i+1, revision.Revision)
+		}
+
+		revision, err := repo.GetPostRevision(ctx, post.Id, 2)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected body", "Second", revision.Body)
+		test.AssertEqual(t, "Unexpected title", post.Title, revision.Title)
+		test.AssertEqual(t, "Unexpected editor", poster.Id, revision.EditorId)
+
+		revisions, err := repo.GetPostRevisions(ctx, post.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 3 revisions", 3, len(revisions))
+		test.AssertEqual(t, "Expected latest revision first", 3, revisions[0].Revision)
+
+		latest, err := repo.GetLatestPostRevisionIds(ctx, []forum.PostId{post.Id, uuid.New()})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected latest revisions", map[forum.PostId]forum.RevisionId{
+			post.Id: revisions[0].Id,
+		}, latest)
+	})
+
+	t.Run("comment revisions are numbered in order", func(t *testing.T) {
+		poster, post := setup(t)
+
+		comment, err := commentsRepo.CreateComment(ctx, post.Id, poster.Id, forum.CommentValue{
+			Body: "First",
+		}, nil)
+		test.NilErr(t, err)
+
+		for i, body := range []string{"First", "Second"} {
+			revision, err := repo.CreateCommentRevision(ctx, comment.Id, poster.Id, forum.CommentValue{
+				Body: body,
+			})
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Unexpected revision number", i+1, revision.Revision)
+		}
+
+		revision, err := repo.GetCommentRevision(ctx, comment.Id, 1)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected body", "First", revision.Body)
+
+		revisions, err := repo.GetCommentRevisions(ctx, comment.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 2 revisions", 2, len(revisions))
+		test.AssertEqual(t, "Expected latest revision first", 2, revisions[0].Revision)
+
+		latest, err := repo.GetLatestCommentRevisionIds(ctx, []forum.CommentId{comment.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected latest revision", revisions[0].Id, latest[comment.Id])
+	})
+
+	t.Run("concurrent post revisions get distinct numbers", func(t *testing.T) {
+		poster, post := setup(t)
+
+		const editors = 10
+		errs := make([]error, editors)
+		wg := sync.WaitGroup{}
+		for i := range editors {
+			wg.Go(func() {
+				errs[i] = func() error {
+					ctx, err := txs.CtxTx(ctx)
+					if err != nil {
+						return err
+					}
+					defer func() { _ = txs.TxRollback(ctx) }()
+
+					_, err = repo.CreatePostRevision(ctx, post.Id, poster.Id, post.PostValue)
+					if err != nil {
+						return err
+					}
+
+					return txs.TxCommit(ctx)
+				}()
+			})
+		}
+		wg.Wait()
+		for _, err := range errs {
+			test.NilErr(t, err)
+		}
+
+		revisions, err := repo.GetPostRevisions(ctx, post.Id, editors, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected a revision per editor", editors, len(revisions))
+		test.AssertEqual(t, "Expected latest revision to be numbered last", editors, revisions[0].Revision)
+	})
+
+	t.Run("revisions cannot be modified", func(t *testing.T) {
+		poster, post := setup(t)
+
+		_, err := repo.CreatePostRevision(ctx, post.Id, poster.Id, post.PostValue)
+		test.NilErr(t, err)
+
+		_, err = pool.Exec(ctx, "UPDATE forum_post_revisions SET body = 'Changed' WHERE post_id = $1", post.Id)
+		test.NilErr(t, err)
+
+		revision, err := repo.GetPostRevision(ctx, post.Id, 1)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected revision to be unchanged", post.Body, revision.Body)
+	})
+}
@@ -0,0 +1,255 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SavedItemsRepo implements the dbportsforum.SavedItemsRepo interface.
+type SavedItemsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewSavedItemsRepo creates a new SavedItemsRepo.
+func NewSavedItemsRepo(pool *pgxpool.Pool) SavedItemsRepo {
+	return SavedItemsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r SavedItemsRepo) CreateCollection(ctx context.Context, userId auth.UserId, value forum.CollectionValue) (
+	collection *forum.Collection, err error,
+) {
+	const stmt = "INSERT INTO forum_collections (user_id, name) VALUES ($1, $2) RETURNING id, created_at"
+	args := []any{userId, value.Name}
+
+	collection = &forum.Collection{
+		UserId:          userId,
+		CollectionValue: value,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&collection.Id, &collection.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "collection")
+	}
+
+	return collection, nil
+}
+
+func (r SavedItemsRepo) GetCollectionById(ctx context.Context, id forum.CollectionId) (
+	collection *forum.Collection, err error,
+) {
+	const stmt = "SELECT user_id, name, created_at FROM forum_collections WHERE id = $1"
+	args := []any{id}
+
+	collection = &forum.Collection{
+		Id: id,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&collection.UserId, &collection.Name, &collection.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "collection")
+	}
+
+	return collection, nil
+}
+
+func (r SavedItemsRepo) GetCollections(ctx context.Context, userId auth.UserId, limit int, offset int) (
+	collections []forum.Collection, err error,
+) {
+	const stmt = "SELECT id, name, created_at FROM forum_collections WHERE user_id = $1 ORDER BY name LIMIT $2 OFFSET $3"
+	args := []any{userId, limit, offset}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections = make([]forum.Collection, 0, limit)
+	for rows.Next() {
+		collection := forum.Collection{
+			UserId: userId,
+		}
+		err = rows.Scan(&collection.Id, &collection.Name, &collection.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+func (r SavedItemsRepo) DeleteCollection(ctx context.Context, id forum.CollectionId) (err error) {
+	const stmt = "DELETE FROM forum_collections WHERE id = $1 RETURNING id"
+	args := []any{id}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&id)
+	if err != nil {
+		return postgres.TranslateError(err, "collection")
+	}
+
+	return nil
+}
+
+func (r SavedItemsRepo) SavePost(ctx context.Context, userId auth.UserId, postId forum.PostId,
+	value forum.SavedItemValue,
+) (item *forum.SavedItem, err error) {
+	const stmt = `INSERT INTO forum_saved_items (user_id, post_id, collection_id, note) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id) WHERE post_id IS NOT NULL
+DO UPDATE SET collection_id = EXCLUDED.collection_id, note = EXCLUDED.note
+RETURNING id, created_at`
+	args := []any{userId, postId, value.CollectionId, value.Note}
+
+	item = &forum.SavedItem{
+		UserId:         userId,
+		PostId:         &postId,
+		SavedItemValue: value,
+	}
+
+	return r.saveItemAux(ctx, stmt, args, item)
+}
+
+func (r SavedItemsRepo) SaveComment(ctx context.Context, userId auth.UserId, commentId forum.CommentId,
+	value forum.SavedItemValue,
+) (item *forum.SavedItem, err error) {
+	const stmt = `INSERT INTO forum_saved_items (user_id, comment_id, collection_id, note) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, comment_id) WHERE comment_id IS NOT NULL
+DO UPDATE SET collection_id = EXCLUDED.collection_id, note = EXCLUDED.note
+RETURNING id, created_at`
+	args := []any{userId, commentId, value.CollectionId, value.Note}
+
+	item = &forum.SavedItem{
+		UserId:         userId,
+		CommentId:      &commentId,
+		SavedItemValue: value,
+	}
+
+	return r.saveItemAux(ctx, stmt, args, item)
+}
+
+func (r SavedItemsRepo) saveItemAux(ctx context.Context, stmt string, args []any, item *forum.SavedItem) (
+	*forum.SavedItem, error,
+) {
+	err := r.QueryRow(ctx, stmt, args...).Scan(&item.Id, &item.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "saved item")
+	}
+
+	return item, nil
+}
+
+func (r SavedItemsRepo) UnsavePost(ctx context.Context, userId auth.UserId, postId forum.PostId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_saved_items WHERE user_id = $1 AND post_id = $2"
+	args := []any{userId, postId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r SavedItemsRepo) UnsaveComment(ctx context.Context, userId auth.UserId, commentId forum.CommentId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_saved_items WHERE user_id = $1 AND comment_id = $2"
+	args := []any{userId, commentId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r SavedItemsRepo) GetSavedItems(ctx context.Context, userId auth.UserId, filter forum.SavedItemFilter,
+	limit int, offset int, opts ...dbports.ReadOption,
+) (items []forum.SavedItem, err error) {
+	const stmt = `SELECT s.id, s.created_at, s.post_id, s.comment_id, s.collection_id, s.note FROM forum_saved_items s
+LEFT JOIN forum_posts p ON p.id = s.post_id
+LEFT JOIN forum_comments c ON c.id = s.comment_id
+LEFT JOIN forum_posts cp ON cp.id = c.post_id
+WHERE s.user_id = $1 AND p.deleted_at IS NULL AND c.deleted_at IS NULL AND cp.deleted_at IS NULL
+  AND ($8 OR (p.removed_at IS NULL AND c.removed_at IS NULL AND cp.removed_at IS NULL))
+  AND ($2::text IS NULL OR ($2::text = 'post') = (s.post_id IS NOT NULL))
+  AND ($3::uuid IS NULL OR s.collection_id = $3::uuid)
+  AND forum_community_visible(COALESCE(p.community_id, cp.community_id), $6, $7)
+ORDER BY s.created_at DESC LIMIT $4 OFFSET $5`
+	var kind *string
+	if filter.Kind != "" {
+		str := string(filter.Kind)
+		kind = &str
+	}
+	options := dbports.NewReadOptions(opts...)
+	args := []any{userId, kind, filter.CollectionId, limit, offset, options.ViewerId, options.IncludePrivate,
+		options.IncludeRemoved}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items = make([]forum.SavedItem, 0, limit)
+	for rows.Next() {
+		item := forum.SavedItem{
+			UserId: userId,
+		}
+		err = rows.Scan(&item.Id, &item.CreatedAt, &item.PostId, &item.CommentId, &item.CollectionId, &item.Note)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r SavedItemsRepo) GetSavedPostIds(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+	saved []forum.PostId, err error,
+) {
+	const stmt = "SELECT post_id FROM forum_saved_items WHERE user_id = $1 AND post_id = ANY($2)"
+	args := []any{userId, postIds}
+
+	return r.getIdsAux(ctx, stmt, args)
+}
+
+func (r SavedItemsRepo) GetSavedCommentIds(ctx context.Context, userId auth.UserId, commentIds []forum.CommentId) (
+	saved []forum.CommentId, err error,
+) {
+	const stmt = "SELECT comment_id FROM forum_saved_items WHERE user_id = $1 AND comment_id = ANY($2)"
+	args := []any{userId, commentIds}
+
+	return r.getIdsAux(ctx, stmt, args)
+}
+
+func (r SavedItemsRepo) getIdsAux(ctx context.Context, stmt string, args []any) (ids []uuid.UUID, err error) {
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids = []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,198 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	dbports "greddit/internal/ports/db"
+)
+
+func TestSavedItemsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewSavedItemsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (user *auth.User, post *forum.Post, comment *forum.Comment) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		post, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		comment, err = commentsRepo.CreateComment(ctx, post.Id, user.Id, forum.CommentValue{
+			Body: "Great post",
+		}, nil)
+		test.NilErr(t, err)
+
+		return user, post, comment
+	}
+
+	t.Run("saving again replaces the note and collection", func(t *testing.T) {
+		user, post, _ := setup(t)
+
+		collection, err := repo.CreateCollection(ctx, user.Id, forum.CollectionValue{Name: "Read later"})
+		test.NilErr(t, err)
+
+		first, err := repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{Note: "first"})
+		test.NilErr(t, err)
+
+		second, err := repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{
+			CollectionId: &collection.Id,
+			Note:         "second",
+		})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Saved item should be reused", first.Id, second.Id)
+
+		items, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of saved items not as expected", 1, len(items))
+		test.AssertEqual(t, "Note not as expected", "second", items[0].Note)
+		test.AssertEqual(t, "Collection not as expected", collection.Id, *items[0].CollectionId)
+	})
+
+	t.Run("filters by kind and collection", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		collection, err := repo.CreateCollection(ctx, user.Id, forum.CollectionValue{Name: "Read later"})
+		test.NilErr(t, err)
+
+		_, err = repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+		_, err = repo.SaveComment(ctx, user.Id, comment.Id, forum.SavedItemValue{CollectionId: &collection.Id})
+		test.NilErr(t, err)
+
+		posts, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{Kind: forum.SavedItemKindPost}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of saved posts not as expected", 1, len(posts))
+		test.AssertEqual(t, "Saved post not as expected", post.Id, *posts[0].PostId)
+
+		collected, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{CollectionId: &collection.Id}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of collected items not as expected", 1, len(collected))
+		test.AssertEqual(t, "Collected comment not as expected", comment.Id, *collected[0].CommentId)
+	})
+
+	t.Run("deleted content is excluded", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		_, err := repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+		_, err = repo.SaveComment(ctx, user.Id, comment.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+
+		_, err = commentsRepo.DeleteComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		items, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of saved items not as expected", 1, len(items))
+		test.AssertEqual(t, "Saved post not as expected", post.Id, *items[0].PostId)
+	})
+
+	t.Run("removed content and comments on deleted posts are excluded", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		_, err := repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+		_, err = repo.SaveComment(ctx, user.Id, comment.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+
+		_, err = commentsRepo.RemoveComment(ctx, comment.Id, "Spam")
+		test.NilErr(t, err)
+
+		items, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of saved items not as expected", 1, len(items))
+		test.AssertEqual(t, "Saved post not as expected", post.Id, *items[0].PostId)
+
+		items, err = repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0, dbports.IncludeRemoved())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected removed comment to be included", 2, len(items))
+
+		_, err = postsRepo.DeletePost(ctx, post.Id)
+		test.NilErr(t, err)
+
+		items, err = repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0, dbports.IncludeRemoved())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no items of the deleted post", 0, len(items))
+	})
+
+	t.Run("saved flags", func(t *testing.T) {
+		user, post, comment := setup(t)
+
+		_, err := repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{})
+		test.NilErr(t, err)
+
+		postIds, err := repo.GetSavedPostIds(ctx, user.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Saved post IDs not as expected", []forum.PostId{post.Id}, postIds)
+
+		commentIds, err := repo.GetSavedCommentIds(ctx, user.Id, []forum.CommentId{comment.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no saved comments", 0, len(commentIds))
+
+		removed, err := repo.UnsavePost(ctx, user.Id, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected post to be unsaved", removed)
+
+		postIds, err = repo.GetSavedPostIds(ctx, user.Id, []forum.PostId{post.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no saved posts", 0, len(postIds))
+	})
+
+	t.Run("deleting a collection keeps its items", func(t *testing.T) {
+		user, post, _ := setup(t)
+
+		collection, err := repo.CreateCollection(ctx, user.Id, forum.CollectionValue{Name: "Read later"})
+		test.NilErr(t, err)
+
+		_, err = repo.CreateCollection(ctx, user.Id, forum.CollectionValue{Name: "Read later"})
+		test.Assert(t, "Expected conflict for duplicate name", errors.Is(err, shared.ErrConflict))
+
+		_, err = repo.SavePost(ctx, user.Id, post.Id, forum.SavedItemValue{CollectionId: &collection.Id})
+		test.NilErr(t, err)
+
+		err = repo.DeleteCollection(ctx, collection.Id)
+		test.NilErr(t, err)
+
+		_, err = repo.GetCollectionById(ctx, collection.Id)
+		test.Assert(t, "Expected collection to be deleted", errors.Is(err, shared.ErrNotFound))
+
+		items, err := repo.GetSavedItems(ctx, user.Id, forum.SavedItemFilter{}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of saved items not as expected", 1, len(items))
+		test.Assert(t, "Expected item outside of any collection", items[0].CollectionId == nil)
+	})
+}
@@ -0,0 +1,143 @@
+package forumdb
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SubscriptionsRepo implements the dbportsforum.SubscriptionsRepo interface.
+type SubscriptionsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewSubscriptionsRepo creates a new SubscriptionsRepo.
+func NewSubscriptionsRepo(pool *pgxpool.Pool) SubscriptionsRepo {
+	return SubscriptionsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r SubscriptionsRepo) Subscribe(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+	subscription *forum.Subscription, err error,
+) {
+	// The existing row is not visible to the outer select if it was inserted
+	// by the statement itself, so exactly one row is returned.
+	const stmt = `WITH inserted AS (
+    INSERT INTO forum_subscriptions (user_id, community_id) VALUES ($1, $2)
+    ON CONFLICT (user_id, community_id) DO NOTHING
+    RETURNING created_at
+)
+SELECT created_at FROM inserted
+UNION ALL
+SELECT created_at FROM forum_subscriptions WHERE user_id = $1 AND community_id = $2`
+	args := []any{userId, communityId}
+
+	subscription = &forum.Subscription{
+		UserId:      userId,
+		CommunityId: communityId,
+	}
+	err = r.QueryRow(ctx, stmt, args...).Scan(&subscription.CreatedAt)
+	if err != nil {
+		return nil, postgres.TranslateError(err, "subscription")
+	}
+
+	return subscription, nil
+}
+
+func (r SubscriptionsRepo) Unsubscribe(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+	removed bool, err error,
+) {
+	const stmt = "DELETE FROM forum_subscriptions WHERE user_id = $1 AND community_id = $2"
+	args := []any{userId, communityId}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r SubscriptionsRepo) HasSubscriptions(ctx context.Context, userId auth.UserId, opts ...dbports.ReadOption) (
+	ok bool, err error,
+) {
+	const stmt = `SELECT EXISTS (
+    SELECT 1 FROM forum_subscriptions s JOIN forum_communities c ON c.id = s.community_id
+    WHERE s.user_id = $1 AND c.deleted_at IS NULL AND forum_community_visible(c.id, $2, $3)
+)`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{userId, options.ViewerId, options.IncludePrivate}
+
+	err = r.QueryRow(ctx, stmt, args...).Scan(&ok)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (r SubscriptionsRepo) GetSubscribedCommunities(ctx context.Context, userId auth.UserId, limit int, offset int,
+	opts ...dbports.ReadOption,
+) (communities []forum.Community, err error) {
+	const stmt = `SELECT c.id, c.name, c.description, c.created_at, c.updated_at, c.deleted_at,
+	c.rules, c.allowed_post_types, c.require_flair, c.visibility
+FROM forum_subscriptions s JOIN forum_communities c ON c.id = s.community_id
+WHERE s.user_id = $1 AND c.deleted_at IS NULL AND forum_community_visible(c.id, $4, $5)
+ORDER BY c.name LIMIT $2 OFFSET $3`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{userId, limit, offset, options.ViewerId, options.IncludePrivate}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	communities = make([]forum.Community, 0, limit)
+	for rows.Next() {
+		c := forum.Community{}
+		err = rows.Scan(
+			&c.Id, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
+			&c.Settings.Rules, &c.Settings.AllowedPostTypes, &c.Settings.RequireFlair, &c.Settings.Visibility,
+		)
+		if err != nil {
+			return nil, err
+		}
+		communities = append(communities, c)
+	}
+
+	return communities, nil
+}
+
+func (r SubscriptionsRepo) GetSubscribedCommunityIds(ctx context.Context, userId auth.UserId,
+	opts ...dbports.ReadOption,
+) (communityIds []forum.CommunityId, err error) {
+	const stmt = `SELECT c.id FROM forum_subscriptions s JOIN forum_communities c ON c.id = s.community_id
+WHERE s.user_id = $1 AND c.deleted_at IS NULL AND forum_community_visible(c.id, $2, $3)`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{userId, options.ViewerId, options.IncludePrivate}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	communityIds = []forum.CommunityId{}
+	for rows.Next() {
+		var id forum.CommunityId
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		communityIds = append(communityIds, id)
+	}
+
+	return communityIds, rows.Err()
+}
@@ -0,0 +1,192 @@
+package forumdb
+
+import (
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestSubscriptionsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewSubscriptionsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T, names ...string) (user *auth.User, communities []*forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		for _, name := range names {
+			community, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+				Name:        name,
+				Description: "Description of " + name,
+			})
+			test.NilErr(t, err)
+			communities = append(communities, community)
+		}
+
+		return user, communities
+	}
+
+	createPost := func(t *testing.T, communityId forum.CommunityId, posterId auth.UserId, title string) *forum.Post {
+		t.Helper()
+
+		post, err := postsRepo.CreatePost(ctx, communityId, posterId, forum.PostValue{
+			Title: title,
+			Body:  "Body of " + title,
+		})
+		test.NilErr(t, err)
+
+		return post
+	}
+
+	titles := func(posts []forum.Post) []string {
+		titles := make([]string, 0, len(posts))
+		for _, post := range posts {
+			titles = append(titles, post.Title)
+		}
+		return titles
+	}
+
+	t.Run("subscribing is idempotent", func(t *testing.T) {
+		user, communities := setup(t, "golang")
+
+		first, err := repo.Subscribe(ctx, user.Id, communities[0].Id)
+		test.NilErr(t, err)
+
+		second, err := repo.Subscribe(ctx, user.Id, communities[0].Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Subscription time should not change", first.CreatedAt, second.CreatedAt)
+
+		ok, err := repo.HasSubscriptions(ctx, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected subscriptions", ok)
+	})
+
+	t.Run("unsubscribing", func(t *testing.T) {
+		user, communities := setup(t, "golang")
+
+		_, err := repo.Subscribe(ctx, user.Id, communities[0].Id)
+		test.NilErr(t, err)
+
+		removed, err := repo.Unsubscribe(ctx, user.Id, communities[0].Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected subscription to be removed", removed)
+
+		removed, err = repo.Unsubscribe(ctx, user.Id, communities[0].Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected nothing to be removed", !removed)
+
+		ok, err := repo.HasSubscriptions(ctx, user.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected no subscriptions", !ok)
+	})
+
+	t.Run("subscribed communities exclude deleted communities", func(t *testing.T) {
+		user, communities := setup(t, "rust", "golang", "zig")
+
+		for _, community := range communities {
+			_, err := repo.Subscribe(ctx, user.Id, community.Id)
+			test.NilErr(t, err)
+		}
+
+		_, err := communitiesRepo.DeleteCommunity(ctx, communities[2].Id)
+		test.NilErr(t, err)
+
+		subscribed, err := repo.GetSubscribedCommunities(ctx, user.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of communities not as expected", 2, len(subscribed))
+		test.AssertEqual(t, "Communities not sorted by name", "golang", subscribed[0].Name)
+		test.AssertEqual(t, "Communities not sorted by name", "rust", subscribed[1].Name)
+
+		ids, err := repo.GetSubscribedCommunityIds(ctx, user.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of community IDs not as expected", 2, len(ids))
+	})
+
+	t.Run("feed merges subscribed communities with cursors", func(t *testing.T) {
+		user, communities := setup(t, "golang", "rust", "zig")
+
+		for _, community := range communities[:2] {
+			_, err := repo.Subscribe(ctx, user.Id, community.Id)
+			test.NilErr(t, err)
+		}
+
+		createPost(t, communities[0].Id, user.Id, "Go 1")
+		createPost(t, communities[1].Id, user.Id, "Rust 1")
+		createPost(t, communities[2].Id, user.Id, "Zig 1")
+		createPost(t, communities[0].Id, user.Id, "Go 2")
+		deleted := createPost(t, communities[1].Id, user.Id, "Rust 2")
+		createPost(t, communities[1].Id, user.Id, "Rust 3")
+
+		_, err := postsRepo.DeletePost(ctx, deleted.Id)
+		test.NilErr(t, err)
+
+		page, err := postsRepo.GetFeedPosts(ctx, &user.Id, forum.FeedSortNew, forum.PostFilter{}, nil, 2)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "First page not as expected", []string{"Rust 3", "Go 2"}, titles(page))
+
+		cursor := forum.FeedSortNew.CursorOf(page[len(page)-1])
+		page, err = postsRepo.GetFeedPosts(ctx, &user.Id, forum.FeedSortNew, forum.PostFilter{}, &cursor, 2)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Second page not as expected", []string{"Rust 1", "Go 1"}, titles(page))
+
+		cursor = forum.FeedSortNew.CursorOf(page[len(page)-1])
+		page, err = postsRepo.GetFeedPosts(ctx, &user.Id, forum.FeedSortNew, forum.PostFilter{}, &cursor, 2)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no more posts", 0, len(page))
+	})
+
+	t.Run("feed sorts", func(t *testing.T) {
+		user, communities := setup(t, "golang")
+
+		first := createPost(t, communities[0].Id, user.Id, "First")
+		createPost(t, communities[0].Id, user.Id, "Second")
+
+		_, err := postsRepo.UpdatePostContent(ctx, first.Id, "Edited")
+		test.NilErr(t, err)
+
+		oldPage, err := postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortOld, forum.PostFilter{}, nil, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Old sort not as expected", []string{"First", "Second"}, titles(oldPage))
+
+		activePage, err := postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortActive, forum.PostFilter{}, nil, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Active sort not as expected", []string{"First", "Second"}, titles(activePage))
+
+		cursor := forum.FeedSortOld.CursorOf(oldPage[1])
+		page, err := postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortOld, forum.PostFilter{}, &cursor, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no posts after the newest", 0, len(page))
+	})
+
+	t.Run("all feed includes every community", func(t *testing.T) {
+		user, communities := setup(t, "golang", "rust")
+
+		createPost(t, communities[0].Id, user.Id, "Go")
+		createPost(t, communities[1].Id, user.Id, "Rust")
+
+		page, err := postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortNew, forum.PostFilter{}, nil, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "All feed not as expected", []string{"Rust", "Go"}, titles(page))
+	})
+}
@@ -0,0 +1,154 @@
+package forumdb
+
+import (
+	"context"
+	"strings"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TagsRepo implements the dbportsforum.TagsRepo interface.
+type TagsRepo struct {
+	postgres.BaseRepo
+}
+
+// NewTagsRepo creates a new TagsRepo.
+func NewTagsRepo(pool *pgxpool.Pool) TagsRepo {
+	return TagsRepo{
+		BaseRepo: postgres.NewBaseRepo(pool),
+	}
+}
+
+func (r TagsRepo) ReplacePostTags(ctx context.Context, postId forum.PostId, tags []string) (err error) {
+	// The statements are run separately rather than as one statement with
+	// data-modifying CTEs, so that the last one sees tags created
+	// concurrently by other posts.
+	stmts := []string{
+		"INSERT INTO forum_tags (name) SELECT UNNEST($2::text[]) ON CONFLICT (name) DO NOTHING",
+		`DELETE FROM forum_post_tags pt USING forum_tags t
+WHERE pt.tag_id = t.id AND pt.post_id = $1 AND NOT t.name = ANY($2::text[])`,
+		`INSERT INTO forum_post_tags (post_id, tag_id) SELECT $1, id FROM forum_tags WHERE name = ANY($2::text[])
+ON CONFLICT DO NOTHING`,
+	}
+	args := []any{postId, tags}
+
+	for _, stmt := range stmts {
+		_, err = r.Exec(ctx, stmt, args...)
+		if err != nil {
+			return postgres.TranslateError(err, "tag")
+		}
+	}
+
+	return nil
+}
+
+func (r TagsRepo) GetTagsByPosts(ctx context.Context, postIds []forum.PostId) (
+	tags map[forum.PostId][]string, err error,
+) {
+	const stmt = `SELECT pt.post_id, t.name FROM forum_post_tags pt JOIN forum_tags t ON t.id = pt.tag_id
+WHERE pt.post_id = ANY($1) ORDER BY t.name`
+	args := []any{postIds}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags = make(map[forum.PostId][]string, len(postIds))
+	for rows.Next() {
+		var (
+			postId forum.PostId
+			name   string
+		)
+		err = rows.Scan(&postId, &name)
+		if err != nil {
+			return nil, err
+		}
+		tags[postId] = append(tags[postId], name)
+	}
+
+	return tags, rows.Err()
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r TagsRepo) SearchTags(ctx context.Context, prefix string, limit int, opts ...dbports.ReadOption) (
+	tags []forum.Tag, err error,
+) {
+	const stmt = `SELECT t.name, COUNT(*) FROM forum_tags t
+JOIN forum_post_tags pt ON pt.tag_id = t.id
+JOIN forum_posts p ON p.id = pt.post_id
+WHERE t.name LIKE $1 AND p.deleted_at IS NULL AND p.removed_at IS NULL
+  AND forum_community_visible(p.community_id, $3, $4)
+GROUP BY t.name ORDER BY COUNT(*) DESC, t.name LIMIT $2`
+	options := dbports.NewReadOptions(opts...)
+	args := []any{likeEscaper.Replace(prefix) + "%", limit, options.ViewerId, options.IncludePrivate}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags = make([]forum.Tag, 0, limit)
+	for rows.Next() {
+		tag := forum.Tag{}
+		err = rows.Scan(&tag.Name, &tag.PostCount)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r TagsRepo) RenameTag(ctx context.Context, name string, newName string) (err error) {
+	const stmt = "UPDATE forum_tags SET name = $2 WHERE name = $1"
+	args := []any{name, newName}
+
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return postgres.TranslateError(err, "tag")
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{Entity: "tag"}
+	}
+
+	return nil
+}
+
+func (r TagsRepo) MergeTags(ctx context.Context, source string, target string) (err error) {
+	const selectStmt = "SELECT (SELECT id FROM forum_tags WHERE name = $1), (SELECT id FROM forum_tags WHERE name = $2)"
+	args := []any{source, target}
+
+	var sourceId, targetId *uuid.UUID
+	err = r.QueryRow(ctx, selectStmt, args...).Scan(&sourceId, &targetId)
+	if err != nil {
+		return err
+	} else if sourceId == nil || targetId == nil {
+		return shared.NotFoundError{Entity: "tag"}
+	}
+
+	const mergeStmt = `INSERT INTO forum_post_tags (post_id, tag_id) SELECT post_id, $2 FROM forum_post_tags WHERE tag_id = $1
+ON CONFLICT DO NOTHING`
+	args = []any{*sourceId, *targetId}
+
+	_, err = r.Exec(ctx, mergeStmt, args...)
+	if err != nil {
+		return err
+	}
+
+	const deleteStmt = "DELETE FROM forum_tags WHERE id = $1"
+	args = []any{*sourceId}
+
+	_, err = r.Exec(ctx, deleteStmt, args...)
+	return err
+}
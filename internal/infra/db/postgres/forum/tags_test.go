@@ -0,0 +1,170 @@
+package forumdb
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+)
+
+func TestTagsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	repo := NewTagsRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (community *forum.Community, first *forum.Post, second *forum.Post) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		first, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Generics",
+			Body:  "Type parameters",
+		})
+		test.NilErr(t, err)
+
+		second, err = postsRepo.CreatePost(ctx, community.Id, user.Id, forum.PostValue{
+			Title: "Iterators",
+			Body:  "Range over functions",
+		})
+		test.NilErr(t, err)
+
+		return community, first, second
+	}
+
+	t.Run("replacing tags keeps only the new tags", func(t *testing.T) {
+		_, first, second := setup(t)
+
+		err := repo.ReplacePostTags(ctx, first.Id, []string{"generics", "types"})
+		test.NilErr(t, err)
+		err = repo.ReplacePostTags(ctx, first.Id, []string{"types", "go1.18"})
+		test.NilErr(t, err)
+
+		tags, err := repo.GetTagsByPosts(ctx, []forum.PostId{first.Id, second.Id})
+		test.NilErr(t, err)
+		test.Assert(t, "Tags not as expected", slices.Equal([]string{"go1.18", "types"}, tags[first.Id]))
+		_, ok := tags[second.Id]
+		test.Assert(t, "Post without tags should be left out", !ok)
+
+		err = repo.ReplacePostTags(ctx, first.Id, []string{})
+		test.NilErr(t, err)
+
+		tags, err = repo.GetTagsByPosts(ctx, []forum.PostId{first.Id})
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Tags should be cleared", 0, len(tags))
+	})
+
+	t.Run("search returns the most used tags with the prefix", func(t *testing.T) {
+		_, first, second := setup(t)
+
+		err := repo.ReplacePostTags(ctx, first.Id, []string{"generics", "go_tips"})
+		test.NilErr(t, err)
+		err = repo.ReplacePostTags(ctx, second.Id, []string{"goroutines", "go_tips", "iterators"})
+		test.NilErr(t, err)
+
+		tags, err := repo.SearchTags(ctx, "go", 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of tags not as expected", 2, len(tags))
+		test.AssertEqual(t, "First tag not as expected", forum.Tag{Name: "go_tips", PostCount: 2}, tags[0])
+		test.AssertEqual(t, "Second tag not as expected", forum.Tag{Name: "goroutines", PostCount: 1}, tags[1])
+
+		tags, err = repo.SearchTags(ctx, "go_", 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Underscore should not be a wildcard", 1, len(tags))
+
+		_, err = postsRepo.DeletePost(ctx, second.Id)
+		test.NilErr(t, err)
+
+		tags, err = repo.SearchTags(ctx, "", 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Deleted posts should not be counted", 2, len(tags))
+	})
+
+	t.Run("community posts and feed are filtered by tag", func(t *testing.T) {
+		community, first, second := setup(t)
+
+		err := repo.ReplacePostTags(ctx, first.Id, []string{"generics"})
+		test.NilErr(t, err)
+		err = repo.ReplacePostTags(ctx, second.Id, []string{"iterators"})
+		test.NilErr(t, err)
+
+		posts, err := postsRepo.GetPostsByCommunitySortedCreatedAt(ctx, community.Id, forum.PostFilter{Tag: "generics"}, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of posts not as expected", 1, len(posts))
+		test.AssertEqual(t, "Post not as expected", first.Id, posts[0].Id)
+
+		posts, err = postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortNew, forum.PostFilter{Tag: "iterators"}, nil, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of feed posts not as expected", 1, len(posts))
+		test.AssertEqual(t, "Feed post not as expected", second.Id, posts[0].Id)
+	})
+
+	t.Run("renaming to an existing tag conflicts", func(t *testing.T) {
+		_, first, second := setup(t)
+
+		err := repo.ReplacePostTags(ctx, first.Id, []string{"golang"})
+		test.NilErr(t, err)
+		err = repo.ReplacePostTags(ctx, second.Id, []string{"go"})
+		test.NilErr(t, err)
+
+		err = repo.RenameTag(ctx, "golang", "go")
+		test.Assert(t, "Expected conflict error", errors.Is(err, shared.ErrConflict))
+
+		err = repo.RenameTag(ctx, "golang", "go-lang")
+		test.NilErr(t, err)
+
+		err = repo.RenameTag(ctx, "missing", "other")
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		tags, err := repo.GetTagsByPosts(ctx, []forum.PostId{first.Id})
+		test.NilErr(t, err)
+		test.Assert(t, "Tags not as expected", slices.Equal([]string{"go-lang"}, tags[first.Id]))
+	})
+
+	t.Run("merging moves posts to the target tag", func(t *testing.T) {
+		_, first, second := setup(t)
+
+		err := repo.ReplacePostTags(ctx, first.Id, []string{"golang", "go"})
+		test.NilErr(t, err)
+		err = repo.ReplacePostTags(ctx, second.Id, []string{"golang"})
+		test.NilErr(t, err)
+
+		err = repo.MergeTags(ctx, "golang", "go")
+		test.NilErr(t, err)
+
+		tags, err := repo.GetTagsByPosts(ctx, []forum.PostId{first.Id, second.Id})
+		test.NilErr(t, err)
+		test.Assert(t, "First post tags not as expected", slices.Equal([]string{"go"}, tags[first.Id]))
+		test.Assert(t, "Second post tags not as expected", slices.Equal([]string{"go"}, tags[second.Id]))
+
+		err = repo.MergeTags(ctx, "golang", "go")
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
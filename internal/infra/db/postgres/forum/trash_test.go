@@ -0,0 +1,203 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+
+	dbports "greddit/internal/ports/db"
+)
+
+func TestTrash(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	communitiesRepo := NewCommunitiesRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	setup := func(t *testing.T) (poster *auth.User, community *forum.Community) {
+		t.Helper()
+
+		postgres.ClearAllTables(t, pool)
+
+		poster, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "testuser",
+			DisplayName: "testuser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		community, err = communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+			Name:        "golang",
+			Description: "Go programming",
+		})
+		test.NilErr(t, err)
+
+		return poster, community
+	}
+
+	t.Run("restoring a community restores posts deleted with it", func(t *testing.T) {
+		poster, community := setup(t)
+
+		kept, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Kept Post",
+			Body:  "Kept",
+		})
+		test.NilErr(t, err)
+
+		removed, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Removed Post",
+			Body:  "Removed",
+		})
+		test.NilErr(t, err)
+
+		_, err = postsRepo.DeletePost(ctx, removed.Id)
+		test.NilErr(t, err)
+
+		live, err := communitiesRepo.GetCommunityById(ctx, community.Id)
+		test.NilErr(t, err)
+
+		_, err = communitiesRepo.DeleteCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+
+		post, err := postsRepo.GetPostById(ctx, kept.Id, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.Assert(t, "Expected post to be deleted with community", post.DeletedAt != nil)
+
+		communities, err := communitiesRepo.GetDeletedCommunities(ctx, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 deleted community", 1, len(communities))
+		test.Assert(t, "Expected deleted timestamp", communities[0].DeletedAt != nil)
+
+		posts, err := postsRepo.GetDeletedPosts(ctx, nil, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected only individually deleted post", 1, len(posts))
+		test.AssertEqual(t, "Unexpected deleted post", removed.Id, posts[0].Id)
+
+		updatedAt, err := communitiesRepo.RestoreCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected updated timestamp to be kept", updatedAt != nil && updatedAt.Equal(live.UpdatedAt))
+
+		restoredCommunity, err := communitiesRepo.GetCommunityById(ctx, community.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected community to be restored", restoredCommunity.DeletedAt == nil)
+
+		post, err = postsRepo.GetPostById(ctx, kept.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected post deleted with community to be restored", post.DeletedAt == nil)
+
+		post, err = postsRepo.GetPostById(ctx, removed.Id, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.Assert(t, "Expected individually deleted post to remain deleted", post.DeletedAt != nil)
+	})
+
+	t.Run("deleting a deleted community fails", func(t *testing.T) {
+		_, community := setup(t)
+
+		deletedAt, err := communitiesRepo.DeleteCommunity(ctx, community.Id)
+		test.NilErr(t, err)
+
+		_, err = communitiesRepo.DeleteCommunity(ctx, community.Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		deleted, err := communitiesRepo.GetCommunityById(ctx, community.Id, dbports.IncludeDeleted())
+		test.NilErr(t, err)
+		test.Assert(t, "Expected deleted timestamp to be kept", deleted.DeletedAt != nil && deleted.DeletedAt.Equal(*deletedAt))
+	})
+
+	t.Run("restoring a live community fails", func(t *testing.T) {
+		_, community := setup(t)
+
+		updatedAt, err := communitiesRepo.RestoreCommunity(ctx, community.Id)
+		test.Assert(t, "Expected error for live community", err != nil)
+		test.Assert(t, "Expected updated timestamp to be nil", updatedAt == nil)
+	})
+
+	t.Run("restore post", func(t *testing.T) {
+		poster, community := setup(t)
+
+		other, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    "otheruser",
+			DisplayName: "otheruser",
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Post",
+			Body:  "Body",
+		})
+		test.NilErr(t, err)
+
+		_, err = postsRepo.DeletePost(ctx, post.Id)
+		test.NilErr(t, err)
+
+		posts, err := postsRepo.GetDeletedPosts(ctx, &poster.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected deleted post of poster", 1, len(posts))
+
+		posts, err = postsRepo.GetDeletedPosts(ctx, &other.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected no deleted posts of other user", 0, len(posts))
+
+		_, err = postsRepo.DeletePost(ctx, post.Id)
+		test.Assert(t, "Expected not found error for deleted post", errors.Is(err, shared.ErrNotFound))
+
+		_, err = postsRepo.RestorePost(ctx, post.Id)
+		test.NilErr(t, err)
+
+		restored, err := postsRepo.GetPostById(ctx, post.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected post to be restored", restored.DeletedAt == nil)
+		test.Assert(t, "Expected updated timestamp to be kept", restored.UpdatedAt.Equal(post.UpdatedAt))
+
+		posts, err = postsRepo.GetDeletedPosts(ctx, nil, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected empty trash", 0, len(posts))
+	})
+
+	t.Run("restore comment", func(t *testing.T) {
+		poster, community := setup(t)
+
+		post, err := postsRepo.CreatePost(ctx, community.Id, poster.Id, forum.PostValue{
+			Title: "Post",
+			Body:  "Body",
+		})
+		test.NilErr(t, err)
+
+		comment, err := commentsRepo.CreateComment(ctx, post.Id, poster.Id, forum.CommentValue{
+			Body: "Comment",
+		}, nil)
+		test.NilErr(t, err)
+
+		_, err = commentsRepo.DeleteComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		comments, err := commentsRepo.GetDeletedComments(ctx, &poster.Id, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected deleted comment", 1, len(comments))
+
+		_, err = commentsRepo.RestoreComment(ctx, comment.Id)
+		test.NilErr(t, err)
+
+		restored, err := commentsRepo.GetCommentById(ctx, comment.Id)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected comment to be restored", restored.DeletedAt == nil)
+
+		comments, err = commentsRepo.GetDeletedComments(ctx, nil, 10, 0)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected empty trash", 0, len(comments))
+	})
+}
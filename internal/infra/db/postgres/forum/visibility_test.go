@@ -0,0 +1,243 @@
+package forumdb
+
+import (
+	"errors"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	dbports "greddit/internal/ports/db"
+)
+
+// TestVisibility checks that no read of the forum repositories leaks the
+// contents of a private community to users outside of it, including reads
+// given no viewer.
+func TestVisibility(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	communitiesRepo := NewCommunitiesRepo(pool)
+	postsRepo := NewPostsRepo(pool)
+	commentsRepo := NewCommentsRepo(pool)
+	subscriptionsRepo := NewSubscriptionsRepo(pool)
+	savedRepo := NewSavedItemsRepo(pool)
+	linksRepo := NewLinksRepo(pool)
+	membersRepo := NewMembersRepo(pool)
+	tagsRepo := NewTagsRepo(pool)
+	moderationRepo := NewModerationRepo(pool)
+	notificationsRepo := NewNotificationsRepo(pool)
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	postgres.ClearAllTables(t, pool)
+
+	users := map[string]*auth.User{}
+	for _, username := range []string{"owner", "member", "outsider"} {
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    username,
+			DisplayName: username,
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+		users[username] = user
+	}
+	owner, member, outsider := users["owner"], users["member"], users["outsider"]
+
+	public, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+		Name:        "golang",
+		Description: "Go programming",
+	})
+	test.NilErr(t, err)
+
+	private, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+		Name:        "secret",
+		Description: "Invited members only",
+	})
+	test.NilErr(t, err)
+
+	settings := forum.DefaultCommunitySettings()
+	settings.Visibility = forum.CommunityVisibilityPrivate
+	_, err = communitiesRepo.UpdateCommunitySettings(ctx, private.Id, settings)
+	test.NilErr(t, err)
+
+	_, err = moderationRepo.SetModerator(ctx, private.Id, owner.Id, forum.ModeratorRoleOwner)
+	test.NilErr(t, err)
+	_, err = membersRepo.AddMember(ctx, private.Id, member.Id)
+	test.NilErr(t, err)
+
+	publicPost, err := postsRepo.CreatePost(ctx, public.Id, outsider.Id, forum.PostValue{
+		Title: "Public Post",
+		Body:  "See [[secret/Secret Post]]",
+	})
+	test.NilErr(t, err)
+	err = linksRepo.ReplacePostLinks(ctx, publicPost.Id, forum.ParseWikiLinks(publicPost.Body))
+	test.NilErr(t, err)
+
+	privatePost, err := postsRepo.CreatePost(ctx, private.Id, owner.Id, forum.PostValue{
+		Title: "Secret Post",
+		Body:  "Body of Secret Post",
+	})
+	test.NilErr(t, err)
+
+	err = tagsRepo.ReplacePostTags(ctx, privatePost.Id, []string{"secret"})
+	test.NilErr(t, err)
+
+	privateComment, err := commentsRepo.CreateComment(ctx, privatePost.Id, owner.Id, forum.CommentValue{
+		Body: "Unlike [[golang/Public Post]]",
+	}, nil)
+	test.NilErr(t, err)
+	err = linksRepo.ReplaceCommentLinks(ctx, privatePost.Id, privateComment.Id, forum.ParseWikiLinks(privateComment.Body))
+	test.NilErr(t, err)
+
+	// The outsider kept a subscription and saved items from before the
+	// community was made private.
+	_, err = subscriptionsRepo.Subscribe(ctx, outsider.Id, private.Id)
+	test.NilErr(t, err)
+	_, err = savedRepo.SavePost(ctx, outsider.Id, privatePost.Id, forum.SavedItemValue{})
+	test.NilErr(t, err)
+	_, err = savedRepo.SaveComment(ctx, outsider.Id, privateComment.Id, forum.SavedItemValue{})
+	test.NilErr(t, err)
+
+	// Mentions notify members of the private community only.
+	mention := func(recipientId auth.UserId) (*forum.Notification, error) {
+		return notificationsRepo.Notify(ctx, forum.NotificationValue{
+			RecipientId: recipientId,
+			ActorId:     owner.Id,
+			Kind:        forum.NotificationKindMention,
+			PostId:      privatePost.Id,
+			CommentId:   privateComment.Id,
+		})
+	}
+	notification, err := mention(member.Id)
+	test.NilErr(t, err)
+	test.Assert(t, "Expected member to be notified", notification != nil)
+	notification, err = mention(outsider.Id)
+	test.NilErr(t, err)
+	test.Assert(t, "Expected outsider not to be notified", notification == nil)
+
+	// count returns the expected number of rows for the viewer, out of the
+	// number of rows when visible and the number of rows when hidden.
+	count := func(visible bool, ifVisible int, ifHidden int) int {
+		if visible {
+			return ifVisible
+		}
+		return ifHidden
+	}
+
+	data := []struct {
+		name    string
+		opts    []dbports.ReadOption
+		visible bool
+	}{
+		{name: "private included", opts: []dbports.ReadOption{dbports.IncludePrivate()}, visible: true},
+		{name: "owner", opts: []dbports.ReadOption{dbports.VisibleTo(&owner.Id)}, visible: true},
+		{name: "member", opts: []dbports.ReadOption{dbports.VisibleTo(&member.Id)}, visible: true},
+		{name: "outsider", opts: []dbports.ReadOption{dbports.VisibleTo(&outsider.Id)}, visible: false},
+		{name: "no viewer", opts: []dbports.ReadOption{dbports.VisibleTo(nil)}, visible: false},
+		{name: "no options", opts: nil, visible: false},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			opts := d.opts
+			expectFound := func(t *testing.T, entity string, err error) {
+				t.Helper()
+
+				if d.visible {
+					test.NilErr(t, err)
+				} else {
+					test.Assert(t, "Expected "+entity+" to not be found", errors.Is(err, shared.ErrNotFound))
+				}
+			}
+
+			_, err := communitiesRepo.GetCommunityById(ctx, private.Id, opts...)
+			expectFound(t, "community", err)
+
+			communities, err := communitiesRepo.GetAllCommunitiesSortedByName(ctx, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Communities by name not as expected", count(d.visible, 2, 1), len(communities))
+
+			communities, err = communitiesRepo.GetAllCommunitiesSortedByCreatedAt(ctx, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Communities by creation not as expected", count(d.visible, 2, 1), len(communities))
+
+			communities, err = communitiesRepo.GetAllCommunitiesSortedByUpdatedAt(ctx, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Communities by update not as expected", count(d.visible, 2, 1), len(communities))
+
+			_, err = postsRepo.GetPostById(ctx, privatePost.Id, opts...)
+			expectFound(t, "post", err)
+
+			posts, err := postsRepo.GetPostsByCommunitySortedCreatedAt(ctx, private.Id, forum.PostFilter{}, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Community posts not as expected", count(d.visible, 1, 0), len(posts))
+
+			posts, err = postsRepo.GetFeedPosts(ctx, nil, forum.FeedSortNew, forum.PostFilter{}, nil, 10, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Feed posts not as expected", count(d.visible, 2, 1), len(posts))
+
+			posts, err = postsRepo.GetFeedPosts(ctx, &outsider.Id, forum.FeedSortNew, forum.PostFilter{}, nil, 10, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Subscribed feed posts not as expected", count(d.visible, 1, 0), len(posts))
+
+			_, err = commentsRepo.GetCommentById(ctx, privateComment.Id, opts...)
+			expectFound(t, "comment", err)
+
+			comments, err := commentsRepo.GetCommentsByPostSortedCreatedAt(ctx, privatePost.Id, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Post comments not as expected", count(d.visible, 1, 0), len(comments))
+
+			comments, err = commentsRepo.GetCommentsByCommenterSortedCreatedAt(ctx, owner.Id, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Commenter comments not as expected", count(d.visible, 1, 0), len(comments))
+
+			ok, err := subscriptionsRepo.HasSubscriptions(ctx, outsider.Id, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Subscriptions presence not as expected", d.visible, ok)
+
+			communities, err = subscriptionsRepo.GetSubscribedCommunities(ctx, outsider.Id, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Subscribed communities not as expected", count(d.visible, 1, 0), len(communities))
+
+			communityIds, err := subscriptionsRepo.GetSubscribedCommunityIds(ctx, outsider.Id, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Subscribed community IDs not as expected", count(d.visible, 1, 0), len(communityIds))
+
+			items, err := savedRepo.GetSavedItems(ctx, outsider.Id, forum.SavedItemFilter{}, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Saved items not as expected", count(d.visible, 2, 0), len(items))
+
+			links, err := linksRepo.GetLinksByPosts(ctx, []forum.PostId{publicPost.Id}, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Number of links not as expected", 1, len(links))
+			test.AssertEqual(t, "Link target should only resolve if visible", d.visible, links[0].TargetPostId != nil)
+
+			backlinks, err := linksRepo.GetBacklinks(ctx, publicPost.Id, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Backlinks not as expected", count(d.visible, 1, 0), len(backlinks))
+
+			dangling, err := linksRepo.GetDanglingLinks(ctx, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Dangling links not as expected", count(d.visible, 0, 1), len(dangling))
+
+			notifications, err := notificationsRepo.GetNotifications(ctx, member.Id, false, 10, 0, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Notifications not as expected", count(d.visible, 1, 0), len(notifications))
+
+			unread, err := notificationsRepo.CountUnread(ctx, member.Id, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Unread notifications not as expected", count(d.visible, 1, 0), unread)
+
+			tags, err := tagsRepo.SearchTags(ctx, "secret", 10, opts...)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Tags not as expected", count(d.visible, 1, 0), len(tags))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"greddit/internal/domains/shared"
+
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobsRepo implements the dbports.JobsRepo interface.
+type JobsRepo struct {
+	BaseRepo
+}
+
+// NewJobsRepo creates a new JobsRepo.
+func NewJobsRepo(pool *pgxpool.Pool) JobsRepo {
+	return JobsRepo{
+		BaseRepo: NewBaseRepo(pool),
+	}
+}
+
+func (r JobsRepo) EnqueueJob(ctx context.Context, kind string, payload json.RawMessage, runAt time.Time) (
+	err error,
+) {
+	const stmt = "INSERT INTO jobs (kind, payload, run_at) VALUES ($1, $2, $3)"
+	args := []any{kind, payload, runAt}
+
+	_, err = r.Exec(ctx, stmt, args...)
+	return err
+}
+
+func (r JobsRepo) ClaimJobs(ctx context.Context, kinds []string, lockFor time.Duration, limit int) (
+	jobs []dbports.Job, err error,
+) {
+	const stmt = `UPDATE jobs SET locked_until = NOW() + make_interval(secs => $2), attempts = attempts + 1
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE kind = ANY($1) AND failed_at IS NULL AND run_at <= NOW()
+      AND (locked_until IS NULL OR locked_until < NOW())
+    ORDER BY run_at
+    LIMIT $3 FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, attempts`
+	args := []any{kinds, lockFor.Seconds(), limit}
+
+	rows, err := r.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs = []dbports.Job{}
+	for rows.Next() {
+		job := dbports.Job{}
+		err = rows.Scan(&job.Id, &job.Kind, &job.Payload, &job.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (r JobsRepo) CompleteJob(ctx context.Context, id uuid.UUID) (err error) {
+	const stmt = "DELETE FROM jobs WHERE id = $1"
+	args := []any{id}
+
+	return r.execJob(ctx, stmt, args)
+}
+
+func (r JobsRepo) RetryJob(ctx context.Context, id uuid.UUID, runAt time.Time, lastError string) (err error) {
+	const stmt = "UPDATE jobs SET run_at = $2, locked_until = NULL, last_error = $3 WHERE id = $1"
+	args := []any{id, runAt, lastError}
+
+	return r.execJob(ctx, stmt, args)
+}
+
+func (r JobsRepo) FailJob(ctx context.Context, id uuid.UUID, lastError string) (err error) {
+	const stmt = "UPDATE jobs SET failed_at = NOW(), locked_until = NULL, last_error = $2 WHERE id = $1"
+	args := []any{id, lastError}
+
+	return r.execJob(ctx, stmt, args)
+}
+
+// execJob executes a statement affecting a single job, returning a not found
+// error if the job does not exist.
+func (r JobsRepo) execJob(ctx context.Context, stmt string, args []any) (err error) {
+	tag, err := r.Exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	} else if tag.RowsAffected() == 0 {
+		return shared.NotFoundError{
+			Entity: "job",
+		}
+	}
+
+	return nil
+}
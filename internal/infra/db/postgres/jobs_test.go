@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestJobsRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := NewTestPool(t)
+	defer cleanup()
+
+	repo := NewJobsRepo(pool)
+	ctx := t.Context()
+
+	kinds := []string{"test"}
+	payload := json.RawMessage(`{"n": 1}`)
+
+	t.Run("claimed jobs are locked", func(t *testing.T) {
+		ClearAllTables(t, pool)
+
+		err := repo.EnqueueJob(ctx, "test", payload, time.Now())
+		test.NilErr(t, err)
+		err = repo.EnqueueJob(ctx, "other", payload, time.Now())
+		test.NilErr(t, err)
+
+		jobs, err := repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of jobs not as expected", 1, len(jobs))
+		test.AssertEqual(t, "Kind not as expected", "test", jobs[0].Kind)
+		test.AssertEqual(t, "Attempts not as expected", 1, jobs[0].Attempts)
+
+		var got map[string]int
+		test.NilErr(t, json.Unmarshal(jobs[0].Payload, &got))
+		test.AssertEqual(t, "Payload not as expected", 1, got["n"])
+
+		jobs, err = repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Locked job should not be claimed", 0, len(jobs))
+	})
+
+	t.Run("expired locks are claimed again", func(t *testing.T) {
+		ClearAllTables(t, pool)
+
+		err := repo.EnqueueJob(ctx, "test", payload, time.Now())
+		test.NilErr(t, err)
+
+		jobs, err := repo.ClaimJobs(ctx, kinds, -time.Second, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of jobs not as expected", 1, len(jobs))
+
+		jobs, err = repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of jobs not as expected", 1, len(jobs))
+		test.AssertEqual(t, "Attempts not as expected", 2, jobs[0].Attempts)
+	})
+
+	t.Run("retried jobs run later and failed jobs never", func(t *testing.T) {
+		ClearAllTables(t, pool)
+
+		err := repo.EnqueueJob(ctx, "test", payload, time.Now())
+		test.NilErr(t, err)
+		err = repo.EnqueueJob(ctx, "test", payload, time.Now().Add(time.Hour))
+		test.NilErr(t, err)
+
+		jobs, err := repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Only due jobs should be claimed", 1, len(jobs))
+		id := jobs[0].Id
+
+		err = repo.RetryJob(ctx, id, time.Now().Add(-time.Second), "transient")
+		test.NilErr(t, err)
+
+		jobs, err = repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Retried job should be claimed", 1, len(jobs))
+
+		err = repo.FailJob(ctx, id, "permanent")
+		test.NilErr(t, err)
+
+		_, err = pool.Exec(ctx, "UPDATE jobs SET locked_until = NULL")
+		test.NilErr(t, err)
+		jobs, err = repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Failed job should not be claimed", 0, len(jobs))
+	})
+
+	t.Run("completed jobs are deleted", func(t *testing.T) {
+		ClearAllTables(t, pool)
+
+		err := repo.EnqueueJob(ctx, "test", payload, time.Now())
+		test.NilErr(t, err)
+
+		jobs, err := repo.ClaimJobs(ctx, kinds, time.Minute, 10)
+		test.NilErr(t, err)
+
+		err = repo.CompleteJob(ctx, jobs[0].Id)
+		test.NilErr(t, err)
+
+		err = repo.CompleteJob(ctx, jobs[0].Id)
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+
+		err = repo.RetryJob(ctx, uuid.New(), time.Now(), "")
+		test.Assert(t, "Expected not found error", errors.Is(err, shared.ErrNotFound))
+	})
+}
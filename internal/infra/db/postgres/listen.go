@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Listen calls the handler with the payload of every notification on the
+// channel, holding a connection of the pool for as long as it listens. The
+// listening function is called once notifications are being received, before
+// any is handled. It blocks until the context is cancelled or the connection
+// fails, returning the error of the context in the former case.
+func (r *BaseRepo) Listen(ctx context.Context, channel string, listening func(), handle func(payload string)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection may still be listening, so it is taken out of the pool
+	// rather than released back into it.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+	listening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(notification.Payload)
+	}
+}
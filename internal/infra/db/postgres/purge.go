@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PurgeRepo implements the dbports.PurgeRepo interface.
+type PurgeRepo struct {
+	BaseRepo
+}
+
+// NewPurgeRepo creates a new PurgeRepo.
+func NewPurgeRepo(pool *pgxpool.Pool) PurgeRepo {
+	return PurgeRepo{
+		BaseRepo: NewBaseRepo(pool),
+	}
+}
+
+func (r PurgeRepo) PurgeComments(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	const stmt = `DELETE FROM forum_comments WHERE id IN (
+    SELECT c.id FROM forum_comments c
+    WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM forum_comments r WHERE r.parent_id = c.id)
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	return r.purge(ctx, stmt, before, limit)
+}
+
+func (r PurgeRepo) PurgePosts(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	const stmt = `DELETE FROM forum_posts WHERE id IN (
+    SELECT id FROM forum_posts WHERE deleted_at < $1
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	return r.purge(ctx, stmt, before, limit)
+}
+
+func (r PurgeRepo) PurgeCommunities(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	const stmt = `DELETE FROM forum_communities WHERE id IN (
+    SELECT c.id FROM forum_communities c
+    WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM forum_posts p WHERE p.community_id = c.id)
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	return r.purge(ctx, stmt, before, limit)
+}
+
+func (r PurgeRepo) PurgeUsers(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	tx, err := r.txs.ctxGetTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, "SELECT set_config('greddit.allow_purge', 'on', true)")
+	if err != nil {
+		return 0, err
+	}
+
+	const lockStmt = "SELECT id FROM auth_users WHERE deleted_at < $1 LIMIT $2 FOR UPDATE SKIP LOCKED"
+
+	rows, err := tx.Query(ctx, lockStmt, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	userIds, err := pgx.CollectRows(rows, pgx.RowTo[auth.UserId])
+	if err != nil || len(userIds) == 0 {
+		return 0, err
+	}
+
+	const placeholderStmt = `INSERT INTO auth_users (id, username, display_name, role) VALUES ($1, $2, $2, 'user')
+ON CONFLICT DO NOTHING`
+
+	_, err = tx.Exec(ctx, placeholderStmt, auth.DeletedUserId, auth.DeletedUsername)
+	if err != nil {
+		return 0, err
+	}
+
+	// The content of the users would otherwise be deleted along with them,
+	// taking the replies of other users with it.
+	reassignStmts := []string{
+		"UPDATE forum_posts SET poster_id = $2 WHERE poster_id = ANY($1)",
+		"UPDATE forum_comments SET commenter_id = $2 WHERE commenter_id = ANY($1)",
+		"UPDATE forum_post_revisions SET editor_id = $2 WHERE editor_id = ANY($1)",
+		"UPDATE forum_comment_revisions SET editor_id = $2 WHERE editor_id = ANY($1)",
+		"UPDATE forum_attachments SET uploader_id = $2 WHERE uploader_id = ANY($1)",
+	}
+	for _, stmt := range reassignStmts {
+		_, err = tx.Exec(ctx, stmt, userIds, auth.DeletedUserId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM auth_users WHERE id = ANY($1)", userIds)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r PurgeRepo) PurgeExpiredBans(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	const stmt = `DELETE FROM forum_bans WHERE (community_id, user_id) IN (
+    SELECT community_id, user_id FROM forum_bans WHERE expires_at < $1
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	return r.purge(ctx, stmt, before, limit)
+}
+
+func (r PurgeRepo) PurgeExpiredSuspensions(ctx context.Context, before time.Time, limit int) (
+	count int64, err error,
+) {
+	const stmt = `DELETE FROM forum_suspensions WHERE user_id IN (
+    SELECT user_id FROM forum_suspensions WHERE expires_at < $1
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+)`
+
+	return r.purge(ctx, stmt, before, limit)
+}
+
+func (r PurgeRepo) MarkUnusedBlobs(ctx context.Context, before time.Time, limit int) (count int64, err error) {
+	tx, err := r.txs.ctxGetTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Once the rows are locked, the statement sees the attachments committed
+	// while waiting for the locks.
+	const stmt = `UPDATE forum_blobs b SET status = 'purging' WHERE b.sha256 IN (
+    SELECT sha256 FROM forum_blobs
+    WHERE created_at < $1 AND status <> 'purging'
+      AND NOT EXISTS (SELECT 1 FROM forum_attachments a WHERE a.sha256 = forum_blobs.sha256)
+    LIMIT $2 FOR UPDATE SKIP LOCKED
+) AND NOT EXISTS (SELECT 1 FROM forum_attachments a WHERE a.sha256 = b.sha256)`
+
+	tag, err := tx.Exec(ctx, stmt, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r PurgeRepo) LockPurgingBlobs(ctx context.Context, limit int) (blobs []forum.Blob, err error) {
+	tx, err := r.txs.ctxGetTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const stmt = `SELECT sha256, content_type FROM forum_blobs WHERE status = 'purging'
+LIMIT $1 FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(ctx, stmt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blobs = make([]forum.Blob, 0, limit)
+	for rows.Next() {
+		blob := forum.Blob{
+			Status: forum.BlobStatusPurging,
+		}
+		err = rows.Scan(&blob.Sha256, &blob.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, blob)
+	}
+
+	return blobs, rows.Err()
+}
+
+func (r PurgeRepo) DeletePurgingBlobs(ctx context.Context, sha256s []string) (count int64, err error) {
+	tx, err := r.txs.ctxGetTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	const stmt = "DELETE FROM forum_blobs WHERE sha256 = ANY($1) AND status = 'purging'"
+
+	tag, err := tx.Exec(ctx, stmt, sha256s)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// purge runs the delete statement with the delete rules bypassed for the rest
+// of the transaction in the context.
+func (r PurgeRepo) purge(ctx context.Context, stmt string, before time.Time, limit int) (count int64, err error) {
+	tx, err := r.txs.ctxGetTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, "SELECT set_config('greddit.allow_purge', 'on', true)")
+	if err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, stmt, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
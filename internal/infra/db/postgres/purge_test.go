@@ -0,0 +1,330 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestPurgeRepo(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := NewTestPool(t)
+	defer cleanup()
+
+	repo := NewPurgeRepo(pool)
+	txs := NewTransactional(pool)
+	ctx := t.Context()
+
+	insert := func(t *testing.T, stmt string, args ...any) (id uuid.UUID) {
+		t.Helper()
+
+		err := pool.QueryRow(ctx, stmt, args...).Scan(&id)
+		test.NilErr(t, err)
+		return id
+	}
+
+	exists := func(t *testing.T, table string, id uuid.UUID) bool {
+		t.Helper()
+
+		var ok bool
+		err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = $1)", id).Scan(&ok)
+		test.NilErr(t, err)
+		return ok
+	}
+
+	inTx := func(t *testing.T, f func(ctx context.Context) (int64, error)) int64 {
+		t.Helper()
+
+		ctx, err := txs.CtxTx(ctx)
+		test.NilErr(t, err)
+		defer txs.TxRollback(ctx)
+
+		count, err := f(ctx)
+		test.NilErr(t, err)
+
+		err = txs.TxCommit(ctx)
+		test.NilErr(t, err)
+		return count
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	setup := func(t *testing.T) (userId uuid.UUID, communityId uuid.UUID, postId uuid.UUID) {
+		t.Helper()
+
+		ClearAllTables(t, pool)
+
+		userId = insert(t, "INSERT INTO auth_users (username, display_name, role) VALUES ('user', 'user', 'user') RETURNING id")
+		communityId = insert(t, "INSERT INTO forum_communities (name, description) VALUES ('golang', 'Go') RETURNING id")
+		postId = insert(t, "INSERT INTO forum_posts (title, body, poster_id, community_id) VALUES ('Post', 'Body', $1, $2) RETURNING id",
+			userId, communityId)
+		return userId, communityId, postId
+	}
+
+	t.Run("deletes remain disabled outside purges", func(t *testing.T) {
+		userId, _, _ := setup(t)
+
+		_, err := pool.Exec(ctx, "DELETE FROM auth_users WHERE id = $1", userId)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected user to remain", exists(t, "auth_users", userId))
+	})
+
+	t.Run("purges comments past retention without replies", func(t *testing.T) {
+		userId, _, postId := setup(t)
+
+		parentId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id, deleted_at) VALUES ('Parent', $1, $2, $3) RETURNING id",
+			userId, postId, old)
+		replyId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id, parent_id) VALUES ('Reply', $1, $2, $3) RETURNING id",
+			userId, postId, parentId)
+		oldId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id, deleted_at) VALUES ('Old', $1, $2, $3) RETURNING id",
+			userId, postId, old)
+		recentId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id, deleted_at) VALUES ('Recent', $1, $2, NOW()) RETURNING id",
+			userId, postId)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeComments(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 comment purged", int64(1), count)
+
+		test.Assert(t, "Expected old comment to be purged", !exists(t, "forum_comments", oldId))
+		test.Assert(t, "Expected recently deleted comment to remain", exists(t, "forum_comments", recentId))
+		test.Assert(t, "Expected comment with replies to remain", exists(t, "forum_comments", parentId))
+		test.Assert(t, "Expected reply to remain", exists(t, "forum_comments", replyId))
+	})
+
+	t.Run("purges posts with their comments and revisions", func(t *testing.T) {
+		userId, communityId, postId := setup(t)
+
+		commentId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id) VALUES ('Comment', $1, $2) RETURNING id",
+			userId, postId)
+		insert(t, "INSERT INTO forum_post_revisions (revision, title, body, post_id, editor_id) VALUES (1, 'Post', 'Body', $1, $2) RETURNING id",
+			postId, userId)
+
+		_, err := pool.Exec(ctx, "UPDATE forum_posts SET deleted_at = $1 WHERE id = $2", old, postId)
+		test.NilErr(t, err)
+		_, err = pool.Exec(ctx, "UPDATE forum_communities SET deleted_at = $1 WHERE id = $2", old, communityId)
+		test.NilErr(t, err)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgePosts(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 post purged", int64(1), count)
+		test.Assert(t, "Expected post to be purged", !exists(t, "forum_posts", postId))
+		test.Assert(t, "Expected comment to be purged with post", !exists(t, "forum_comments", commentId))
+
+		count = inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeCommunities(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 community purged", int64(1), count)
+		test.Assert(t, "Expected community to be purged", !exists(t, "forum_communities", communityId))
+	})
+
+	t.Run("reassigns content of purged users", func(t *testing.T) {
+		userId, _, postId := setup(t)
+
+		commentId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id) VALUES ('Comment', $1, $2) RETURNING id",
+			userId, postId)
+		revisionId := insert(t, "INSERT INTO forum_post_revisions (revision, title, body, post_id, editor_id) VALUES (1, 'Post', 'Body', $1, $2) RETURNING id",
+			postId, userId)
+		_, err := pool.Exec(ctx, "UPDATE auth_users SET deleted_at = $1 WHERE id = $2", old, userId)
+		test.NilErr(t, err)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeUsers(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 user purged", int64(1), count)
+		test.Assert(t, "Expected user to be purged", !exists(t, "auth_users", userId))
+		test.Assert(t, "Expected post to remain", exists(t, "forum_posts", postId))
+		test.Assert(t, "Expected comment to remain", exists(t, "forum_comments", commentId))
+
+		deletedId := uuid.UUID(auth.DeletedUserId)
+		var posterId, commenterId, editorId uuid.UUID
+		err = pool.QueryRow(ctx, "SELECT poster_id FROM forum_posts WHERE id = $1", postId).Scan(&posterId)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected post to be reassigned", deletedId, posterId)
+		err = pool.QueryRow(ctx, "SELECT commenter_id FROM forum_comments WHERE id = $1", commentId).Scan(&commenterId)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected comment to be reassigned", deletedId, commenterId)
+		err = pool.QueryRow(ctx, "SELECT editor_id FROM forum_post_revisions WHERE id = $1", revisionId).Scan(&editorId)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected revision to be reassigned", deletedId, editorId)
+
+		var username string
+		err = pool.QueryRow(ctx, "SELECT username FROM auth_users WHERE id = $1", deletedId).Scan(&username)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Username not as expected", auth.DeletedUsername, username)
+
+		count = inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeUsers(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected placeholder to be kept", int64(0), count)
+	})
+
+	t.Run("keeps moderation log of purged posts and moderators", func(t *testing.T) {
+		userId, communityId, postId := setup(t)
+
+		moderatorId := insert(t, "INSERT INTO auth_users (username, display_name, role, deleted_at) VALUES ('mod', 'mod', 'user', $1) RETURNING id",
+			old)
+		entryId := insert(t, `INSERT INTO forum_moderation_log (community_id, moderator_id, action, post_id, target_user_id, reason)
+VALUES ($1, $2, 'remove_post', $3, $4, 'Spam') RETURNING id`, communityId, moderatorId, postId, userId)
+
+		_, err := pool.Exec(ctx, "UPDATE forum_posts SET deleted_at = $1 WHERE id = $2", old, postId)
+		test.NilErr(t, err)
+
+		inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgePosts(ctx, cutoff, 10)
+		})
+		inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeUsers(ctx, cutoff, 10)
+		})
+		test.Assert(t, "Expected moderator to be purged", !exists(t, "auth_users", moderatorId))
+		test.Assert(t, "Expected log entry to remain", exists(t, "forum_moderation_log", entryId))
+
+		var (
+			entryModeratorId, entryPostId, entryTargetUserId *uuid.UUID
+			action                                           string
+		)
+		err = pool.QueryRow(ctx, "SELECT moderator_id, post_id, target_user_id, action FROM forum_moderation_log WHERE id = $1",
+			entryId).Scan(&entryModeratorId, &entryPostId, &entryTargetUserId, &action)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected moderator to be cleared", entryModeratorId == nil)
+		test.Assert(t, "Expected post to be cleared", entryPostId == nil)
+		test.Assert(t, "Expected author to be kept", entryTargetUserId != nil && *entryTargetUserId == userId)
+		test.AssertEqual(t, "Action not as expected", "remove_post", action)
+	})
+
+	t.Run("keeps reports of purged comments", func(t *testing.T) {
+		userId, _, postId := setup(t)
+
+		commentId := insert(t, "INSERT INTO forum_comments (body, commenter_id, post_id, deleted_at) VALUES ('Spam', $1, $2, $3) RETURNING id",
+			userId, postId, old)
+		reportId := insert(t, `INSERT INTO forum_reports (reporter_id, post_id, comment_id, target_type, reason, resolved_at, resolver_id, resolution)
+VALUES ($1, $2, $3, 'comment', 'spam', NOW(), $1, 'remove') RETURNING id`, userId, postId, commentId)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeComments(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 comment purged", int64(1), count)
+		test.Assert(t, "Expected report to remain", exists(t, "forum_reports", reportId))
+
+		var (
+			reportCommentId *uuid.UUID
+			targetType      string
+		)
+		err := pool.QueryRow(ctx, "SELECT comment_id, target_type FROM forum_reports WHERE id = $1", reportId).
+			Scan(&reportCommentId, &targetType)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected comment to be cleared", reportCommentId == nil)
+		test.AssertEqual(t, "Target type not as expected", "comment", targetType)
+	})
+
+	t.Run("purges expired bans and suspensions", func(t *testing.T) {
+		userId, communityId, _ := setup(t)
+
+		_, err := pool.Exec(ctx, `INSERT INTO forum_bans (community_id, user_id, moderator_id, reason, expires_at)
+VALUES ($1, $2, $2, 'Spam', $3)`, communityId, userId, old)
+		test.NilErr(t, err)
+		_, err = pool.Exec(ctx, `INSERT INTO forum_suspensions (user_id, admin_id, reason)
+VALUES ($1, $1, 'Spam')`, userId)
+		test.NilErr(t, err)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeExpiredBans(ctx, time.Now(), 10)
+		})
+		test.AssertEqual(t, "Expected 1 ban purged", int64(1), count)
+
+		count = inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeExpiredSuspensions(ctx, time.Now(), 10)
+		})
+		test.AssertEqual(t, "Expected permanent suspension to remain", int64(0), count)
+	})
+
+	t.Run("keeps bans and suspensions of purged issuers", func(t *testing.T) {
+		userId, communityId, _ := setup(t)
+
+		adminId := insert(t, "INSERT INTO auth_users (username, display_name, role, deleted_at) VALUES ('admin', 'admin', 'admin', $1) RETURNING id",
+			old)
+		_, err := pool.Exec(ctx, `INSERT INTO forum_bans (community_id, user_id, moderator_id, reason)
+VALUES ($1, $2, $3, 'Spam')`, communityId, userId, adminId)
+		test.NilErr(t, err)
+		_, err = pool.Exec(ctx, `INSERT INTO forum_suspensions (user_id, admin_id, reason)
+VALUES ($1, $2, 'Spam')`, userId, adminId)
+		test.NilErr(t, err)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.PurgeUsers(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 user purged", int64(1), count)
+		test.Assert(t, "Expected admin to be purged", !exists(t, "auth_users", adminId))
+
+		var moderatorId, suspensionAdminId *uuid.UUID
+		err = pool.QueryRow(ctx, "SELECT moderator_id FROM forum_bans WHERE user_id = $1", userId).Scan(&moderatorId)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected ban moderator to be cleared", moderatorId == nil)
+		err = pool.QueryRow(ctx, "SELECT admin_id FROM forum_suspensions WHERE user_id = $1", userId).
+			Scan(&suspensionAdminId)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected suspension admin to be cleared", suspensionAdminId == nil)
+	})
+
+	t.Run("purges blobs left without attachments", func(t *testing.T) {
+		userId, _, postId := setup(t)
+
+		insertBlob := func(sha256 string, createdAt time.Time) {
+			_, err := pool.Exec(ctx, `INSERT INTO forum_blobs (sha256, content_type, size, created_at)
+VALUES ($1, 'image/png', 1, $2)`, sha256, createdAt)
+			test.NilErr(t, err)
+		}
+		unused := strings.Repeat("a", 64)
+		used := strings.Repeat("b", 64)
+		recent := strings.Repeat("c", 64)
+		insertBlob(unused, old)
+		insertBlob(used, old)
+		insertBlob(recent, time.Now())
+		insert(t, `INSERT INTO forum_attachments (uploader_id, post_id, filename, sha256)
+VALUES ($1, $2, 'image.png', $3) RETURNING id`, userId, postId, used)
+
+		count := inTx(t, func(ctx context.Context) (int64, error) {
+			return repo.MarkUnusedBlobs(ctx, cutoff, 10)
+		})
+		test.AssertEqual(t, "Expected 1 blob marked", int64(1), count)
+
+		var status string
+		err := pool.QueryRow(ctx, "SELECT status FROM forum_blobs WHERE sha256 = $1", unused).Scan(&status)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Status not as expected", string(forum.BlobStatusPurging), status)
+
+		count = inTx(t, func(ctx context.Context) (count int64, err error) {
+			blobs, err := repo.LockPurgingBlobs(ctx, 10)
+			if err != nil {
+				return 0, err
+			}
+			test.AssertEqual(t, "Expected 1 purging blob", 1, len(blobs))
+			test.AssertEqual(t, "Purging blob not as expected", unused, blobs[0].Sha256)
+			test.AssertEqual(t, "Content type not as expected", "image/png", blobs[0].ContentType)
+			return repo.DeletePurgingBlobs(ctx, []string{blobs[0].Sha256})
+		})
+		test.AssertEqual(t, "Expected 1 blob purged", int64(1), count)
+
+		var remaining int
+		err = pool.QueryRow(ctx, "SELECT COUNT(*) FROM forum_blobs").Scan(&remaining)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected used and recent blobs to remain", 2, remaining)
+	})
+
+	t.Run("requires a transaction", func(t *testing.T) {
+		setup(t)
+
+		_, err := repo.PurgePosts(ctx, cutoff, 10)
+		test.Assert(t, "Expected error without transaction", err != nil)
+	})
+}
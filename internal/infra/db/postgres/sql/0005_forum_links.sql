@@ -0,0 +1,18 @@
+CREATE TABLE forum_links
+(
+    id                UUID PRIMARY KEY      DEFAULT gen_random_uuid(),
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    target_community  VARCHAR(255) NOT NULL DEFAULT '',
+    target_title      VARCHAR(255) NOT NULL,
+
+    source_post_id    UUID         NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    source_comment_id UUID REFERENCES forum_comments (id) ON DELETE CASCADE,
+    target_post_id    UUID REFERENCES forum_posts (id) ON DELETE SET NULL,
+
+    UNIQUE NULLS NOT DISTINCT (source_post_id, source_comment_id, target_community, target_title)
+);
+
+CREATE INDEX forum_links_target_post_id_idx ON forum_links (target_post_id);
+CREATE INDEX forum_links_target_title_idx ON forum_links (LOWER(target_title)) WHERE target_post_id IS NULL;
+CREATE INDEX forum_posts_title_idx ON forum_posts (LOWER(title));
@@ -0,0 +1,43 @@
+CREATE TABLE forum_post_revisions
+(
+    id         UUID PRIMARY KEY      DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    revision   INTEGER      NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL,
+
+    post_id    UUID         NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    editor_id  UUID         NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+
+    UNIQUE (post_id, revision)
+);
+
+CREATE RULE forum_post_revisions_disable_update AS ON UPDATE TO forum_post_revisions DO INSTEAD NOTHING;
+CREATE RULE forum_post_revisions_disable_delete AS ON DELETE TO forum_post_revisions DO INSTEAD NOTHING;
+
+CREATE TABLE forum_comment_revisions
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    revision   INTEGER     NOT NULL,
+    body       TEXT        NOT NULL,
+
+    comment_id UUID        NOT NULL REFERENCES forum_comments (id) ON DELETE CASCADE,
+    editor_id  UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+
+    UNIQUE (comment_id, revision)
+);
+
+CREATE RULE forum_comment_revisions_disable_update AS ON UPDATE TO forum_comment_revisions DO INSTEAD NOTHING;
+CREATE RULE forum_comment_revisions_disable_delete AS ON DELETE TO forum_comment_revisions DO INSTEAD NOTHING;
+
+-- Existing content starts with its current version as the first revision.
+INSERT INTO forum_post_revisions (created_at, revision, title, body, post_id, editor_id)
+SELECT updated_at, 1, title, body, id, poster_id
+FROM forum_posts;
+
+INSERT INTO forum_comment_revisions (created_at, revision, body, comment_id, editor_id)
+SELECT updated_at, 1, body, id, commenter_id
+FROM forum_comments;
@@ -0,0 +1,13 @@
+CREATE TABLE forum_post_redirects
+(
+    id           UUID PRIMARY KEY      DEFAULT gen_random_uuid(),
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    title        VARCHAR(255) NOT NULL,
+
+    community_id UUID         NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    post_id      UUID         NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX forum_post_redirects_title_idx ON forum_post_redirects (LOWER(title));
+CREATE INDEX forum_post_redirects_post_id_idx ON forum_post_redirects (post_id);
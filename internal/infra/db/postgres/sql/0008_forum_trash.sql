@@ -0,0 +1,7 @@
+-- Posts soft deleted along with their community are restored with it, unlike
+-- posts which were deleted individually.
+ALTER TABLE forum_posts
+    ADD COLUMN deleted_with_community BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX forum_posts_deleted_at_idx ON forum_posts (deleted_at DESC) WHERE deleted_at IS NOT NULL;
+CREATE INDEX forum_comments_deleted_at_idx ON forum_comments (deleted_at DESC) WHERE deleted_at IS NOT NULL;
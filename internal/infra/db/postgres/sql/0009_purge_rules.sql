@@ -0,0 +1,32 @@
+-- Deletes stay disabled unless the transaction has opted in to purging with
+-- SET LOCAL greddit.allow_purge = 'on'. The rules on tables reached through
+-- ON DELETE CASCADE must also let the cascaded deletes through.
+CREATE OR REPLACE RULE auth_users_disable_delete AS ON DELETE TO auth_users
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_communities_disable_delete AS ON DELETE TO forum_communities
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_posts_disable_delete AS ON DELETE TO forum_posts
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_comments_disable_delete AS ON DELETE TO forum_comments
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_post_revisions_disable_delete AS ON DELETE TO forum_post_revisions
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_comment_revisions_disable_delete AS ON DELETE TO forum_comment_revisions
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+-- Revisions stay immutable, except for their editors being reassigned to the
+-- deleted user placeholder when purged.
+CREATE OR REPLACE RULE forum_post_revisions_disable_update AS ON UPDATE TO forum_post_revisions
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE OR REPLACE RULE forum_comment_revisions_disable_update AS ON UPDATE TO forum_comment_revisions
+    WHERE current_setting('greddit.allow_purge', true) IS DISTINCT FROM 'on' DO INSTEAD NOTHING;
+
+CREATE INDEX forum_communities_deleted_at_idx ON forum_communities (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX auth_users_deleted_at_idx ON auth_users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX forum_comments_parent_id_idx ON forum_comments (parent_id);
@@ -0,0 +1,18 @@
+CREATE TABLE forum_subscriptions
+(
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, community_id)
+);
+
+CREATE INDEX forum_subscriptions_community_id_idx ON forum_subscriptions (community_id);
+
+-- Feeds read the newest posts of each subscribed community through these
+-- indexes, merging the per-community pages, while the "all" feed walks the
+-- global ones. Both are scanned backwards for the descending sorts.
+CREATE INDEX forum_posts_community_created_at_idx ON forum_posts (community_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX forum_posts_community_updated_at_idx ON forum_posts (community_id, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX forum_posts_created_at_idx ON forum_posts (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX forum_posts_updated_at_idx ON forum_posts (updated_at, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,31 @@
+CREATE TABLE forum_collections
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    user_id    UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    name       VARCHAR(64) NOT NULL,
+
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE forum_saved_items
+(
+    id            UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    user_id       UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    post_id       UUID REFERENCES forum_posts (id) ON DELETE CASCADE,
+    comment_id    UUID REFERENCES forum_comments (id) ON DELETE CASCADE,
+    collection_id UUID REFERENCES forum_collections (id) ON DELETE SET NULL,
+    note          TEXT        NOT NULL DEFAULT '',
+
+    CHECK ((post_id IS NULL) <> (comment_id IS NULL))
+);
+
+-- The unique indexes also serve the lookups of the saved flags of posts and
+-- comments.
+CREATE UNIQUE INDEX forum_saved_items_user_post_idx ON forum_saved_items (user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX forum_saved_items_user_comment_idx ON forum_saved_items (user_id, comment_id) WHERE comment_id IS NOT NULL;
+CREATE INDEX forum_saved_items_user_created_at_idx ON forum_saved_items (user_id, created_at DESC);
+CREATE INDEX forum_saved_items_collection_id_idx ON forum_saved_items (collection_id) WHERE collection_id IS NOT NULL;
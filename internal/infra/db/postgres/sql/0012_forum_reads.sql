@@ -0,0 +1,24 @@
+-- The last time each user saw each post. Marking a community as read records
+-- a single watermark instead of a row per post, and a post counts as seen at
+-- the later of the two.
+CREATE TABLE forum_post_reads
+(
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    post_id      UUID        NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE forum_community_reads
+(
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    read_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, community_id)
+);
+
+CREATE INDEX forum_post_reads_post_id_idx ON forum_post_reads (post_id);
+CREATE INDEX forum_community_reads_community_id_idx ON forum_community_reads (community_id);
+CREATE INDEX forum_comments_post_created_at_idx ON forum_comments (post_id, created_at) WHERE deleted_at IS NULL;
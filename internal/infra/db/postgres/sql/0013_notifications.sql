@@ -0,0 +1,27 @@
+CREATE TABLE notifications
+(
+    id           UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    recipient_id UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    actor_id     UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    kind         VARCHAR(16) NOT NULL CHECK (kind IN ('post_reply', 'comment_reply', 'mention')),
+    post_id      UUID        NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    comment_id   UUID        NOT NULL REFERENCES forum_comments (id) ON DELETE CASCADE,
+    read_at      TIMESTAMPTZ,
+
+    -- A comment notifies each user at most once, even if it both replies to
+    -- and mentions them.
+    UNIQUE (recipient_id, comment_id)
+);
+
+CREATE INDEX notifications_recipient_created_at_idx ON notifications (recipient_id, created_at DESC);
+CREATE INDEX notifications_recipient_unread_idx ON notifications (recipient_id) WHERE read_at IS NULL;
+CREATE INDEX notifications_comment_id_idx ON notifications (comment_id);
+
+-- Users without a row receive every kind of notification.
+CREATE TABLE notification_preferences
+(
+    user_id     UUID PRIMARY KEY REFERENCES auth_users (id) ON DELETE CASCADE,
+    muted_kinds TEXT[] NOT NULL DEFAULT '{}'
+);
@@ -0,0 +1,17 @@
+-- Events streamed to clients in real time. Each event is announced on the
+-- forum_events channel with its ID as the payload once its transaction
+-- commits, and kept for a while so that reconnecting clients can replay the
+-- events they missed.
+CREATE TABLE forum_events
+(
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    kind         VARCHAR(32) NOT NULL,
+    community_id UUID REFERENCES forum_communities (id) ON DELETE CASCADE,
+    post_id      UUID REFERENCES forum_posts (id) ON DELETE CASCADE,
+    recipient_id UUID REFERENCES auth_users (id) ON DELETE CASCADE,
+    data         JSONB       NOT NULL
+);
+
+CREATE INDEX forum_events_created_at_idx ON forum_events (created_at);
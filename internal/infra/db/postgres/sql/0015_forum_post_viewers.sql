@@ -0,0 +1,14 @@
+-- The live sessions viewing each post, refreshed by the server holding the
+-- session so that the sessions of crashed servers expire. Changes are
+-- announced on the forum_presence channel with the post ID as the payload.
+CREATE TABLE forum_post_viewers
+(
+    session_id UUID        NOT NULL,
+    post_id    UUID        NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    seen_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (session_id, post_id)
+);
+
+CREATE INDEX forum_post_viewers_post_id_idx ON forum_post_viewers (post_id, seen_at);
@@ -0,0 +1,44 @@
+CREATE TABLE forum_moderators
+(
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    role         VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'moderator')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (community_id, user_id)
+);
+
+-- A community has at most one owner.
+CREATE UNIQUE INDEX forum_moderators_owner_idx ON forum_moderators (community_id) WHERE role = 'owner';
+CREATE INDEX forum_moderators_user_id_idx ON forum_moderators (user_id);
+
+ALTER TABLE forum_posts
+    ADD COLUMN removed_at     TIMESTAMPTZ,
+    ADD COLUMN removal_reason TEXT,
+    ADD COLUMN locked_at      TIMESTAMPTZ,
+    ADD COLUMN pinned_at      TIMESTAMPTZ;
+
+ALTER TABLE forum_comments
+    ADD COLUMN removed_at     TIMESTAMPTZ,
+    ADD COLUMN removal_reason TEXT;
+
+CREATE INDEX forum_posts_community_pinned_at_idx ON forum_posts (community_id, pinned_at) WHERE pinned_at IS NOT NULL;
+
+-- Log entries outlive the moderators, posts, comments and users they refer
+-- to, so that purges do not erase the moderation history of a community. The
+-- action and the author of the moderated content are kept in the entry.
+CREATE TABLE forum_moderation_log
+(
+    id             UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    community_id   UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    moderator_id   UUID REFERENCES auth_users (id) ON DELETE SET NULL,
+    action         VARCHAR(32) NOT NULL,
+    post_id        UUID REFERENCES forum_posts (id) ON DELETE SET NULL,
+    comment_id     UUID REFERENCES forum_comments (id) ON DELETE SET NULL,
+    target_user_id UUID REFERENCES auth_users (id) ON DELETE SET NULL,
+    reason         TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX forum_moderation_log_community_created_at_idx ON forum_moderation_log (community_id, created_at DESC);
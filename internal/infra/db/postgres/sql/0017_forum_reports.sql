@@ -0,0 +1,41 @@
+-- Reports outlive the posts and comments they target, so that purges do not
+-- erase how they were resolved. The type of the target is kept in the report,
+-- so that reports of purged comments are not taken for reports of their post.
+CREATE TABLE forum_reports
+(
+    id          UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    reporter_id UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    post_id     UUID REFERENCES forum_posts (id) ON DELETE SET NULL,
+    comment_id  UUID REFERENCES forum_comments (id) ON DELETE SET NULL,
+    target_type VARCHAR(8)  NOT NULL CHECK (target_type IN ('post', 'comment')),
+    reason      VARCHAR(32) NOT NULL CHECK (reason IN
+                                            ('spam', 'harassment', 'hate', 'misinformation', 'off_topic', 'other')),
+    details     TEXT        NOT NULL DEFAULT '',
+
+    resolved_at TIMESTAMPTZ,
+    resolver_id UUID REFERENCES auth_users (id) ON DELETE SET NULL,
+    resolution  VARCHAR(16) CHECK (resolution IN ('dismiss', 'remove', 'ban'))
+);
+
+-- A user has at most one open report of an item. Open reports of purged
+-- targets are left out of the moderation queue.
+CREATE UNIQUE INDEX forum_reports_open_reporter_item_idx ON forum_reports (reporter_id, post_id, comment_id)
+    NULLS NOT DISTINCT WHERE resolved_at IS NULL AND post_id IS NOT NULL
+    AND (comment_id IS NOT NULL OR target_type = 'post');
+CREATE INDEX forum_reports_open_item_idx ON forum_reports (post_id, comment_id)
+    WHERE resolved_at IS NULL AND post_id IS NOT NULL AND (comment_id IS NOT NULL OR target_type = 'post');
+
+-- Bans outlive the moderators who issued them, so that purging a moderator
+-- does not lift them.
+CREATE TABLE forum_bans
+(
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    moderator_id UUID REFERENCES auth_users (id) ON DELETE SET NULL,
+    reason       TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (community_id, user_id)
+);
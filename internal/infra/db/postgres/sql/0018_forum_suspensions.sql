@@ -0,0 +1,19 @@
+-- Bans without an expiry are permanent. Expired bans are ignored on read
+-- until the sweeper deletes them.
+ALTER TABLE forum_bans
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX forum_bans_expires_at_idx ON forum_bans (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Suspensions outlive the admins who issued them, so that purging an admin
+-- does not lift them.
+CREATE TABLE forum_suspensions
+(
+    user_id    UUID PRIMARY KEY REFERENCES auth_users (id) ON DELETE CASCADE,
+    admin_id   UUID REFERENCES auth_users (id) ON DELETE SET NULL,
+    reason     TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX forum_suspensions_expires_at_idx ON forum_suspensions (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,13 @@
+-- The description was limited to 255 characters while the domain allows 2048.
+ALTER TABLE forum_communities
+    ALTER COLUMN description TYPE VARCHAR(2048);
+
+ALTER TABLE forum_communities
+    ADD COLUMN rules              JSONB         NOT NULL DEFAULT '[]',
+    ADD COLUMN allowed_post_types VARCHAR(16)[] NOT NULL DEFAULT '{text,link,image}',
+    ADD COLUMN require_flair      BOOLEAN       NOT NULL DEFAULT FALSE,
+    ADD COLUMN visibility         VARCHAR(16)   NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'restricted', 'private'));
+
+ALTER TABLE forum_posts
+    ADD COLUMN type VARCHAR(16) NOT NULL DEFAULT 'text' CHECK (type IN ('text', 'link', 'image'));
@@ -0,0 +1,40 @@
+CREATE TABLE forum_members
+(
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (community_id, user_id)
+);
+
+CREATE INDEX forum_members_user_id_idx ON forum_members (user_id);
+
+-- An invite is pending until the invitee accepts it, becoming a member, or
+-- declines it.
+CREATE TABLE forum_invites
+(
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    invitee_id   UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    inviter_id   UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (community_id, invitee_id)
+);
+
+CREATE INDEX forum_invites_invitee_id_idx ON forum_invites (invitee_id);
+
+-- forum_community_visible is the visibility filter shared by every read of
+-- communities and their content. Public and restricted communities are
+-- visible to everyone, while private ones are only visible to their members
+-- and moderators, or to every viewer if include_private is set. A NULL viewer
+-- only sees public and restricted communities.
+CREATE FUNCTION forum_community_visible(community UUID, viewer UUID, include_private BOOLEAN) RETURNS BOOLEAN
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT include_private
+    OR EXISTS (SELECT 1 FROM forum_communities c WHERE c.id = community AND c.visibility <> 'private')
+    OR EXISTS (SELECT 1 FROM forum_members m WHERE m.community_id = community AND m.user_id = viewer)
+    OR EXISTS (SELECT 1 FROM forum_moderators m WHERE m.community_id = community AND m.user_id = viewer)
+$$;
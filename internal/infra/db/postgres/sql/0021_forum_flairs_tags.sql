@@ -0,0 +1,39 @@
+CREATE TABLE forum_flairs
+(
+    id           UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    community_id UUID        NOT NULL REFERENCES forum_communities (id) ON DELETE CASCADE,
+    text         VARCHAR(32) NOT NULL,
+    color        VARCHAR(7)  NOT NULL,
+
+    UNIQUE (community_id, text)
+);
+
+ALTER TABLE forum_posts
+    ADD COLUMN flair_id UUID REFERENCES forum_flairs (id) ON DELETE SET NULL;
+
+CREATE INDEX forum_posts_flair_id_idx ON forum_posts (flair_id) WHERE flair_id IS NOT NULL;
+
+-- Tags are shared across communities and stored once, so that renaming or
+-- merging a tag updates every post carrying it.
+CREATE TABLE forum_tags
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    name       VARCHAR(32) NOT NULL UNIQUE
+);
+
+-- The pattern index serves the prefix lookups of tag autocomplete.
+CREATE INDEX forum_tags_name_pattern_idx ON forum_tags (name text_pattern_ops);
+
+CREATE TABLE forum_post_tags
+(
+    post_id UUID NOT NULL REFERENCES forum_posts (id) ON DELETE CASCADE,
+    tag_id  UUID NOT NULL REFERENCES forum_tags (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX forum_post_tags_tag_id_idx ON forum_post_tags (tag_id);
@@ -0,0 +1,20 @@
+ALTER TABLE forum_posts
+    ADD COLUMN url VARCHAR(2048) NOT NULL DEFAULT '';
+
+-- Previews are keyed by URL and shared by the posts linking to it, which
+-- makes the table the cache of fetched pages. A preview is fetched again once
+-- it is stale and was requested since it was last fetched.
+CREATE TABLE forum_link_previews
+(
+    url          VARCHAR(2048) PRIMARY KEY,
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    requested_at TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+
+    status       VARCHAR(16)   NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    title        VARCHAR(256)  NOT NULL DEFAULT '',
+    description  VARCHAR(1024) NOT NULL DEFAULT '',
+    image_url    VARCHAR(2048) NOT NULL DEFAULT '',
+    fetched_at   TIMESTAMPTZ
+);
+
+CREATE INDEX forum_link_previews_requested_at_idx ON forum_link_previews (requested_at);
@@ -0,0 +1,29 @@
+-- Blobs are keyed by the SHA-256 hash of their content, so that identical
+-- uploads are stored once. A row exists while its content is in the blob
+-- store, and is locked while attachments to it are created or removed.
+CREATE TABLE forum_blobs
+(
+    sha256       CHAR(64)     PRIMARY KEY,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    content_type VARCHAR(255) NOT NULL,
+    size         BIGINT       NOT NULL CHECK (size > 0)
+);
+
+CREATE TABLE forum_attachments
+(
+    id          UUID PRIMARY KEY      DEFAULT gen_random_uuid(),
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+
+    uploader_id UUID         NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    post_id     UUID REFERENCES forum_posts (id) ON DELETE CASCADE,
+    comment_id  UUID REFERENCES forum_comments (id) ON DELETE CASCADE,
+    filename    VARCHAR(255) NOT NULL,
+    sha256      CHAR(64)     NOT NULL REFERENCES forum_blobs (sha256),
+
+    CHECK ((post_id IS NULL) <> (comment_id IS NULL))
+);
+
+CREATE INDEX forum_attachments_post_id_idx ON forum_attachments (post_id, created_at) WHERE post_id IS NOT NULL;
+CREATE INDEX forum_attachments_comment_id_idx ON forum_attachments (comment_id, created_at) WHERE comment_id IS NOT NULL;
+CREATE INDEX forum_attachments_sha256_idx ON forum_attachments (sha256);
@@ -0,0 +1,31 @@
+-- Jobs are run in the background by workers claiming them. A claimed job is
+-- locked until it completes, fails or its lock expires, after which it can be
+-- claimed again. Jobs which failed for good are kept for inspection.
+CREATE TABLE jobs
+(
+    id           UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    kind         VARCHAR(64) NOT NULL,
+    payload      JSONB       NOT NULL,
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts     INT         NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    failed_at    TIMESTAMPTZ,
+    last_error   TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX jobs_kind_run_at_idx ON jobs (kind, run_at) WHERE failed_at IS NULL;
+
+-- Images are pending until their metadata is stripped and their thumbnail is
+-- generated. Unused blobs are purging until their content is deleted.
+ALTER TABLE forum_blobs
+    ADD COLUMN status                 VARCHAR(16) NOT NULL DEFAULT 'ready'
+        CHECK (status IN ('pending', 'ready', 'failed', 'purging')),
+    ADD COLUMN width                  INT         NOT NULL DEFAULT 0,
+    ADD COLUMN height                 INT         NOT NULL DEFAULT 0,
+    ADD COLUMN thumbnail_content_type VARCHAR(255),
+    ADD COLUMN thumbnail_width        INT,
+    ADD COLUMN thumbnail_height       INT,
+    ADD CHECK ((thumbnail_content_type IS NULL) = (thumbnail_width IS NULL)
+        AND (thumbnail_content_type IS NULL) = (thumbnail_height IS NULL));
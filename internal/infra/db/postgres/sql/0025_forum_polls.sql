@@ -0,0 +1,45 @@
+ALTER TABLE forum_posts
+    DROP CONSTRAINT forum_posts_type_check,
+    ADD CONSTRAINT forum_posts_type_check CHECK (type IN ('text', 'link', 'image', 'poll'));
+
+-- Communities allowing every post type keep doing so.
+ALTER TABLE forum_communities
+    ALTER COLUMN allowed_post_types SET DEFAULT '{text,link,image,poll}';
+
+UPDATE forum_communities
+SET allowed_post_types = array_append(allowed_post_types, 'poll')
+WHERE allowed_post_types @> '{text,link,image}';
+
+-- The vote counts are kept on the polls and their options, and incremented
+-- in the transaction recording each vote.
+CREATE TABLE forum_polls
+(
+    post_id         UUID PRIMARY KEY REFERENCES forum_posts (id) ON DELETE CASCADE,
+    multiple_choice BOOLEAN     NOT NULL DEFAULT FALSE,
+    closes_at       TIMESTAMPTZ,
+    voter_count     INT         NOT NULL DEFAULT 0
+);
+
+CREATE TABLE forum_poll_options
+(
+    id         UUID PRIMARY KEY      DEFAULT gen_random_uuid(),
+
+    post_id    UUID         NOT NULL REFERENCES forum_polls (post_id) ON DELETE CASCADE,
+    position   INT          NOT NULL,
+    text       VARCHAR(128) NOT NULL,
+    vote_count INT          NOT NULL DEFAULT 0,
+
+    UNIQUE (post_id, position)
+);
+
+-- A user votes once per poll, for one or more of its options.
+CREATE TABLE forum_poll_votes
+(
+    post_id    UUID        NOT NULL REFERENCES forum_polls (post_id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES auth_users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    option_ids UUID[]      NOT NULL,
+
+    PRIMARY KEY (post_id, user_id)
+);
@@ -21,6 +21,10 @@ var (
 	tables = []string{
 		"auth_users",
 		"forum_communities",
+		"forum_tags",
+		"forum_link_previews",
+		"forum_blobs",
+		"jobs",
 	}
 )
 
@@ -2,6 +2,7 @@ package httpapiauth
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -56,10 +57,7 @@ func (rtr AuthRouter) login(w http.ResponseWriter, r *http.Request) {
 
 	signed, err := rtr.ser.Login(r.Context(), reqBody.Username)
 	if err != nil {
-		rtr.logger.ErrorContext(r.Context(), "Error signing JWT token",
-			"error", err,
-		)
-		httputil.GenericInternalServerError(w, r)
+		rtr.respServiceError(w, r, err)
 		return
 	}
 
@@ -100,3 +98,21 @@ func (rtr AuthRouter) checkAuth(w http.ResponseWriter, r *http.Request) {
 		"message": "valid token",
 	})
 }
+
+// respServiceError writes the error response matching an error returned by
+// the auth service.
+func (rtr AuthRouter) respServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, servicesauth.InvalidCredentialsError) {
+		httputil.RespError(w, r, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if httputil.RespDomainError(w, r, err) {
+		return
+	}
+
+	rtr.logger.ErrorContext(r.Context(), "Error from auth service",
+		"error", err,
+	)
+	httputil.GenericInternalServerError(w, r)
+}
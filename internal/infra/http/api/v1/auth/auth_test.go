@@ -0,0 +1,66 @@
+package httpapiauth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	dbports "greddit/internal/ports/db"
+	dbportsauth "greddit/internal/ports/db/auth"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// usersRepo is a users repository failing to get users by username.
+type usersRepo struct {
+	dbportsauth.UsersRepo
+	err error
+}
+
+func (r usersRepo) GetUserByUsername(ctx context.Context, username string, opts ...dbports.ReadOption) (
+	user *auth.User, err error,
+) {
+	return nil, r.err
+}
+
+func TestAuthRouter_Login(t *testing.T) {
+	data := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "unknown users are unauthorized", err: shared.NotFoundError{Entity: "user"},
+			expectedStatus: http.StatusUnauthorized},
+		{name: "other errors are internal", err: errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.DiscardHandler)
+			rtr := AuthRouter{
+				logger: logger,
+				ser:    servicesauth.NewService(logger, nil, usersRepo{err: d.err}),
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"nobody"}`))
+			w := httptest.NewRecorder()
+
+			rtr.login(w, r)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			test.AssertEqual(t, "Unexpected status code", d.expectedStatus, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package httpapiforum
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// attachmentFormField is the name of the multipart form field holding the
+	// uploaded file.
+	attachmentFormField = "file"
+	// attachmentMaxBodySize is the maximum size of an upload request body,
+	// leaving room for the multipart headers around the file.
+	attachmentMaxBodySize = forum.AttachmentMaxSize + 64*1024
+)
+
+// uploadAttachment returns a handler attaching the file uploaded in the
+// multipart form field "file" to a post or a comment, with the given service
+// method.
+func (rtr ForumRouter) uploadAttachment(
+	upload func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID, filename string, r io.Reader) (
+		*servicesforum.AttachmentView, error,
+	),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, attachmentMaxBodySize)
+		reader, err := r.MultipartReader()
+		if err != nil {
+			httputil.RespError(w, r, http.StatusBadRequest, "expected a multipart form")
+			return
+		}
+
+		var part io.ReadCloser
+		var filename string
+		for {
+			p, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				httputil.RespError(w, r, http.StatusBadRequest, "missing file")
+				return
+			} else if err != nil {
+				respUploadError(w, r, err)
+				return
+			}
+
+			if p.FormName() == attachmentFormField {
+				part, filename = p, p.FileName()
+				break
+			}
+			p.Close()
+		}
+		defer part.Close()
+
+		attachment, err := upload(r.Context(), httpauth.GetClaims(r), id, filename, part)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respUploadError(w, r, err)
+				return
+			}
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusCreated, attachment)
+	}
+}
+
+// respUploadError writes the error response matching an error reading an
+// upload request body.
+func respUploadError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		httputil.RespError(w, r, http.StatusRequestEntityTooLarge, "file is too large")
+		return
+	}
+	httputil.RespError(w, r, http.StatusBadRequest, "invalid multipart form")
+}
+
+// getAttachment returns the metadata of an attachment.
+func (rtr ForumRouter) getAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	attachment, err := rtr.ser.GetAttachment(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, attachment)
+}
+
+// getAttachmentContent serves the content of an attachment, supporting range
+// and conditional requests. The content of an attachment never changes, so it
+// may be cached indefinitely, but only privately since the post or comment it
+// is attached to may not be public.
+func (rtr ForumRouter) getAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	attachment, content, err := rtr.ser.OpenAttachment(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if attachment.IsImage() {
+		disposition = "inline"
+	}
+
+	serveAttachment(w, r, content, attachment.ContentType, disposition, attachment.Filename, attachment.Sha256)
+}
+
+// getAttachmentThumbnail serves the thumbnail of an image attachment, cached
+// like its content.
+func (rtr ForumRouter) getAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	attachment, content, err := rtr.ser.OpenAttachmentThumbnail(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+	defer content.Close()
+
+	serveAttachment(w, r, content, attachment.Thumbnail.ContentType, "inline", attachment.Filename,
+		attachment.ThumbnailKey())
+}
+
+// serveAttachment serves content stored in the blob store under the key, which
+// identifies the content and thus is its ETag.
+func serveAttachment(w http.ResponseWriter, r *http.Request, content io.ReadSeeker, contentType string,
+	disposition string, filename string, key string,
+) {
+	header := w.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{
+		"filename": filename,
+	}))
+	header.Set("ETag", `"`+key+`"`)
+	header.Set("Cache-Control", "private, max-age=31536000, immutable")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "default-src 'none'; sandbox")
+
+	http.ServeContent(w, r, "", time.Time{}, content)
+}
+
+// deleteAttachment deletes an attachment.
+func (rtr ForumRouter) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.DeleteAttachment(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted": true,
+	})
+}
@@ -0,0 +1,181 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesforum "greddit/internal/services/forum"
+)
+
+// codeBanned is the code of the error returned to banned and suspended users.
+const codeBanned = "banned"
+
+// bannedProblem is the problem returned to banned and suspended users, with
+// the details of their ban or suspension.
+type bannedProblem struct {
+	httputil.Problem
+
+	Ban        *forum.Ban        `json:"ban,omitempty"`
+	Suspension *forum.Suspension `json:"suspension,omitempty"`
+}
+
+// respBanned writes the 403 response to a banned or suspended user.
+func respBanned(w http.ResponseWriter, r *http.Request, err servicesforum.BannedError) {
+	problem := bannedProblem{
+		Problem:    httputil.NewProblem(r, http.StatusForbidden, codeBanned, err.Error()),
+		Ban:        err.Ban,
+		Suspension: err.Suspension,
+	}
+	httputil.RespExtendedProblem(w, r, problem.Problem, problem)
+}
+
+// getBans returns the active bans of a community.
+func (rtr ForumRouter) getBans(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	bans, err := rtr.ser.GetBans(r.Context(), httpauth.GetClaims(r), communityId, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"bans": bans,
+	})
+}
+
+// banUser bans the user in the path from the community, with the reason and
+// optional expiry in the request body.
+func (rtr ForumRouter) banUser(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var value forum.BanValue
+	err = httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	ban, err := rtr.ser.BanUser(r.Context(), httpauth.GetClaims(r), communityId, userId, value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, ban)
+}
+
+// unbanUser lifts the ban of the user in the path from the community.
+func (rtr ForumRouter) unbanUser(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.UnbanUser(r.Context(), httpauth.GetClaims(r), communityId, userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": true,
+	})
+}
+
+// getSuspension returns the active suspension of the user in the path.
+func (rtr ForumRouter) getSuspension(w http.ResponseWriter, r *http.Request) {
+	userId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	suspension, err := rtr.ser.GetSuspension(r.Context(), httpauth.GetClaims(r), userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, suspension)
+}
+
+// suspendUser suspends the user in the path, with the reason and optional
+// expiry in the request body.
+func (rtr ForumRouter) suspendUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var value forum.BanValue
+	err = httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	suspension, err := rtr.ser.SuspendUser(r.Context(), httpauth.GetClaims(r), userId, value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, suspension)
+}
+
+// liftSuspension lifts the suspension of the user in the path.
+func (rtr ForumRouter) liftSuspension(w http.ResponseWriter, r *http.Request) {
+	userId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.LiftSuspension(r.Context(), httpauth.GetClaims(r), userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": true,
+	})
+}
@@ -0,0 +1,132 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getCommentsByPost returns the comments on a post.
+func (rtr ForumRouter) getCommentsByPost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	comments, err := rtr.ser.GetCommentsByPost(r.Context(), httpauth.GetClaims(r), id, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"comments": comments,
+	})
+}
+
+// createComment creates a comment on a post.
+func (rtr ForumRouter) createComment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var reqBody struct {
+		forum.CommentValue
+		ParentId *forum.CommentId `json:"parent_id"`
+	}
+	err = httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	comment, err := rtr.ser.CreateComment(r.Context(), httpauth.GetClaims(r), id, reqBody.CommentValue, reqBody.ParentId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusCreated, comment)
+}
+
+// getComment returns a comment by its ID.
+func (rtr ForumRouter) getComment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	comment, err := rtr.ser.GetComment(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, comment)
+}
+
+// updateComment updates the body of a comment.
+func (rtr ForumRouter) updateComment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var reqBody struct {
+		Body string `json:"body"`
+	}
+	err = httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.UpdateCommentBody(r.Context(), httpauth.GetClaims(r), id, reqBody.Body)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// deleteComment soft deletes a comment.
+func (rtr ForumRouter) deleteComment(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	deletedAt, err := rtr.ser.DeleteComment(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted_at": deletedAt,
+	})
+}
@@ -0,0 +1,146 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getCommunities returns the communities visible to the user sorted by name.
+func (rtr ForumRouter) getCommunities(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	communities, err := rtr.ser.GetCommunities(r.Context(), httpauth.GetClaims(r), limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"communities": communities,
+	})
+}
+
+// createCommunity creates a community.
+func (rtr ForumRouter) createCommunity(w http.ResponseWriter, r *http.Request) {
+	var value forum.CommunityValue
+	err := httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	community, err := rtr.ser.CreateCommunity(r.Context(), httpauth.GetClaims(r), value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusCreated, community)
+}
+
+// getCommunity returns a community by its ID.
+func (rtr ForumRouter) getCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	community, err := rtr.ser.GetCommunity(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, community)
+}
+
+// updateCommunity updates the description of a community.
+func (rtr ForumRouter) updateCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var reqBody struct {
+		Description string `json:"description"`
+	}
+	err = httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.UpdateCommunityDescription(r.Context(), httpauth.GetClaims(r), id, reqBody.Description)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// updateCommunitySettings replaces the settings of a community.
+func (rtr ForumRouter) updateCommunitySettings(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var settings forum.CommunitySettings
+	err = httputil.ReadJson(r, &settings)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.UpdateCommunitySettings(r.Context(), httpauth.GetClaims(r), id, settings)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// deleteCommunity soft deletes a community.
+func (rtr ForumRouter) deleteCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	deletedAt, err := rtr.ser.DeleteCommunity(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted_at": deletedAt,
+	})
+}
@@ -0,0 +1,94 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getFeed returns a page of the home feed of the user, optionally filtered by
+// tag or flair.
+func (rtr ForumRouter) getFeed(w http.ResponseWriter, r *http.Request) {
+	limit, cursor, err := httputil.CursorPagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	sort := forum.FeedSort(r.URL.Query().Get("sort"))
+	if sort == "" {
+		sort = forum.FeedSortNew
+	}
+
+	filter, err := postFilter(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	page, err := rtr.ser.GetFeed(r.Context(), httpauth.GetClaims(r), sort, filter, cursor, limit)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, page)
+}
+
+// getSubscriptions returns the communities the user is subscribed to.
+func (rtr ForumRouter) getSubscriptions(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	communities, err := rtr.ser.GetSubscriptions(r.Context(), httpauth.GetClaims(r), limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"communities": communities,
+	})
+}
+
+// subscribe subscribes the user to a community.
+func (rtr ForumRouter) subscribe(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	subscription, err := rtr.ser.Subscribe(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, subscription)
+}
+
+// unsubscribe unsubscribes the user from a community.
+func (rtr ForumRouter) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	removed, err := rtr.ser.Unsubscribe(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": removed,
+	})
+}
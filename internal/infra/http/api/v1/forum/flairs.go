@@ -0,0 +1,141 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getFlairs returns the flairs of a community.
+func (rtr ForumRouter) getFlairs(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	flairs, err := rtr.ser.GetFlairs(r.Context(), httpauth.GetClaims(r), communityId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"flairs": flairs,
+	})
+}
+
+// createFlair creates a flair in a community.
+func (rtr ForumRouter) createFlair(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var value forum.FlairValue
+	err = httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	flair, err := rtr.ser.CreateFlair(r.Context(), httpauth.GetClaims(r), communityId, value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusCreated, flair)
+}
+
+// updateFlair replaces the text and color of a flair of a community.
+func (rtr ForumRouter) updateFlair(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	flairId, err := httputil.PathUuid(r, "flairId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var value forum.FlairValue
+	err = httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	flair, err := rtr.ser.UpdateFlair(r.Context(), httpauth.GetClaims(r), communityId, flairId, value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, flair)
+}
+
+// deleteFlair deletes a flair of a community.
+func (rtr ForumRouter) deleteFlair(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	flairId, err := httputil.PathUuid(r, "flairId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.DeleteFlair(r.Context(), httpauth.GetClaims(r), communityId, flairId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted": true,
+	})
+}
+
+// updatePostLabels replaces the flair and tags of a post.
+func (rtr ForumRouter) updatePostLabels(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var labels forum.PostLabels
+	err = httputil.ReadJson(r, &labels)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updated, err := rtr.ser.UpdatePostLabels(r.Context(), httpauth.GetClaims(r), id, labels)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, updated)
+}
@@ -0,0 +1,393 @@
+package httpapiforum
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+
+	"greddit/internal/infra/http/routing"
+	servicesforum "greddit/internal/services/forum"
+)
+
+// ForumRouter is a router for the forum endpoints.
+type ForumRouter struct {
+	logger *slog.Logger
+	ser    servicesforum.Service
+}
+
+// ForumRoutes returns the routes for the forum endpoints. All routes require
+// a valid token.
+func ForumRoutes(p routing.RouterParams) http.Handler {
+	mux := http.NewServeMux()
+
+	rtr := ForumRouter{
+		logger: p.Logger,
+		ser:    *p.ForumSer,
+	}
+
+	mux.HandleFunc("/communities", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:  rtr.getCommunities,
+		http.MethodPost: rtr.createCommunity,
+	}))
+
+	mux.HandleFunc("/communities/{id}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:    rtr.getCommunity,
+		http.MethodPatch:  rtr.updateCommunity,
+		http.MethodDelete: rtr.deleteCommunity,
+	}))
+
+	mux.HandleFunc("/communities/{id}/settings", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut: rtr.updateCommunitySettings,
+	}))
+
+	mux.HandleFunc("/communities/{id}/flairs", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:  rtr.getFlairs,
+		http.MethodPost: rtr.createFlair,
+	}))
+
+	mux.HandleFunc("/communities/{id}/flairs/{flairId}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.updateFlair,
+		http.MethodDelete: rtr.deleteFlair,
+	}))
+
+	mux.HandleFunc("/communities/{id}/posts", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:  rtr.getPostsByCommunity,
+		http.MethodPost: rtr.createPost,
+	}))
+
+	mux.HandleFunc("/communities/{id}/subscription", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.subscribe,
+		http.MethodDelete: rtr.unsubscribe,
+	}))
+
+	mux.HandleFunc("/communities/{id}/read", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.markRead(rtr.ser.MarkCommunityRead),
+	}))
+
+	mux.HandleFunc("/subscriptions", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getSubscriptions,
+	}))
+
+	mux.HandleFunc("/feed", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getFeed,
+	}))
+
+	mux.HandleFunc("/posts/{id}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:    rtr.getPost,
+		http.MethodPatch:  rtr.updatePost,
+		http.MethodDelete: rtr.deletePost,
+	}))
+
+	mux.HandleFunc("/posts/{id}/move", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.movePost,
+	}))
+
+	mux.HandleFunc("/posts/{id}/labels", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut: rtr.updatePostLabels,
+	}))
+
+	mux.HandleFunc("/posts/{id}/read", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.markRead(rtr.ser.MarkPostRead),
+	}))
+
+	mux.HandleFunc("/posts/{id}/comments", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:  rtr.getCommentsByPost,
+		http.MethodPost: rtr.createComment,
+	}))
+
+	mux.HandleFunc("/posts/{id}/links", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getPostLinks,
+	}))
+
+	mux.HandleFunc("/posts/{id}/revisions", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getPostRevisions,
+	}))
+
+	mux.HandleFunc("/posts/{id}/revisions/diff", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.diffPostRevisions,
+	}))
+
+	mux.HandleFunc("/posts/{id}/revisions/{revision}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getPostRevision,
+	}))
+
+	mux.HandleFunc("/posts/{id}/revisions/{revision}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restorePostRevision,
+	}))
+
+	mux.HandleFunc("/tags", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.searchTags,
+	}))
+
+	mux.HandleFunc("/tags/{name}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPatch: rtr.renameTag,
+	}))
+
+	mux.HandleFunc("/tags/{name}/merge", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.mergeTag,
+	}))
+
+	mux.HandleFunc("/links/dangling", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getDanglingLinks,
+	}))
+
+	mux.HandleFunc("/comments/{id}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:    rtr.getComment,
+		http.MethodPatch:  rtr.updateComment,
+		http.MethodDelete: rtr.deleteComment,
+	}))
+
+	mux.HandleFunc("/comments/{id}/revisions", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getCommentRevisions,
+	}))
+
+	mux.HandleFunc("/comments/{id}/revisions/diff", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.diffCommentRevisions,
+	}))
+
+	mux.HandleFunc("/comments/{id}/revisions/{revision}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getCommentRevision,
+	}))
+
+	mux.HandleFunc("/comments/{id}/revisions/{revision}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restoreCommentRevision,
+	}))
+
+	mux.HandleFunc("/posts/{id}/poll", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getPoll,
+	}))
+
+	mux.HandleFunc("/posts/{id}/poll/votes", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.votePoll,
+	}))
+
+	mux.HandleFunc("/posts/{id}/attachments", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.uploadAttachment(rtr.ser.UploadPostAttachment),
+	}))
+
+	mux.HandleFunc("/comments/{id}/attachments", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.uploadAttachment(rtr.ser.UploadCommentAttachment),
+	}))
+
+	mux.HandleFunc("/attachments/{id}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:    rtr.getAttachment,
+		http.MethodDelete: rtr.deleteAttachment,
+	}))
+
+	mux.HandleFunc("/attachments/{id}/content", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getAttachmentContent,
+	}))
+
+	mux.HandleFunc("/attachments/{id}/thumbnail", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getAttachmentThumbnail,
+	}))
+
+	mux.HandleFunc("/posts/{id}/save", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.save(rtr.ser.SavePost),
+		http.MethodDelete: rtr.unsave(rtr.ser.UnsavePost),
+	}))
+
+	mux.HandleFunc("/comments/{id}/save", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.save(rtr.ser.SaveComment),
+		http.MethodDelete: rtr.unsave(rtr.ser.UnsaveComment),
+	}))
+
+	mux.HandleFunc("/saved", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getSavedItems,
+	}))
+
+	mux.HandleFunc("/collections", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:  rtr.getCollections,
+		http.MethodPost: rtr.createCollection,
+	}))
+
+	mux.HandleFunc("/collections/{id}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodDelete: rtr.deleteCollection,
+	}))
+
+	mux.HandleFunc("/notifications", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getNotifications,
+	}))
+
+	mux.HandleFunc("/notifications/read", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.markAllNotificationsRead,
+	}))
+
+	mux.HandleFunc("/notifications/preferences", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getNotificationPreferences,
+		http.MethodPut: rtr.updateNotificationPreferences,
+	}))
+
+	mux.HandleFunc("/notifications/{id}/read", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.markRead(rtr.ser.MarkNotificationRead),
+	}))
+
+	mux.HandleFunc("/trash/communities", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: getTrash(rtr, "communities", rtr.ser.GetDeletedCommunities),
+	}))
+
+	mux.HandleFunc("/trash/posts", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: getTrash(rtr, "posts", rtr.ser.GetDeletedPosts),
+	}))
+
+	mux.HandleFunc("/trash/comments", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: getTrash(rtr, "comments", rtr.ser.GetDeletedComments),
+	}))
+
+	mux.HandleFunc("/trash/users", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: getTrash(rtr, "users", rtr.ser.GetDeletedUsers),
+	}))
+
+	mux.HandleFunc("/communities/{id}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restore(rtr.ser.RestoreCommunity),
+	}))
+
+	mux.HandleFunc("/posts/{id}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restore(rtr.ser.RestorePost),
+	}))
+
+	mux.HandleFunc("/comments/{id}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restore(rtr.ser.RestoreComment),
+	}))
+
+	mux.HandleFunc("/users/{id}/restore", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.restore(rtr.ser.RestoreUser),
+	}))
+
+	mux.HandleFunc("/communities/{id}/moderators", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getModerators,
+	}))
+
+	mux.HandleFunc("/communities/{id}/moderators/{userId}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.setModerator,
+		http.MethodDelete: rtr.removeModerator,
+	}))
+
+	mux.HandleFunc("/communities/{id}/modlog", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getModerationLog,
+	}))
+
+	mux.HandleFunc("/posts/{id}/remove", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.moderate(rtr.ser.RemovePost),
+	}))
+
+	mux.HandleFunc("/posts/{id}/approve", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.moderate(rtr.ser.ApprovePost),
+	}))
+
+	mux.HandleFunc("/posts/{id}/lock", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.moderate(rtr.ser.LockPost),
+		http.MethodDelete: rtr.moderate(rtr.ser.UnlockPost),
+	}))
+
+	mux.HandleFunc("/posts/{id}/pin", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.moderate(rtr.ser.PinPost),
+		http.MethodDelete: rtr.moderate(rtr.ser.UnpinPost),
+	}))
+
+	mux.HandleFunc("/comments/{id}/remove", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.moderate(rtr.ser.RemoveComment),
+	}))
+
+	mux.HandleFunc("/comments/{id}/approve", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.moderate(rtr.ser.ApproveComment),
+	}))
+
+	mux.HandleFunc("/communities/{id}/reports", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getReportQueue,
+	}))
+
+	mux.HandleFunc("/posts/{id}/report", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.report(rtr.ser.ReportPost),
+	}))
+
+	mux.HandleFunc("/comments/{id}/report", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.report(rtr.ser.ReportComment),
+	}))
+
+	mux.HandleFunc("/posts/{id}/reports/resolve", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.resolveReports(rtr.ser.ResolvePostReports),
+	}))
+
+	mux.HandleFunc("/comments/{id}/reports/resolve", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPost: rtr.resolveReports(rtr.ser.ResolveCommentReports),
+	}))
+
+	mux.HandleFunc("/communities/{id}/bans", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getBans,
+	}))
+
+	mux.HandleFunc("/communities/{id}/bans/{userId}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.banUser,
+		http.MethodDelete: rtr.unbanUser,
+	}))
+
+	mux.HandleFunc("/communities/{id}/members", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getMembers,
+	}))
+
+	mux.HandleFunc("/communities/{id}/members/{userId}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodDelete: rtr.removeMember,
+	}))
+
+	mux.HandleFunc("/communities/{id}/membership", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.joinCommunity,
+		http.MethodDelete: rtr.leaveCommunity,
+	}))
+
+	mux.HandleFunc("/communities/{id}/invites", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getCommunityInvites,
+	}))
+
+	mux.HandleFunc("/communities/{id}/invites/{userId}", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodPut:    rtr.inviteMember,
+		http.MethodDelete: rtr.deleteInvite,
+	}))
+
+	mux.HandleFunc("/invites", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.getInvites,
+	}))
+
+	mux.HandleFunc("/users/{id}/suspension", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet:    rtr.getSuspension,
+		http.MethodPut:    rtr.suspendUser,
+		http.MethodDelete: rtr.liftSuspension,
+	}))
+
+	return httpauth.AuthMiddleware(mux, *p.AuthSer)
+}
+
+// respServiceError writes the error response matching an error returned by
+// the forum service.
+func (rtr ForumRouter) respServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if httputil.RespDomainError(w, r, err) {
+		return
+	}
+
+	var bannedErr servicesforum.BannedError
+	switch {
+	case errors.As(err, &bannedErr):
+		respBanned(w, r, bannedErr)
+	case errors.Is(err, servicesforum.NotMemberError):
+		httputil.RespError(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, servicesforum.ForbiddenError):
+		httputil.GenericForbidden(w, r)
+	case errors.Is(err, servicesforum.DeletedTargetError):
+		httputil.RespErrorCode(w, r, http.StatusNotFound, httputil.CodeNotFound, err.Error())
+	case errors.Is(err, servicesforum.LockedTargetError):
+		httputil.RespError(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, servicesforum.ClosedPollError):
+		httputil.RespError(w, r, http.StatusForbidden, err.Error())
+	case errors.Is(err, servicesforum.PendingAttachmentError):
+		w.Header().Set("Retry-After", "5")
+		httputil.RespError(w, r, http.StatusServiceUnavailable, err.Error())
+	default:
+		rtr.logger.ErrorContext(r.Context(), "Error from forum service",
+			"error", err,
+		)
+		httputil.GenericInternalServerError(w, r)
+	}
+}
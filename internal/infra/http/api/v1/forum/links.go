@@ -0,0 +1,44 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getPostLinks returns the backlinks to a post and the links from its body.
+func (rtr ForumRouter) getPostLinks(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	links, err := rtr.ser.GetPostLinks(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, links)
+}
+
+// getDanglingLinks returns the report of links which do not resolve to a post.
+func (rtr ForumRouter) getDanglingLinks(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	links, err := rtr.ser.GetDanglingLinks(r.Context(), httpauth.GetClaims(r), limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"links": links,
+	})
+}
@@ -0,0 +1,189 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getMembers returns the members of a community.
+func (rtr ForumRouter) getMembers(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	members, err := rtr.ser.GetMembers(r.Context(), httpauth.GetClaims(r), communityId, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"members": members,
+	})
+}
+
+// removeMember removes the user in the path from the members of the
+// community.
+func (rtr ForumRouter) removeMember(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.RemoveMember(r.Context(), httpauth.GetClaims(r), communityId, userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": true,
+	})
+}
+
+// joinCommunity makes the user a member of a community, accepting their
+// invite to it.
+func (rtr ForumRouter) joinCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	member, err := rtr.ser.JoinCommunity(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, member)
+}
+
+// leaveCommunity removes the user from the members of a community.
+func (rtr ForumRouter) leaveCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	removed, err := rtr.ser.LeaveCommunity(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": removed,
+	})
+}
+
+// getCommunityInvites returns the pending invites to a community.
+func (rtr ForumRouter) getCommunityInvites(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	invites, err := rtr.ser.GetCommunityInvites(r.Context(), httpauth.GetClaims(r), communityId, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"invites": invites,
+	})
+}
+
+// getInvites returns the pending invites of the user.
+func (rtr ForumRouter) getInvites(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	invites, err := rtr.ser.GetInvites(r.Context(), httpauth.GetClaims(r), limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"invites": invites,
+	})
+}
+
+// inviteMember invites the user in the path to the community.
+func (rtr ForumRouter) inviteMember(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	invite, err := rtr.ser.InviteMember(r.Context(), httpauth.GetClaims(r), communityId, userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, invite)
+}
+
+// deleteInvite revokes the invite of the user in the path to the community,
+// or declines it if the user in the path is the requesting user.
+func (rtr ForumRouter) deleteInvite(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.DeleteInvite(r.Context(), httpauth.GetClaims(r), communityId, userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": true,
+	})
+}
@@ -0,0 +1,164 @@
+package httpapiforum
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+
+	"github.com/google/uuid"
+)
+
+// moderationRequest is the optional body of a moderation action.
+type moderationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// moderate returns a handler taking the moderation action on the post or
+// comment with the ID in the path using the service function, writing the
+// moderation log entry.
+func (rtr ForumRouter) moderate(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID, reason string) (
+		*forum.ModerationLogEntry, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		var req moderationRequest
+		err = httputil.ReadJson(r, &req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+				"error", err,
+			)
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+
+		entry, err := f(r.Context(), httpauth.GetClaims(r), id, req.Reason)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, entry)
+	}
+}
+
+// getModerators returns the moderators of a community.
+func (rtr ForumRouter) getModerators(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	moderators, err := rtr.ser.GetModerators(r.Context(), httpauth.GetClaims(r), communityId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"moderators": moderators,
+	})
+}
+
+// setModeratorRequest is the request body of setModerator.
+type setModeratorRequest struct {
+	Role forum.ModeratorRole `json:"role"`
+}
+
+// setModerator makes the user in the path a moderator of the community with
+// the role in the request body.
+func (rtr ForumRouter) setModerator(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var req setModeratorRequest
+	err = httputil.ReadJson(r, &req)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	moderator, err := rtr.ser.SetModerator(r.Context(), httpauth.GetClaims(r), communityId, userId, req.Role)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, moderator)
+}
+
+// removeModerator removes the user in the path from the moderators of the
+// community.
+func (rtr ForumRouter) removeModerator(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	userId, err := httputil.PathUuid(r, "userId")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.RemoveModerator(r.Context(), httpauth.GetClaims(r), communityId, userId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"removed": true,
+	})
+}
+
+// getModerationLog returns the moderation log of a community.
+func (rtr ForumRouter) getModerationLog(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	entries, err := rtr.ser.GetModerationLog(r.Context(), httpauth.GetClaims(r), communityId, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"entries": entries,
+	})
+}
@@ -0,0 +1,81 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getNotifications returns the notifications of the user, optionally only the
+// unread ones, along with the number of unread notifications.
+func (rtr ForumRouter) getNotifications(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	unreadOnly, err := httputil.QueryBool(r, "unread", false)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	page, err := rtr.ser.GetNotifications(r.Context(), httpauth.GetClaims(r), unreadOnly, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, page)
+}
+
+// markAllNotificationsRead marks all notifications of the user as read.
+func (rtr ForumRouter) markAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	count, err := rtr.ser.MarkAllNotificationsRead(r.Context(), httpauth.GetClaims(r))
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"marked": count,
+	})
+}
+
+// getNotificationPreferences returns which kinds of notifications the user
+// receives.
+func (rtr ForumRouter) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	preferences, err := rtr.ser.GetNotificationPreferences(r.Context(), httpauth.GetClaims(r))
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, preferences)
+}
+
+// updateNotificationPreferences replaces which kinds of notifications the
+// user receives. Kinds left out of the request body are received.
+func (rtr ForumRouter) updateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	preferences := forum.DefaultNotificationPreferences()
+	err := httputil.ReadJson(r, &preferences)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	err = rtr.ser.UpdateNotificationPreferences(r.Context(), httpauth.GetClaims(r), preferences)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, preferences)
+}
@@ -0,0 +1,60 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// getPoll returns the poll of a poll post.
+func (rtr ForumRouter) getPoll(w http.ResponseWriter, r *http.Request) {
+	postId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	poll, err := rtr.ser.GetPoll(r.Context(), httpauth.GetClaims(r), postId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, poll)
+}
+
+// votePollRequest is the body of a vote in a poll.
+type votePollRequest struct {
+	OptionIds []forum.PollOptionId `json:"option_ids"`
+}
+
+// votePoll votes for options of the poll of a poll post, returning the poll
+// with its results.
+func (rtr ForumRouter) votePoll(w http.ResponseWriter, r *http.Request) {
+	postId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var req votePollRequest
+	err = httputil.ReadJson(r, &req)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	poll, err := rtr.ser.VotePoll(r.Context(), httpauth.GetClaims(r), postId, req.OptionIds)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, poll)
+}
@@ -0,0 +1,195 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesforum "greddit/internal/services/forum"
+
+	"github.com/google/uuid"
+)
+
+// postFilter parses the tag and flair_id query parameters filtering listings
+// of posts.
+func postFilter(r *http.Request) (filter forum.PostFilter, err error) {
+	query := r.URL.Query()
+	filter = forum.PostFilter{
+		Tag: query.Get("tag"),
+	}
+	if str := query.Get("flair_id"); str != "" {
+		flairId, err := uuid.Parse(str)
+		if err != nil {
+			return forum.PostFilter{}, err
+		}
+		filter.FlairId = &flairId
+	}
+
+	return filter, nil
+}
+
+// getPostsByCommunity returns the posts in a community, optionally filtered
+// by tag or flair.
+func (rtr ForumRouter) getPostsByCommunity(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	filter, err := postFilter(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	posts, err := rtr.ser.GetPostsByCommunity(r.Context(), httpauth.GetClaims(r), id, filter, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"posts": posts,
+	})
+}
+
+// createPost creates a post in a community, along with its flair and tags
+// and, for poll posts, its poll.
+func (rtr ForumRouter) createPost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var reqBody struct {
+		forum.PostValue
+		forum.PostLabels
+
+		Poll *forum.PollValue `json:"poll"`
+	}
+	err = httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	post, err := rtr.ser.CreatePost(r.Context(), httpauth.GetClaims(r), id, reqBody.PostValue, reqBody.PostLabels,
+		reqBody.Poll)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusCreated, post)
+}
+
+// getPost returns a post by its ID.
+func (rtr ForumRouter) getPost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	post, err := rtr.ser.GetPost(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, post)
+}
+
+// updatePost updates the title and body of a post.
+func (rtr ForumRouter) updatePost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var patch servicesforum.PostPatch
+	err = httputil.ReadJson(r, &patch)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.UpdatePost(r.Context(), httpauth.GetClaims(r), id, patch)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// movePost moves a post to another community, setting its flair in the new
+// community.
+func (rtr ForumRouter) movePost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	var reqBody struct {
+		CommunityId forum.CommunityId `json:"community_id"`
+		FlairId     *forum.FlairId    `json:"flair_id"`
+	}
+	err = httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.MovePost(r.Context(), httpauth.GetClaims(r), id, reqBody.CommunityId, reqBody.FlairId)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// deletePost soft deletes a post.
+func (rtr ForumRouter) deletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	deletedAt, err := rtr.ser.DeletePost(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted_at": deletedAt,
+	})
+}
@@ -0,0 +1,37 @@
+package httpapiforum
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+
+	"github.com/google/uuid"
+)
+
+// markRead returns a handler marking the item with the ID in the path as read
+// using the service function.
+func (rtr ForumRouter) markRead(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID) (*time.Time, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		readAt, err := f(r.Context(), httpauth.GetClaims(r), id)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, map[string]any{
+			"read_at": readAt,
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package httpapiforum
+
+import (
+	"context"
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+
+	"github.com/google/uuid"
+)
+
+// report returns a handler reporting the item with the ID in the path using
+// the service function.
+func (rtr ForumRouter) report(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID, value forum.ReportValue) (
+		*forum.Report, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		var value forum.ReportValue
+		err = httputil.ReadJson(r, &value)
+		if err != nil {
+			rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+				"error", err,
+			)
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+
+		report, err := f(r.Context(), httpauth.GetClaims(r), id, value)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusCreated, report)
+	}
+}
+
+// resolveReports returns a handler resolving the reports of the item with the
+// ID in the path using the service function.
+func (rtr ForumRouter) resolveReports(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID, value forum.ResolutionValue) (
+		*forum.Resolution, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		var value forum.ResolutionValue
+		err = httputil.ReadJson(r, &value)
+		if err != nil {
+			rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+				"error", err,
+			)
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+
+		resolution, err := f(r.Context(), httpauth.GetClaims(r), id, value)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, resolution)
+	}
+}
+
+// getReportQueue returns the moderation queue of a community, sorted by the
+// number of reports unless sorted by age.
+func (rtr ForumRouter) getReportQueue(w http.ResponseWriter, r *http.Request) {
+	communityId, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	sort := forum.ReportQueueSort(r.URL.Query().Get("sort"))
+	if sort == "" {
+		sort = forum.ReportQueueSortCount
+	}
+
+	items, err := rtr.ser.GetReportQueue(r.Context(), httpauth.GetClaims(r), communityId, sort, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"items": items,
+	})
+}
@@ -0,0 +1,218 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// diffQuery parses the from and to revision numbers of a diff request.
+func diffQuery(r *http.Request) (from int, to int, ok bool) {
+	from, err := httputil.QueryInt(r, "from", 0)
+	if err != nil || from <= 0 {
+		return 0, 0, false
+	}
+
+	to, err = httputil.QueryInt(r, "to", 0)
+	if err != nil || to <= 0 {
+		return 0, 0, false
+	}
+
+	return from, to, true
+}
+
+// getPostRevisions returns the revisions of a post, latest first.
+func (rtr ForumRouter) getPostRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	revisions, err := rtr.ser.GetPostRevisions(r.Context(), httpauth.GetClaims(r), id, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"revisions": revisions,
+	})
+}
+
+// getPostRevision returns a revision of a post by its number.
+func (rtr ForumRouter) getPostRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	revision, err := httputil.PathInt(r, "revision")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	postRevision, err := rtr.ser.GetPostRevision(r.Context(), httpauth.GetClaims(r), id, revision)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, postRevision)
+}
+
+// diffPostRevisions returns the unified diff between two revisions of a post.
+func (rtr ForumRouter) diffPostRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	from, to, ok := diffQuery(r)
+	if !ok {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	diff, err := rtr.ser.DiffPostRevisions(r.Context(), httpauth.GetClaims(r), id, from, to)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, diff)
+}
+
+// restorePostRevision restores the title and body of a post to those of one of
+// its revisions.
+func (rtr ForumRouter) restorePostRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	revision, err := httputil.PathInt(r, "revision")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.RestorePostRevision(r.Context(), httpauth.GetClaims(r), id, revision)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
+
+// getCommentRevisions returns the revisions of a comment, latest first.
+func (rtr ForumRouter) getCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	revisions, err := rtr.ser.GetCommentRevisions(r.Context(), httpauth.GetClaims(r), id, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"revisions": revisions,
+	})
+}
+
+// getCommentRevision returns a revision of a comment by its number.
+func (rtr ForumRouter) getCommentRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	revision, err := httputil.PathInt(r, "revision")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	commentRevision, err := rtr.ser.GetCommentRevision(r.Context(), httpauth.GetClaims(r), id, revision)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, commentRevision)
+}
+
+// diffCommentRevisions returns the unified diff between two revisions of a
+// comment.
+func (rtr ForumRouter) diffCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	from, to, ok := diffQuery(r)
+	if !ok {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	diff, err := rtr.ser.DiffCommentRevisions(r.Context(), httpauth.GetClaims(r), id, from, to)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, diff)
+}
+
+// restoreCommentRevision restores the body of a comment to that of one of its
+// revisions.
+func (rtr ForumRouter) restoreCommentRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	revision, err := httputil.PathInt(r, "revision")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	updatedAt, err := rtr.ser.RestoreCommentRevision(r.Context(), httpauth.GetClaims(r), id, revision)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"updated_at": updatedAt,
+	})
+}
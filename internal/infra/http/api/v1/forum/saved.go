@@ -0,0 +1,166 @@
+package httpapiforum
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+
+	"github.com/google/uuid"
+)
+
+// save returns a handler saving the item with the ID in the path using the
+// service function. The request body with the collection and note is
+// optional.
+func (rtr ForumRouter) save(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID, value forum.SavedItemValue) (
+		*forum.SavedItem, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		var value forum.SavedItemValue
+		err = httputil.ReadJson(r, &value)
+		if err != nil && !errors.Is(err, io.EOF) {
+			rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+				"error", err,
+			)
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+
+		item, err := f(r.Context(), httpauth.GetClaims(r), id, value)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, item)
+	}
+}
+
+// unsave returns a handler removing the saved item with the ID in the path
+// using the service function.
+func (rtr ForumRouter) unsave(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID) (bool, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		removed, err := f(r.Context(), httpauth.GetClaims(r), id)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, map[string]any{
+			"removed": removed,
+		})
+	}
+}
+
+// getSavedItems returns the saved items of the user, optionally filtered by
+// kind and collection.
+func (rtr ForumRouter) getSavedItems(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := forum.SavedItemFilter{
+		Kind: forum.SavedItemKind(query.Get("kind")),
+	}
+	if str := query.Get("collection_id"); str != "" {
+		collectionId, err := uuid.Parse(str)
+		if err != nil {
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+		filter.CollectionId = &collectionId
+	}
+
+	items, err := rtr.ser.GetSavedItems(r.Context(), httpauth.GetClaims(r), filter, limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"saved": items,
+	})
+}
+
+// getCollections returns the collections of the user.
+func (rtr ForumRouter) getCollections(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := httputil.Pagination(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	collections, err := rtr.ser.GetCollections(r.Context(), httpauth.GetClaims(r), limit, offset)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"collections": collections,
+	})
+}
+
+// createCollection creates a collection for the user.
+func (rtr ForumRouter) createCollection(w http.ResponseWriter, r *http.Request) {
+	var value forum.CollectionValue
+	err := httputil.ReadJson(r, &value)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	collection, err := rtr.ser.CreateCollection(r.Context(), httpauth.GetClaims(r), value)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusCreated, collection)
+}
+
+// deleteCollection deletes a collection of the user.
+func (rtr ForumRouter) deleteCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := httputil.PathUuid(r, "id")
+	if err != nil {
+		httputil.GenericNotFound(w, r)
+		return
+	}
+
+	err = rtr.ser.DeleteCollection(r.Context(), httpauth.GetClaims(r), id)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"deleted": true,
+	})
+}
@@ -0,0 +1,80 @@
+package httpapiforum
+
+import (
+	"net/http"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+)
+
+// searchTags returns the tags starting with the prefix query parameter, for
+// autocompletion.
+func (rtr ForumRouter) searchTags(w http.ResponseWriter, r *http.Request) {
+	limit, err := httputil.QueryLimit(r)
+	if err != nil {
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	prefix := r.URL.Query().Get("prefix")
+
+	tags, err := rtr.ser.SearchTags(r.Context(), httpauth.GetClaims(r), prefix, limit)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"tags": tags,
+	})
+}
+
+// renameTag renames a tag on every post carrying it.
+func (rtr ForumRouter) renameTag(w http.ResponseWriter, r *http.Request) {
+	var reqBody struct {
+		Name string `json:"name"`
+	}
+	err := httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	err = rtr.ser.RenameTag(r.Context(), httpauth.GetClaims(r), r.PathValue("name"), reqBody.Name)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"renamed": true,
+	})
+}
+
+// mergeTag merges a tag into the tag given in the request body.
+func (rtr ForumRouter) mergeTag(w http.ResponseWriter, r *http.Request) {
+	var reqBody struct {
+		Into string `json:"into"`
+	}
+	err := httputil.ReadJson(r, &reqBody)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error decoding request body",
+			"error", err,
+		)
+		httputil.GenericBadRequest(w, r)
+		return
+	}
+
+	err = rtr.ser.MergeTags(r.Context(), httpauth.GetClaims(r), r.PathValue("name"), reqBody.Into)
+	if err != nil {
+		rtr.respServiceError(w, r, err)
+		return
+	}
+
+	httputil.WriteJson(w, http.StatusOK, map[string]any{
+		"merged": true,
+	})
+}
@@ -0,0 +1,61 @@
+package httpapiforum
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+	servicesauth "greddit/internal/services/auth"
+
+	"github.com/google/uuid"
+)
+
+// getTrash returns a handler listing the soft-deleted items returned by the
+// service function under the given key.
+func getTrash[T any](rtr ForumRouter, key string,
+	f func(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) ([]T, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := httputil.Pagination(r)
+		if err != nil {
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+
+		items, err := f(r.Context(), httpauth.GetClaims(r), limit, offset)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, map[string]any{
+			key: items,
+		})
+	}
+}
+
+// restore returns a handler restoring the soft-deleted item with the ID in
+// the path using the service function.
+func (rtr ForumRouter) restore(
+	f func(ctx context.Context, claims servicesauth.TokenClaims, id uuid.UUID) (*time.Time, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := httputil.PathUuid(r, "id")
+		if err != nil {
+			httputil.GenericNotFound(w, r)
+			return
+		}
+
+		updatedAt, err := f(r.Context(), httpauth.GetClaims(r), id)
+		if err != nil {
+			rtr.respServiceError(w, r, err)
+			return
+		}
+
+		httputil.WriteJson(w, http.StatusOK, map[string]any{
+			"updated_at": updatedAt,
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	httpapiauth "greddit/internal/infra/http/api/v1/auth"
+	httpapiforum "greddit/internal/infra/http/api/v1/forum"
+	httpapistream "greddit/internal/infra/http/api/v1/stream"
 	httputil "greddit/internal/infra/http/util"
 
 	"greddit/internal/infra/http/routing"
@@ -17,5 +19,11 @@ func ApiRoutes(p routing.RouterParams) (mux *http.ServeMux) {
 		"/auth": httpapiauth.AuthRoutes(p),
 	})
 
+	stream := httpapistream.StreamRoutes(p)
+	mux.Handle("/stream", stream)
+	mux.Handle("/live", stream)
+
+	mux.Handle("/", httpapiforum.ForumRoutes(p))
+
 	return mux
 }
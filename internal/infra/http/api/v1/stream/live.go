@@ -0,0 +1,214 @@
+package httpapistream
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"greddit/internal/domains/shared"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+
+	servicesstream "greddit/internal/services/stream"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// liveProtocol is the WebSocket subprotocol of the live threads, which
+	// browsers must offer alongside the token subprotocol.
+	liveProtocol = "greddit.v1"
+	// liveTokenProtocolPrefix prefixes the token offered as a subprotocol by
+	// browsers, which cannot set the Authorization header on upgrade.
+	liveTokenProtocolPrefix = "bearer."
+
+	// liveIdleTimeout is how long a connection may go without a client
+	// message, so clients are expected to ping more often.
+	liveIdleTimeout = 60 * time.Second
+	// liveWriteTimeout is how long writing a message may take before the
+	// client is disconnected.
+	liveWriteTimeout = 10 * time.Second
+	// liveMaxMessageBytes is the maximum size of a client message.
+	liveMaxMessageBytes = 4 << 10
+)
+
+// liveTokenMiddleware uses the token offered as a WebSocket subprotocol as
+// the Authorization header of requests without one.
+func liveTokenMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		for protocol := range strings.SplitSeq(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+			token, ok := strings.CutPrefix(strings.TrimSpace(protocol), liveTokenProtocolPrefix)
+			if ok {
+				r = r.Clone(r.Context())
+				r.Header.Set("Authorization", token)
+				break
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// liveHandshake accepts WebSocket connections from any origin, as clients
+// authenticate with a token rather than cookies. Clients offering
+// subprotocols must offer the live protocol.
+func liveHandshake(config *websocket.Config, _ *http.Request) error {
+	if len(config.Protocol) == 0 {
+		return nil
+	}
+	if !slices.Contains(config.Protocol, liveProtocol) {
+		return errors.New("unsupported subprotocol")
+	}
+
+	config.Protocol = []string{liveProtocol}
+	return nil
+}
+
+// live upgrades the request to a WebSocket over which the client subscribes
+// to posts, receiving the created, edited and deleted comments of the posts
+// and the number of users viewing them. See messages.go for the schema of
+// the messages.
+func (rtr StreamRouter) live(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{
+		Handshake: liveHandshake,
+		Handler:   rtr.serveLive,
+	}.ServeHTTP(w, r)
+}
+
+// inbound is a message read from a client, or the reason it is invalid.
+type inbound struct {
+	msg clientMessage
+	err error
+}
+
+// serveLive serves the live threads over an upgraded connection, until the
+// client disconnects, the server shuts down or the client falls behind.
+func (rtr StreamRouter) serveLive(ws *websocket.Conn) {
+	r := ws.Request()
+	ctx := r.Context()
+	defer ws.Close()
+
+	stream := rtr.ser.OpenThreads(httpauth.GetClaims(r))
+	defer rtr.ser.CloseThreads(ctx, stream)
+
+	ws.MaxPayloadBytes = liveMaxMessageBytes
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan inbound)
+
+	// Messages are read separately so that events are written while waiting
+	// for the client, with every write made below.
+	go func() {
+		defer close(messages)
+		for {
+			_ = ws.SetReadDeadline(time.Now().Add(liveIdleTimeout))
+
+			var raw []byte
+			err := websocket.Message.Receive(ws, &raw)
+			if err != nil {
+				return
+			}
+
+			var in inbound
+			in.err = json.Unmarshal(raw, &in.msg)
+			select {
+			case messages <- in:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		var (
+			msg serverMessage
+			ok  bool
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-httputil.ShutdownFromCtx(ctx):
+			return
+		case in, open := <-messages:
+			if !open {
+				return
+			}
+			msg, ok = rtr.handleLive(r, stream, in), true
+		case event, open := <-stream.Events():
+			if !open {
+				return
+			}
+			msg, ok = newEventMessage(event)
+		}
+		if !ok {
+			continue
+		}
+
+		_ = ws.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		err := websocket.JSON.Send(ws, msg)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleLive handles a client message, returning the reply.
+func (rtr StreamRouter) handleLive(r *http.Request, stream *servicesstream.Stream, in inbound) serverMessage {
+	ctx := r.Context()
+	msg := in.msg
+
+	if in.err != nil {
+		return newErrorMessage(nil, codeInvalidMessage, "Message is not valid JSON")
+	} else if msg.Version != liveVersion {
+		return newErrorMessage(msg.PostId, codeUnsupportedVersion, "Only version 1 is supported")
+	}
+
+	switch msg.Type {
+	case clientPing:
+		return newServerMessage(serverPong, nil)
+
+	case clientSubscribe:
+		if msg.PostId == nil {
+			return newErrorMessage(nil, codeInvalidMessage, "post_id is required")
+		}
+
+		viewers, err := rtr.ser.Watch(ctx, stream, *msg.PostId)
+		if errors.Is(err, shared.ErrNotFound) {
+			return newErrorMessage(msg.PostId, codeNotFound, "Post not found")
+		} else if err != nil {
+			return newErrorMessage(msg.PostId, codeInternalError, "Error subscribing to post")
+		}
+
+		reply := newServerMessage(serverSubscribed, msg.PostId)
+		reply.Data, err = json.Marshal(subscribedData{
+			Viewers: viewers,
+		})
+		if err != nil {
+			return newErrorMessage(msg.PostId, codeInternalError, "Error subscribing to post")
+		}
+		return reply
+
+	case clientUnsubscribe:
+		if msg.PostId == nil {
+			return newErrorMessage(nil, codeInvalidMessage, "post_id is required")
+		}
+
+		err := rtr.ser.Unwatch(ctx, stream, *msg.PostId)
+		if err != nil {
+			return newErrorMessage(msg.PostId, codeInternalError, "Error unsubscribing from post")
+		}
+		return newServerMessage(serverUnsubscribed, msg.PostId)
+
+	default:
+		return newErrorMessage(msg.PostId, codeInvalidMessage, "Unknown message type")
+	}
+}
@@ -0,0 +1,413 @@
+package httpapistream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/auth/local/hs256"
+	"greddit/internal/infra/http/routing"
+	"greddit/internal/test"
+
+	dbports "greddit/internal/ports/db"
+	dbportsauth "greddit/internal/ports/db/auth"
+	dbportsforum "greddit/internal/ports/db/forum"
+	servicesauth "greddit/internal/services/auth"
+	servicesstream "greddit/internal/services/stream"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// fakeUsersRepo finds users by username.
+type fakeUsersRepo struct {
+	dbportsauth.UsersRepo
+	users map[string]auth.User
+}
+
+func (r fakeUsersRepo) GetUserByUsername(_ context.Context, username string, _ ...dbports.ReadOption) (
+	*auth.User, error,
+) {
+	user, ok := r.users[username]
+	if !ok {
+		return nil, shared.NotFoundError{Entity: "user"}
+	}
+	return &user, nil
+}
+
+// fakePostsRepo finds a single post.
+type fakePostsRepo struct {
+	dbportsforum.PostsRepo
+	post forum.Post
+}
+
+func (r fakePostsRepo) GetPostById(_ context.Context, id forum.PostId, _ ...dbports.ReadOption) (
+	*forum.Post, error,
+) {
+	if id != r.post.Id {
+		return nil, shared.NotFoundError{Entity: "post"}
+	}
+	return &r.post, nil
+}
+
+// fakeEventsRepo announces the events published while listening.
+type fakeEventsRepo struct {
+	dbportsforum.EventsRepo
+
+	mu        sync.Mutex
+	events    map[forum.EventId]forum.Event
+	published chan forum.EventId
+	listening chan struct{}
+}
+
+func (r *fakeEventsRepo) publish(event forum.Event) {
+	r.mu.Lock()
+	r.events[event.Id] = event
+	r.mu.Unlock()
+
+	r.published <- event.Id
+}
+
+func (r *fakeEventsRepo) GetEventById(_ context.Context, id forum.EventId) (*forum.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event, ok := r.events[id]
+	if !ok {
+		return nil, shared.NotFoundError{Entity: "event"}
+	}
+	return &event, nil
+}
+
+func (r *fakeEventsRepo) Listen(ctx context.Context, listening func(), handle func(id forum.EventId)) error {
+	listening()
+	close(r.listening)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case id := <-r.published:
+			handle(id)
+		}
+	}
+}
+
+// fakePresenceRepo keeps the viewers of posts in memory.
+type fakePresenceRepo struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]map[forum.PostId]auth.UserId
+	changed  chan forum.PostId
+}
+
+func (r *fakePresenceRepo) Join(_ context.Context, sessionId uuid.UUID, postId forum.PostId,
+	userId auth.UserId,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessions[sessionId] == nil {
+		r.sessions[sessionId] = make(map[forum.PostId]auth.UserId)
+	}
+	r.sessions[sessionId][postId] = userId
+	r.changed <- postId
+	return nil
+}
+
+func (r *fakePresenceRepo) Leave(_ context.Context, sessionId uuid.UUID, postId forum.PostId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions[sessionId], postId)
+	r.changed <- postId
+	return nil
+}
+
+func (r *fakePresenceRepo) LeaveAll(_ context.Context, sessionId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for postId := range r.sessions[sessionId] {
+		r.changed <- postId
+	}
+	delete(r.sessions, sessionId)
+	return nil
+}
+
+func (r *fakePresenceRepo) Touch(context.Context, []uuid.UUID) error {
+	return nil
+}
+
+func (r *fakePresenceRepo) CountViewers(_ context.Context, postIds []forum.PostId, _ time.Time) (
+	map[forum.PostId]int, error,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make(map[forum.PostId]map[auth.UserId]struct{})
+	for _, posts := range r.sessions {
+		for postId, userId := range posts {
+			if users[postId] == nil {
+				users[postId] = make(map[auth.UserId]struct{})
+			}
+			users[postId][userId] = struct{}{}
+		}
+	}
+
+	viewers := make(map[forum.PostId]int)
+	for _, postId := range postIds {
+		if len(users[postId]) > 0 {
+			viewers[postId] = len(users[postId])
+		}
+	}
+	return viewers, nil
+}
+
+func (r *fakePresenceRepo) DeleteStale(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakePresenceRepo) Listen(ctx context.Context, listening func(), handle func(postId forum.PostId)) error {
+	listening()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case postId := <-r.changed:
+			handle(postId)
+		}
+	}
+}
+
+var _ dbportsforum.PresenceRepo = (*fakePresenceRepo)(nil)
+
+func TestStreamRouter_Live(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+
+	alice := auth.User{
+		UserValue:    auth.UserValue{Username: "alice", DisplayName: "alice", Role: auth.RoleUser},
+		UserMetadata: auth.UserMetadata{Id: uuid.New()},
+	}
+	bob := auth.User{
+		UserValue:    auth.UserValue{Username: "bob", DisplayName: "bob", Role: auth.RoleUser},
+		UserMetadata: auth.UserMetadata{Id: uuid.New()},
+	}
+	post := forum.Post{
+		PostMetadata: forum.PostMetadata{Id: uuid.New(), PosterId: alice.Id, CommunityId: uuid.New()},
+	}
+
+	secret, err := hs256.NewSecret()
+	test.NilErr(t, err)
+	source, err := hs256.NewSource(secret)
+	test.NilErr(t, err)
+	authSer := servicesauth.NewService(logger, source, fakeUsersRepo{
+		users: map[string]auth.User{
+			alice.Username: alice,
+			bob.Username:   bob,
+		},
+	})
+
+	events := &fakeEventsRepo{
+		events:    make(map[forum.EventId]forum.Event),
+		published: make(chan forum.EventId),
+		listening: make(chan struct{}),
+	}
+	streamSer := servicesstream.NewService(logger, servicesstream.Repos{
+		Events: events,
+		Posts:  fakePostsRepo{post: post},
+		Presence: &fakePresenceRepo{
+			sessions: make(map[uuid.UUID]map[forum.PostId]auth.UserId),
+			changed:  make(chan forum.PostId, 64),
+		},
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- streamSer.Start(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	<-events.listening
+
+	srv := httptest.NewServer(StreamRoutes(routing.RouterParams{
+		Logger:    logger,
+		AuthSer:   &authSer,
+		StreamSer: &streamSer,
+	}))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/live"
+
+	login := func(t *testing.T, username string) string {
+		t.Helper()
+
+		token, err := authSer.Login(t.Context(), username)
+		test.NilErr(t, err)
+		return string(token)
+	}
+
+	dial := func(t *testing.T, configure func(config *websocket.Config)) *websocket.Conn {
+		t.Helper()
+
+		config, err := websocket.NewConfig(url, srv.URL)
+		test.NilErr(t, err)
+		configure(config)
+
+		ws, err := websocket.DialConfig(config)
+		test.NilErr(t, err)
+		t.Cleanup(func() {
+			_ = ws.Close()
+		})
+		return ws
+	}
+
+	dialAs := func(t *testing.T, username string) *websocket.Conn {
+		t.Helper()
+
+		return dial(t, func(config *websocket.Config) {
+			config.Header.Set("Authorization", login(t, username))
+		})
+	}
+
+	send := func(t *testing.T, ws *websocket.Conn, msg clientMessage) {
+		t.Helper()
+
+		test.NilErr(t, websocket.JSON.Send(ws, msg))
+	}
+
+	// until receives messages until one matches, failing after a timeout.
+	until := func(t *testing.T, ws *websocket.Conn, matches func(msg serverMessage) bool) serverMessage {
+		t.Helper()
+
+		test.NilErr(t, ws.SetReadDeadline(time.Now().Add(5*time.Second)))
+		for {
+			var msg serverMessage
+			test.NilErr(t, websocket.JSON.Receive(ws, &msg))
+			test.AssertEqual(t, "Unexpected version", liveVersion, msg.Version)
+			if matches(msg) {
+				return msg
+			}
+		}
+	}
+
+	ofType := func(messageType string) func(msg serverMessage) bool {
+		return func(msg serverMessage) bool {
+			return msg.Type == messageType
+		}
+	}
+
+	presenceOf := func(viewers int) func(msg serverMessage) bool {
+		return func(msg serverMessage) bool {
+			if msg.Type != string(forum.EventKindPresence) {
+				return false
+			}
+
+			var presence forum.Presence
+			err := json.Unmarshal(msg.Data, &presence)
+			return err == nil && presence.PostId == post.Id && presence.Viewers == viewers
+		}
+	}
+
+	subscribe := func(t *testing.T, ws *websocket.Conn) subscribedData {
+		t.Helper()
+
+		send(t, ws, clientMessage{Version: liveVersion, Type: clientSubscribe, PostId: &post.Id})
+		msg := until(t, ws, ofType(serverSubscribed))
+		test.AssertEqual(t, "Unexpected subscribed post", post.Id, *msg.PostId)
+
+		var data subscribedData
+		test.NilErr(t, json.Unmarshal(msg.Data, &data))
+		return data
+	}
+
+	t.Run("comments and presence are streamed to subscribers", func(t *testing.T) {
+		aliceWs := dialAs(t, alice.Username)
+		data := subscribe(t, aliceWs)
+		test.AssertEqual(t, "Unexpected viewers", 1, data.Viewers)
+
+		bobWs := dialAs(t, bob.Username)
+		data = subscribe(t, bobWs)
+		test.AssertEqual(t, "Unexpected viewers", 2, data.Viewers)
+		until(t, aliceWs, presenceOf(2))
+
+		comment := forum.Comment{
+			CommentValue:    forum.CommentValue{Body: "Nice post"},
+			CommentMetadata: forum.CommentMetadata{Id: uuid.New(), CommenterId: bob.Id, PostId: post.Id},
+		}
+		value, err := forum.NewCommentEvent(forum.EventKindCommentCreated, comment)
+		test.NilErr(t, err)
+		events.publish(forum.Event{Id: 1, EventValue: value})
+
+		for _, ws := range []*websocket.Conn{aliceWs, bobWs} {
+			msg := until(t, ws, ofType(string(forum.EventKindCommentCreated)))
+			test.AssertEqual(t, "Unexpected event ID", forum.EventId(1), *msg.EventId)
+			test.AssertEqual(t, "Unexpected post", post.Id, *msg.PostId)
+
+			var got forum.Comment
+			test.NilErr(t, json.Unmarshal(msg.Data, &got))
+			test.AssertEqual(t, "Unexpected comment", comment.Id, got.Id)
+		}
+
+		send(t, bobWs, clientMessage{Version: liveVersion, Type: clientUnsubscribe, PostId: &post.Id})
+		until(t, bobWs, ofType(serverUnsubscribed))
+		until(t, aliceWs, presenceOf(1))
+
+		send(t, bobWs, clientMessage{Version: liveVersion, Type: clientSubscribe, PostId: &post.Id})
+		until(t, bobWs, ofType(serverSubscribed))
+		until(t, aliceWs, presenceOf(2))
+
+		test.NilErr(t, bobWs.Close())
+		until(t, aliceWs, presenceOf(1))
+	})
+
+	t.Run("invalid messages are answered with errors", func(t *testing.T) {
+		ws := dialAs(t, alice.Username)
+
+		send(t, ws, clientMessage{Version: 2, Type: clientPing})
+		msg := until(t, ws, ofType(serverError))
+		test.AssertEqual(t, "Unexpected error code", codeUnsupportedVersion, msg.Error.Code)
+
+		missing := uuid.New()
+		send(t, ws, clientMessage{Version: liveVersion, Type: clientSubscribe, PostId: &missing})
+		msg = until(t, ws, ofType(serverError))
+		test.AssertEqual(t, "Unexpected error code", codeNotFound, msg.Error.Code)
+		test.AssertEqual(t, "Unexpected post", missing, *msg.PostId)
+
+		test.NilErr(t, websocket.Message.Send(ws, "not json"))
+		msg = until(t, ws, ofType(serverError))
+		test.AssertEqual(t, "Unexpected error code", codeInvalidMessage, msg.Error.Code)
+
+		send(t, ws, clientMessage{Version: liveVersion, Type: clientPing})
+		until(t, ws, ofType(serverPong))
+	})
+
+	t.Run("token is accepted as a subprotocol", func(t *testing.T) {
+		ws := dial(t, func(config *websocket.Config) {
+			config.Protocol = []string{liveProtocol, liveTokenProtocolPrefix + login(t, alice.Username)}
+		})
+		test.AssertEqual(t, "Unexpected protocol", []string{liveProtocol}, ws.Config().Protocol)
+
+		send(t, ws, clientMessage{Version: liveVersion, Type: clientPing})
+		until(t, ws, ofType(serverPong))
+	})
+
+	t.Run("upgrade requires a valid token", func(t *testing.T) {
+		config, err := websocket.NewConfig(url, srv.URL)
+		test.NilErr(t, err)
+		config.Header.Set("Authorization", "invalid")
+
+		_, err = websocket.DialConfig(config)
+		test.Assert(t, "Expected upgrade to be refused", err != nil)
+	})
+}
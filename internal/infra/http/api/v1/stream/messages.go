@@ -0,0 +1,108 @@
+package httpapistream
+
+import (
+	"encoding/json"
+
+	"greddit/internal/domains/forum"
+)
+
+// liveVersion is the version of the live thread messages. Every message
+// carries the version in its v field, and messages of other versions are
+// rejected, so that the schema can change without breaking older clients
+// silently.
+const liveVersion = 1
+
+// Types of the messages sent by clients.
+const (
+	// clientSubscribe subscribes to the comments and viewers of a post.
+	clientSubscribe = "subscribe"
+	// clientUnsubscribe unsubscribes from a post.
+	clientUnsubscribe = "unsubscribe"
+	// clientPing keeps the connection alive, answered with a pong.
+	clientPing = "ping"
+)
+
+// Types of the messages sent by the server, besides the comment and presence
+// events named after their forum.EventKind.
+const (
+	// serverSubscribed confirms a subscription, with the number of viewers
+	// of the post.
+	serverSubscribed = "subscribed"
+	// serverUnsubscribed confirms an unsubscription.
+	serverUnsubscribed = "unsubscribed"
+	// serverPong answers a ping.
+	serverPong = "pong"
+	// serverError reports a message which could not be handled.
+	serverError = "error"
+)
+
+// Codes of the errors sent to clients.
+const (
+	codeUnsupportedVersion = "unsupported_version"
+	codeInvalidMessage     = "invalid_message"
+	codeNotFound           = "not_found"
+	codeInternalError      = "internal_error"
+)
+
+// clientMessage is a message sent by a client.
+type clientMessage struct {
+	Version int           `json:"v"`
+	Type    string        `json:"type"`
+	PostId  *forum.PostId `json:"post_id,omitempty"`
+}
+
+// serverMessage is a message sent by the server. The event ID is set for
+// stored events, and the data depends on the type of the message.
+type serverMessage struct {
+	Version int             `json:"v"`
+	Type    string          `json:"type"`
+	PostId  *forum.PostId   `json:"post_id,omitempty"`
+	EventId *forum.EventId  `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *messageError   `json:"error,omitempty"`
+}
+
+// messageError describes why a client message could not be handled.
+type messageError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// subscribedData is the data of a subscribed message.
+type subscribedData struct {
+	Viewers int `json:"viewers"`
+}
+
+// newServerMessage returns a message of the type about the post.
+func newServerMessage(messageType string, postId *forum.PostId) serverMessage {
+	return serverMessage{
+		Version: liveVersion,
+		Type:    messageType,
+		PostId:  postId,
+	}
+}
+
+// newErrorMessage returns an error message about the post, if any.
+func newErrorMessage(postId *forum.PostId, code string, detail string) serverMessage {
+	msg := newServerMessage(serverError, postId)
+	msg.Error = &messageError{
+		Code:   code,
+		Detail: detail,
+	}
+	return msg
+}
+
+// newEventMessage returns the message streaming the comment or presence
+// event, or false for events of other kinds.
+func newEventMessage(event forum.Event) (msg serverMessage, ok bool) {
+	if !forum.IsCommentEventKind(event.Kind) && event.Kind != forum.EventKindPresence {
+		return serverMessage{}, false
+	}
+
+	msg = newServerMessage(string(event.Kind), event.PostId)
+	msg.Data = event.Data
+	if event.Id != 0 {
+		msg.EventId = &event.Id
+	}
+	return msg, true
+}
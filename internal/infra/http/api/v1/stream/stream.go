@@ -0,0 +1,141 @@
+package httpapistream
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	httpauth "greddit/internal/infra/http/auth"
+	httputil "greddit/internal/infra/http/util"
+
+	"greddit/internal/infra/http/routing"
+	servicesstream "greddit/internal/services/stream"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// heartbeatInterval is the interval between comments keeping idle
+	// streams open through proxies.
+	heartbeatInterval = 30 * time.Second
+	// retryDelay is how long clients wait before reconnecting.
+	retryDelay = 3 * time.Second
+)
+
+// StreamRouter is a router for the real-time endpoints.
+type StreamRouter struct {
+	logger *slog.Logger
+	ser    servicesstream.Service
+}
+
+// StreamRoutes returns the routes for the real-time endpoints. All routes
+// require a valid token.
+func StreamRoutes(p routing.RouterParams) http.Handler {
+	mux := http.NewServeMux()
+
+	rtr := StreamRouter{
+		logger: p.Logger,
+		ser:    *p.StreamSer,
+	}
+
+	mux.HandleFunc("/stream", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.stream,
+	}))
+	mux.HandleFunc("/live", httputil.Methods(map[string]http.HandlerFunc{
+		http.MethodGet: rtr.live,
+	}))
+
+	return liveTokenMiddleware(httpauth.AuthMiddleware(mux, *p.AuthSer))
+}
+
+// stream streams server-sent events of new posts in the subscribed
+// communities of the user, new notifications of the user and, given the
+// post_id query parameter, the changes to the comments on the post. Clients
+// reconnecting with the Last-Event-ID header first receive the events they
+// missed.
+func (rtr StreamRouter) stream(w http.ResponseWriter, r *http.Request) {
+	var postId *forum.PostId
+	if str := r.URL.Query().Get("post_id"); str != "" {
+		id, err := uuid.Parse(str)
+		if err != nil {
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+		postId = &id
+	}
+
+	var lastEventId *forum.EventId
+	if str := r.Header.Get("Last-Event-ID"); str != "" {
+		id, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			httputil.GenericBadRequest(w, r)
+			return
+		}
+		lastEventId = &id
+	}
+
+	stream, err := rtr.ser.Connect(r.Context(), httpauth.GetClaims(r), postId, lastEventId)
+	if err != nil {
+		if !httputil.RespDomainError(w, r, err) {
+			httputil.GenericInternalServerError(w, r)
+		}
+		return
+	}
+	defer stream.Close()
+
+	sse, err := httputil.NewSSEWriter(w)
+	if err != nil {
+		rtr.logger.ErrorContext(r.Context(), "Error starting event stream",
+			"error", err,
+		)
+		return
+	}
+
+	err = sse.WriteRetry(retryDelay)
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-httputil.ShutdownFromCtx(r.Context()):
+			return
+		case <-heartbeat.C:
+			err = sse.WriteComment("heartbeat")
+		case event, ok := <-stream.Events():
+			if !ok {
+				return
+			}
+			err = writeEvent(sse, event)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes the event with its ID and kind as the event type. Events
+// which are not stored, such as presence, are written without an ID so that
+// the client keeps the ID of the last stored event.
+func writeEvent(sse *httputil.SSEWriter, event forum.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var id string
+	if event.Id != 0 {
+		id = strconv.FormatInt(event.Id, 10)
+	}
+
+	return sse.WriteEvent(id, string(event.Kind), data)
+}
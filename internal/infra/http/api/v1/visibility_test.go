@@ -0,0 +1,271 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/infra/auth/local/hs256"
+	"greddit/internal/infra/blob/localfs"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/infra/http/routing"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	forumdb "greddit/internal/infra/db/postgres/forum"
+	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+	servicesrender "greddit/internal/services/render"
+	servicesstream "greddit/internal/services/stream"
+)
+
+// TestVisibility checks that no endpoint leaks the contents of a private
+// community to users outside of it, while its members see them.
+func TestVisibility(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	ctx := t.Context()
+	postgres.ClearAllTables(t, pool)
+
+	logger := slog.New(slog.DiscardHandler)
+	secret, err := hs256.NewSecret()
+	test.NilErr(t, err)
+	jwkSource, err := hs256.NewSource(secret)
+	test.NilErr(t, err)
+
+	usersRepo := authdb.NewUsersRepo(pool)
+	communitiesRepo := forumdb.NewCommunitiesRepo(pool)
+	subscriptionsRepo := forumdb.NewSubscriptionsRepo(pool)
+	savedRepo := forumdb.NewSavedItemsRepo(pool)
+	membersRepo := forumdb.NewMembersRepo(pool)
+	moderationRepo := forumdb.NewModerationRepo(pool)
+
+	blobs, err := localfs.NewStore(t.TempDir())
+	test.NilErr(t, err)
+
+	authSer := servicesauth.NewService(logger, jwkSource, usersRepo)
+	forumSer := servicesforum.NewService(logger, postgres.NewTransactional(pool), servicesrender.NewService(), blobs,
+		servicesforum.Repos{
+			Communities:   communitiesRepo,
+			Posts:         forumdb.NewPostsRepo(pool),
+			Comments:      forumdb.NewCommentsRepo(pool),
+			Links:         forumdb.NewLinksRepo(pool),
+			Revisions:     forumdb.NewRevisionsRepo(pool),
+			Subscriptions: subscriptionsRepo,
+			SavedItems:    savedRepo,
+			Reads:         forumdb.NewReadsRepo(pool),
+			Notifications: forumdb.NewNotificationsRepo(pool),
+			Events:        forumdb.NewEventsRepo(pool),
+			Moderation:    moderationRepo,
+			Reports:       forumdb.NewReportsRepo(pool),
+			Bans:          forumdb.NewBansRepo(pool),
+			Members:       membersRepo,
+			Users:         usersRepo,
+			Flairs:        forumdb.NewFlairsRepo(pool),
+			Tags:          forumdb.NewTagsRepo(pool),
+			Attachments:   forumdb.NewAttachmentsRepo(pool),
+			Polls:         forumdb.NewPollsRepo(pool),
+		})
+	streamSer := servicesstream.NewService(logger, servicesstream.Repos{
+		Events:        forumdb.NewEventsRepo(pool),
+		Subscriptions: subscriptionsRepo,
+		Posts:         forumdb.NewPostsRepo(pool),
+		Presence:      forumdb.NewPresenceRepo(pool),
+	})
+
+	server := httptest.NewServer(ApiRoutes(routing.RouterParams{
+		Logger:    logger,
+		AuthSer:   &authSer,
+		ForumSer:  &forumSer,
+		StreamSer: &streamSer,
+	}))
+	defer server.Close()
+
+	tokens := map[string]string{}
+	claims := map[string]servicesauth.TokenClaims{}
+	users := map[string]*auth.User{}
+	for _, username := range []string{"owner", "member", "outsider"} {
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    username,
+			DisplayName: username,
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+		users[username] = user
+
+		token, err := authSer.Login(ctx, username)
+		test.NilErr(t, err)
+		tokens[username] = string(token)
+
+		userClaims, err := authSer.ExtractClaims(ctx, token)
+		test.NilErr(t, err)
+		claims[username] = *userClaims
+	}
+	owner, member, outsider := users["owner"], users["member"], users["outsider"]
+
+	public, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+		Name:        "golang",
+		Description: "Go programming",
+	})
+	test.NilErr(t, err)
+
+	private, err := communitiesRepo.CreateCommunity(ctx, forum.CommunityValue{
+		Name:        "secret",
+		Description: "Invited members only",
+	})
+	test.NilErr(t, err)
+
+	settings := forum.DefaultCommunitySettings()
+	settings.Visibility = forum.CommunityVisibilityPrivate
+	_, err = communitiesRepo.UpdateCommunitySettings(ctx, private.Id, settings)
+	test.NilErr(t, err)
+
+	_, err = moderationRepo.SetModerator(ctx, private.Id, owner.Id, forum.ModeratorRoleOwner)
+	test.NilErr(t, err)
+	_, err = membersRepo.AddMember(ctx, private.Id, member.Id)
+	test.NilErr(t, err)
+
+	// The outsider kept a subscription from before the community was made
+	// private.
+	_, err = subscriptionsRepo.Subscribe(ctx, outsider.Id, private.Id)
+	test.NilErr(t, err)
+
+	publicPost, err := forumSer.CreatePost(ctx, claims["outsider"], public.Id, forum.PostValue{
+		Title: "Public Post",
+		Body:  "See [[secret/Secret Post]]",
+	}, forum.PostLabels{}, nil)
+	test.NilErr(t, err)
+
+	privatePost, err := forumSer.CreatePost(ctx, claims["owner"], private.Id, forum.PostValue{
+		Title: "Secret Post",
+		Body:  "Body of Secret Post",
+	}, forum.PostLabels{Tags: []string{"classified"}}, nil)
+	test.NilErr(t, err)
+
+	privatePoll, err := forumSer.CreatePost(ctx, claims["owner"], private.Id, forum.PostValue{
+		Type:  forum.PostTypePoll,
+		Title: "Secret Poll",
+	}, forum.PostLabels{}, &forum.PollValue{
+		Options: []string{"Option of Secret Poll", "Other option"},
+	})
+	test.NilErr(t, err)
+
+	privateComment, err := forumSer.CreateComment(ctx, claims["owner"], privatePost.Id, forum.CommentValue{
+		Body: "Unlike [[golang/Public Post]], ask @member or @outsider",
+	}, nil)
+	test.NilErr(t, err)
+
+	attachment, err := forumSer.UploadPostAttachment(ctx, claims["owner"], privatePost.Id, "plan.txt",
+		strings.NewReader("Attachment of Secret Post"))
+	test.NilErr(t, err)
+
+	// The outsider also kept saved items from before.
+	_, err = savedRepo.SavePost(ctx, outsider.Id, privatePost.Id, forum.SavedItemValue{})
+	test.NilErr(t, err)
+	_, err = savedRepo.SaveComment(ctx, outsider.Id, privateComment.Id, forum.SavedItemValue{})
+	test.NilErr(t, err)
+	_, err = savedRepo.SavePost(ctx, member.Id, privatePost.Id, forum.SavedItemValue{})
+	test.NilErr(t, err)
+
+	// secrets are the traces of the private community which must not appear
+	// in any response to the outsider.
+	secrets := []string{
+		private.Id.String(),
+		privatePost.Id.String(),
+		privateComment.Id.String(),
+		"Body of Secret Post",
+		"ask @member",
+		"classified",
+		attachment.Id.String(),
+		"Attachment of Secret Post",
+		privatePoll.Id.String(),
+		"Option of Secret Poll",
+	}
+
+	get := func(t *testing.T, username string, path string, header http.Header) (status int, body string) {
+		t.Helper()
+
+		// Streams never end, so their body is read until the timeout.
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		test.NilErr(t, err)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+		r.Header.Set("Authorization", tokens[username])
+
+		res, err := server.Client().Do(r)
+		test.NilErr(t, err)
+		defer res.Body.Close()
+
+		data, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(data)
+	}
+
+	replay := http.Header{"Last-Event-ID": {"0"}}
+
+	data := []struct {
+		name   string
+		path   string
+		header http.Header
+		// notFound is whether the endpoint reports the private content as not
+		// found to the outsider, rather than leaving it out.
+		notFound bool
+		// listed is the trace of the private community listed to members.
+		listed string
+	}{
+		{name: "community", path: "/communities/" + private.Id.String(), notFound: true},
+		{name: "communities", path: "/communities", listed: private.Id.String()},
+		{name: "community flairs", path: "/communities/" + private.Id.String() + "/flairs", notFound: true},
+		{name: "community posts", path: "/communities/" + private.Id.String() + "/posts", notFound: true},
+		{name: "post", path: "/posts/" + privatePost.Id.String(), notFound: true},
+		{name: "poll", path: "/posts/" + privatePoll.Id.String() + "/poll", notFound: true},
+		{name: "post comments", path: "/posts/" + privatePost.Id.String() + "/comments", notFound: true},
+		{name: "post revisions", path: "/posts/" + privatePost.Id.String() + "/revisions", notFound: true},
+		{name: "attachment", path: "/attachments/" + attachment.Id.String(), notFound: true},
+		{name: "attachment content", path: "/attachments/" + attachment.Id.String() + "/content", notFound: true},
+		{name: "comment", path: "/comments/" + privateComment.Id.String(), notFound: true},
+		{name: "feed", path: "/feed", listed: privatePost.Id.String()},
+		{name: "tagged feed", path: "/feed?tag=classified", listed: privatePost.Id.String()},
+		{name: "tags", path: "/tags?prefix=class", listed: "classified"},
+		{name: "subscriptions", path: "/subscriptions"},
+		{name: "links", path: "/posts/" + publicPost.Id.String() + "/links", listed: privateComment.Id.String()},
+		{name: "dangling links", path: "/links/dangling"},
+		{name: "saved items", path: "/saved", listed: privatePost.Id.String()},
+		{name: "notifications", path: "/notifications", listed: privateComment.Id.String()},
+		{name: "post stream", path: "/stream?post_id=" + privatePost.Id.String(), notFound: true},
+		{name: "stream replay", path: "/stream", header: replay},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			status, body := get(t, "outsider", d.path, d.header)
+			if d.notFound {
+				test.AssertEqual(t, "Expected not found for outsider", http.StatusNotFound, status)
+			} else {
+				test.AssertEqual(t, "Expected OK for outsider", http.StatusOK, status)
+			}
+			for _, secret := range secrets {
+				test.Assert(t, "Response to outsider leaks "+secret, !strings.Contains(body, secret))
+			}
+
+			status, body = get(t, "member", d.path, d.header)
+			test.AssertEqual(t, "Expected OK for member", http.StatusOK, status)
+			if d.listed != "" {
+				test.Assert(t, "Expected private content listed to member", strings.Contains(body, d.listed))
+			}
+		})
+	}
+}
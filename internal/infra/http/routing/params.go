@@ -4,6 +4,8 @@ import (
 	"log/slog"
 
 	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+	servicesstream "greddit/internal/services/stream"
 )
 
 // RouterParams contains all the dependencies required by the router and sub
@@ -12,7 +14,9 @@ type RouterParams struct {
 	Logger *slog.Logger
 	IsDev  bool
 
-	AuthSer *servicesauth.Service
+	AuthSer   *servicesauth.Service
+	ForumSer  *servicesforum.Service
+	StreamSer *servicesstream.Service
 }
 
 // Validate validates the dependencies of the router.
@@ -21,6 +25,10 @@ func (p RouterParams) Validate() error {
 		return newInvalidRouterParamError("Logger")
 	} else if p.AuthSer == nil {
 		return newInvalidRouterParamError("AuthSer")
+	} else if p.ForumSer == nil {
+		return newInvalidRouterParamError("ForumSer")
+	} else if p.StreamSer == nil {
+		return newInvalidRouterParamError("StreamSer")
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"greddit/internal/infra/http/routing"
+	httputil "greddit/internal/infra/http/util"
 )
 
 // Server represents an HTTP server.
@@ -53,10 +54,19 @@ func (s Server) Addr() string {
 func (s Server) Start(ctx context.Context, readyCh ...chan struct{}) error {
 	logger := s.params.Logger
 
+	// Closed once shutdown starts, ending long-lived responses which would
+	// otherwise hold up the shutdown.
+	shutdownCh := make(chan struct{})
 	server := http.Server{
 		Addr:    s.Addr(),
 		Handler: s.handler,
+		BaseContext: func(net.Listener) context.Context {
+			return httputil.CtxWithShutdown(context.Background(), shutdownCh)
+		},
 	}
+	server.RegisterOnShutdown(func() {
+		close(shutdownCh)
+	})
 
 	ch := make(chan error, 1)
 	go func() {
@@ -0,0 +1,61 @@
+package httpserver
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"greddit/internal/infra/http/routing"
+	"greddit/internal/test"
+
+	httputil "greddit/internal/infra/http/util"
+)
+
+func TestHttpServer_ShutdownEndsStreams(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	test.NilErr(t, err)
+
+	streaming := make(chan struct{})
+	config := defaultConfig()
+	config.ln = ln
+	config.addr = ln.Addr().String()
+	config.shutdownTimeout = 5 * time.Second
+	s := Server{
+		config: config,
+		params: routing.RouterParams{
+			Logger: slog.New(slog.DiscardHandler),
+		},
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			close(streaming)
+			<-httputil.ShutdownFromCtx(r.Context())
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	readyCh := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Start(ctx, readyCh)
+	}()
+	<-readyCh
+
+	res, err := http.Get("http://" + s.Addr())
+	test.NilErr(t, err)
+	defer res.Body.Close()
+	<-streaming
+
+	cancel()
+	select {
+	case err := <-done:
+		test.NilErr(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not shut down while a stream was open")
+	}
+}
@@ -1,18 +1,142 @@
 package httputil
 
-import "net/http"
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
 
-// RespError writes an error response to the http.ResponseWriter.
-func RespError(w http.ResponseWriter, _ *http.Request, statusCode int, reason string, logArgs ...any) {
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/log"
+)
+
+// Machine-readable codes for errors in the shared domain error taxonomy.
+const (
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeInvalidReference = "invalid_reference"
+	CodeValidationFailed = "validation_failed"
+)
+
+// ProblemContentType is the media type of error responses, as defined by
+// RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object, extended with the
+// machine-readable code and the field-level validation failures.
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   []shared.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem creates a problem for the status and code, identifying the
+// request by its trace id.
+func NewProblem(r *http.Request, statusCode int, code string, detail string) Problem {
+	problem := Problem{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  codeTitle(code),
+		Status: statusCode,
+		Detail: detail,
+		Code:   code,
+	}
+
+	traceId, err := log.TraceIdFromCtx(r.Context())
+	if err == nil {
+		problem.Instance = traceId.URN()
+	}
+
+	return problem
+}
+
+// RespError writes an error response to the http.ResponseWriter, with a code
+// derived from the status.
+func RespError(w http.ResponseWriter, r *http.Request, statusCode int, reason string, logArgs ...any) {
+	RespErrorCode(w, r, statusCode, statusErrorCode(statusCode), reason, logArgs...)
+}
+
+// RespErrorCode writes an error response with a machine-readable code to the
+// http.ResponseWriter.
+func RespErrorCode(w http.ResponseWriter, r *http.Request, statusCode int, code string, reason string,
+	logArgs ...any,
+) {
+	RespProblem(w, r, NewProblem(r, statusCode, code, reason), logArgs...)
+}
+
+// RespProblem writes the problem as the error response to the
+// http.ResponseWriter.
+func RespProblem(w http.ResponseWriter, r *http.Request, problem Problem, logArgs ...any) {
+	RespExtendedProblem(w, r, problem, problem, logArgs...)
+}
+
+// RespExtendedProblem writes the extended problem as the error response to the
+// http.ResponseWriter. The extended problem embeds the problem alongside its
+// extension members, as allowed by RFC 9457.
+func RespExtendedProblem(w http.ResponseWriter, r *http.Request, problem Problem, extended any, logArgs ...any) {
 	logArgs = append([]any{
-		"status", statusCode,
-		"reason", reason,
+		"status", problem.Status,
+		"code", problem.Code,
+		"reason", problem.Detail,
 	}, logArgs...)
-	logger.Error("HTTP Response error", logArgs...)
+	logger.ErrorContext(r.Context(), "HTTP Response error", logArgs...)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
 
-	WriteJson(w, statusCode, map[string]any{
-		"error": reason,
-	})
+	err := json.NewEncoder(w).Encode(extended)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error writing problem response",
+			"error", err,
+		)
+	}
+}
+
+// RespDomainError writes the error response for errors in the shared domain
+// error taxonomy, reporting false without writing if err is not part of it.
+func RespDomainError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, shared.ErrNotFound):
+		RespErrorCode(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, shared.ErrConflict):
+		RespErrorCode(w, r, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, shared.ErrInvalidReference):
+		RespErrorCode(w, r, http.StatusUnprocessableEntity, CodeInvalidReference, err.Error())
+	case errors.Is(err, shared.ErrValidation):
+		problem := NewProblem(r, http.StatusUnprocessableEntity, CodeValidationFailed, err.Error())
+
+		var validationErr shared.ValidationError
+		if errors.As(err, &validationErr) {
+			problem.Errors = validationErr.FieldErrors()
+		}
+		RespProblem(w, r, problem)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// codeTitle derives the problem title from a code, such as "Not Found" for
+// "not_found".
+func codeTitle(code string) string {
+	words := strings.Split(code, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// statusErrorCode derives a code from the status text, such as "not_found"
+// for a 404.
+func statusErrorCode(statusCode int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(statusCode)), " ", "_")
 }
 
 // GenericNotFound writes a generic 404 error response.
@@ -39,3 +163,8 @@ func GenericInternalServerError(w http.ResponseWriter, r *http.Request) {
 func GenericUnauthorized(w http.ResponseWriter, r *http.Request) {
 	RespError(w, r, http.StatusUnauthorized, "unauthorized")
 }
+
+// GenericForbidden writes a generic 403 error response.
+func GenericForbidden(w http.ResponseWriter, r *http.Request) {
+	RespError(w, r, http.StatusForbidden, "forbidden")
+}
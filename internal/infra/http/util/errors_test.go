@@ -0,0 +1,100 @@
+package httputil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/log"
+	"greddit/internal/test"
+)
+
+func TestRespDomainError(t *testing.T) {
+	t.Run("validation failure lists fields", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", nil)
+		r = r.WithContext(log.CtxWithTraceID(r.Context()))
+		w := httptest.NewRecorder()
+
+		err := forum.PostValue{Title: "", Body: "body"}.Validate()
+		test.Assert(t, "Expected error to be written", RespDomainError(w, r, err))
+
+		res := w.Result()
+		defer res.Body.Close()
+
+		test.AssertEqual(t, "Unexpected status code", http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertEqual(t, "Unexpected content type", ProblemContentType, res.Header.Get("Content-Type"))
+
+		var got Problem
+		test.NilErr(t, json.NewDecoder(res.Body).Decode(&got))
+		test.AssertEqual(t, "Unexpected code", CodeValidationFailed, got.Code)
+		test.AssertEqual(t, "Unexpected title", "Validation Failed", got.Title)
+		test.AssertEqual(t, "Unexpected status", http.StatusUnprocessableEntity, got.Status)
+		test.AssertEqual(t, "Unexpected errors", []shared.FieldError{{
+			Field:  "title",
+			Reason: "title cannot be empty",
+		}}, got.Errors)
+
+		traceId, err := log.TraceIdFromCtx(r.Context())
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Unexpected instance", traceId.URN(), got.Instance)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/posts/x", nil)
+		w := httptest.NewRecorder()
+
+		test.Assert(t, "Expected error to be written",
+			RespDomainError(w, r, shared.NotFoundError{Entity: "post"}))
+
+		var got Problem
+		test.NilErr(t, json.NewDecoder(w.Body).Decode(&got))
+		test.AssertEqual(t, "Unexpected problem", Problem{
+			Type:   "/problems/not-found",
+			Title:  "Not Found",
+			Status: http.StatusNotFound,
+			Detail: "post not found",
+			Code:   CodeNotFound,
+		}, got)
+	})
+
+	t.Run("other errors are not written", func(t *testing.T) {
+		t.Parallel()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		test.Assert(t, "Expected error not to be written", !RespDomainError(w, r, http.ErrBodyNotAllowed))
+		test.AssertEqual(t, "Unexpected body", 0, w.Body.Len())
+	})
+}
+
+func TestRespExtendedProblem(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/posts", nil)
+	w := httptest.NewRecorder()
+
+	problem := NewProblem(r, http.StatusForbidden, "banned", "user is banned")
+	RespExtendedProblem(w, r, problem, struct {
+		Problem
+		Reason string `json:"reason"`
+	}{
+		Problem: problem,
+		Reason:  "Spam",
+	})
+
+	test.AssertEqual(t, "Unexpected status code", http.StatusForbidden, w.Code)
+	test.AssertEqual(t, "Unexpected content type", ProblemContentType, w.Header().Get("Content-Type"))
+
+	var got map[string]any
+	test.NilErr(t, json.NewDecoder(w.Body).Decode(&got))
+	test.AssertEqual(t, "Unexpected code", any("banned"), got["code"])
+	test.AssertEqual(t, "Unexpected extension member", any("Spam"), got["reason"])
+}
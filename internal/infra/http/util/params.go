@@ -0,0 +1,91 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// PathUuid parses the path value with the given name as a UUID.
+func PathUuid(r *http.Request, name string) (id uuid.UUID, err error) {
+	return uuid.Parse(r.PathValue(name))
+}
+
+// QueryInt parses the query parameter with the given key as an integer,
+// returning the default value if the parameter is not set.
+func QueryInt(r *http.Request, key string, defV int) (v int, err error) {
+	str := r.URL.Query().Get(key)
+	if str == "" {
+		return defV, nil
+	}
+
+	return strconv.Atoi(str)
+}
+
+// QueryBool parses the query parameter with the given key as a boolean,
+// returning the default value if the parameter is not set.
+func QueryBool(r *http.Request, key string, defV bool) (v bool, err error) {
+	str := r.URL.Query().Get(key)
+	if str == "" {
+		return defV, nil
+	}
+
+	return strconv.ParseBool(str)
+}
+
+// Pagination parses the limit and offset query parameters. The limit is
+// clamped to at most maxLimit.
+func Pagination(r *http.Request) (limit int, offset int, err error) {
+	limit, err = QueryLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err = QueryInt(r, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset, nil
+}
+
+// CursorPagination parses the limit and cursor query parameters. The limit is
+// clamped to at most maxLimit, and the cursor is empty for the first page.
+func CursorPagination(r *http.Request) (limit int, cursor string, err error) {
+	limit, err = QueryLimit(r)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return limit, r.URL.Query().Get("cursor"), nil
+}
+
+// QueryLimit parses the limit query parameter, clamped to at most maxLimit.
+func QueryLimit(r *http.Request) (limit int, err error) {
+	limit, err = QueryInt(r, "limit", defaultLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	if limit <= 0 {
+		limit = defaultLimit
+	} else if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return limit, nil
+}
+
+// PathInt parses the path value with the given name as an integer.
+func PathInt(r *http.Request, name string) (v int, err error) {
+	return strconv.Atoi(r.PathValue(name))
+}
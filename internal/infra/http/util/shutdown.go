@@ -0,0 +1,21 @@
+package httputil
+
+import "context"
+
+// shutdownKey is the key of the shutdown channel in a context.
+type shutdownKey struct{}
+
+// CtxWithShutdown returns a context carrying the channel closed when the
+// server starts shutting down.
+func CtxWithShutdown(ctx context.Context, ch <-chan struct{}) context.Context {
+	return context.WithValue(ctx, shutdownKey{}, ch)
+}
+
+// ShutdownFromCtx returns the channel closed when the server starts shutting
+// down. Long-lived responses such as streams should end once it is closed, as
+// the server waits for them before shutting down. Returns a nil channel, which
+// is never closed, if the context carries none.
+func ShutdownFromCtx(ctx context.Context) <-chan struct{} {
+	ch, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return ch
+}
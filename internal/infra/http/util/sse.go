@@ -0,0 +1,78 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SSEContentType is the content type of server-sent event streams.
+const SSEContentType = "text/event-stream"
+
+// SSEWriter writes server-sent events, flushing each one to the client.
+type SSEWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewSSEWriter writes the headers of an event stream, returning an error if
+// the response cannot be flushed.
+func NewSSEWriter(w http.ResponseWriter) (sse *SSEWriter, err error) {
+	sse = &SSEWriter{
+		w:  w,
+		rc: http.NewResponseController(w),
+	}
+
+	w.Header().Set("Content-Type", SSEContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disables response buffering by reverse proxies such as nginx.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	err = sse.rc.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return sse, nil
+}
+
+// WriteEvent writes an event with the ID, event type and data. Each line of
+// the data is written as a separate data field.
+func (s *SSEWriter) WriteEvent(id string, event string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for line := range strings.Lines(string(data)) {
+		fmt.Fprintf(&b, "data: %s\n", strings.TrimRight(line, "\r\n"))
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// WriteRetry tells the client how long to wait before reconnecting.
+func (s *SSEWriter) WriteRetry(delay time.Duration) error {
+	return s.write(fmt.Sprintf("retry: %d\n\n", delay.Milliseconds()))
+}
+
+// WriteComment writes a comment, which clients ignore, such as to keep the
+// connection alive.
+func (s *SSEWriter) WriteComment(comment string) error {
+	return s.write(": " + comment + "\n\n")
+}
+
+// write writes the raw text and flushes it.
+func (s *SSEWriter) write(text string) error {
+	_, err := s.w.Write([]byte(text))
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
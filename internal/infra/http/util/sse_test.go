@@ -0,0 +1,39 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"greddit/internal/test"
+)
+
+func TestSSEWriter(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+
+	sse, err := NewSSEWriter(w)
+	test.NilErr(t, err)
+	test.NilErr(t, sse.WriteRetry(3*time.Second))
+	test.NilErr(t, sse.WriteEvent("42", "post_created", []byte("{\"id\":1}")))
+	test.NilErr(t, sse.WriteEvent("", "", []byte("first\nsecond")))
+	test.NilErr(t, sse.WriteComment("ping"))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	test.AssertEqual(t, "Unexpected status code", http.StatusOK, res.StatusCode)
+	test.AssertEqual(t, "Unexpected content type", SSEContentType, res.Header.Get("Content-Type"))
+	test.AssertEqual(t, "Unexpected cache control", "no-cache", res.Header.Get("Cache-Control"))
+	test.Assert(t, "Expected response to be flushed", w.Flushed)
+
+	body, err := io.ReadAll(res.Body)
+	test.NilErr(t, err)
+	test.AssertEqual(t, "Unexpected body", "retry: 3000\n\n"+
+		"id: 42\nevent: post_created\ndata: {\"id\":1}\n\n"+
+		"data: first\ndata: second\n\n"+
+		": ping\n\n", string(body))
+}
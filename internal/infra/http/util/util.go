@@ -33,3 +33,10 @@ func WriteJsonFull(w http.ResponseWriter, status int, value any) error {
 	}
 	return err
 }
+
+// ReadJson decodes the JSON request body into the value, closing the body.
+func ReadJson(r *http.Request, value any) error {
+	defer r.Body.Close()
+
+	return json.NewDecoder(r.Body).Decode(value)
+}
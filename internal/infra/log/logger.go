@@ -36,7 +36,7 @@ func (h *Handler) AddHandler(handler slog.Handler) {
 // Handle handles a log record.
 func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
 	for _, sh := range h.subHandlers {
-		traceId, err := traceIdFromCtx(ctx)
+		traceId, err := TraceIdFromCtx(ctx)
 		if err == nil {
 			r.Add("traceId", traceId.String())
 		}
@@ -27,8 +27,8 @@ func CtxWithTraceID(ctx context.Context) context.Context {
 	return context.WithValue(ctx, ctxTraceID, traceId)
 }
 
-// traceIdFromCtx returns the trace id from the context.
-func traceIdFromCtx(ctx context.Context) (*uuid.UUID, error) {
+// TraceIdFromCtx returns the trace id from the context.
+func TraceIdFromCtx(ctx context.Context) (*uuid.UUID, error) {
 	raw := ctx.Value(ctxTraceID)
 	if raw == nil {
 		return nil, fmt.Errorf("trace id not found in context")
@@ -0,0 +1,37 @@
+package blobports
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore stores immutable blobs by key. Keys are made of ASCII letters,
+// digits, dots, dashes and underscores, and do not start with a dot.
+type BlobStore interface {
+	// Put stores the size bytes read from r under the key, replacing any blob
+	// stored under it.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error)
+
+	// Open opens the blob stored under the key for reading. Returns a
+	// shared.NotFoundError if there is none.
+	Open(ctx context.Context, key string) (blob io.ReadSeekCloser, err error)
+
+	// Delete deletes the blob stored under the key, if any.
+	Delete(ctx context.Context, key string) (err error)
+}
+
+// ValidKey returns whether the key may be used to store a blob.
+func ValidKey(key string) bool {
+	if key == "" || key[0] == '.' || len(key) > 255 {
+		return false
+	}
+
+	for _, c := range key {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -5,22 +5,33 @@ import (
 	"time"
 
 	"greddit/internal/domains/auth"
+
+	dbports "greddit/internal/ports/db"
 )
 
-// UsersRepo is a repository for users.
+// UsersRepo is a repository for users. Reads exclude soft-deleted rows unless
+// dbports.IncludeDeleted is given, and return a shared.NotFoundError when no
+// row matches.
 type UsersRepo interface {
 	// CreateUser creates a user.
 	CreateUser(ctx context.Context, value auth.UserValue) (user *auth.User, err error)
 
 	// GetUserById returns a user by its ID.
-	GetUserById(ctx context.Context, id auth.UserId) (user *auth.User, err error)
+	GetUserById(ctx context.Context, id auth.UserId, opts ...dbports.ReadOption) (user *auth.User, err error)
 
 	// GetUserByUsername returns a user by its username.
-	GetUserByUsername(ctx context.Context, username string) (user *auth.User, err error)
+	GetUserByUsername(ctx context.Context, username string, opts ...dbports.ReadOption) (user *auth.User, err error)
 
 	// UpdateDisplayName updates the display name of a user.
 	UpdateDisplayName(ctx context.Context, id auth.UserId, displayName string) (updatedAt *time.Time, err error)
 
-	// DeleteUser soft deletes a user.
+	// DeleteUser soft deletes a user who is not yet deleted.
 	DeleteUser(ctx context.Context, id auth.UserId) (deletedAt *time.Time, err error)
+
+	// GetDeletedUsers returns the soft-deleted users, most recently deleted first.
+	GetDeletedUsers(ctx context.Context, limit int, offset int) (users []auth.User, err error)
+
+	// RestoreUser restores a soft-deleted user, leaving their updated timestamp
+	// as is.
+	RestoreUser(ctx context.Context, id auth.UserId) (updatedAt *time.Time, err error)
 }
@@ -0,0 +1,53 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// AttachmentsRepo is a repository for the attachments of posts and comments,
+// and the blobs holding their content. Blobs are created and deleted within
+// the transaction storing or deleting their content, which their rows are
+// locked for.
+type AttachmentsRepo interface {
+	// CreateBlob creates a blob if it does not exist, and locks it until the
+	// end of the transaction. Returns whether the blob was created, in which
+	// case its content is expected to be stored before committing. Purging
+	// blobs are created again.
+	CreateBlob(ctx context.Context, blob forum.Blob) (created bool, err error)
+
+	// LockBlob returns a blob, locking it until the end of the transaction.
+	LockBlob(ctx context.Context, sha256 string) (blob *forum.Blob, err error)
+
+	// UpdateBlob updates the size, status, dimensions and thumbnail of a
+	// blob.
+	UpdateBlob(ctx context.Context, blob forum.Blob) (err error)
+
+	// DeleteUnusedBlob deletes a blob if no attachment uses it, locking it
+	// until the end of the transaction. Returns whether the blob was deleted,
+	// in which case its content is expected to be deleted before committing.
+	DeleteUnusedBlob(ctx context.Context, sha256 string) (deleted bool, err error)
+
+	// CreateAttachment creates an attachment to the blob, which must exist.
+	CreateAttachment(ctx context.Context, uploaderId auth.UserId, value forum.AttachmentValue, blob forum.Blob) (
+		attachment *forum.Attachment, err error,
+	)
+
+	// GetAttachmentById returns an attachment by its ID.
+	GetAttachmentById(ctx context.Context, id forum.AttachmentId) (attachment *forum.Attachment, err error)
+
+	// GetAttachmentsByPosts returns the attachments of the posts sorted by
+	// creation date.
+	GetAttachmentsByPosts(ctx context.Context, postIds []forum.PostId) (attachments []forum.Attachment, err error)
+
+	// GetAttachmentsByComments returns the attachments of the comments sorted
+	// by creation date.
+	GetAttachmentsByComments(ctx context.Context, commentIds []forum.CommentId) (
+		attachments []forum.Attachment, err error,
+	)
+
+	// DeleteAttachment deletes an attachment, leaving its blob.
+	DeleteAttachment(ctx context.Context, id forum.AttachmentId) (err error)
+}
@@ -0,0 +1,38 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// BansRepo is a repository for the users banned from communities and the
+// users suspended from the forum. Expired bans and suspensions are not found,
+// although they are only deleted by the purge.
+type BansRepo interface {
+	// BanUser bans the user from the community, replacing any existing ban.
+	BanUser(ctx context.Context, communityId forum.CommunityId, userId auth.UserId, moderatorId auth.UserId,
+		value forum.BanValue) (ban *forum.Ban, err error)
+
+	// UnbanUser lifts the ban of the user from the community, returning
+	// whether the user was banned.
+	UnbanUser(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (removed bool, err error)
+
+	// GetBan returns the ban of the user from the community.
+	GetBan(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (ban *forum.Ban, err error)
+
+	// GetBans returns the bans of the community, most recent first.
+	GetBans(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (bans []forum.Ban, err error)
+
+	// SuspendUser suspends the user, replacing any existing suspension.
+	SuspendUser(ctx context.Context, userId auth.UserId, adminId auth.UserId, value forum.BanValue) (
+		suspension *forum.Suspension, err error)
+
+	// LiftSuspension lifts the suspension of the user, returning whether the
+	// user was suspended.
+	LiftSuspension(ctx context.Context, userId auth.UserId) (removed bool, err error)
+
+	// GetSuspension returns the suspension of the user.
+	GetSuspension(ctx context.Context, userId auth.UserId) (suspension *forum.Suspension, err error)
+}
@@ -7,28 +7,49 @@ import (
 	"greddit/internal/domains/auth"
 
 	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
 )
 
-// CommentsRepo is a repository for comments.
+// CommentsRepo is a repository for comments. Reads exclude soft-deleted rows unless
+// dbports.IncludeDeleted is given, and return a shared.NotFoundError when no
+// row matches. Reads exclude comments in private communities, unless the
+// viewer given with dbports.VisibleTo may see them or dbports.IncludePrivate
+// is given.
 type CommentsRepo interface {
-	// CreateComment creates a comment.
-	CreateComment(ctx context.Context, postId forum.PostId, commenterId auth.UserId, value forum.CommentValue) (
-		comment *forum.Comment, err error)
+	// CreateComment creates a comment. The parent ID is nil for top level comments.
+	CreateComment(ctx context.Context, postId forum.PostId, commenterId auth.UserId, value forum.CommentValue,
+		parentId *forum.CommentId) (comment *forum.Comment, err error)
 
 	// GetCommentById returns a comment by its ID.
-	GetCommentById(ctx context.Context, id forum.CommentId) (comment *forum.Comment, err error)
+	GetCommentById(ctx context.Context, id forum.CommentId, opts ...dbports.ReadOption) (comment *forum.Comment, err error)
 
 	// GetCommentsByPostSortedCreatedAt returns all comments in a post sorted by creation date.
-	GetCommentsByPostSortedCreatedAt(ctx context.Context, postId forum.PostId, limit int, offset int) (
-		comments []forum.Comment, err error)
+	GetCommentsByPostSortedCreatedAt(ctx context.Context, postId forum.PostId, limit int, offset int,
+		opts ...dbports.ReadOption) (comments []forum.Comment, err error)
 
 	// GetCommentsByCommenterSortedCreatedAt returns all comments by a commenter sorted by creation date.
-	GetCommentsByCommenterSortedCreatedAt(ctx context.Context, commenterId auth.UserId, limit int, offset int) (
-		comments []forum.Comment, err error)
+	GetCommentsByCommenterSortedCreatedAt(ctx context.Context, commenterId auth.UserId, limit int, offset int,
+		opts ...dbports.ReadOption) (comments []forum.Comment, err error)
 
 	// UpdateCommentBody updates the body of a comment.
 	UpdateCommentBody(ctx context.Context, id forum.CommentId, body string) (updatedAt *time.Time, err error)
 
-	// DeleteComment soft deletes a comment.
+	// DeleteComment soft deletes a comment which is not yet deleted.
 	DeleteComment(ctx context.Context, id forum.CommentId) (deletedAt *time.Time, err error)
+
+	// GetDeletedComments returns the soft-deleted comments, most recently deleted
+	// first. Comments by all commenters are returned if the commenter ID is nil.
+	GetDeletedComments(ctx context.Context, commenterId *auth.UserId, limit int, offset int) (
+		comments []forum.Comment, err error)
+
+	// RestoreComment restores a soft-deleted comment, leaving its updated
+	// timestamp as is.
+	RestoreComment(ctx context.Context, id forum.CommentId) (updatedAt *time.Time, err error)
+
+	// RemoveComment removes a comment from its thread for the reason.
+	RemoveComment(ctx context.Context, id forum.CommentId, reason string) (removedAt *time.Time, err error)
+
+	// ApproveComment reinstates a comment, clearing any removal.
+	ApproveComment(ctx context.Context, id forum.CommentId) (err error)
 }
@@ -5,34 +5,54 @@ import (
 	"time"
 
 	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
 )
 
-// CommunitiesRepo is a repository for communities.
+// CommunitiesRepo is a repository for communities. Reads exclude soft-deleted rows unless
+// dbports.IncludeDeleted is given, and return a shared.NotFoundError when no
+// row matches. Reads exclude private communities, unless the viewer given with
+// dbports.VisibleTo may see them or dbports.IncludePrivate is given.
 type CommunitiesRepo interface {
-	// CreateCommunity creates a community.
+	// CreateCommunity creates a community with the default settings.
 	CreateCommunity(ctx context.Context, value forum.CommunityValue) (community *forum.Community, err error)
 
 	// GetCommunityById returns a community by its ID.
-	GetCommunityById(ctx context.Context, id forum.CommunityId) (community *forum.Community, err error)
+	GetCommunityById(ctx context.Context, id forum.CommunityId, opts ...dbports.ReadOption) (
+		community *forum.Community, err error)
 
-	// GetAllCommunitiesSortedByName returns all communities sorted by name. Note
-	// that soft-deleted communities are not returned.
-	GetAllCommunitiesSortedByName(ctx context.Context, limit int, offset int) (
+	// GetAllCommunitiesSortedByName returns all communities sorted by name.
+	GetAllCommunitiesSortedByName(ctx context.Context, limit int, offset int, opts ...dbports.ReadOption) (
 		communities []forum.Community, err error)
 
 	// GetAllCommunitiesSortedByCreatedAt returns all communities sorted by creation
-	// date. Note that soft-deleted communities are not returned.
-	GetAllCommunitiesSortedByCreatedAt(ctx context.Context, limit int, offset int) (
+	// date.
+	GetAllCommunitiesSortedByCreatedAt(ctx context.Context, limit int, offset int, opts ...dbports.ReadOption) (
 		communities []forum.Community, err error)
 
 	// GetAllCommunitiesSortedByUpdatedAt returns all communities sorted by update
-	// date. Note that soft-deleted communities are not returned.
-	GetAllCommunitiesSortedByUpdatedAt(ctx context.Context, limit int, offset int) (
+	// date.
+	GetAllCommunitiesSortedByUpdatedAt(ctx context.Context, limit int, offset int, opts ...dbports.ReadOption) (
 		communities []forum.Community, err error)
 
 	// UpdateCommunityDescription updates the description of a community.
 	UpdateCommunityDescription(ctx context.Context, id forum.CommunityId, description string) (updatedAt *time.Time, err error)
 
-	// DeleteCommunity soft deletes a community.
+	// UpdateCommunitySettings replaces the settings of a community.
+	UpdateCommunitySettings(ctx context.Context, id forum.CommunityId, settings forum.CommunitySettings) (
+		updatedAt *time.Time, err error)
+
+	// DeleteCommunity soft deletes a community, along with the posts in it which
+	// are not yet deleted. Returns a shared.NotFoundError if the community is
+	// already deleted, rather than restarting its retention period.
 	DeleteCommunity(ctx context.Context, id forum.CommunityId) (deletedAt *time.Time, err error)
+
+	// GetDeletedCommunities returns the soft-deleted communities, most recently
+	// deleted first.
+	GetDeletedCommunities(ctx context.Context, limit int, offset int) (communities []forum.Community, err error)
+
+	// RestoreCommunity restores a soft-deleted community, along with the posts
+	// which were deleted with it. Posts deleted individually remain deleted.
+	// The updated timestamp is left as is, as a restore is not an edit.
+	RestoreCommunity(ctx context.Context, id forum.CommunityId) (updatedAt *time.Time, err error)
 }
@@ -0,0 +1,38 @@
+package dbportsforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+)
+
+// EventsRepo is a repository for the events streamed to clients, announcing
+// each published event to every listener once its transaction commits.
+// Reads return a shared.NotFoundError when no row matches.
+type EventsRepo interface {
+	// PublishEvent stores the event and announces it to the listeners.
+	PublishEvent(ctx context.Context, value forum.EventValue) (event *forum.Event, err error)
+
+	// GetEventById returns an event by its ID.
+	GetEventById(ctx context.Context, id forum.EventId) (event *forum.Event, err error)
+
+	// GetEventsAfter returns the events with IDs after the given ID, sorted
+	// by ID.
+	GetEventsAfter(ctx context.Context, afterId forum.EventId, limit int) (events []forum.Event, err error)
+
+	// GetMatchingEventsAfter returns the events matching the filter with IDs
+	// after the given ID, sorted by ID.
+	GetMatchingEventsAfter(ctx context.Context, afterId forum.EventId, filter forum.EventFilter, limit int) (
+		events []forum.Event, err error)
+
+	// DeleteEventsBefore deletes the events published before the cutoff,
+	// returning how many were deleted.
+	DeleteEventsBefore(ctx context.Context, before time.Time) (count int64, err error)
+
+	// Listen calls the handler with the ID of every event announced while
+	// listening, in the order announced. The listening function is called
+	// once announcements are being received, before any is handled. It blocks
+	// until the context is cancelled or the connection fails.
+	Listen(ctx context.Context, listening func(), handle func(id forum.EventId)) (err error)
+}
@@ -0,0 +1,30 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+)
+
+// FlairsRepo is a repository for the flairs defined by communities. Reads
+// return a shared.NotFoundError when no row matches.
+type FlairsRepo interface {
+	// CreateFlair creates a flair in the community.
+	CreateFlair(ctx context.Context, communityId forum.CommunityId, value forum.FlairValue) (flair *forum.Flair, err error)
+
+	// GetFlairById returns a flair by its ID.
+	GetFlairById(ctx context.Context, id forum.FlairId) (flair *forum.Flair, err error)
+
+	// GetFlairsByIds returns the flairs with the given IDs, in no particular
+	// order. Missing flairs are left out.
+	GetFlairsByIds(ctx context.Context, ids []forum.FlairId) (flairs []forum.Flair, err error)
+
+	// GetFlairsByCommunity returns the flairs of the community sorted by text.
+	GetFlairsByCommunity(ctx context.Context, communityId forum.CommunityId) (flairs []forum.Flair, err error)
+
+	// UpdateFlair replaces the value of a flair.
+	UpdateFlair(ctx context.Context, id forum.FlairId, value forum.FlairValue) (err error)
+
+	// DeleteFlair deletes a flair, removing it from the posts carrying it.
+	DeleteFlair(ctx context.Context, id forum.FlairId) (err error)
+}
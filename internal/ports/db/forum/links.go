@@ -0,0 +1,52 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// LinksRepo is a repository for wiki-style links between posts. Reads treat
+// links to posts in communities not visible to the viewer given with
+// dbports.VisibleTo as dangling, and exclude links from them. Private
+// communities are only visible to their members and moderators, unless
+// dbports.IncludePrivate is given.
+type LinksRepo interface {
+	// ReplacePostLinks replaces the links from the body of a post, resolving
+	// each link to a post where possible.
+	ReplacePostLinks(ctx context.Context, postId forum.PostId, links []forum.WikiLink) (err error)
+
+	// ReplaceCommentLinks replaces the links from the body of a comment,
+	// resolving each link to a post where possible.
+	ReplaceCommentLinks(ctx context.Context, postId forum.PostId, commentId forum.CommentId, links []forum.WikiLink) (
+		err error)
+
+	// ResolveDanglingLinks resolves the dangling links matching the title of
+	// the post, i.e. after the post has been created.
+	ResolveDanglingLinks(ctx context.Context, postId forum.PostId) (err error)
+
+	// GetLinksByPosts returns the links from the bodies of the posts. Links to
+	// soft-deleted posts are returned as dangling.
+	GetLinksByPosts(ctx context.Context, postIds []forum.PostId, opts ...dbports.ReadOption) (
+		links []forum.Link, err error)
+
+	// GetLinksByComments returns the links from the bodies of the comments.
+	// Links to soft-deleted posts are returned as dangling.
+	GetLinksByComments(ctx context.Context, commentIds []forum.CommentId, opts ...dbports.ReadOption) (
+		links []forum.Link, err error)
+
+	// GetBacklinks returns the links to a post from other posts and comments,
+	// sorted by creation date. Links from soft-deleted content are not
+	// returned, nor are links from removed content unless
+	// dbports.IncludeRemoved is given.
+	GetBacklinks(ctx context.Context, postId forum.PostId, opts ...dbports.ReadOption) (
+		backlinks []forum.Backlink, err error)
+
+	// GetDanglingLinks returns all links which do not resolve to a post, sorted
+	// by creation date. Links from soft-deleted content are not returned, nor
+	// are links from removed content unless dbports.IncludeRemoved is given.
+	GetDanglingLinks(ctx context.Context, limit int, offset int, opts ...dbports.ReadOption) (
+		links []forum.Link, err error)
+}
@@ -0,0 +1,47 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// MembersRepo is a repository for the members of communities and the pending
+// invites to become one. Reads return a shared.NotFoundError when no row
+// matches.
+type MembersRepo interface {
+	// AddMember makes the user a member of the community, returning the
+	// existing membership if the user is already a member.
+	AddMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (member *forum.Member, err error)
+
+	// RemoveMember removes the user from the members of the community,
+	// returning whether the user was a member.
+	RemoveMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (removed bool, err error)
+
+	// IsMember returns whether the user is a member of the community.
+	IsMember(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (ok bool, err error)
+
+	// GetMembers returns the members of the community, earliest first.
+	GetMembers(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+		members []forum.Member, err error)
+
+	// CreateInvite invites the user to the community, returning the existing
+	// invite if the user is already invited.
+	CreateInvite(ctx context.Context, communityId forum.CommunityId, inviteeId auth.UserId, inviterId auth.UserId) (
+		invite *forum.Invite, err error)
+
+	// DeleteInvite deletes the invite of the user to the community, returning
+	// whether the user was invited.
+	DeleteInvite(ctx context.Context, communityId forum.CommunityId, inviteeId auth.UserId) (removed bool, err error)
+
+	// GetInvitesByCommunity returns the pending invites to the community, most
+	// recent first.
+	GetInvitesByCommunity(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+		invites []forum.Invite, err error)
+
+	// GetInvitesByInvitee returns the pending invites of the user to live
+	// communities, most recent first.
+	GetInvitesByInvitee(ctx context.Context, inviteeId auth.UserId, limit int, offset int) (
+		invites []forum.Invite, err error)
+}
@@ -0,0 +1,39 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// ModerationRepo is a repository for the moderators of communities and the
+// log of their actions.
+type ModerationRepo interface {
+	// SetModerator makes the user a moderator of the community with the role,
+	// or changes the role of an existing moderator. Giving the owner role to
+	// a user while the community has another owner returns a
+	// shared.ConflictError.
+	SetModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId, role forum.ModeratorRole) (
+		moderator *forum.Moderator, err error)
+
+	// GetModerator returns the moderator of the community with the user ID.
+	GetModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (
+		moderator *forum.Moderator, err error)
+
+	// GetModerators returns the moderators of the community, starting with
+	// the owner, then in the order they were added.
+	GetModerators(ctx context.Context, communityId forum.CommunityId) (moderators []forum.Moderator, err error)
+
+	// RemoveModerator removes the user from the moderators of the community,
+	// returning whether the user was a moderator.
+	RemoveModerator(ctx context.Context, communityId forum.CommunityId, userId auth.UserId) (removed bool, err error)
+
+	// LogAction records the action in the moderation log of its community.
+	LogAction(ctx context.Context, value forum.ModerationLogValue) (entry *forum.ModerationLogEntry, err error)
+
+	// GetModerationLog returns the moderation log of the community, most
+	// recent first.
+	GetModerationLog(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (
+		entries []forum.ModerationLogEntry, err error)
+}
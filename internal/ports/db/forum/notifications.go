@@ -0,0 +1,51 @@
+package dbportsforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// NotificationsRepo is a repository for the notifications of users and their
+// preferences. Reads return a shared.NotFoundError when no row matches.
+// Listings exclude notifications of content in private communities, unless the
+// viewer given with dbports.VisibleTo may see them or dbports.IncludePrivate
+// is given.
+type NotificationsRepo interface {
+	// Notify creates a notification unless the recipient has muted its kind,
+	// may not see the community of the post as a member or was already
+	// notified of the comment, in which case the returned notification is
+	// nil.
+	Notify(ctx context.Context, value forum.NotificationValue) (notification *forum.Notification, err error)
+
+	// GetNotificationById returns a notification by its ID.
+	GetNotificationById(ctx context.Context, id forum.NotificationId) (notification *forum.Notification, err error)
+
+	// GetNotifications returns the notifications of the user, newest first.
+	// Notifications of soft-deleted comments are excluded.
+	GetNotifications(ctx context.Context, recipientId auth.UserId, unreadOnly bool, limit int, offset int,
+		opts ...dbports.ReadOption) (notifications []forum.Notification, err error)
+
+	// CountUnread returns the number of unread notifications of the user,
+	// excluding those of soft-deleted comments.
+	CountUnread(ctx context.Context, recipientId auth.UserId, opts ...dbports.ReadOption) (count int, err error)
+
+	// MarkRead marks a notification as read, keeping the time it was first
+	// read.
+	MarkRead(ctx context.Context, id forum.NotificationId) (readAt *time.Time, err error)
+
+	// MarkAllRead marks all unread notifications of the user as read,
+	// returning how many were marked.
+	MarkAllRead(ctx context.Context, recipientId auth.UserId) (count int64, err error)
+
+	// GetPreferences returns the notification preferences of the user, or
+	// the defaults if the user has not set any.
+	GetPreferences(ctx context.Context, userId auth.UserId) (preferences forum.NotificationPreferences, err error)
+
+	// UpdatePreferences replaces the notification preferences of the user.
+	UpdatePreferences(ctx context.Context, userId auth.UserId, preferences forum.NotificationPreferences) (err error)
+}
@@ -0,0 +1,36 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// PollsRepo is a repository for the polls of poll posts and their votes.
+type PollsRepo interface {
+	// CreatePoll creates the poll of a post, with its options in the given
+	// order.
+	CreatePoll(ctx context.Context, postId forum.PostId, value forum.PollValue) (poll *forum.Poll, err error)
+
+	// GetPollByPost returns the poll of a post.
+	GetPollByPost(ctx context.Context, postId forum.PostId) (poll *forum.Poll, err error)
+
+	// GetPollsByPosts returns the polls of the posts. Posts without a poll are
+	// left out.
+	GetPollsByPosts(ctx context.Context, postIds []forum.PostId) (polls []forum.Poll, err error)
+
+	// GetPollVotes returns the options the user voted for in the polls of each
+	// of the posts. Polls the user has not voted in are left out.
+	GetPollVotes(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+		votes map[forum.PostId][]forum.PollOptionId, err error,
+	)
+
+	// CastPollVote records the vote of the user for the options of the poll,
+	// and increments their counts. Returns a shared.ConflictError if the user
+	// has already voted in the poll. Must be run within a transaction, so that
+	// the counts are only incremented along with the vote.
+	CastPollVote(ctx context.Context, postId forum.PostId, userId auth.UserId, optionIds []forum.PollOptionId) (
+		err error,
+	)
+}
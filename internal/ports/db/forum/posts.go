@@ -7,22 +7,76 @@ import (
 	"greddit/internal/domains/auth"
 
 	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
 )
 
-// PostsRepo is a repository for posts.
+// PostsRepo is a repository for posts. Reads exclude soft-deleted rows unless
+// dbports.IncludeDeleted is given, and return a shared.NotFoundError when no
+// row matches. Listings exclude posts removed by moderators unless
+// dbports.IncludeRemoved is given. Reads exclude posts in private communities,
+// unless the viewer given with dbports.VisibleTo may see them or
+// dbports.IncludePrivate is given.
 type PostsRepo interface {
 	// CreatePost creates a post.
 	CreatePost(ctx context.Context, communityId forum.CommunityId, posterId auth.UserId, value forum.PostValue) (post *forum.Post, err error)
 
 	// GetPostById returns a post by its ID.
-	GetPostById(ctx context.Context, id forum.PostId) (post *forum.Post, err error)
+	GetPostById(ctx context.Context, id forum.PostId, opts ...dbports.ReadOption) (post *forum.Post, err error)
+
+	// GetPostsByCommunitySortedCreatedAt returns all posts in a community matching the filter sorted by creation
+	// date, after the pinned posts in the order they were pinned.
+	GetPostsByCommunitySortedCreatedAt(ctx context.Context, communityId forum.CommunityId, filter forum.PostFilter,
+		limit int, offset int, opts ...dbports.ReadOption) (posts []forum.Post, err error)
 
-	// GetPostsByCommunitySortedCreatedAt returns all posts in a community sorted by creation date.
-	GetPostsByCommunitySortedCreatedAt(ctx context.Context, communityId forum.CommunityId, limit int, offset int) (posts []forum.Post, err error)
+	// GetFeedPosts returns a page of live, unremoved posts matching the filter in
+	// the given sort, starting after the cursor if given. Only posts in
+	// communities the subscriber is subscribed to are returned, or posts in all
+	// communities if the subscriber is nil.
+	GetFeedPosts(ctx context.Context, subscriberId *auth.UserId, sort forum.FeedSort, filter forum.PostFilter,
+		cursor *forum.FeedCursor, limit int, opts ...dbports.ReadOption) (posts []forum.Post, err error)
 
 	// UpdatePostContent updates the content of a post.
 	UpdatePostContent(ctx context.Context, id forum.PostId, content string) (updatedAt *time.Time, err error)
 
-	// DeletePost soft deletes a post.
+	// UpdatePostTitle updates the title of a post, recording a redirect from the
+	// previous title.
+	UpdatePostTitle(ctx context.Context, id forum.PostId, title string) (updatedAt *time.Time, err error)
+
+	// MovePost moves a post to another community, recording a redirect from the
+	// previous community.
+	MovePost(ctx context.Context, id forum.PostId, communityId forum.CommunityId) (updatedAt *time.Time, err error)
+
+	// SetPostFlair sets the flair of a post, or clears it if the flair ID is
+	// nil.
+	SetPostFlair(ctx context.Context, id forum.PostId, flairId *forum.FlairId) (err error)
+
+	// DeletePost soft deletes a post which is not yet deleted.
 	DeletePost(ctx context.Context, id forum.PostId) (deletedAt *time.Time, err error)
+
+	// GetDeletedPosts returns the posts which were soft deleted individually,
+	// most recently deleted first. Posts by all posters are returned if the
+	// poster ID is nil.
+	GetDeletedPosts(ctx context.Context, posterId *auth.UserId, limit int, offset int) (posts []forum.Post, err error)
+
+	// RestorePost restores a soft-deleted post, leaving its updated timestamp
+	// as is.
+	RestorePost(ctx context.Context, id forum.PostId) (updatedAt *time.Time, err error)
+
+	// RemovePost removes a post from its community for the reason.
+	RemovePost(ctx context.Context, id forum.PostId, reason string) (removedAt *time.Time, err error)
+
+	// ApprovePost reinstates a post, clearing any removal.
+	ApprovePost(ctx context.Context, id forum.PostId) (err error)
+
+	// SetPostLocked locks or unlocks a post, returning when it was locked or
+	// nil if unlocked. Locking a locked post keeps its lock time.
+	SetPostLocked(ctx context.Context, id forum.PostId, locked bool) (lockedAt *time.Time, err error)
+
+	// SetPostPinned pins or unpins a post, returning when it was pinned or nil
+	// if unpinned. Pinning a pinned post keeps its pin time.
+	SetPostPinned(ctx context.Context, id forum.PostId, pinned bool) (pinnedAt *time.Time, err error)
+
+	// CountPinnedPosts returns the number of live posts pinned in a community.
+	CountPinnedPosts(ctx context.Context, communityId forum.CommunityId) (count int, err error)
 }
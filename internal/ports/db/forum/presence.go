@@ -0,0 +1,43 @@
+package dbportsforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	"github.com/google/uuid"
+)
+
+// PresenceRepo is a repository for the live sessions viewing posts. Joining
+// and leaving a post announces the change to every listener once committed.
+type PresenceRepo interface {
+	// Join records the session of the user as viewing the post.
+	Join(ctx context.Context, sessionId uuid.UUID, postId forum.PostId, userId auth.UserId) (err error)
+
+	// Leave records the session as no longer viewing the post.
+	Leave(ctx context.Context, sessionId uuid.UUID, postId forum.PostId) (err error)
+
+	// LeaveAll records the session as no longer viewing any post.
+	LeaveAll(ctx context.Context, sessionId uuid.UUID) (err error)
+
+	// Touch marks the sessions as still viewing their posts.
+	Touch(ctx context.Context, sessionIds []uuid.UUID) (err error)
+
+	// CountViewers returns the number of distinct users viewing each of the
+	// posts in sessions seen since the cutoff. Posts without viewers are left
+	// out.
+	CountViewers(ctx context.Context, postIds []forum.PostId, since time.Time) (
+		viewers map[forum.PostId]int, err error)
+
+	// DeleteStale deletes the sessions last seen before the cutoff, returning
+	// how many were deleted.
+	DeleteStale(ctx context.Context, before time.Time) (count int64, err error)
+
+	// Listen calls the handler with the ID of every post whose viewers change
+	// while listening. The listening function is called once changes are
+	// being received, before any is handled. It blocks until the context is
+	// cancelled or the connection fails.
+	Listen(ctx context.Context, listening func(), handle func(postId forum.PostId)) (err error)
+}
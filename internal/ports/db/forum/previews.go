@@ -0,0 +1,29 @@
+package dbportsforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+)
+
+// LinkPreviewsRepo is a repository for the previews of the URLs of link
+// posts, which are fetched in the background.
+type LinkPreviewsRepo interface {
+	// RequestLinkPreview requests the preview of a URL, creating a pending
+	// preview if the URL has none yet.
+	RequestLinkPreview(ctx context.Context, url string) (err error)
+
+	// GetLinkPreviewsByUrls returns the previews of the URLs. URLs without a
+	// preview are left out.
+	GetLinkPreviewsByUrls(ctx context.Context, urls []string) (previews []forum.LinkPreview, err error)
+
+	// GetDueLinkPreviewUrls returns the URLs whose previews are due to be
+	// fetched, oldest request first. Previews are due if they were never
+	// fetched, or if they were fetched before staleBefore and requested since.
+	GetDueLinkPreviewUrls(ctx context.Context, staleBefore time.Time, limit int) (urls []string, err error)
+
+	// SaveLinkPreview stores the result of fetching a preview. Returns a
+	// shared.NotFoundError if the URL has no preview.
+	SaveLinkPreview(ctx context.Context, preview forum.LinkPreview) (err error)
+}
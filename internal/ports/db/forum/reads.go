@@ -0,0 +1,33 @@
+package dbportsforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// ReadsRepo is a repository for the times users last saw posts. A post counts
+// as seen at the later of when it was last marked as read and when its
+// community was last marked as read.
+type ReadsRepo interface {
+	// MarkPostRead marks the post as seen by the user now.
+	MarkPostRead(ctx context.Context, userId auth.UserId, postId forum.PostId) (lastSeenAt *time.Time, err error)
+
+	// MarkCommunityRead marks all posts in the community as seen by the user
+	// now.
+	MarkCommunityRead(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+		readAt *time.Time, err error)
+
+	// GetLastSeenByPosts returns when the user last saw each of the posts.
+	// Posts the user has never seen are left out.
+	GetLastSeenByPosts(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+		lastSeen map[forum.PostId]time.Time, err error)
+
+	// GetUnreadCommentCounts returns the number of live comments by other
+	// users on each of the posts since the user last saw it. Posts without
+	// unread comments are left out.
+	GetUnreadCommentCounts(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (
+		counts map[forum.PostId]int, err error)
+}
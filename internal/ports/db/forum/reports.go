@@ -0,0 +1,28 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// ReportsRepo is a repository for the reports of posts and comments.
+type ReportsRepo interface {
+	// CreateReport reports the post, or the comment on the post if the
+	// comment ID is not nil. Reporting an item which the user has an open
+	// report of returns a shared.ConflictError.
+	CreateReport(ctx context.Context, reporterId auth.UserId, postId forum.PostId, commentId *forum.CommentId,
+		value forum.ReportValue) (report *forum.Report, err error)
+
+	// GetReportQueue returns the items with open reports in the community,
+	// aggregating their reports, in the order of the sort.
+	GetReportQueue(ctx context.Context, communityId forum.CommunityId, sort forum.ReportQueueSort, limit int,
+		offset int) (items []forum.ReportedItem, err error)
+
+	// ResolveReports resolves the open reports of the post, or of the comment
+	// on the post if the comment ID is not nil, returning the number of
+	// reports resolved.
+	ResolveReports(ctx context.Context, postId forum.PostId, commentId *forum.CommentId, resolverId auth.UserId,
+		action forum.ReportAction) (resolved int, err error)
+}
@@ -0,0 +1,48 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// RevisionsRepo is an append-only repository for revisions of posts and
+// comments.
+type RevisionsRepo interface {
+	// CreatePostRevision appends the value as the next revision of a post,
+	// locking the post until the end of the transaction in the context.
+	CreatePostRevision(ctx context.Context, postId forum.PostId, editorId auth.UserId, value forum.PostValue) (
+		revision *forum.PostRevision, err error)
+
+	// GetPostRevision returns a revision of a post by its number.
+	GetPostRevision(ctx context.Context, postId forum.PostId, revision int) (postRevision *forum.PostRevision, err error)
+
+	// GetPostRevisions returns the revisions of a post, latest first.
+	GetPostRevisions(ctx context.Context, postId forum.PostId, limit int, offset int) (
+		revisions []forum.PostRevision, err error)
+
+	// GetLatestPostRevisionIds returns the IDs of the latest revisions of the
+	// posts, which hold their current title and body.
+	GetLatestPostRevisionIds(ctx context.Context, postIds []forum.PostId) (
+		ids map[forum.PostId]forum.RevisionId, err error)
+
+	// CreateCommentRevision appends the value as the next revision of a
+	// comment, locking the comment until the end of the transaction in the
+	// context.
+	CreateCommentRevision(ctx context.Context, commentId forum.CommentId, editorId auth.UserId,
+		value forum.CommentValue) (revision *forum.CommentRevision, err error)
+
+	// GetCommentRevision returns a revision of a comment by its number.
+	GetCommentRevision(ctx context.Context, commentId forum.CommentId, revision int) (
+		commentRevision *forum.CommentRevision, err error)
+
+	// GetCommentRevisions returns the revisions of a comment, latest first.
+	GetCommentRevisions(ctx context.Context, commentId forum.CommentId, limit int, offset int) (
+		revisions []forum.CommentRevision, err error)
+
+	// GetLatestCommentRevisionIds returns the IDs of the latest revisions of
+	// the comments, which hold their current body.
+	GetLatestCommentRevisionIds(ctx context.Context, commentIds []forum.CommentId) (
+		ids map[forum.CommentId]forum.RevisionId, err error)
+}
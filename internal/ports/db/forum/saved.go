@@ -0,0 +1,60 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// SavedItemsRepo is a repository for the saved items and collections of
+// users. Reads return a shared.NotFoundError when no row matches.
+type SavedItemsRepo interface {
+	// CreateCollection creates a collection for the user.
+	CreateCollection(ctx context.Context, userId auth.UserId, value forum.CollectionValue) (
+		collection *forum.Collection, err error)
+
+	// GetCollectionById returns a collection by its ID.
+	GetCollectionById(ctx context.Context, id forum.CollectionId) (collection *forum.Collection, err error)
+
+	// GetCollections returns the collections of the user sorted by name.
+	GetCollections(ctx context.Context, userId auth.UserId, limit int, offset int) (
+		collections []forum.Collection, err error)
+
+	// DeleteCollection deletes a collection, keeping its saved items outside
+	// of any collection.
+	DeleteCollection(ctx context.Context, id forum.CollectionId) (err error)
+
+	// SavePost saves a post for the user, replacing the value if the post is
+	// already saved.
+	SavePost(ctx context.Context, userId auth.UserId, postId forum.PostId, value forum.SavedItemValue) (
+		item *forum.SavedItem, err error)
+
+	// SaveComment saves a comment for the user, replacing the value if the
+	// comment is already saved.
+	SaveComment(ctx context.Context, userId auth.UserId, commentId forum.CommentId, value forum.SavedItemValue) (
+		item *forum.SavedItem, err error)
+
+	// UnsavePost removes a saved post, returning whether it was saved.
+	UnsavePost(ctx context.Context, userId auth.UserId, postId forum.PostId) (removed bool, err error)
+
+	// UnsaveComment removes a saved comment, returning whether it was saved.
+	UnsaveComment(ctx context.Context, userId auth.UserId, commentId forum.CommentId) (removed bool, err error)
+
+	// GetSavedItems returns the saved items of the user matching the filter,
+	// most recently saved first. Items of soft-deleted posts and comments, and
+	// of comments on soft-deleted posts, are excluded, as are items of removed
+	// content unless dbports.IncludeRemoved is given, and items in private
+	// communities not visible to the viewer given with dbports.VisibleTo.
+	GetSavedItems(ctx context.Context, userId auth.UserId, filter forum.SavedItemFilter, limit int, offset int,
+		opts ...dbports.ReadOption) (items []forum.SavedItem, err error)
+
+	// GetSavedPostIds returns which of the posts the user has saved.
+	GetSavedPostIds(ctx context.Context, userId auth.UserId, postIds []forum.PostId) (saved []forum.PostId, err error)
+
+	// GetSavedCommentIds returns which of the comments the user has saved.
+	GetSavedCommentIds(ctx context.Context, userId auth.UserId, commentIds []forum.CommentId) (
+		saved []forum.CommentId, err error)
+}
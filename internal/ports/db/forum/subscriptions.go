@@ -0,0 +1,38 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// SubscriptionsRepo is a repository for the subscriptions of users to
+// communities. Reads exclude private communities, unless the viewer given with
+// dbports.VisibleTo may see them or dbports.IncludePrivate is given.
+type SubscriptionsRepo interface {
+	// Subscribe subscribes the user to the community, returning the existing
+	// subscription if the user is already subscribed.
+	Subscribe(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+		subscription *forum.Subscription, err error)
+
+	// Unsubscribe unsubscribes the user from the community, returning whether
+	// the user was subscribed.
+	Unsubscribe(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (removed bool, err error)
+
+	// HasSubscriptions returns whether the user is subscribed to any live
+	// community.
+	HasSubscriptions(ctx context.Context, userId auth.UserId, opts ...dbports.ReadOption) (ok bool, err error)
+
+	// GetSubscribedCommunities returns the live communities the user is
+	// subscribed to, sorted by name.
+	GetSubscribedCommunities(ctx context.Context, userId auth.UserId, limit int, offset int,
+		opts ...dbports.ReadOption) (communities []forum.Community, err error)
+
+	// GetSubscribedCommunityIds returns the IDs of all live communities the
+	// user is subscribed to.
+	GetSubscribedCommunityIds(ctx context.Context, userId auth.UserId, opts ...dbports.ReadOption) (
+		communityIds []forum.CommunityId, err error)
+}
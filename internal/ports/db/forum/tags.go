@@ -0,0 +1,36 @@
+package dbportsforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// TagsRepo is a repository for the tags of posts. Tags are expected to be in
+// canonical form, see forum.NormalizeTag. Writes return a
+// shared.NotFoundError when a tag does not exist.
+type TagsRepo interface {
+	// ReplacePostTags replaces the tags of a post, creating the tags which do
+	// not exist yet.
+	ReplacePostTags(ctx context.Context, postId forum.PostId, tags []string) (err error)
+
+	// GetTagsByPosts returns the tags of each of the posts sorted by name.
+	// Posts without tags are left out.
+	GetTagsByPosts(ctx context.Context, postIds []forum.PostId) (tags map[forum.PostId][]string, err error)
+
+	// SearchTags returns the tags starting with the prefix, most used first,
+	// for autocompletion. Only live posts are counted, and tags without any
+	// are left out.
+	SearchTags(ctx context.Context, prefix string, limit int, opts ...dbports.ReadOption) (tags []forum.Tag, err error)
+
+	// RenameTag renames a tag on every post carrying it. Returns a
+	// shared.ConflictError if a tag with the new name exists, in which case
+	// the tags are merged with MergeTags instead.
+	RenameTag(ctx context.Context, name string, newName string) (err error)
+
+	// MergeTags replaces the source tag with the target tag on every post
+	// carrying it, and deletes the source tag.
+	MergeTags(ctx context.Context, source string, target string) (err error)
+}
@@ -0,0 +1,42 @@
+package dbports
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a job claimed by a worker, with the number of times it has been
+// claimed including this one.
+type Job struct {
+	Id       uuid.UUID
+	Kind     string
+	Payload  json.RawMessage
+	Attempts int
+}
+
+// JobsRepo is a queue of jobs run in the background. Jobs are enqueued within
+// the transaction of the change requiring them, and claimed by workers, which
+// lock them until they complete or fail.
+type JobsRepo interface {
+	// EnqueueJob enqueues a job to be run from the given time.
+	EnqueueJob(ctx context.Context, kind string, payload json.RawMessage, runAt time.Time) (err error)
+
+	// ClaimJobs claims at most limit jobs of the kinds due to run, oldest
+	// first, locking them for the given duration. Jobs whose lock expired
+	// are claimed again, as their worker is assumed to have stopped.
+	ClaimJobs(ctx context.Context, kinds []string, lockFor time.Duration, limit int) (jobs []Job, err error)
+
+	// CompleteJob deletes a completed job.
+	CompleteJob(ctx context.Context, id uuid.UUID) (err error)
+
+	// RetryJob unlocks a job which failed, to be run again from the given
+	// time.
+	RetryJob(ctx context.Context, id uuid.UUID, runAt time.Time, lastError string) (err error)
+
+	// FailJob marks a job as failed for good, so that it is not claimed
+	// again.
+	FailJob(ctx context.Context, id uuid.UUID, lastError string) (err error)
+}
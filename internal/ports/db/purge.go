@@ -0,0 +1,48 @@
+package dbports
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+)
+
+// PurgeRepo permanently deletes rows which were soft deleted, or which
+// expired, before a cutoff. Each method handles at most limit rows and must be
+// called within a transaction, returning the number of rows deleted unless
+// stated otherwise.
+type PurgeRepo interface {
+	// PurgeComments deletes soft-deleted comments which have no replies.
+	PurgeComments(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// PurgePosts deletes soft-deleted posts, along with their comments.
+	PurgePosts(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// PurgeCommunities deletes soft-deleted communities which have no posts.
+	PurgeCommunities(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// PurgeUsers deletes soft-deleted users, reassigning their posts,
+	// comments, revisions and attachments to the auth.DeletedUserId
+	// placeholder, which is created if needed.
+	PurgeUsers(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// PurgeExpiredBans deletes community bans which expired.
+	PurgeExpiredBans(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// PurgeExpiredSuspensions deletes suspensions which expired.
+	PurgeExpiredSuspensions(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// MarkUnusedBlobs marks the blobs created before the cutoff which no
+	// attachment uses, such as those of attachments deleted along with their
+	// post or comment, as purging. Returns the number of blobs marked.
+	MarkUnusedBlobs(ctx context.Context, before time.Time, limit int) (count int64, err error)
+
+	// LockPurgingBlobs returns blobs marked as purging, locking them until the
+	// end of the transaction. Their content may already have been deleted
+	// from the blob store.
+	LockPurgingBlobs(ctx context.Context, limit int) (blobs []forum.Blob, err error)
+
+	// DeletePurgingBlobs deletes the blobs which are still purging, once their
+	// content has been deleted from the blob store.
+	DeletePurgingBlobs(ctx context.Context, sha256s []string) (count int64, err error)
+}
@@ -0,0 +1,64 @@
+package dbports
+
+import "greddit/internal/domains/auth"
+
+// ReadOptions configure reads from a repository.
+type ReadOptions struct {
+	// IncludeDeleted includes soft-deleted rows, which are excluded by default.
+	IncludeDeleted bool
+	// IncludeRemoved includes rows removed by moderators in listings, which
+	// exclude them by default.
+	IncludeRemoved bool
+	// ViewerId restricts reads to the communities visible to the viewer, and
+	// their content. Only public and restricted communities are read if nil.
+	ViewerId *auth.UserId
+	// IncludePrivate includes private communities, and their content,
+	// regardless of the viewer.
+	IncludePrivate bool
+}
+
+// ReadOption represents an option for reads from a repository.
+type ReadOption func(*ReadOptions)
+
+// NewReadOptions returns the read options with the options applied.
+func NewReadOptions(opts ...ReadOption) ReadOptions {
+	options := ReadOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// IncludeDeleted includes soft-deleted rows in reads.
+func IncludeDeleted() ReadOption {
+	return func(o *ReadOptions) {
+		o.IncludeDeleted = true
+	}
+}
+
+// IncludeRemoved includes rows removed by moderators in listings.
+func IncludeRemoved() ReadOption {
+	return func(o *ReadOptions) {
+		o.IncludeRemoved = true
+	}
+}
+
+// VisibleTo restricts reads to the communities visible to the viewer, and
+// their content, so that private communities are only read by their members
+// and moderators. Only public and restricted communities are read if the
+// viewer is nil.
+func VisibleTo(viewerId *auth.UserId) ReadOption {
+	return func(o *ReadOptions) {
+		o.ViewerId = viewerId
+	}
+}
+
+// IncludePrivate includes private communities, and their content, in reads
+// regardless of the viewer. Meant for admins and for internal reads not made
+// on behalf of a user.
+func IncludePrivate() ReadOption {
+	return func(o *ReadOptions) {
+		o.IncludePrivate = true
+	}
+}
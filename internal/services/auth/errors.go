@@ -0,0 +1,15 @@
+package servicesauth
+
+var (
+	InvalidCredentialsError = invalidCredentialsError{}
+)
+
+// invalidCredentialsError represents an error when logging in with a username
+// which does not belong to any user. It does not tell apart unknown users, so
+// that logins do not reveal which users exist.
+type invalidCredentialsError struct{}
+
+// Error returns the error message.
+func (e invalidCredentialsError) Error() string {
+	return "invalid credentials"
+}
@@ -3,10 +3,12 @@ package servicesauth
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"time"
 
 	"greddit/internal/domains/auth"
+	"greddit/internal/domains/shared"
 
 	portsauth "greddit/internal/ports/auth"
 	dbportsauth "greddit/internal/ports/db/auth"
@@ -42,10 +44,13 @@ type TokenClaims struct {
 	Role        string
 }
 
-// Login logs in a user.
+// Login logs in a user. Returns InvalidCredentialsError if there is no user
+// with the username, or if it is the placeholder of purged users.
 func (s Service) Login(ctx context.Context, username string) (signed []byte, err error) {
 	user, err := s.users.GetUserByUsername(ctx, username)
-	if err != nil {
+	if errors.Is(err, shared.ErrNotFound) || (err == nil && user.Id == auth.DeletedUserId) {
+		return nil, InvalidCredentialsError
+	} else if err != nil {
 		s.logger.ErrorContext(ctx, "auth.service :: Error getting user by username",
 			"error", err,
 		)
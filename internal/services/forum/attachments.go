@@ -0,0 +1,355 @@
+package servicesforum
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	servicesauth "greddit/internal/services/auth"
+	servicesmedia "greddit/internal/services/media"
+)
+
+const (
+	// sniffLength is the number of bytes the content type of an attachment
+	// is sniffed from.
+	sniffLength = 512
+	// attachmentsPath is the path the attachments are served under, which
+	// must match the routes of the HTTP API.
+	attachmentsPath = "/api/v1/attachments/"
+)
+
+// AttachmentView is an attachment as returned to clients, along with the URLs
+// of its content and, for processed images, of its thumbnail.
+type AttachmentView struct {
+	forum.Attachment
+
+	Url          string  `json:"url"`
+	ThumbnailUrl *string `json:"thumbnail_url"`
+}
+
+// newAttachmentView returns the view of an attachment.
+func newAttachmentView(attachment forum.Attachment) AttachmentView {
+	view := AttachmentView{
+		Attachment: attachment,
+		Url:        attachmentsPath + attachment.Id.String() + "/content",
+	}
+	if attachment.Thumbnail != nil {
+		url := attachmentsPath + attachment.Id.String() + "/thumbnail"
+		view.ThumbnailUrl = &url
+	}
+
+	return view
+}
+
+// UploadPostAttachment attaches the file read from r to a post. Only the
+// poster and admins may attach files to a post.
+func (s Service) UploadPostAttachment(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	filename string, r io.Reader,
+) (view *AttachmentView, err error) {
+	post, err := s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	return s.uploadAttachment(ctx, claims, forum.AttachmentValue{
+		PostId:   &post.Id,
+		Filename: forum.SanitizeFilename(filename),
+	}, r)
+}
+
+// UploadCommentAttachment attaches the file read from r to a comment. Only the
+// commenter and admins may attach files to a comment.
+func (s Service) UploadCommentAttachment(ctx context.Context, claims servicesauth.TokenClaims,
+	commentId forum.CommentId, filename string, r io.Reader,
+) (view *AttachmentView, err error) {
+	comment, err := s.comments.GetCommentById(ctx, commentId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, comment.CommenterId) {
+		return nil, ForbiddenError
+	}
+
+	return s.uploadAttachment(ctx, claims, forum.AttachmentValue{
+		CommentId: &comment.Id,
+		Filename:  forum.SanitizeFilename(filename),
+	}, r)
+}
+
+// uploadAttachment spools the file read from r to a temporary file while
+// hashing it, sniffs its content type and creates the attachment. The content
+// is only stored if no attachment shares it yet, in which case images are
+// queued to be processed, and only served once processed. Permissions are
+// expected to have been checked.
+func (s Service) uploadAttachment(ctx context.Context, claims servicesauth.TokenClaims, value forum.AttachmentValue,
+	r io.Reader,
+) (view *AttachmentView, err error) {
+	file, err := os.CreateTemp("", "greddit-upload-*")
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating temporary file",
+			"error", err,
+		)
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(r, forum.AttachmentMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	blob := forum.Blob{
+		Sha256:      hex.EncodeToString(hash.Sum(nil)),
+		ContentType: http.DetectContentType(head[:n]),
+		Size:        size,
+		Status:      forum.BlobStatusReady,
+	}
+	err = blob.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if blob.IsImage() {
+		blob.Status = forum.BlobStatusPending
+	}
+
+	var attachment *forum.Attachment
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		created, err := s.attachments.CreateBlob(ctx, blob)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			_, err = file.Seek(0, io.SeekStart)
+			if err != nil {
+				return err
+			}
+
+			err = s.blobs.Put(ctx, blob.Key(), file, blob.Size, blob.ContentType)
+			if err != nil {
+				return err
+			}
+
+			if blob.Status == forum.BlobStatusPending {
+				err = servicesmedia.EnqueueProcessImage(ctx, s.jobs, blob.Sha256)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		attachment, err = s.attachments.CreateAttachment(ctx, claims.UserId, value, blob)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating attachment",
+			"sha256", blob.Sha256,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	v := newAttachmentView(*attachment)
+	return &v, nil
+}
+
+// getVisibleAttachment returns an attachment if the post or comment it is
+// attached to is visible to the user in the claims, along with the community
+// of that post.
+func (s Service) getVisibleAttachment(ctx context.Context, claims servicesauth.TokenClaims, id forum.AttachmentId) (
+	attachment *forum.Attachment, communityId forum.CommunityId, err error,
+) {
+	attachment, err = s.attachments.GetAttachmentById(ctx, id)
+	if err != nil {
+		return nil, communityId, err
+	}
+
+	if attachment.PostId != nil {
+		post, err := s.getVisiblePost(ctx, claims, *attachment.PostId)
+		if err != nil {
+			return nil, communityId, notFoundAttachment(err)
+		}
+
+		return attachment, post.CommunityId, nil
+	}
+
+	_, post, err := s.getVisibleComment(ctx, claims, *attachment.CommentId)
+	if err != nil {
+		return nil, communityId, notFoundAttachment(err)
+	}
+
+	return attachment, post.CommunityId, nil
+}
+
+// notFoundAttachment returns a not found error for the attachment if the
+// error is a not found error for its post or comment, so that hidden parents
+// are not revealed.
+func notFoundAttachment(err error) error {
+	if errors.Is(err, shared.ErrNotFound) {
+		return shared.NotFoundError{
+			Entity: "attachment",
+		}
+	}
+	return err
+}
+
+// GetAttachment returns an attachment, if the post or comment it is attached
+// to is visible to the user in the claims.
+func (s Service) GetAttachment(ctx context.Context, claims servicesauth.TokenClaims, id forum.AttachmentId) (
+	view *AttachmentView, err error,
+) {
+	attachment, _, err := s.getVisibleAttachment(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	v := newAttachmentView(*attachment)
+	return &v, nil
+}
+
+// OpenAttachment returns an attachment along with its content, if the post or
+// comment it is attached to is visible to the user in the claims. The content
+// must be closed by the caller. Images are only served once processed, as
+// their metadata has not been stripped before, and never if they could not
+// be processed.
+func (s Service) OpenAttachment(ctx context.Context, claims servicesauth.TokenClaims, id forum.AttachmentId) (
+	attachment *forum.Attachment, content io.ReadSeekCloser, err error,
+) {
+	attachment, _, err = s.getVisibleAttachment(ctx, claims, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch attachment.Status {
+	case forum.BlobStatusPending:
+		return nil, nil, PendingAttachmentError
+	case forum.BlobStatusFailed:
+		return nil, nil, shared.NotFoundError{
+			Entity: "attachment",
+		}
+	}
+
+	content, err = s.openBlob(ctx, id, attachment.Key())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// OpenAttachmentThumbnail returns an image attachment along with the content
+// of its thumbnail, if the post or comment it is attached to is visible to the
+// user in the claims. The content must be closed by the caller.
+func (s Service) OpenAttachmentThumbnail(ctx context.Context, claims servicesauth.TokenClaims,
+	id forum.AttachmentId,
+) (attachment *forum.Attachment, content io.ReadSeekCloser, err error) {
+	attachment, _, err = s.getVisibleAttachment(ctx, claims, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment.Status == forum.BlobStatusPending {
+		return nil, nil, PendingAttachmentError
+	} else if attachment.Thumbnail == nil {
+		return nil, nil, shared.NotFoundError{
+			Entity: "thumbnail",
+		}
+	}
+
+	content, err = s.openBlob(ctx, id, attachment.ThumbnailKey())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// openBlob opens the content stored under the key for an attachment.
+func (s Service) openBlob(ctx context.Context, id forum.AttachmentId, key string) (
+	content io.ReadSeekCloser, err error,
+) {
+	content, err = s.blobs.Open(ctx, key)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error opening attachment content",
+			"attachmentId", id,
+			"key", key,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// DeleteAttachment deletes an attachment, along with its content and thumbnail
+// if no other attachment shares them. Only the uploader, the moderators of the
+// community and admins may delete an attachment.
+func (s Service) DeleteAttachment(ctx context.Context, claims servicesauth.TokenClaims, id forum.AttachmentId) (
+	err error,
+) {
+	attachment, communityId, err := s.getVisibleAttachment(ctx, claims, id)
+	if err != nil {
+		return err
+	}
+
+	if !canModify(claims, attachment.UploaderId) {
+		ok, err := s.canModerate(ctx, claims, communityId)
+		if err != nil {
+			return err
+		} else if !ok {
+			return ForbiddenError
+		}
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		err = s.attachments.DeleteAttachment(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := s.attachments.DeleteUnusedBlob(ctx, attachment.Sha256)
+		if err != nil || !deleted {
+			return err
+		}
+
+		// The thumbnail may have been generated since the attachment was
+		// read, deleting the blob waiting for its processing to commit.
+		if attachment.IsImage() {
+			err = s.blobs.Delete(ctx, attachment.ThumbnailKey())
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.blobs.Delete(ctx, attachment.Key())
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error deleting attachment",
+			"attachmentId", id,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
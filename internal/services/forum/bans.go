@@ -0,0 +1,224 @@
+package servicesforum
+
+import (
+	"context"
+	"errors"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// checkNotBanned returns a BannedError if the user in the claims is suspended
+// or banned from the community. Expired bans and suspensions are ignored.
+func (s Service) checkNotBanned(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId,
+) (err error) {
+	suspension, err := s.bans.GetSuspension(ctx, claims.UserId)
+	if err == nil {
+		return BannedError{
+			Suspension: suspension,
+		}
+	} else if !errors.Is(err, shared.ErrNotFound) {
+		return err
+	}
+
+	ban, err := s.bans.GetBan(ctx, communityId, claims.UserId)
+	if err == nil {
+		return BannedError{
+			Ban: ban,
+		}
+	} else if !errors.Is(err, shared.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// GetBans returns the active bans of a community, most recent first. Only the
+// moderators of the community and admins may list its bans.
+func (s Service) GetBans(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	limit int, offset int,
+) (bans []forum.Ban, err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.bans.GetBans(ctx, communityId, limit, offset)
+}
+
+// BanUser bans a user from a community until the expiry, or permanently,
+// replacing any existing ban, and records it in the moderation log. Only the
+// moderators of the community and admins may ban users, and only admins may
+// ban moderators.
+func (s Service) BanUser(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId, value forum.BanValue,
+) (ban *forum.Ban, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	role, err := s.moderatorRole(ctx, userId, communityId)
+	if err != nil {
+		return nil, err
+	} else if role != nil && !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		ban, err = s.bans.BanUser(ctx, communityId, userId, claims.UserId, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionBanUser,
+			TargetUserId: &userId,
+			Reason:       value.Reason,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error banning user",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// UnbanUser lifts the ban of a user from a community, and records it in the
+// moderation log. Only the moderators of the community and admins may lift
+// bans.
+func (s Service) UnbanUser(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId,
+) (err error) {
+	ok, err := s.canModerate(ctx, claims, communityId)
+	if err != nil {
+		return err
+	} else if !ok {
+		return ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		removed, err := s.bans.UnbanUser(ctx, communityId, userId)
+		if err != nil {
+			return err
+		} else if !removed {
+			return shared.NotFoundError{
+				Entity: "ban",
+			}
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionUnbanUser,
+			TargetUserId: &userId,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error unbanning user",
+			"communityId", communityId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// GetSuspension returns the active suspension of a user. Users may read their
+// own suspension, and admins any suspension.
+func (s Service) GetSuspension(ctx context.Context, claims servicesauth.TokenClaims, userId auth.UserId) (
+	suspension *forum.Suspension, err error,
+) {
+	if !canModify(claims, userId) {
+		return nil, ForbiddenError
+	}
+
+	return s.bans.GetSuspension(ctx, userId)
+}
+
+// SuspendUser suspends a user from posting and commenting in every community
+// until the expiry, or permanently, replacing any existing suspension. Only
+// admins may suspend users.
+func (s Service) SuspendUser(ctx context.Context, claims servicesauth.TokenClaims, userId auth.UserId,
+	value forum.BanValue,
+) (suspension *forum.Suspension, err error) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	suspension, err = s.bans.SuspendUser(ctx, userId, claims.UserId, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error suspending user",
+			"userId", userId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return suspension, nil
+}
+
+// LiftSuspension lifts the suspension of a user. Only admins may lift
+// suspensions.
+func (s Service) LiftSuspension(ctx context.Context, claims servicesauth.TokenClaims, userId auth.UserId) (
+	err error,
+) {
+	if !isAdmin(claims) {
+		return ForbiddenError
+	}
+
+	removed, err := s.bans.LiftSuspension(ctx, userId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error lifting suspension",
+			"userId", userId,
+			"error", err,
+		)
+		return err
+	} else if !removed {
+		return shared.NotFoundError{
+			Entity: "suspension",
+		}
+	}
+
+	return nil
+}
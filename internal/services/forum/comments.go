@@ -0,0 +1,400 @@
+package servicesforum
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// renderComments renders the bodies of the comments, resolving their
+// wiki-style links, and flags the comments saved by and new to the user in the
+// claims. Soft-deleted comments are rendered as tombstones, as are removed
+// comments unless the user wrote them or moderates their community.
+func (s Service) renderComments(ctx context.Context, claims servicesauth.TokenClaims, comments []forum.Comment) (
+	views []CommentView, err error,
+) {
+	ids := make([]forum.CommentId, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.Id)
+	}
+
+	links, err := s.links.GetLinksByComments(ctx, ids, VisibleTo(claims))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting links of comments",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	linksByComment := make(map[forum.CommentId][]forum.Link, len(comments))
+	for _, link := range links {
+		linksByComment[*link.SourceCommentId] = append(linksByComment[*link.SourceCommentId], link)
+	}
+
+	revisionIds, err := s.revisions.GetLatestCommentRevisionIds(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting revisions of comments",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	savedIds, err := s.savedItems.GetSavedCommentIds(ctx, claims.UserId, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting saved comments",
+			"error", err,
+		)
+		return nil, err
+	}
+	saved := set.New[forum.CommentId](set.WithSlice(savedIds))
+
+	attachments, err := s.attachments.GetAttachmentsByComments(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting attachments of comments",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	attachmentsByComment := make(map[forum.CommentId][]AttachmentView, len(comments))
+	for _, attachment := range attachments {
+		attachmentsByComment[*attachment.CommentId] = append(attachmentsByComment[*attachment.CommentId],
+			newAttachmentView(attachment))
+	}
+
+	postIds := set.New[forum.PostId]()
+	for _, comment := range comments {
+		postIds.Add(comment.PostId)
+	}
+	lastSeen, err := s.reads.GetLastSeenByPosts(ctx, claims.UserId, postIds.Keys())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting last seen times of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	moderated := make(map[forum.PostId]bool)
+	canSeeRemoved := func(comment forum.Comment) (ok bool, err error) {
+		if comment.CommenterId == claims.UserId {
+			return true, nil
+		}
+
+		ok, seen := moderated[comment.PostId]
+		if seen {
+			return ok, nil
+		}
+
+		post, err := s.posts.GetPostById(ctx, comment.PostId, dbports.IncludeDeleted(), VisibleTo(claims))
+		if err != nil {
+			return false, err
+		}
+
+		ok, err = s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return false, err
+		}
+		moderated[comment.PostId] = ok
+
+		return ok, nil
+	}
+
+	views = make([]CommentView, 0, len(comments))
+	for _, comment := range comments {
+		hidden := comment.DeletedAt != nil
+		if !hidden && comment.IsRemoved() {
+			ok, err := canSeeRemoved(comment)
+			if err != nil {
+				return nil, err
+			}
+			hidden = !ok
+		}
+
+		if hidden {
+			views = append(views, CommentView{
+				Comment:     comment.Tombstone(),
+				Attachments: []AttachmentView{},
+				Saved:       saved.Contains(comment.Id),
+			})
+			continue
+		}
+
+		var lastSeenAt *time.Time
+		if at, ok := lastSeen[comment.PostId]; ok {
+			lastSeenAt = &at
+		}
+
+		html, err := s.renderer.Render(revisionIds[comment.Id], comment.Body, linksByComment[comment.Id])
+		if err != nil {
+			s.logger.ErrorContext(ctx, "forum.service :: Error rendering comment body",
+				"commentId", comment.Id,
+				"error", err,
+			)
+			return nil, err
+		}
+
+		view := CommentView{
+			Comment:     comment,
+			BodyHtml:    html,
+			Attachments: attachmentsByComment[comment.Id],
+			Saved:       saved.Contains(comment.Id),
+			New:         comment.IsUnreadBy(claims.UserId, lastSeenAt),
+		}
+		if view.Attachments == nil {
+			view.Attachments = []AttachmentView{}
+		}
+
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+// renderComment renders the body of the comment, resolving its wiki-style
+// links.
+func (s Service) renderComment(ctx context.Context, claims servicesauth.TokenClaims, comment forum.Comment) (
+	view *CommentView, err error,
+) {
+	views, err := s.renderComments(ctx, claims, []forum.Comment{comment})
+	if err != nil {
+		return nil, err
+	}
+
+	return &views[0], nil
+}
+
+// CreateComment creates a comment on a post as the user in the claims, along
+// with its first revision, and notifies the users it replies to or mentions.
+// The comment and notifications are streamed to clients once committed.
+// The parent ID is nil for top level comments. The user must be allowed to
+// post in the community, see checkCanPost, and only moderators may comment on
+// locked posts.
+func (s Service) CreateComment(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	value forum.CommentValue, parentId *forum.CommentId,
+) (view *CommentView, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, post.CommunityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCanPost(ctx, claims, *community)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.LockedAt != nil {
+		ok, err := s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, LockedTargetError
+		}
+	}
+
+	var parent *forum.Comment
+	if parentId != nil {
+		parent, err = s.comments.GetCommentById(ctx, *parentId, dbports.IncludeDeleted(), VisibleTo(claims))
+		if errors.Is(err, shared.ErrNotFound) || (err == nil && parent.PostId != postId) {
+			return nil, shared.InvalidReferenceError{
+				Entity: "comment",
+				Field:  "parent_id",
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	var comment *forum.Comment
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		comment, err = s.comments.CreateComment(ctx, postId, claims.UserId, value, parentId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.revisions.CreateCommentRevision(ctx, comment.Id, claims.UserId, value)
+		if err != nil {
+			return err
+		}
+
+		err = s.links.ReplaceCommentLinks(ctx, postId, comment.Id, forum.ParseWikiLinks(comment.Body))
+		if err != nil {
+			return err
+		}
+
+		err = s.publishCommentEvent(ctx, forum.EventKindCommentCreated, *comment)
+		if err != nil {
+			return err
+		}
+
+		return s.notifyComment(ctx, *post, parent, *comment)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating comment",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return s.renderComment(ctx, claims, *comment)
+}
+
+// GetComment returns a comment by its ID, see getVisibleComment.
+func (s Service) GetComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId) (
+	view *CommentView, err error,
+) {
+	comment, _, err := s.getVisibleComment(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderComment(ctx, claims, *comment)
+}
+
+// GetCommentsByPost returns the thread of comments on a post sorted by creation
+// date, with soft-deleted comments as tombstones.
+func (s Service) GetCommentsByPost(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	limit int, offset int,
+) (views []CommentView, err error) {
+	_, err = s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.comments.GetCommentsByPostSortedCreatedAt(ctx, postId, limit, offset, dbports.IncludeDeleted(),
+		VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderComments(ctx, claims, comments)
+}
+
+// UpdateCommentBody updates the body of a comment, appending a revision and
+// replacing its links, see getEditableComment.
+func (s Service) UpdateCommentBody(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId,
+	body string,
+) (updatedAt *time.Time, err error) {
+	comment, err := s.getEditableComment(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateCommentBody(ctx, claims, *comment, body)
+}
+
+// getEditableComment returns a comment by its ID if the user in the claims may
+// edit it. Only the commenter and admins may edit a comment, removed comments
+// cannot be edited, and only moderators may edit comments on locked posts.
+func (s Service) getEditableComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId) (
+	comment *forum.Comment, err error,
+) {
+	comment, post, err := s.getVisibleComment(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, comment.CommenterId) || comment.IsRemoved() {
+		return nil, ForbiddenError
+	}
+
+	if post.LockedAt != nil {
+		ok, err := s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, LockedTargetError
+		}
+	}
+
+	return comment, nil
+}
+
+// updateCommentBody updates the body of the comment, appending a revision and
+// replacing its links. Permissions are expected to have been checked.
+func (s Service) updateCommentBody(ctx context.Context, claims servicesauth.TokenClaims, comment forum.Comment,
+	body string,
+) (updatedAt *time.Time, err error) {
+	value := comment.CommentValue
+	value.Body = body
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		updatedAt, err = s.comments.UpdateCommentBody(ctx, comment.Id, body)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.revisions.CreateCommentRevision(ctx, comment.Id, claims.UserId, value)
+		if err != nil {
+			return err
+		}
+
+		err = s.links.ReplaceCommentLinks(ctx, comment.PostId, comment.Id, forum.ParseWikiLinks(body))
+		if err != nil {
+			return err
+		}
+
+		comment.Body = body
+		comment.UpdatedAt = *updatedAt
+		return s.publishCommentEvent(ctx, forum.EventKindCommentUpdated, comment)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating comment body",
+			"commentId", comment.Id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// DeleteComment soft deletes a comment. Only the commenter and admins may
+// delete a comment.
+func (s Service) DeleteComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId) (
+	deletedAt *time.Time, err error,
+) {
+	comment, err := s.comments.GetCommentById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, comment.CommenterId) {
+		return nil, ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		deletedAt, err = s.comments.DeleteComment(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		comment.DeletedAt = deletedAt
+		return s.publishCommentEvent(ctx, forum.EventKindCommentDeleted, *comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deletedAt, nil
+}
@@ -0,0 +1,148 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// CreateCommunity creates a community, owned by the user in the claims.
+func (s Service) CreateCommunity(ctx context.Context, claims servicesauth.TokenClaims, value forum.CommunityValue) (
+	community *forum.Community, err error,
+) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		community, err = s.communities.CreateCommunity(ctx, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.SetModerator(ctx, community.Id, claims.UserId, forum.ModeratorRoleOwner)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating community",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return community, nil
+}
+
+// GetCommunity returns a community by its ID. Private communities are only
+// found by their members and moderators.
+func (s Service) GetCommunity(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommunityId) (
+	community *forum.Community, err error,
+) {
+	return s.communities.GetCommunityById(ctx, id, VisibleTo(claims))
+}
+
+// GetCommunities returns the communities visible to the user in the claims
+// sorted by name.
+func (s Service) GetCommunities(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	communities []forum.Community, err error,
+) {
+	return s.communities.GetAllCommunitiesSortedByName(ctx, limit, offset, VisibleTo(claims))
+}
+
+// UpdateCommunityDescription updates the description of a community. Only
+// admins may update communities.
+func (s Service) UpdateCommunityDescription(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommunityId,
+	description string,
+) (updatedAt *time.Time, err error) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	value := community.CommunityValue
+	value.Description = description
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.communities.UpdateCommunityDescription(ctx, id, description)
+}
+
+// UpdateCommunitySettings replaces the settings of a community, and records it
+// in the moderation log. Making the community private closes every stream.
+// Only the moderators of the community and admins may update its settings.
+func (s Service) UpdateCommunitySettings(ctx context.Context, claims servicesauth.TokenClaims,
+	id forum.CommunityId, settings forum.CommunitySettings,
+) (updatedAt *time.Time, err error) {
+	if settings.Rules == nil {
+		settings.Rules = []forum.CommunityRule{}
+	}
+	err = settings.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canModerate(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		updatedAt, err = s.communities.UpdateCommunitySettings(ctx, id, settings)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: id,
+			ModeratorId: &claims.UserId,
+			Action:      forum.ModerationActionUpdateSettings,
+		})
+		if err != nil {
+			return err
+		}
+
+		if settings.Visibility == forum.CommunityVisibilityPrivate &&
+			community.Settings.Visibility != forum.CommunityVisibilityPrivate {
+			return s.publishAccessRevoked(ctx, id, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating community settings",
+			"communityId", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// DeleteCommunity soft deletes a community. Only admins may delete
+// communities.
+func (s Service) DeleteCommunity(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommunityId) (
+	deletedAt *time.Time, err error,
+) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	return s.communities.DeleteCommunity(ctx, id)
+}
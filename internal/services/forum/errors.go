@@ -0,0 +1,93 @@
+package servicesforum
+
+import "greddit/internal/domains/forum"
+
+var (
+	ForbiddenError     = forbiddenError{}
+	DeletedTargetError = deletedTargetError{}
+	LockedTargetError  = lockedTargetError{}
+	NotMemberError     = notMemberError{}
+	ClosedPollError    = closedPollError{}
+
+	PendingAttachmentError = pendingAttachmentError{}
+)
+
+// forbiddenError represents an error when the user is not allowed to perform
+// an action.
+type forbiddenError struct{}
+
+// Error returns the error message.
+func (e forbiddenError) Error() string {
+	return "forbidden"
+}
+
+// deletedTargetError represents an error when an action targets a resource
+// which has been deleted.
+type deletedTargetError struct{}
+
+// Error returns the error message.
+func (e deletedTargetError) Error() string {
+	return "target has been deleted"
+}
+
+// lockedTargetError represents an error when a comment is created on a post
+// which has been locked by a moderator.
+type lockedTargetError struct{}
+
+// Error returns the error message.
+func (e lockedTargetError) Error() string {
+	return "post is locked"
+}
+
+// closedPollError represents an error when a user votes in a poll which has
+// closed.
+type closedPollError struct{}
+
+// Error returns the error message.
+func (e closedPollError) Error() string {
+	return "poll is closed"
+}
+
+// notMemberError represents an error when a user who is not a member of a
+// restricted or private community posts or comments in it.
+type notMemberError struct{}
+
+// Error returns the error message.
+func (e notMemberError) Error() string {
+	return "only members may post in the community"
+}
+
+// Is reports whether the error matches ForbiddenError.
+func (e notMemberError) Is(target error) bool {
+	return target == ForbiddenError
+}
+
+// pendingAttachmentError represents an error when the content of an image
+// attachment is requested before the image has been processed.
+type pendingAttachmentError struct{}
+
+// Error returns the error message.
+func (e pendingAttachmentError) Error() string {
+	return "attachment is being processed"
+}
+
+// BannedError represents an error when a user banned from a community, or
+// suspended, posts or comments in it. Either the ban or the suspension is
+// set.
+type BannedError struct {
+	Ban        *forum.Ban
+	Suspension *forum.Suspension
+}
+
+// Error returns the error message.
+func (e BannedError) Error() string {
+	if e.Suspension != nil {
+		return "user is suspended"
+	}
+	return "user is banned from the community"
+}
+
+// Is reports whether the error matches ForbiddenError.
+func (e BannedError) Is(target error) bool {
+	return target == ForbiddenError
+}
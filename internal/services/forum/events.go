@@ -0,0 +1,43 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+)
+
+// publishCommentEvent publishes the change to the comment to the clients
+// viewing its post. Removed and deleted comments are published as tombstones.
+// Expected to run within the transaction making the change.
+func (s Service) publishCommentEvent(ctx context.Context, kind forum.EventKind, comment forum.Comment) (err error) {
+	if comment.IsRemoved() || comment.DeletedAt != nil {
+		comment = comment.Tombstone()
+	}
+
+	event, err := forum.NewCommentEvent(kind, comment)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.events.PublishEvent(ctx, event)
+	return err
+}
+
+// publishAccessRevoked closes the streams of the user, or every stream if the
+// user is nil, after a change which may hide the community from them.
+// Expected to run within the transaction making the change.
+func (s Service) publishAccessRevoked(ctx context.Context, communityId forum.CommunityId, userId *auth.UserId) (
+	err error,
+) {
+	event, err := forum.NewAccessRevokedEvent(forum.AccessRevocation{
+		CommunityId: communityId,
+		UserId:      userId,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.events.PublishEvent(ctx, event)
+	return err
+}
@@ -0,0 +1,127 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// FeedScope is the set of communities a feed draws its posts from.
+type FeedScope string
+
+const (
+	// FeedScopeSubscribed draws posts from the subscribed communities.
+	FeedScopeSubscribed FeedScope = "subscribed"
+	// FeedScopeAll draws posts from all communities, for users without
+	// subscriptions.
+	FeedScopeAll FeedScope = "all"
+)
+
+// FeedPage is a page of the home feed of a user.
+type FeedPage struct {
+	Scope FeedScope  `json:"scope"`
+	Posts []PostView `json:"posts"`
+	// NextCursor is the cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor"`
+}
+
+// Subscribe subscribes the user in the claims to a community.
+func (s Service) Subscribe(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	subscription *forum.Subscription, err error,
+) {
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err = s.subscriptions.Subscribe(ctx, claims.UserId, communityId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error subscribing to community",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// Unsubscribe unsubscribes the user in the claims from a community, returning
+// whether the user was subscribed.
+func (s Service) Unsubscribe(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	removed bool, err error,
+) {
+	return s.subscriptions.Unsubscribe(ctx, claims.UserId, communityId)
+}
+
+// GetSubscriptions returns the communities the user in the claims is
+// subscribed to and can still see, sorted by name.
+func (s Service) GetSubscriptions(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	communities []forum.Community, err error,
+) {
+	return s.subscriptions.GetSubscribedCommunities(ctx, claims.UserId, limit, offset, VisibleTo(claims))
+}
+
+// GetFeed returns a page of the home feed of the user in the claims matching
+// the filter, starting after the cursor if it is not empty. The feed merges the
+// posts of the subscribed communities, or of all communities if the user has no
+// subscriptions, leaving out the communities the user cannot see.
+func (s Service) GetFeed(ctx context.Context, claims servicesauth.TokenClaims, sort forum.FeedSort,
+	filter forum.PostFilter, cursor string, limit int,
+) (page *FeedPage, err error) {
+	err = sort.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err = filter.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var after *forum.FeedCursor
+	if cursor != "" {
+		parsed, err := forum.ParseFeedCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &parsed
+	}
+
+	subscribed, err := s.subscriptions.HasSubscriptions(ctx, claims.UserId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	page = &FeedPage{
+		Scope: FeedScopeAll,
+	}
+	var subscriberId *auth.UserId
+	if subscribed {
+		page.Scope = FeedScopeSubscribed
+		subscriberId = &claims.UserId
+	}
+
+	// One extra post is read to tell whether there is a next page.
+	posts, err := s.posts.GetFeedPosts(ctx, subscriberId, sort, filter, after, limit+1, VisibleTo(claims))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting feed posts",
+			"error", err,
+		)
+		return nil, err
+	}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		page.NextCursor = sort.CursorOf(posts[limit-1]).String()
+	}
+
+	page.Posts, err = s.renderPosts(ctx, claims, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,241 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// validateFlair returns an error if a post in the community may not carry the
+// flair, see forum.Community.ValidateFlair. A nil flair ID is no flair.
+func (s Service) validateFlair(ctx context.Context, community forum.Community, flairId *forum.FlairId) (err error) {
+	var flair *forum.Flair
+	if flairId != nil {
+		flair, err = s.flairs.GetFlairById(ctx, *flairId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return community.ValidateFlair(flair)
+}
+
+// setPostLabels replaces the flair and tags of a post. The labels are
+// expected to have been normalized and validated.
+func (s Service) setPostLabels(ctx context.Context, postId forum.PostId, labels forum.PostLabels) (err error) {
+	err = s.posts.SetPostFlair(ctx, postId, labels.FlairId)
+	if err != nil {
+		return err
+	}
+
+	return s.tags.ReplacePostTags(ctx, postId, labels.Tags)
+}
+
+// getCommunityFlair returns a flair of a community, which is not found if it
+// belongs to another community.
+func (s Service) getCommunityFlair(ctx context.Context, communityId forum.CommunityId, flairId forum.FlairId) (
+	flair *forum.Flair, err error,
+) {
+	flair, err = s.flairs.GetFlairById(ctx, flairId)
+	if err != nil {
+		return nil, err
+	} else if flair.CommunityId != communityId {
+		return nil, shared.NotFoundError{
+			Entity: "flair",
+		}
+	}
+
+	return flair, nil
+}
+
+// GetFlairs returns the flairs of a community sorted by text.
+func (s Service) GetFlairs(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	flairs []forum.Flair, err error,
+) {
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.flairs.GetFlairsByCommunity(ctx, communityId)
+}
+
+// CreateFlair creates a flair in a community, and records it in the moderation
+// log. Only the moderators of the community and admins may create flairs.
+func (s Service) CreateFlair(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	value forum.FlairValue,
+) (flair *forum.Flair, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		flair, err = s.flairs.CreateFlair(ctx, communityId, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: communityId,
+			ModeratorId: &claims.UserId,
+			Action:      forum.ModerationActionUpdateFlairs,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating flair",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return flair, nil
+}
+
+// UpdateFlair replaces the value of a flair of a community, returning the
+// updated flair, and records it in the moderation log. Only the moderators of
+// the community and admins may update flairs.
+func (s Service) UpdateFlair(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	flairId forum.FlairId, value forum.FlairValue,
+) (flair *forum.Flair, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	flair, err = s.getCommunityFlair(ctx, communityId, flairId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		err = s.flairs.UpdateFlair(ctx, flairId, value)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: communityId,
+			ModeratorId: &claims.UserId,
+			Action:      forum.ModerationActionUpdateFlairs,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating flair",
+			"communityId", communityId,
+			"flairId", flairId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	flair.FlairValue = value
+	return flair, nil
+}
+
+// DeleteFlair deletes a flair of a community, removing it from the posts
+// carrying it, and records it in the moderation log. Only the moderators of
+// the community and admins may delete flairs.
+func (s Service) DeleteFlair(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	flairId forum.FlairId,
+) (err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.getCommunityFlair(ctx, communityId, flairId)
+	if err != nil {
+		return err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		err = s.flairs.DeleteFlair(ctx, flairId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId: communityId,
+			ModeratorId: &claims.UserId,
+			Action:      forum.ModerationActionUpdateFlairs,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error deleting flair",
+			"communityId", communityId,
+			"flairId", flairId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePostLabels replaces the flair and tags of a post, returning the labels
+// with the tags in canonical form. The flair must be allowed by the community
+// of the post. Only the poster, the moderators of the community and admins may
+// label a post.
+func (s Service) UpdatePostLabels(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	labels forum.PostLabels,
+) (updated *forum.PostLabels, err error) {
+	labels, err = labels.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.PosterId != claims.UserId {
+		ok, err := s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ForbiddenError
+		}
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, post.CommunityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validateFlair(ctx, *community, labels.FlairId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		return s.setPostLabels(ctx, postId, labels)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating post labels",
+			"postId", postId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return &labels, nil
+}
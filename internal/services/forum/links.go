@@ -0,0 +1,54 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// PostLinks contains the links to and from a post.
+type PostLinks struct {
+	// Backlinks are the links to the post from other posts and comments.
+	Backlinks []forum.Backlink `json:"backlinks"`
+
+	// Outgoing are the links from the body of the post. Dangling links have no
+	// target post.
+	Outgoing []forum.Link `json:"outgoing"`
+}
+
+// GetPostLinks returns the links to and from a post. Links to and from posts
+// in communities not visible to the user in the claims are left out, or
+// dangling.
+func (s Service) GetPostLinks(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId) (
+	links *PostLinks, err error,
+) {
+	_, err = s.getVisiblePost(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	backlinks, err := s.links.GetBacklinks(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	outgoing, err := s.links.GetLinksByPosts(ctx, []forum.PostId{id}, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostLinks{
+		Backlinks: backlinks,
+		Outgoing:  outgoing,
+	}, nil
+}
+
+// GetDanglingLinks returns the links across all posts and comments visible to
+// the user in the claims which do not resolve to a post.
+func (s Service) GetDanglingLinks(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	links []forum.Link, err error,
+) {
+	return s.links.GetDanglingLinks(ctx, limit, offset, VisibleTo(claims))
+}
@@ -0,0 +1,321 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// isMember returns whether the claims belong to a member of the community.
+// The moderators of the community and admins count as members.
+func (s Service) isMember(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	ok bool, err error,
+) {
+	ok, err = s.canModerate(ctx, claims, communityId)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	ok, err = s.members.IsMember(ctx, communityId, claims.UserId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error checking membership",
+			"communityId", communityId,
+			"error", err,
+		)
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// checkCanPost returns an error if the user in the claims may not post or
+// comment in the community. Suspended and banned users cannot post, and only
+// members may post in restricted and private communities.
+func (s Service) checkCanPost(ctx context.Context, claims servicesauth.TokenClaims, community forum.Community) (
+	err error,
+) {
+	err = s.checkNotBanned(ctx, claims, community.Id)
+	if err != nil {
+		return err
+	}
+
+	if !community.Settings.MembersOnlyPosting() {
+		return nil
+	}
+
+	ok, err := s.isMember(ctx, claims, community.Id)
+	if err != nil {
+		return err
+	} else if !ok {
+		return NotMemberError
+	}
+
+	return nil
+}
+
+// checkCanModerate returns an error if the community is not found by the user
+// in the claims, or if the user may not moderate it.
+func (s Service) checkCanModerate(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId,
+) (err error) {
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.canModerate(ctx, claims, communityId)
+	if err != nil {
+		return err
+	} else if !ok {
+		return ForbiddenError
+	}
+
+	return nil
+}
+
+// GetMembers returns the members of a community, earliest first. Only the
+// moderators of the community and admins may list its members.
+func (s Service) GetMembers(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	limit int, offset int,
+) (members []forum.Member, err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.members.GetMembers(ctx, communityId, limit, offset)
+}
+
+// RemoveMember removes a user from the members of a community, closing their
+// streams, and records it in the moderation log. Only the moderators of the community and admins may
+// remove members, while members leave with LeaveCommunity.
+func (s Service) RemoveMember(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId,
+) (err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		removed, err := s.members.RemoveMember(ctx, communityId, userId)
+		if err != nil {
+			return err
+		} else if !removed {
+			return shared.NotFoundError{
+				Entity: "member",
+			}
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionRemoveMember,
+			TargetUserId: &userId,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.publishAccessRevoked(ctx, communityId, &userId)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error removing member",
+			"communityId", communityId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// JoinCommunity makes the user in the claims a member of a community by
+// accepting their pending invite to it.
+func (s Service) JoinCommunity(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	member *forum.Member, err error,
+) {
+	// The community is not visible to the invitee yet, and is reported as not
+	// found along with the invite if the user was not invited.
+	_, err = s.communities.GetCommunityById(ctx, communityId, dbports.IncludePrivate())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		removed, err := s.members.DeleteInvite(ctx, communityId, claims.UserId)
+		if err != nil {
+			return err
+		} else if !removed {
+			return shared.NotFoundError{
+				Entity: "invite",
+			}
+		}
+
+		member, err = s.members.AddMember(ctx, communityId, claims.UserId)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error joining community",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// LeaveCommunity removes the user in the claims from the members of a
+// community, closing their streams, returning whether the user was a member.
+func (s Service) LeaveCommunity(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	removed bool, err error,
+) {
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		removed, err = s.members.RemoveMember(ctx, communityId, claims.UserId)
+		if err != nil || !removed {
+			return err
+		}
+
+		return s.publishAccessRevoked(ctx, communityId, &claims.UserId)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error leaving community",
+			"communityId", communityId,
+			"error", err,
+		)
+		return false, err
+	}
+
+	return removed, nil
+}
+
+// GetCommunityInvites returns the pending invites to a community, most recent
+// first. Only the moderators of the community and admins may list its
+// invites.
+func (s Service) GetCommunityInvites(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId, limit int, offset int,
+) (invites []forum.Invite, err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.members.GetInvitesByCommunity(ctx, communityId, limit, offset)
+}
+
+// GetInvites returns the pending invites of the user in the claims, most
+// recent first.
+func (s Service) GetInvites(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	invites []forum.Invite, err error,
+) {
+	return s.members.GetInvitesByInvitee(ctx, claims.UserId, limit, offset)
+}
+
+// InviteMember invites a user to become a member of a community, returning
+// the existing invite if the user is already invited, and records it in the
+// moderation log. Only the moderators of the community and admins may invite
+// users, who must not be members already.
+func (s Service) InviteMember(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId,
+) (invite *forum.Invite, err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.members.IsMember(ctx, communityId, userId)
+	if err != nil {
+		return nil, err
+	} else if member {
+		return nil, shared.ConflictError{
+			Entity: "member",
+		}
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		invite, err = s.members.CreateInvite(ctx, communityId, userId, claims.UserId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionInviteMember,
+			TargetUserId: &userId,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error inviting member",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// DeleteInvite deletes the pending invite of a user to a community. Invitees
+// decline their own invites, while the moderators of the community and admins
+// revoke invites, which is recorded in the moderation log.
+func (s Service) DeleteInvite(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId,
+) (err error) {
+	notFound := shared.NotFoundError{
+		Entity: "invite",
+	}
+
+	if userId == claims.UserId {
+		removed, err := s.members.DeleteInvite(ctx, communityId, userId)
+		if err != nil {
+			return err
+		} else if !removed {
+			return notFound
+		}
+		return nil
+	}
+
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		removed, err := s.members.DeleteInvite(ctx, communityId, userId)
+		if err != nil {
+			return err
+		} else if !removed {
+			return notFound
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionRevokeInvite,
+			TargetUserId: &userId,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error revoking invite",
+			"communityId", communityId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
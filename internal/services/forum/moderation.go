@@ -0,0 +1,538 @@
+package servicesforum
+
+import (
+	"context"
+	"errors"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// moderatorRole returns the role of the user in the community, or nil if the
+// user does not moderate it.
+func (s Service) moderatorRole(ctx context.Context, userId auth.UserId, communityId forum.CommunityId) (
+	role *forum.ModeratorRole, err error,
+) {
+	moderator, err := s.moderation.GetModerator(ctx, communityId, userId)
+	if errors.Is(err, shared.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting moderator",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return &moderator.Role, nil
+}
+
+// canModerate returns whether the claims allow moderating the community.
+// Admins may moderate all communities.
+func (s Service) canModerate(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	ok bool, err error,
+) {
+	if isAdmin(claims) {
+		return true, nil
+	}
+
+	role, err := s.moderatorRole(ctx, claims.UserId, communityId)
+	if err != nil {
+		return false, err
+	}
+
+	return role != nil, nil
+}
+
+// canManageModerators returns whether the claims allow managing the
+// moderators of the community, which only its owner and admins may.
+func (s Service) canManageModerators(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId,
+) (ok bool, err error) {
+	if isAdmin(claims) {
+		return true, nil
+	}
+
+	role, err := s.moderatorRole(ctx, claims.UserId, communityId)
+	if err != nil {
+		return false, err
+	}
+
+	return role != nil && *role == forum.ModeratorRoleOwner, nil
+}
+
+// getVisiblePost returns a post by its ID. Removed posts are only visible to
+// their posters and the moderators of their communities, and are not found
+// by other users, as are posts in communities not visible to the user.
+func (s Service) getVisiblePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId) (
+	post *forum.Post, err error,
+) {
+	post, err = s.posts.GetPostById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if post.IsRemoved() && post.PosterId != claims.UserId {
+		ok, err := s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, shared.NotFoundError{
+				Entity: "post",
+			}
+		}
+	}
+
+	return post, nil
+}
+
+// getVisibleComment returns a comment by its ID, along with its post. Removed
+// comments are only visible to their commenters and the moderators of their
+// communities, and are not found by other users, as are comments on posts not
+// visible to the user.
+func (s Service) getVisibleComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId) (
+	comment *forum.Comment, post *forum.Post, err error,
+) {
+	comment, err = s.comments.GetCommentById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	post, err = s.getVisiblePost(ctx, claims, comment.PostId)
+	if errors.Is(err, shared.ErrNotFound) {
+		return nil, nil, shared.NotFoundError{
+			Entity: "comment",
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	if comment.IsRemoved() && comment.CommenterId != claims.UserId {
+		ok, err := s.canModerate(ctx, claims, post.CommunityId)
+		if err != nil {
+			return nil, nil, err
+		} else if !ok {
+			return nil, nil, shared.NotFoundError{
+				Entity: "comment",
+			}
+		}
+	}
+
+	return comment, post, nil
+}
+
+// GetModerators returns the moderators of a community, starting with the
+// owner.
+func (s Service) GetModerators(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+	moderators []forum.Moderator, err error,
+) {
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.moderation.GetModerators(ctx, communityId)
+}
+
+// SetModerator makes a user a moderator of a community with the role, or
+// changes the role of an existing moderator. Giving the owner role transfers
+// the ownership, leaving the previous owner a moderator. Only the owner and
+// admins may manage moderators, and only admins may demote the owner without
+// transferring the ownership.
+func (s Service) SetModerator(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId, role forum.ModeratorRole,
+) (moderator *forum.Moderator, err error) {
+	err = role.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canManageModerators(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	current, err := s.moderatorRole(ctx, userId, communityId)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && *current == role {
+		return s.moderation.GetModerator(ctx, communityId, userId)
+	} else if current != nil && *current == forum.ModeratorRoleOwner && !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	action := forum.ModerationActionAddModerator
+	if role == forum.ModeratorRoleOwner {
+		action = forum.ModerationActionTransferOwnership
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		if role == forum.ModeratorRoleOwner {
+			moderators, err := s.moderation.GetModerators(ctx, communityId)
+			if err != nil {
+				return err
+			}
+
+			for _, m := range moderators {
+				if m.Role != forum.ModeratorRoleOwner {
+					continue
+				}
+				_, err = s.moderation.SetModerator(ctx, communityId, m.UserId, forum.ModeratorRoleModerator)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		moderator, err = s.moderation.SetModerator(ctx, communityId, userId, role)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       action,
+			TargetUserId: &userId,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error setting moderator",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return moderator, nil
+}
+
+// RemoveModerator removes a user from the moderators of a community, closing
+// their streams. The owner and admins may remove moderators, and moderators
+// may step down, but only admins may remove the owner.
+func (s Service) RemoveModerator(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	userId auth.UserId,
+) (err error) {
+	role, err := s.moderatorRole(ctx, userId, communityId)
+	if err != nil {
+		return err
+	} else if role == nil {
+		return shared.NotFoundError{
+			Entity: "moderator",
+		}
+	}
+
+	ok, err := s.canManageModerators(ctx, claims, communityId)
+	if err != nil {
+		return err
+	}
+	if *role == forum.ModeratorRoleOwner {
+		ok = isAdmin(claims)
+	} else if userId == claims.UserId {
+		ok = true
+	}
+	if !ok {
+		return ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		_, err = s.moderation.RemoveModerator(ctx, communityId, userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       forum.ModerationActionRemoveModerator,
+			TargetUserId: &userId,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.publishAccessRevoked(ctx, communityId, &userId)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error removing moderator",
+			"communityId", communityId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// GetModerationLog returns the moderation log of a community, most recent
+// first. Only the moderators of the community and admins may read the log.
+func (s Service) GetModerationLog(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	limit int, offset int,
+) (entries []forum.ModerationLogEntry, err error) {
+	err = s.checkCanModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.moderation.GetModerationLog(ctx, communityId, limit, offset)
+}
+
+// moderatePost applies the moderation action to a post within a transaction,
+// recording it in the moderation log of the community of the post. Only the
+// moderators of the community and admins may moderate its posts.
+func (s Service) moderatePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId,
+	action forum.ModerationAction, reason string, apply func(ctx context.Context, post forum.Post) error,
+) (entry *forum.ModerationLogEntry, err error) {
+	err = forum.ValidateModerationReason(action, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.posts.GetPostById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canModerate(ctx, claims, post.CommunityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		err = apply(ctx, *post)
+		if err != nil {
+			return err
+		}
+
+		entry, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  post.CommunityId,
+			ModeratorId:  &claims.UserId,
+			Action:       action,
+			PostId:       &post.Id,
+			TargetUserId: &post.PosterId,
+			Reason:       reason,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error moderating post",
+			"postId", id,
+			"action", action,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// RemovePost removes a post from its community for the reason, hiding it
+// from users other than its poster and the moderators, and resolves its open
+// reports.
+func (s Service) RemovePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionRemovePost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			return s.removePost(ctx, claims, post, reason)
+		})
+}
+
+// removePost removes a post for the reason, resolving its open reports.
+func (s Service) removePost(ctx context.Context, claims servicesauth.TokenClaims, post forum.Post,
+	reason string,
+) (err error) {
+	_, err = s.posts.RemovePost(ctx, post.Id, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.reports.ResolveReports(ctx, post.Id, nil, claims.UserId, forum.ReportActionRemove)
+	return err
+}
+
+// ApprovePost approves a post, reinstating it if it was removed.
+func (s Service) ApprovePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionApprovePost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			return s.posts.ApprovePost(ctx, post.Id)
+		})
+}
+
+// LockPost locks a post against new comments, except from moderators.
+func (s Service) LockPost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionLockPost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			_, err := s.posts.SetPostLocked(ctx, post.Id, true)
+			return err
+		})
+}
+
+// UnlockPost unlocks a post, allowing new comments again.
+func (s Service) UnlockPost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionUnlockPost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			_, err := s.posts.SetPostLocked(ctx, post.Id, false)
+			return err
+		})
+}
+
+// PinPost pins a post to the top of its community, up to
+// forum.MaxPinnedPosts per community.
+func (s Service) PinPost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionPinPost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			if post.PinnedAt == nil {
+				count, err := s.posts.CountPinnedPosts(ctx, post.CommunityId)
+				if err != nil {
+					return err
+				} else if count >= forum.MaxPinnedPosts {
+					return forum.PinLimitError{}
+				}
+			}
+
+			_, err := s.posts.SetPostPinned(ctx, post.Id, true)
+			return err
+		})
+}
+
+// UnpinPost unpins a post from the top of its community.
+func (s Service) UnpinPost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, reason string) (
+	entry *forum.ModerationLogEntry, err error,
+) {
+	return s.moderatePost(ctx, claims, id, forum.ModerationActionUnpinPost, reason,
+		func(ctx context.Context, post forum.Post) error {
+			_, err := s.posts.SetPostPinned(ctx, post.Id, false)
+			return err
+		})
+}
+
+// moderateComment applies the moderation action to a comment within a
+// transaction, recording it in the moderation log of the community of the
+// post of the comment. Only the moderators of the community and admins may
+// moderate its comments.
+func (s Service) moderateComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId,
+	action forum.ModerationAction, reason string, apply func(ctx context.Context, comment forum.Comment) error,
+) (entry *forum.ModerationLogEntry, err error) {
+	err = forum.ValidateModerationReason(action, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.comments.GetCommentById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.posts.GetPostById(ctx, comment.PostId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canModerate(ctx, claims, post.CommunityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		err = apply(ctx, *comment)
+		if err != nil {
+			return err
+		}
+
+		entry, err = s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  post.CommunityId,
+			ModeratorId:  &claims.UserId,
+			Action:       action,
+			PostId:       &post.Id,
+			CommentId:    &comment.Id,
+			TargetUserId: &comment.CommenterId,
+			Reason:       reason,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error moderating comment",
+			"commentId", id,
+			"action", action,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// RemoveComment removes a comment from its thread for the reason, leaving a
+// tombstone for users other than its commenter and the moderators, and
+// resolves its open reports. The tombstone is streamed to the viewers of the
+// post once committed.
+func (s Service) RemoveComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId,
+	reason string,
+) (entry *forum.ModerationLogEntry, err error) {
+	return s.moderateComment(ctx, claims, id, forum.ModerationActionRemoveComment, reason,
+		func(ctx context.Context, comment forum.Comment) error {
+			return s.removeComment(ctx, claims, comment, reason)
+		})
+}
+
+// removeComment removes a comment for the reason, resolving its open reports
+// and publishing its tombstone.
+func (s Service) removeComment(ctx context.Context, claims servicesauth.TokenClaims, comment forum.Comment,
+	reason string,
+) (err error) {
+	comment.RemovedAt, err = s.comments.RemoveComment(ctx, comment.Id, reason)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.reports.ResolveReports(ctx, comment.PostId, &comment.Id, claims.UserId, forum.ReportActionRemove)
+	if err != nil {
+		return err
+	}
+
+	comment.RemovalReason = &reason
+	return s.publishCommentEvent(ctx, forum.EventKindCommentUpdated, comment)
+}
+
+// ApproveComment approves a comment, reinstating it if it was removed. A
+// reinstated comment is streamed to the viewers of the post once committed.
+func (s Service) ApproveComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId,
+	reason string,
+) (entry *forum.ModerationLogEntry, err error) {
+	return s.moderateComment(ctx, claims, id, forum.ModerationActionApproveComment, reason,
+		func(ctx context.Context, comment forum.Comment) (err error) {
+			err = s.comments.ApproveComment(ctx, comment.Id)
+			if err != nil || !comment.IsRemoved() {
+				return err
+			}
+
+			comment.Removal = forum.Removal{}
+			return s.publishCommentEvent(ctx, forum.EventKindCommentUpdated, comment)
+		})
+}
@@ -0,0 +1,154 @@
+package servicesforum
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/util/set"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// NotificationsPage is a page of the notifications of a user, along with the
+// total number of unread notifications.
+type NotificationsPage struct {
+	Notifications []forum.Notification `json:"notifications"`
+	UnreadCount   int                  `json:"unread_count"`
+}
+
+// notifyComment notifies the author of the post or parent comment replied to
+// and the users mentioned in the comment. Users are notified at most once per
+// comment, never of their own comments and only of comments in communities
+// they may see as members. Expected to run within the
+// transaction creating the comment.
+func (s Service) notifyComment(ctx context.Context, post forum.Post, parent *forum.Comment, comment forum.Comment) (
+	err error,
+) {
+	notified := set.New[auth.UserId](set.WithSlice([]auth.UserId{comment.CommenterId}))
+	notify := func(recipientId auth.UserId, kind forum.NotificationKind) error {
+		if notified.Contains(recipientId) {
+			return nil
+		}
+		notified.Add(recipientId)
+
+		notification, err := s.notifications.Notify(ctx, forum.NotificationValue{
+			RecipientId: recipientId,
+			ActorId:     comment.CommenterId,
+			Kind:        kind,
+			PostId:      post.Id,
+			CommentId:   comment.Id,
+		})
+		if err != nil || notification == nil {
+			return err
+		}
+
+		event, err := forum.NewNotificationEvent(*notification)
+		if err != nil {
+			return err
+		}
+		_, err = s.events.PublishEvent(ctx, event)
+		return err
+	}
+
+	switch {
+	case parent == nil:
+		err = notify(post.PosterId, forum.NotificationKindPostReply)
+	case parent.DeletedAt == nil:
+		err = notify(parent.CommenterId, forum.NotificationKindCommentReply)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, username := range forum.ParseMentions(comment.Body) {
+		user, err := s.users.GetUserByUsername(ctx, username)
+		if errors.Is(err, shared.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		err = notify(user.Id, forum.NotificationKindMention)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetNotifications returns the notifications of the user in the claims,
+// newest first, optionally only the unread ones.
+func (s Service) GetNotifications(ctx context.Context, claims servicesauth.TokenClaims, unreadOnly bool,
+	limit int, offset int,
+) (page *NotificationsPage, err error) {
+	notifications, err := s.notifications.GetNotifications(ctx, claims.UserId, unreadOnly, limit, offset,
+		VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.notifications.CountUnread(ctx, claims.UserId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationsPage{
+		Notifications: notifications,
+		UnreadCount:   count,
+	}, nil
+}
+
+// MarkNotificationRead marks a notification of the user in the claims as read.
+// Notifications of other users are reported as not found.
+func (s Service) MarkNotificationRead(ctx context.Context, claims servicesauth.TokenClaims,
+	id forum.NotificationId,
+) (readAt *time.Time, err error) {
+	notification, err := s.notifications.GetNotificationById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.RecipientId != claims.UserId {
+		return nil, shared.NotFoundError{
+			Entity: "notification",
+		}
+	}
+
+	return s.notifications.MarkRead(ctx, id)
+}
+
+// MarkAllNotificationsRead marks every notification of the user in the claims
+// as read, returning how many were unread.
+func (s Service) MarkAllNotificationsRead(ctx context.Context, claims servicesauth.TokenClaims) (
+	count int64, err error,
+) {
+	return s.notifications.MarkAllRead(ctx, claims.UserId)
+}
+
+// GetNotificationPreferences returns which kinds of notifications the user in
+// the claims receives.
+func (s Service) GetNotificationPreferences(ctx context.Context, claims servicesauth.TokenClaims) (
+	preferences forum.NotificationPreferences, err error,
+) {
+	return s.notifications.GetPreferences(ctx, claims.UserId)
+}
+
+// UpdateNotificationPreferences replaces which kinds of notifications the user
+// in the claims receives.
+func (s Service) UpdateNotificationPreferences(ctx context.Context, claims servicesauth.TokenClaims,
+	preferences forum.NotificationPreferences,
+) (err error) {
+	err = s.notifications.UpdatePreferences(ctx, claims.UserId, preferences)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating notification preferences",
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// PollView is a poll as returned to clients, along with the options the
+// requesting user voted for. The vote counts are hidden until the user has
+// voted or the poll has closed, so that early results do not sway votes.
+type PollView struct {
+	MultipleChoice bool                 `json:"multiple_choice"`
+	ClosesAt       *time.Time           `json:"closes_at"`
+	Closed         bool                 `json:"closed"`
+	Options        []PollOptionView     `json:"options"`
+	VoterCount     *int                 `json:"voter_count"`
+	Votes          []forum.PollOptionId `json:"votes"`
+}
+
+// PollOptionView is an option of a poll as returned to clients, with its vote
+// count unless hidden.
+type PollOptionView struct {
+	Id        forum.PollOptionId `json:"id"`
+	Text      string             `json:"text"`
+	VoteCount *int               `json:"vote_count"`
+}
+
+// newPollView returns the view of a poll for a user who voted for the options,
+// if any, at the given time.
+func newPollView(poll forum.Poll, votes []forum.PollOptionId, now time.Time) PollView {
+	view := PollView{
+		MultipleChoice: poll.MultipleChoice,
+		ClosesAt:       poll.ClosesAt,
+		Closed:         poll.IsClosed(now),
+		Options:        make([]PollOptionView, 0, len(poll.Options)),
+		Votes:          votes,
+	}
+	if view.Votes == nil {
+		view.Votes = []forum.PollOptionId{}
+	}
+
+	showResults := view.Closed || len(view.Votes) > 0
+	if showResults {
+		view.VoterCount = &poll.VoterCount
+	}
+	for _, option := range poll.Options {
+		optionView := PollOptionView{
+			Id:   option.Id,
+			Text: option.Text,
+		}
+		if showResults {
+			optionView.VoteCount = &option.VoteCount
+		}
+		view.Options = append(view.Options, optionView)
+	}
+
+	return view
+}
+
+// GetPoll returns the poll of a poll post, if the post is visible to the user
+// in the claims.
+func (s Service) GetPoll(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId) (
+	view *PollView, err error,
+) {
+	post, err := s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getPollView(ctx, claims, post.Id)
+}
+
+// getPollView returns the view of the poll of a post for the user in the
+// claims.
+func (s Service) getPollView(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId) (
+	view *PollView, err error,
+) {
+	poll, err := s.polls.GetPollByPost(ctx, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.polls.GetPollVotes(ctx, claims.UserId, []forum.PostId{postId})
+	if err != nil {
+		return nil, err
+	}
+
+	v := newPollView(*poll, votes[postId], time.Now())
+	return &v, nil
+}
+
+// VotePoll votes for the options of the poll of a post as the user in the
+// claims, and returns the poll with its results. Users vote once per poll,
+// before it closes, and not in removed posts or in communities they are
+// banned from.
+func (s Service) VotePoll(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	optionIds []forum.PollOptionId,
+) (view *PollView, err error) {
+	post, err := s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.IsRemoved() {
+		return nil, ForbiddenError
+	}
+
+	err = s.checkNotBanned(ctx, claims, post.CommunityId)
+	if err != nil {
+		return nil, err
+	}
+
+	poll, err := s.polls.GetPollByPost(ctx, post.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.IsClosed(time.Now()) {
+		return nil, ClosedPollError
+	}
+
+	err = poll.ValidateVote(optionIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		return s.polls.CastPollVote(ctx, post.Id, claims.UserId, optionIds)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error voting in poll",
+			"postId", post.Id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return s.getPollView(ctx, claims, post.Id)
+}
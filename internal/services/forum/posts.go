@@ -0,0 +1,538 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/util/set"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// renderPosts renders the bodies of the posts, resolving their wiki-style
+// links, adds their flairs, tags, link previews and polls, and adds the saved
+// flags, poll votes and unread comment counts for the user in the claims.
+func (s Service) renderPosts(ctx context.Context, claims servicesauth.TokenClaims, posts []forum.Post) (
+	views []PostView, err error,
+) {
+	ids := make([]forum.PostId, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.Id)
+	}
+
+	links, err := s.links.GetLinksByPosts(ctx, ids, VisibleTo(claims))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting links of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	linksByPost := make(map[forum.PostId][]forum.Link, len(posts))
+	for _, link := range links {
+		linksByPost[link.SourcePostId] = append(linksByPost[link.SourcePostId], link)
+	}
+
+	revisionIds, err := s.revisions.GetLatestPostRevisionIds(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting revisions of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	flairIds := make([]forum.FlairId, 0, len(posts))
+	for _, post := range posts {
+		if post.FlairId != nil {
+			flairIds = append(flairIds, *post.FlairId)
+		}
+	}
+
+	flairs, err := s.flairs.GetFlairsByIds(ctx, flairIds)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting flairs of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	flairsById := make(map[forum.FlairId]forum.Flair, len(flairs))
+	for _, flair := range flairs {
+		flairsById[flair.Id] = flair
+	}
+
+	tags, err := s.tags.GetTagsByPosts(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting tags of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	attachments, err := s.attachments.GetAttachmentsByPosts(ctx, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting attachments of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	attachmentsByPost := make(map[forum.PostId][]AttachmentView, len(posts))
+	for _, attachment := range attachments {
+		attachmentsByPost[*attachment.PostId] = append(attachmentsByPost[*attachment.PostId],
+			newAttachmentView(attachment))
+	}
+
+	urls := make([]string, 0, len(posts))
+	for _, post := range posts {
+		if post.Url != "" {
+			urls = append(urls, post.Url)
+		}
+	}
+
+	previews, err := s.linkPreviews.GetLinkPreviewsByUrls(ctx, urls)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting link previews of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	previewsByUrl := make(map[string]forum.LinkPreview, len(previews))
+	for _, preview := range previews {
+		previewsByUrl[preview.Url] = preview
+	}
+
+	pollIds := make([]forum.PostId, 0, len(posts))
+	for _, post := range posts {
+		if post.Type == forum.PostTypePoll {
+			pollIds = append(pollIds, post.Id)
+		}
+	}
+
+	polls, err := s.polls.GetPollsByPosts(ctx, pollIds)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting polls of posts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	votes, err := s.polls.GetPollVotes(ctx, claims.UserId, pollIds)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting poll votes",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	now := time.Now()
+	pollsByPost := make(map[forum.PostId]PollView, len(polls))
+	for _, poll := range polls {
+		pollsByPost[poll.PostId] = newPollView(poll, votes[poll.PostId], now)
+	}
+
+	savedIds, err := s.savedItems.GetSavedPostIds(ctx, claims.UserId, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting saved posts",
+			"error", err,
+		)
+		return nil, err
+	}
+	saved := set.New[forum.PostId](set.WithSlice(savedIds))
+
+	unreadCounts, err := s.reads.GetUnreadCommentCounts(ctx, claims.UserId, ids)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting unread comment counts",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	views = make([]PostView, 0, len(posts))
+	for _, post := range posts {
+		html, err := s.renderer.Render(revisionIds[post.Id], post.Body, linksByPost[post.Id])
+		if err != nil {
+			s.logger.ErrorContext(ctx, "forum.service :: Error rendering post body",
+				"postId", post.Id,
+				"error", err,
+			)
+			return nil, err
+		}
+
+		view := PostView{
+			Post:        post,
+			BodyHtml:    html,
+			Tags:        tags[post.Id],
+			Attachments: attachmentsByPost[post.Id],
+			Saved:       saved.Contains(post.Id),
+
+			UnreadCommentCount: unreadCounts[post.Id],
+		}
+		if view.Tags == nil {
+			view.Tags = []string{}
+		}
+		if view.Attachments == nil {
+			view.Attachments = []AttachmentView{}
+		}
+		if post.FlairId != nil {
+			if flair, ok := flairsById[*post.FlairId]; ok {
+				view.Flair = &flair
+			}
+		}
+		if preview, ok := previewsByUrl[post.Url]; ok {
+			view.LinkPreview = &preview
+		}
+		if poll, ok := pollsByPost[post.Id]; ok {
+			view.Poll = &poll
+		}
+
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+// renderPost renders the body of the post, resolving its wiki-style links.
+func (s Service) renderPost(ctx context.Context, claims servicesauth.TokenClaims, post forum.Post) (
+	view *PostView, err error,
+) {
+	views, err := s.renderPosts(ctx, claims, []forum.Post{post})
+	if err != nil {
+		return nil, err
+	}
+
+	return &views[0], nil
+}
+
+// CreatePost creates a post in a community as the user in the claims, along
+// with its first revision, its labels and, for poll posts, its poll. Links in
+// the body are stored, and dangling links to the title of the post are
+// resolved to it. The preview of the URL of a link post is requested, to be
+// fetched in the background. The post is streamed to the subscribers of the
+// community once committed. The type and flair of the post must be allowed by
+// the community, and the user must be allowed to post in it, see checkCanPost.
+func (s Service) CreatePost(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	value forum.PostValue, labels forum.PostLabels, poll *forum.PollValue,
+) (view *PostView, err error) {
+	if value.Type == "" {
+		value.Type = forum.PostTypeText
+	}
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	err = value.ValidatePoll(poll)
+	if err != nil {
+		return nil, err
+	}
+	if poll != nil {
+		normalized, err := poll.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		poll = &normalized
+	}
+
+	labels, err = labels.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	err = community.Settings.ValidatePost(value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validateFlair(ctx, *community, labels.FlairId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCanPost(ctx, claims, *community)
+	if err != nil {
+		return nil, err
+	}
+
+	var post *forum.Post
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		post, err = s.posts.CreatePost(ctx, communityId, claims.UserId, value)
+		if err != nil {
+			return err
+		}
+
+		err = s.setPostLabels(ctx, post.Id, labels)
+		if err != nil {
+			return err
+		}
+		post.FlairId = labels.FlairId
+
+		_, err = s.revisions.CreatePostRevision(ctx, post.Id, claims.UserId, value)
+		if err != nil {
+			return err
+		}
+
+		err = s.links.ReplacePostLinks(ctx, post.Id, forum.ParseWikiLinks(post.Body))
+		if err != nil {
+			return err
+		}
+
+		err = s.links.ResolveDanglingLinks(ctx, post.Id)
+		if err != nil {
+			return err
+		}
+
+		if post.Type == forum.PostTypeLink {
+			err = s.linkPreviews.RequestLinkPreview(ctx, post.Url)
+			if err != nil {
+				return err
+			}
+		}
+
+		if poll != nil {
+			_, err = s.polls.CreatePoll(ctx, post.Id, *poll)
+			if err != nil {
+				return err
+			}
+		}
+
+		event, err := forum.NewPostCreatedEvent(*post)
+		if err != nil {
+			return err
+		}
+		_, err = s.events.PublishEvent(ctx, event)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating post",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return s.renderPost(ctx, claims, *post)
+}
+
+// GetPost returns a post by its ID. Removed posts are only found by their
+// posters and the moderators of their communities.
+func (s Service) GetPost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId) (
+	view *PostView, err error,
+) {
+	post, err := s.getVisiblePost(ctx, claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderPost(ctx, claims, *post)
+}
+
+// GetPostsByCommunity returns the posts in a community matching the filter
+// sorted by creation date, after its pinned posts. Removed posts are only
+// listed to the moderators of the community, and private communities are only
+// found by their members and moderators.
+func (s Service) GetPostsByCommunity(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId, filter forum.PostFilter, limit int, offset int,
+) (views []PostView, err error) {
+	filter, err = filter.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []dbports.ReadOption{VisibleTo(claims)}
+	ok, err := s.canModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, dbports.IncludeRemoved())
+	}
+
+	posts, err := s.posts.GetPostsByCommunitySortedCreatedAt(ctx, communityId, filter, limit, offset, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.renderPosts(ctx, claims, posts)
+}
+
+// PostPatch contains the fields of a post to update. Nil fields are left
+// unchanged.
+type PostPatch struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
+
+// UpdatePost updates the title and body of a post, appending a revision. Only
+// the poster and admins may update a post.
+func (s Service) UpdatePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId, patch PostPatch) (
+	updatedAt *time.Time, err error,
+) {
+	post, err := s.posts.GetPostById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	value := post.PostValue
+	if patch.Title != nil {
+		value.Title = *patch.Title
+	}
+	if patch.Body != nil {
+		value.Body = *patch.Body
+	}
+
+	return s.updatePost(ctx, claims, *post, value)
+}
+
+// updatePost updates the post to the value, appending a revision. A changed
+// title leaves a redirect from the previous title and resolves dangling links
+// to the new one, while a changed body replaces the links of the post.
+// Permissions are expected to have been checked.
+func (s Service) updatePost(ctx context.Context, claims servicesauth.TokenClaims, post forum.Post,
+	value forum.PostValue,
+) (updatedAt *time.Time, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if value == post.PostValue {
+		return &post.UpdatedAt, nil
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		if value.Title != post.Title {
+			updatedAt, err = s.posts.UpdatePostTitle(ctx, post.Id, value.Title)
+			if err != nil {
+				return err
+			}
+
+			err = s.links.ResolveDanglingLinks(ctx, post.Id)
+			if err != nil {
+				return err
+			}
+		}
+
+		if value.Body != post.Body {
+			updatedAt, err = s.posts.UpdatePostContent(ctx, post.Id, value.Body)
+			if err != nil {
+				return err
+			}
+
+			err = s.links.ReplacePostLinks(ctx, post.Id, forum.ParseWikiLinks(value.Body))
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = s.revisions.CreatePostRevision(ctx, post.Id, claims.UserId, value)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error updating post",
+			"postId", post.Id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// MovePost moves a post to another community, leaving a redirect from the
+// previous community and resolving dangling links to the post in its new
+// community. The flair of the post is replaced with the given flair of the new
+// community, or cleared if nil. Only the poster and admins may move a post, to
+// communities which allow its type and flair and in which the user may post.
+func (s Service) MovePost(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	targetCommunityId forum.CommunityId, flairId *forum.FlairId,
+) (updatedAt *time.Time, err error) {
+	post, err := s.posts.GetPostById(ctx, postId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	target, err := s.communities.GetCommunityById(ctx, targetCommunityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if post.CommunityId == targetCommunityId {
+		return &post.UpdatedAt, nil
+	}
+
+	err = target.Settings.ValidatePost(post.PostValue)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validateFlair(ctx, *target, flairId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCanPost(ctx, claims, *target)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		updatedAt, err = s.posts.MovePost(ctx, postId, targetCommunityId)
+		if err != nil {
+			return err
+		}
+
+		err = s.posts.SetPostFlair(ctx, postId, flairId)
+		if err != nil {
+			return err
+		}
+
+		return s.links.ResolveDanglingLinks(ctx, postId)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error moving post",
+			"postId", postId,
+			"communityId", targetCommunityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// DeletePost soft deletes a post. Only the poster and admins may delete a
+// post.
+func (s Service) DeletePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId) (
+	deletedAt *time.Time, err error,
+) {
+	post, err := s.posts.GetPostById(ctx, id, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	return s.posts.DeletePost(ctx, id)
+}
@@ -0,0 +1,36 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// MarkPostRead marks a post as seen by the user in the claims, so that its
+// comments so far are no longer counted as unread.
+func (s Service) MarkPostRead(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId) (
+	lastSeenAt *time.Time, err error,
+) {
+	_, err = s.posts.GetPostById(ctx, postId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reads.MarkPostRead(ctx, claims.UserId, postId)
+}
+
+// MarkCommunityRead marks every post in a community as seen by the user in the
+// claims.
+func (s Service) MarkCommunityRead(ctx context.Context, claims servicesauth.TokenClaims,
+	communityId forum.CommunityId,
+) (readAt *time.Time, err error) {
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reads.MarkCommunityRead(ctx, claims.UserId, communityId)
+}
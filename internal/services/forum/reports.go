@@ -0,0 +1,294 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// ReportedItemView is an item in the moderation queue, with the reported post
+// and, for reported comments, the comment.
+type ReportedItemView struct {
+	forum.ReportedItem
+
+	Post    *forum.Post    `json:"post"`
+	Comment *forum.Comment `json:"comment,omitempty"`
+}
+
+// ReportPost reports a post to the moderators of its community as the user
+// in the claims.
+func (s Service) ReportPost(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	value forum.ReportValue,
+) (report *forum.Report, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err = s.reports.CreateReport(ctx, claims.UserId, postId, nil, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error reporting post",
+			"postId", postId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ReportComment reports a comment to the moderators of the community of its
+// post as the user in the claims.
+func (s Service) ReportComment(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId,
+	value forum.ReportValue,
+) (report *forum.Report, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.comments.GetCommentById(ctx, commentId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.getVisiblePost(ctx, claims, comment.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err = s.reports.CreateReport(ctx, claims.UserId, comment.PostId, &comment.Id, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error reporting comment",
+			"commentId", commentId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetReportQueue returns the posts and comments with open reports in a
+// community, in the order of the sort. Only the moderators of the community
+// and admins may read the queue.
+func (s Service) GetReportQueue(ctx context.Context, claims servicesauth.TokenClaims, communityId forum.CommunityId,
+	sort forum.ReportQueueSort, limit int, offset int,
+) (views []ReportedItemView, err error) {
+	err = sort.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.communities.GetCommunityById(ctx, communityId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canModerate(ctx, claims, communityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	items, err := s.reports.GetReportQueue(ctx, communityId, sort, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error getting report queue",
+			"communityId", communityId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	views = make([]ReportedItemView, 0, len(items))
+	for _, item := range items {
+		view := ReportedItemView{
+			ReportedItem: item,
+		}
+
+		view.Post, err = s.posts.GetPostById(ctx, item.PostId, dbports.IncludeDeleted(), VisibleTo(claims))
+		if err != nil {
+			return nil, err
+		}
+
+		if item.CommentId != nil {
+			view.Comment, err = s.comments.GetCommentById(ctx, *item.CommentId, dbports.IncludeDeleted(),
+				VisibleTo(claims))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		views = append(views, view)
+	}
+
+	return views, nil
+}
+
+// reportTarget is a reported post or comment, with the action removing it.
+type reportTarget struct {
+	communityId forum.CommunityId
+	postId      forum.PostId
+	commentId   *forum.CommentId
+	authorId    auth.UserId
+
+	removal forum.ModerationAction
+	remove  func(ctx context.Context) error
+}
+
+// ResolvePostReports resolves the open reports of a post, dismissing them,
+// removing the post, or removing the post and banning its poster from the
+// community until the expiry, if any.
+func (s Service) ResolvePostReports(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	value forum.ResolutionValue,
+) (resolution *forum.Resolution, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.posts.GetPostById(ctx, postId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolveReports(ctx, claims, value, reportTarget{
+		communityId: post.CommunityId,
+		postId:      post.Id,
+		authorId:    post.PosterId,
+		removal:     forum.ModerationActionRemovePost,
+		remove: func(ctx context.Context) error {
+			if post.IsRemoved() {
+				return nil
+			}
+			return s.removePost(ctx, claims, *post, value.Reason)
+		},
+	})
+}
+
+// ResolveCommentReports resolves the open reports of a comment, dismissing
+// them, removing the comment, or removing the comment and banning its
+// commenter from the community until the expiry, if any.
+func (s Service) ResolveCommentReports(ctx context.Context, claims servicesauth.TokenClaims,
+	commentId forum.CommentId, value forum.ResolutionValue,
+) (resolution *forum.Resolution, err error) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.comments.GetCommentById(ctx, commentId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.posts.GetPostById(ctx, comment.PostId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolveReports(ctx, claims, value, reportTarget{
+		communityId: post.CommunityId,
+		postId:      post.Id,
+		commentId:   &comment.Id,
+		authorId:    comment.CommenterId,
+		removal:     forum.ModerationActionRemoveComment,
+		remove: func(ctx context.Context) error {
+			if comment.IsRemoved() {
+				return nil
+			}
+			return s.removeComment(ctx, claims, *comment, value.Reason)
+		},
+	})
+}
+
+// resolveReports resolves the open reports of the target within a
+// transaction, recording the actions taken in the moderation log of the
+// community. Only the moderators of the community and admins may resolve its
+// reports.
+func (s Service) resolveReports(ctx context.Context, claims servicesauth.TokenClaims, value forum.ResolutionValue,
+	target reportTarget,
+) (resolution *forum.Resolution, err error) {
+	ok, err := s.canModerate(ctx, claims, target.communityId)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ForbiddenError
+	}
+
+	resolution = &forum.Resolution{
+		Action:  value.Action,
+		Entries: []forum.ModerationLogEntry{},
+	}
+	logAction := func(ctx context.Context, action forum.ModerationAction) error {
+		entry, err := s.moderation.LogAction(ctx, forum.ModerationLogValue{
+			CommunityId:  target.communityId,
+			ModeratorId:  &claims.UserId,
+			Action:       action,
+			PostId:       &target.postId,
+			CommentId:    target.commentId,
+			TargetUserId: &target.authorId,
+			Reason:       value.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		resolution.Entries = append(resolution.Entries, *entry)
+		return nil
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		resolution.Resolved, err = s.reports.ResolveReports(ctx, target.postId, target.commentId, claims.UserId,
+			value.Action)
+		if err != nil {
+			return err
+		} else if resolution.Resolved == 0 {
+			return shared.NotFoundError{
+				Entity: "report",
+			}
+		}
+
+		if value.Action == forum.ReportActionDismiss {
+			return logAction(ctx, forum.ModerationActionDismissReports)
+		}
+
+		err = target.remove(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = logAction(ctx, target.removal)
+		if err != nil || value.Action != forum.ReportActionBan {
+			return err
+		}
+
+		_, err = s.bans.BanUser(ctx, target.communityId, target.authorId, claims.UserId, value.Ban())
+		if err != nil {
+			return err
+		}
+
+		return logAction(ctx, forum.ModerationActionBanUser)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error resolving reports",
+			"postId", target.postId,
+			"action", value.Action,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return resolution, nil
+}
@@ -0,0 +1,147 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// GetPostRevisions returns the revisions of a post, latest first.
+func (s Service) GetPostRevisions(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId, limit int, offset int) (
+	revisions []forum.PostRevision, err error,
+) {
+	_, err = s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisions.GetPostRevisions(ctx, postId, limit, offset)
+}
+
+// GetPostRevision returns a revision of a post by its number.
+func (s Service) GetPostRevision(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId, revision int) (
+	postRevision *forum.PostRevision, err error,
+) {
+	_, err = s.getVisiblePost(ctx, claims, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisions.GetPostRevision(ctx, postId, revision)
+}
+
+// DiffPostRevisions returns the unified diff between the bodies of two
+// revisions of a post.
+func (s Service) DiffPostRevisions(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId, from int, to int) (
+	diff *forum.RevisionDiff, err error,
+) {
+	fromRevision, err := s.GetPostRevision(ctx, claims, postId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := s.revisions.GetPostRevision(ctx, postId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return forum.NewRevisionDiff(
+		fromRevision.RevisionMetadata, fromRevision.Body,
+		toRevision.RevisionMetadata, toRevision.Body,
+	)
+}
+
+// RestorePostRevision restores the title and body of a post to those of one of
+// its revisions. The restore is recorded as a new revision. Only the poster and
+// admins may restore a post.
+func (s Service) RestorePostRevision(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	revision int,
+) (updatedAt *time.Time, err error) {
+	post, err := s.posts.GetPostById(ctx, postId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	postRevision, err := s.revisions.GetPostRevision(ctx, postId, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// Revisions only record the title and body, the type and URL are kept.
+	value := post.PostValue
+	value.Title = postRevision.Title
+	value.Body = postRevision.Body
+
+	return s.updatePost(ctx, claims, *post, value)
+}
+
+// GetCommentRevisions returns the revisions of a comment, latest first.
+func (s Service) GetCommentRevisions(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId, limit int, offset int) (
+	revisions []forum.CommentRevision, err error,
+) {
+	_, _, err = s.getVisibleComment(ctx, claims, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisions.GetCommentRevisions(ctx, commentId, limit, offset)
+}
+
+// GetCommentRevision returns a revision of a comment by its number.
+func (s Service) GetCommentRevision(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId, revision int) (
+	commentRevision *forum.CommentRevision, err error,
+) {
+	_, _, err = s.getVisibleComment(ctx, claims, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.revisions.GetCommentRevision(ctx, commentId, revision)
+}
+
+// DiffCommentRevisions returns the unified diff between the bodies of two
+// revisions of a comment.
+func (s Service) DiffCommentRevisions(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId, from int, to int) (
+	diff *forum.RevisionDiff, err error,
+) {
+	fromRevision, err := s.GetCommentRevision(ctx, claims, commentId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := s.revisions.GetCommentRevision(ctx, commentId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return forum.NewRevisionDiff(
+		fromRevision.RevisionMetadata, fromRevision.Body,
+		toRevision.RevisionMetadata, toRevision.Body,
+	)
+}
+
+// RestoreCommentRevision restores the body of a comment to that of one of its
+// revisions. The restore is recorded as a new revision. Restoring a revision
+// edits the comment, see getEditableComment.
+func (s Service) RestoreCommentRevision(ctx context.Context, claims servicesauth.TokenClaims,
+	commentId forum.CommentId, revision int,
+) (updatedAt *time.Time, err error) {
+	comment, err := s.getEditableComment(ctx, claims, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	commentRevision, err := s.revisions.GetCommentRevision(ctx, commentId, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateCommentBody(ctx, claims, *comment, commentRevision.Body)
+}
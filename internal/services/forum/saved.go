@@ -0,0 +1,181 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// getOwnCollection returns a collection of the user in the claims. Collections
+// of other users are reported as not found, as saved items are private.
+func (s Service) getOwnCollection(ctx context.Context, claims servicesauth.TokenClaims, id forum.CollectionId) (
+	collection *forum.Collection, err error,
+) {
+	collection, err = s.savedItems.GetCollectionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if collection.UserId != claims.UserId {
+		return nil, shared.NotFoundError{
+			Entity: "collection",
+		}
+	}
+
+	return collection, nil
+}
+
+// CreateCollection creates a collection of saved items for the user in the
+// claims.
+func (s Service) CreateCollection(ctx context.Context, claims servicesauth.TokenClaims, value forum.CollectionValue) (
+	collection *forum.Collection, err error,
+) {
+	err = value.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err = s.savedItems.CreateCollection(ctx, claims.UserId, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error creating collection",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// GetCollections returns the collections of the user in the claims sorted by
+// name.
+func (s Service) GetCollections(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	collections []forum.Collection, err error,
+) {
+	return s.savedItems.GetCollections(ctx, claims.UserId, limit, offset)
+}
+
+// DeleteCollection deletes a collection of the user in the claims. Its saved
+// items are kept outside of any collection.
+func (s Service) DeleteCollection(ctx context.Context, claims servicesauth.TokenClaims, id forum.CollectionId) (
+	err error,
+) {
+	_, err = s.getOwnCollection(ctx, claims, id)
+	if err != nil {
+		return err
+	}
+
+	return s.savedItems.DeleteCollection(ctx, id)
+}
+
+// validateSavedItem checks the value of an item to save, and that its
+// collection belongs to the user in the claims.
+func (s Service) validateSavedItem(ctx context.Context, claims servicesauth.TokenClaims, value forum.SavedItemValue) (
+	err error,
+) {
+	err = value.Validate()
+	if err != nil {
+		return err
+	}
+
+	if value.CollectionId != nil {
+		_, err = s.getOwnCollection(ctx, claims, *value.CollectionId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SavePost saves a post for the user in the claims, replacing the collection
+// and note if the post is already saved.
+func (s Service) SavePost(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId,
+	value forum.SavedItemValue,
+) (item *forum.SavedItem, err error) {
+	err = s.validateSavedItem(ctx, claims, value)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.posts.GetPostById(ctx, postId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err = s.savedItems.SavePost(ctx, claims.UserId, postId, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error saving post",
+			"postId", postId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// SaveComment saves a comment for the user in the claims, replacing the
+// collection and note if the comment is already saved.
+func (s Service) SaveComment(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId,
+	value forum.SavedItemValue,
+) (item *forum.SavedItem, err error) {
+	err = s.validateSavedItem(ctx, claims, value)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.comments.GetCommentById(ctx, commentId, VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err = s.savedItems.SaveComment(ctx, claims.UserId, commentId, value)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error saving comment",
+			"commentId", commentId,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// UnsavePost removes a post from the saved items of the user in the claims,
+// returning whether it was saved.
+func (s Service) UnsavePost(ctx context.Context, claims servicesauth.TokenClaims, postId forum.PostId) (
+	removed bool, err error,
+) {
+	return s.savedItems.UnsavePost(ctx, claims.UserId, postId)
+}
+
+// UnsaveComment removes a comment from the saved items of the user in the
+// claims, returning whether it was saved.
+func (s Service) UnsaveComment(ctx context.Context, claims servicesauth.TokenClaims, commentId forum.CommentId) (
+	removed bool, err error,
+) {
+	return s.savedItems.UnsaveComment(ctx, claims.UserId, commentId)
+}
+
+// GetSavedItems returns the saved items of the user in the claims matching the
+// filter, most recently saved first. Items in communities no longer visible to
+// the user are left out.
+func (s Service) GetSavedItems(ctx context.Context, claims servicesauth.TokenClaims, filter forum.SavedItemFilter,
+	limit int, offset int,
+) (items []forum.SavedItem, err error) {
+	err = filter.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.CollectionId != nil {
+		_, err = s.getOwnCollection(ctx, claims, *filter.CollectionId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.savedItems.GetSavedItems(ctx, claims.UserId, filter, limit, offset, VisibleTo(claims))
+}
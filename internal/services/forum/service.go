@@ -0,0 +1,174 @@
+package servicesforum
+
+import (
+	"context"
+	"log/slog"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	blobports "greddit/internal/ports/blob"
+	dbports "greddit/internal/ports/db"
+	dbportsauth "greddit/internal/ports/db/auth"
+	dbportsforum "greddit/internal/ports/db/forum"
+	servicesauth "greddit/internal/services/auth"
+	servicesrender "greddit/internal/services/render"
+)
+
+// Service is the forum service.
+type Service struct {
+	logger   *slog.Logger
+	txs      dbports.Transactional
+	renderer servicesrender.Service
+	blobs    blobports.BlobStore
+
+	communities   dbportsforum.CommunitiesRepo
+	posts         dbportsforum.PostsRepo
+	comments      dbportsforum.CommentsRepo
+	links         dbportsforum.LinksRepo
+	revisions     dbportsforum.RevisionsRepo
+	subscriptions dbportsforum.SubscriptionsRepo
+	savedItems    dbportsforum.SavedItemsRepo
+	reads         dbportsforum.ReadsRepo
+	notifications dbportsforum.NotificationsRepo
+	events        dbportsforum.EventsRepo
+	moderation    dbportsforum.ModerationRepo
+	reports       dbportsforum.ReportsRepo
+	bans          dbportsforum.BansRepo
+	members       dbportsforum.MembersRepo
+	flairs        dbportsforum.FlairsRepo
+	tags          dbportsforum.TagsRepo
+	linkPreviews  dbportsforum.LinkPreviewsRepo
+	attachments   dbportsforum.AttachmentsRepo
+	polls         dbportsforum.PollsRepo
+	users         dbportsauth.UsersRepo
+	jobs          dbports.JobsRepo
+}
+
+// Repos contains the repositories used by the forum service.
+type Repos struct {
+	Communities   dbportsforum.CommunitiesRepo
+	Posts         dbportsforum.PostsRepo
+	Comments      dbportsforum.CommentsRepo
+	Links         dbportsforum.LinksRepo
+	Revisions     dbportsforum.RevisionsRepo
+	Subscriptions dbportsforum.SubscriptionsRepo
+	SavedItems    dbportsforum.SavedItemsRepo
+	Reads         dbportsforum.ReadsRepo
+	Notifications dbportsforum.NotificationsRepo
+	Events        dbportsforum.EventsRepo
+	Moderation    dbportsforum.ModerationRepo
+	Reports       dbportsforum.ReportsRepo
+	Bans          dbportsforum.BansRepo
+	Members       dbportsforum.MembersRepo
+	Flairs        dbportsforum.FlairsRepo
+	Tags          dbportsforum.TagsRepo
+	LinkPreviews  dbportsforum.LinkPreviewsRepo
+	Attachments   dbportsforum.AttachmentsRepo
+	Polls         dbportsforum.PollsRepo
+	Users         dbportsauth.UsersRepo
+	Jobs          dbports.JobsRepo
+}
+
+// NewService creates a new Service. The content of attachments is stored in
+// the blob store.
+func NewService(logger *slog.Logger, txs dbports.Transactional, renderer servicesrender.Service,
+	blobs blobports.BlobStore, repos Repos,
+) Service {
+	return Service{
+		logger:   logger,
+		txs:      txs,
+		renderer: renderer,
+		blobs:    blobs,
+
+		communities:   repos.Communities,
+		posts:         repos.Posts,
+		comments:      repos.Comments,
+		links:         repos.Links,
+		revisions:     repos.Revisions,
+		subscriptions: repos.Subscriptions,
+		savedItems:    repos.SavedItems,
+		reads:         repos.Reads,
+		notifications: repos.Notifications,
+		events:        repos.Events,
+		moderation:    repos.Moderation,
+		reports:       repos.Reports,
+		bans:          repos.Bans,
+		members:       repos.Members,
+		flairs:        repos.Flairs,
+		tags:          repos.Tags,
+		linkPreviews:  repos.LinkPreviews,
+		attachments:   repos.Attachments,
+		polls:         repos.Polls,
+		users:         repos.Users,
+		jobs:          repos.Jobs,
+	}
+}
+
+// withTx runs the function within a transaction, committing if the function
+// succeeds. Must not be nested.
+func (s Service) withTx(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	ctx, err = s.txs.CtxTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.txs.TxRollback(ctx)
+
+	err = f(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.txs.TxCommit(ctx)
+}
+
+// PostView is a post as returned to clients, along with its rendered body,
+// its flair, tags and attachments, the preview of its URL for link posts, the
+// poll of poll posts, whether the requesting user has saved it and how many
+// comments the user has not read yet.
+type PostView struct {
+	forum.Post
+
+	BodyHtml    string             `json:"body_html"`
+	Flair       *forum.Flair       `json:"flair"`
+	Tags        []string           `json:"tags"`
+	Attachments []AttachmentView   `json:"attachments"`
+	LinkPreview *forum.LinkPreview `json:"link_preview"`
+	Poll        *PollView          `json:"poll"`
+	Saved       bool               `json:"saved"`
+
+	UnreadCommentCount int `json:"unread_comment_count"`
+}
+
+// CommentView is a comment as returned to clients, along with its rendered
+// body and attachments, whether the requesting user has saved it and whether
+// it was written since the user last saw its post.
+type CommentView struct {
+	forum.Comment
+
+	BodyHtml    string           `json:"body_html"`
+	Attachments []AttachmentView `json:"attachments"`
+	Saved       bool             `json:"saved"`
+	New         bool             `json:"new"`
+}
+
+// isAdmin returns whether the claims belong to an admin.
+func isAdmin(claims servicesauth.TokenClaims) bool {
+	return auth.Role(claims.Role) == auth.RoleAdmin
+}
+
+// VisibleTo returns the read option restricting reads to the communities
+// visible to the user in the claims, and their content. Admins see every
+// community.
+func VisibleTo(claims servicesauth.TokenClaims) dbports.ReadOption {
+	if isAdmin(claims) {
+		return dbports.IncludePrivate()
+	}
+	return dbports.VisibleTo(&claims.UserId)
+}
+
+// canModify returns whether the claims allow modifying content owned by the
+// given user. Admins may modify all content.
+func canModify(claims servicesauth.TokenClaims, ownerId auth.UserId) bool {
+	return claims.UserId == ownerId || isAdmin(claims)
+}
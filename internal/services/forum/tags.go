@@ -0,0 +1,96 @@
+package servicesforum
+
+import (
+	"context"
+
+	"greddit/internal/domains/forum"
+
+	servicesauth "greddit/internal/services/auth"
+)
+
+// SearchTags returns the tags starting with the prefix, most used first, for
+// autocompletion. Only posts the user in the claims can see are counted, and
+// the most used tags are returned if the prefix is empty.
+func (s Service) SearchTags(ctx context.Context, claims servicesauth.TokenClaims, prefix string, limit int) (
+	tags []forum.Tag, err error,
+) {
+	if prefix != "" {
+		prefix, err = forum.NormalizeTag(prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.tags.SearchTags(ctx, prefix, limit, VisibleTo(claims))
+}
+
+// RenameTag renames a tag on every post carrying it. Only admins may rename
+// tags, and tags are merged with MergeTags rather than renamed to an existing
+// tag.
+func (s Service) RenameTag(ctx context.Context, claims servicesauth.TokenClaims, name string, newName string) (
+	err error,
+) {
+	if !isAdmin(claims) {
+		return ForbiddenError
+	}
+
+	name, err = forum.NormalizeTag(name)
+	if err != nil {
+		return err
+	}
+	newName, err = forum.NormalizeTag(newName)
+	if err != nil {
+		return err
+	}
+
+	if name == newName {
+		return nil
+	}
+
+	err = s.tags.RenameTag(ctx, name, newName)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error renaming tag",
+			"tag", name,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// MergeTags replaces the source tag with the target tag on every post carrying
+// it, and deletes the source tag. Only admins may merge tags.
+func (s Service) MergeTags(ctx context.Context, claims servicesauth.TokenClaims, source string, target string) (
+	err error,
+) {
+	if !isAdmin(claims) {
+		return ForbiddenError
+	}
+
+	source, err = forum.NormalizeTag(source)
+	if err != nil {
+		return err
+	}
+	target, err = forum.NormalizeTag(target)
+	if err != nil {
+		return err
+	}
+
+	if source == target {
+		return nil
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		return s.tags.MergeTags(ctx, source, target)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error merging tags",
+			"tag", source,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
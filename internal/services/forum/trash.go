@@ -0,0 +1,181 @@
+package servicesforum
+
+import (
+	"context"
+	"time"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+
+	dbports "greddit/internal/ports/db"
+	servicesauth "greddit/internal/services/auth"
+)
+
+// trashOwner returns the user whose trash the claims may view, or nil for
+// admins, who may view the trash of all users.
+func trashOwner(claims servicesauth.TokenClaims) *auth.UserId {
+	if isAdmin(claims) {
+		return nil
+	}
+	return &claims.UserId
+}
+
+// GetDeletedCommunities returns the soft-deleted communities. Only admins may
+// view deleted communities.
+func (s Service) GetDeletedCommunities(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	communities []forum.Community, err error,
+) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	return s.communities.GetDeletedCommunities(ctx, limit, offset)
+}
+
+// GetDeletedPosts returns the posts which were soft deleted individually.
+// Users see their own deleted posts, while admins see those of all users.
+func (s Service) GetDeletedPosts(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	posts []forum.Post, err error,
+) {
+	return s.posts.GetDeletedPosts(ctx, trashOwner(claims), limit, offset)
+}
+
+// GetDeletedComments returns the soft-deleted comments. Users see their own
+// deleted comments, while admins see those of all users.
+func (s Service) GetDeletedComments(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	comments []forum.Comment, err error,
+) {
+	return s.comments.GetDeletedComments(ctx, trashOwner(claims), limit, offset)
+}
+
+// GetDeletedUsers returns the soft-deleted users. Only admins may view deleted
+// users.
+func (s Service) GetDeletedUsers(ctx context.Context, claims servicesauth.TokenClaims, limit int, offset int) (
+	users []auth.User, err error,
+) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	return s.users.GetDeletedUsers(ctx, limit, offset)
+}
+
+// RestoreCommunity restores a soft-deleted community, along with the posts
+// which were deleted with it. Only admins may restore a community.
+func (s Service) RestoreCommunity(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommunityId) (
+	updatedAt *time.Time, err error,
+) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	updatedAt, err = s.communities.RestoreCommunity(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error restoring community",
+			"communityId", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// RestorePost restores a soft-deleted post. Posts in a deleted community are
+// restored along with the community instead. Only the poster and admins may
+// restore a post.
+func (s Service) RestorePost(ctx context.Context, claims servicesauth.TokenClaims, id forum.PostId) (
+	updatedAt *time.Time, err error,
+) {
+	post, err := s.posts.GetPostById(ctx, id, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, post.PosterId) {
+		return nil, ForbiddenError
+	}
+
+	community, err := s.communities.GetCommunityById(ctx, post.CommunityId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+	if community.DeletedAt != nil {
+		return nil, DeletedTargetError
+	}
+
+	updatedAt, err = s.posts.RestorePost(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error restoring post",
+			"postId", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// RestoreComment restores a soft-deleted comment. Comments on a deleted post
+// cannot be restored until the post is. Only the commenter and admins may
+// restore a comment.
+func (s Service) RestoreComment(ctx context.Context, claims servicesauth.TokenClaims, id forum.CommentId) (
+	updatedAt *time.Time, err error,
+) {
+	comment, err := s.comments.GetCommentById(ctx, id, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canModify(claims, comment.CommenterId) {
+		return nil, ForbiddenError
+	}
+
+	post, err := s.posts.GetPostById(ctx, comment.PostId, dbports.IncludeDeleted(), VisibleTo(claims))
+	if err != nil {
+		return nil, err
+	}
+	if post.DeletedAt != nil {
+		return nil, DeletedTargetError
+	}
+
+	err = s.withTx(ctx, func(ctx context.Context) (err error) {
+		updatedAt, err = s.comments.RestoreComment(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		comment.DeletedAt = nil
+		comment.UpdatedAt = *updatedAt
+		return s.publishCommentEvent(ctx, forum.EventKindCommentUpdated, *comment)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error restoring comment",
+			"commentId", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
+
+// RestoreUser restores a soft-deleted user. Only admins may restore a user.
+func (s Service) RestoreUser(ctx context.Context, claims servicesauth.TokenClaims, id auth.UserId) (
+	updatedAt *time.Time, err error,
+) {
+	if !isAdmin(claims) {
+		return nil, ForbiddenError
+	}
+
+	updatedAt, err = s.users.RestoreUser(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "forum.service :: Error restoring user",
+			"userId", id,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	return updatedAt, nil
+}
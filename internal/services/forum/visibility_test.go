@@ -0,0 +1,424 @@
+package servicesforum
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/infra/blob/localfs"
+	"greddit/internal/infra/db/postgres"
+	"greddit/internal/test"
+
+	authdb "greddit/internal/infra/db/postgres/auth"
+	forumdb "greddit/internal/infra/db/postgres/forum"
+	servicesauth "greddit/internal/services/auth"
+	servicesrender "greddit/internal/services/render"
+)
+
+// read is a read of the forum service, as done by an endpoint.
+type read struct {
+	name string
+	read func(claims servicesauth.TokenClaims) error
+}
+
+// TestVisibility checks that no read of the forum service leaks the contents
+// of a private community to users outside of it, or removed posts and
+// comments to users who neither wrote nor moderate them.
+func TestVisibility(t *testing.T) {
+	t.Parallel()
+
+	pool, cleanup := postgres.NewTestPool(t)
+	defer cleanup()
+
+	blobs, err := localfs.NewStore(t.TempDir())
+	test.NilErr(t, err)
+
+	eventsRepo := forumdb.NewEventsRepo(pool)
+	s := NewService(slog.New(slog.DiscardHandler), postgres.NewTransactional(pool), servicesrender.NewService(), blobs,
+		Repos{
+			Communities:   forumdb.NewCommunitiesRepo(pool),
+			Posts:         forumdb.NewPostsRepo(pool),
+			Comments:      forumdb.NewCommentsRepo(pool),
+			Links:         forumdb.NewLinksRepo(pool),
+			Revisions:     forumdb.NewRevisionsRepo(pool),
+			Subscriptions: forumdb.NewSubscriptionsRepo(pool),
+			SavedItems:    forumdb.NewSavedItemsRepo(pool),
+			Reads:         forumdb.NewReadsRepo(pool),
+			Notifications: forumdb.NewNotificationsRepo(pool),
+			Events:        eventsRepo,
+			Moderation:    forumdb.NewModerationRepo(pool),
+			Reports:       forumdb.NewReportsRepo(pool),
+			Bans:          forumdb.NewBansRepo(pool),
+			Members:       forumdb.NewMembersRepo(pool),
+			Flairs:        forumdb.NewFlairsRepo(pool),
+			Tags:          forumdb.NewTagsRepo(pool),
+			LinkPreviews:  forumdb.NewLinkPreviewsRepo(pool),
+			Attachments:   forumdb.NewAttachmentsRepo(pool),
+			Polls:         forumdb.NewPollsRepo(pool),
+			Users:         authdb.NewUsersRepo(pool),
+			Jobs:          postgres.NewJobsRepo(pool),
+		})
+	usersRepo := authdb.NewUsersRepo(pool)
+	ctx := t.Context()
+
+	postgres.ClearAllTables(t, pool)
+
+	claims := map[string]servicesauth.TokenClaims{}
+	for _, username := range []string{"owner", "member", "poster", "outsider"} {
+		user, err := usersRepo.CreateUser(ctx, auth.UserValue{
+			Username:    username,
+			DisplayName: username,
+			Role:        auth.RoleUser,
+		})
+		test.NilErr(t, err)
+		claims[username] = servicesauth.TokenClaims{
+			UserId:      user.Id,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Role:        string(user.Role),
+		}
+	}
+	owner, member, poster, outsider := claims["owner"], claims["member"], claims["poster"], claims["outsider"]
+
+	// createCommunity creates a community owned by the owner, with the member
+	// invited to it.
+	createCommunity := func(name string, visibility forum.CommunityVisibility) *forum.Community {
+		community, err := s.CreateCommunity(ctx, owner, forum.CommunityValue{
+			Name:        name,
+			Description: name,
+		})
+		test.NilErr(t, err)
+
+		settings := forum.DefaultCommunitySettings()
+		settings.Visibility = visibility
+		_, err = s.UpdateCommunitySettings(ctx, owner, community.Id, settings)
+		test.NilErr(t, err)
+
+		_, err = s.InviteMember(ctx, owner, community.Id, member.UserId)
+		test.NilErr(t, err)
+		_, err = s.JoinCommunity(ctx, member, community.Id)
+		test.NilErr(t, err)
+
+		return community
+	}
+
+	// createContent creates a poll post with an attachment and an edited body,
+	// and an edited comment with an attachment on it, as the user.
+	createContent := func(claims servicesauth.TokenClaims, communityId forum.CommunityId) (
+		post *PostView, comment *CommentView,
+	) {
+		post, err := s.CreatePost(ctx, claims, communityId, forum.PostValue{
+			Type:  forum.PostTypePoll,
+			Title: "Favourite language",
+			Body:  "See [[golang/Generics]]",
+		}, forum.PostLabels{Tags: []string{"languages"}}, &forum.PollValue{Options: []string{"Go", "Rust"}})
+		test.NilErr(t, err)
+
+		body := "See [[golang/Generics]] and [[golang/Errors]]"
+		_, err = s.UpdatePost(ctx, claims, post.Id, PostPatch{Body: &body})
+		test.NilErr(t, err)
+
+		_, err = s.UploadPostAttachment(ctx, claims, post.Id, "notes.txt", strings.NewReader("Post notes"))
+		test.NilErr(t, err)
+
+		comment, err = s.CreateComment(ctx, claims, post.Id, forum.CommentValue{Body: "Go"}, nil)
+		test.NilErr(t, err)
+
+		_, err = s.UpdateCommentBody(ctx, claims, comment.Id, "Go, for sure")
+		test.NilErr(t, err)
+
+		_, err = s.UploadCommentAttachment(ctx, claims, comment.Id, "notes.txt", strings.NewReader("Comment notes"))
+		test.NilErr(t, err)
+
+		return post, comment
+	}
+
+	// postReads returns the reads of the post and of its attachments.
+	postReads := func(postId forum.PostId) []read {
+		post, err := s.GetPost(ctx, owner, postId)
+		test.NilErr(t, err)
+		attachmentId := post.Attachments[0].Id
+
+		return []read{
+			{name: "GetPost", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPost(ctx, claims, postId)
+				return err
+			}},
+			{name: "GetCommentsByPost", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetCommentsByPost(ctx, claims, postId, 10, 0)
+				return err
+			}},
+			{name: "GetPostRevisions", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPostRevisions(ctx, claims, postId, 10, 0)
+				return err
+			}},
+			{name: "GetPostRevision", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPostRevision(ctx, claims, postId, 1)
+				return err
+			}},
+			{name: "DiffPostRevisions", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.DiffPostRevisions(ctx, claims, postId, 1, 2)
+				return err
+			}},
+			{name: "GetPostLinks", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPostLinks(ctx, claims, postId)
+				return err
+			}},
+			{name: "GetPoll", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPoll(ctx, claims, postId)
+				return err
+			}},
+			{name: "GetAttachment", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetAttachment(ctx, claims, attachmentId)
+				return err
+			}},
+			{name: "OpenAttachment", read: func(claims servicesauth.TokenClaims) error {
+				_, content, err := s.OpenAttachment(ctx, claims, attachmentId)
+				if err == nil {
+					content.Close()
+				}
+				return err
+			}},
+		}
+	}
+
+	// commentReads returns the reads of the comment and of its attachments.
+	commentReads := func(commentId forum.CommentId) []read {
+		comment, err := s.GetComment(ctx, owner, commentId)
+		test.NilErr(t, err)
+		attachmentId := comment.Attachments[0].Id
+
+		return []read{
+			{name: "GetCommentRevisions", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetCommentRevisions(ctx, claims, commentId, 10, 0)
+				return err
+			}},
+			{name: "GetCommentRevision", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetCommentRevision(ctx, claims, commentId, 1)
+				return err
+			}},
+			{name: "DiffCommentRevisions", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.DiffCommentRevisions(ctx, claims, commentId, 1, 2)
+				return err
+			}},
+			{name: "GetAttachment", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetAttachment(ctx, claims, attachmentId)
+				return err
+			}},
+			{name: "OpenAttachment", read: func(claims servicesauth.TokenClaims) error {
+				_, content, err := s.OpenAttachment(ctx, claims, attachmentId)
+				if err == nil {
+					content.Close()
+				}
+				return err
+			}},
+		}
+	}
+
+	// The public community is created first, so that the links of the posts
+	// resolve.
+	public := createCommunity("golang", forum.CommunityVisibilityPublic)
+	for _, title := range []string{"Generics", "Errors"} {
+		_, err = s.CreatePost(ctx, poster, public.Id, forum.PostValue{
+			Title: title,
+			Body:  title,
+		}, forum.PostLabels{}, nil)
+		test.NilErr(t, err)
+	}
+
+	private := createCommunity("secret", forum.CommunityVisibilityPrivate)
+	privatePost, privateComment := createContent(member, private.Id)
+	linkingPost, err := s.CreatePost(ctx, member, public.Id, forum.PostValue{
+		Title: "Secrets",
+		Body:  "See [[secret/Favourite language]]",
+	}, forum.PostLabels{}, nil)
+	test.NilErr(t, err)
+
+	removedPost, removedPostComment := createContent(poster, public.Id)
+	_, err = s.RemovePost(ctx, owner, removedPost.Id, "Spam")
+	test.NilErr(t, err)
+
+	keptPost, removedComment := createContent(poster, public.Id)
+	_, err = s.RemoveComment(ctx, owner, removedComment.Id, "Spam")
+	test.NilErr(t, err)
+
+	restricted := createCommunity("announcements", forum.CommunityVisibilityRestricted)
+	restrictedPost, _ := createContent(owner, restricted.Id)
+
+	t.Run("private communities are not found by outsiders", func(t *testing.T) {
+		reads := []read{
+			{name: "GetCommunity", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetCommunity(ctx, claims, private.Id)
+				return err
+			}},
+			{name: "GetFlairs", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetFlairs(ctx, claims, private.Id)
+				return err
+			}},
+			{name: "GetModerators", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetModerators(ctx, claims, private.Id)
+				return err
+			}},
+			{name: "GetPostsByCommunity", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetPostsByCommunity(ctx, claims, private.Id, forum.PostFilter{}, 10, 0)
+				return err
+			}},
+			{name: "GetComment", read: func(claims servicesauth.TokenClaims) error {
+				_, err := s.GetComment(ctx, claims, privateComment.Id)
+				return err
+			}},
+		}
+		reads = append(reads, postReads(privatePost.Id)...)
+		reads = append(reads, commentReads(privateComment.Id)...)
+
+		for _, r := range reads {
+			err := r.read(outsider)
+			test.Assert(t, r.name+" should not be found by outsiders", errors.Is(err, shared.ErrNotFound))
+
+			err = r.read(member)
+			test.NilErr(t, err)
+		}
+	})
+
+	t.Run("private communities are left out of listings for outsiders", func(t *testing.T) {
+		for _, c := range []servicesauth.TokenClaims{outsider, member} {
+			communities, err := s.GetCommunities(ctx, c, 10, 0)
+			test.NilErr(t, err)
+			found := false
+			for _, community := range communities {
+				found = found || community.Id == private.Id
+			}
+			test.AssertEqual(t, "Listing of the private community not as expected", c == member, found)
+
+			tags, err := s.SearchTags(ctx, c, "languages", 10)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Number of tags not as expected", 1, len(tags))
+			// Only the public posts tagged are counted for outsiders.
+			expected := 2
+			if c == member {
+				expected = 3
+			}
+			test.AssertEqual(t, "Tag count not as expected", expected, tags[0].PostCount)
+
+			links, err := s.GetPostLinks(ctx, c, linkingPost.Id)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Number of links not as expected", 1, len(links.Outgoing))
+			test.AssertEqual(t, "Link to the private post not as expected", c == member,
+				links.Outgoing[0].TargetPostId != nil)
+		}
+	})
+
+	t.Run("removed posts are only found by their posters and moderators", func(t *testing.T) {
+		for _, r := range postReads(removedPost.Id) {
+			for _, c := range []servicesauth.TokenClaims{outsider, member} {
+				err := r.read(c)
+				test.Assert(t, r.name+" should not be found by other users", errors.Is(err, shared.ErrNotFound))
+			}
+
+			for _, c := range []servicesauth.TokenClaims{poster, owner} {
+				err := r.read(c)
+				test.NilErr(t, err)
+			}
+		}
+
+		for _, c := range []servicesauth.TokenClaims{outsider, member} {
+			_, err := s.GetComment(ctx, c, removedPostComment.Id)
+			test.Assert(t, "Comments on removed posts should not be found by other users",
+				errors.Is(err, shared.ErrNotFound))
+		}
+	})
+
+	t.Run("removed comments are only found by their commenters and moderators", func(t *testing.T) {
+		for _, r := range commentReads(removedComment.Id) {
+			for _, c := range []servicesauth.TokenClaims{outsider, member} {
+				err := r.read(c)
+				test.Assert(t, r.name+" should not be found by other users", errors.Is(err, shared.ErrNotFound))
+			}
+
+			for _, c := range []servicesauth.TokenClaims{poster, owner} {
+				err := r.read(c)
+				test.NilErr(t, err)
+			}
+		}
+
+		for _, c := range []servicesauth.TokenClaims{outsider, member} {
+			_, err := s.GetComment(ctx, c, removedComment.Id)
+			test.Assert(t, "Removed comment should not be found by other users", errors.Is(err, shared.ErrNotFound))
+
+			views, err := s.GetCommentsByPost(ctx, c, keptPost.Id, 10, 0)
+			test.NilErr(t, err)
+			test.AssertEqual(t, "Removed comment should be a tombstone in its thread", "", views[0].Body)
+			test.AssertEqual(t, "Removed comment should not be rendered", "", views[0].BodyHtml)
+			test.AssertEqual(t, "Removed comment attachments should be hidden", 0, len(views[0].Attachments))
+		}
+
+		view, err := s.GetComment(ctx, poster, removedComment.Id)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Commenter should see the removed comment", "Go, for sure", view.Body)
+	})
+
+	t.Run("removed comments cannot be edited or streamed", func(t *testing.T) {
+		_, err := s.UpdateCommentBody(ctx, poster, removedComment.Id, "Rust, actually")
+		test.Assert(t, "Removed comment should not be edited", errors.Is(err, ForbiddenError))
+
+		_, err = s.RestoreCommentRevision(ctx, poster, removedComment.Id, 1)
+		test.Assert(t, "Removed comment revisions should not be restored", errors.Is(err, ForbiddenError))
+
+		events, err := eventsRepo.GetEventsAfter(ctx, 0, 1000)
+		test.NilErr(t, err)
+		lastId := events[len(events)-1].Id
+
+		_, err = s.DeleteComment(ctx, poster, removedComment.Id)
+		test.NilErr(t, err)
+		_, err = s.RestoreComment(ctx, poster, removedComment.Id)
+		test.NilErr(t, err)
+
+		events, err = eventsRepo.GetEventsAfter(ctx, lastId, 10)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Number of events not as expected", 2, len(events))
+		for _, event := range events {
+			test.Assert(t, "Removed comment body should not be streamed", !strings.Contains(string(event.Data), "sure"))
+		}
+	})
+
+	t.Run("comments on locked posts are only edited by moderators", func(t *testing.T) {
+		comment, err := s.CreateComment(ctx, poster, keptPost.Id, forum.CommentValue{Body: "Rust"}, nil)
+		test.NilErr(t, err)
+
+		_, err = s.LockPost(ctx, owner, keptPost.Id, "Heated")
+		test.NilErr(t, err)
+
+		_, err = s.UpdateCommentBody(ctx, poster, comment.Id, "Rust, actually")
+		test.Assert(t, "Comment on locked post should not be edited", errors.Is(err, LockedTargetError))
+
+		ownComment, err := s.CreateComment(ctx, owner, keptPost.Id, forum.CommentValue{Body: "Locked"}, nil)
+		test.NilErr(t, err)
+		_, err = s.UpdateCommentBody(ctx, owner, ownComment.Id, "Locked for a week")
+		test.NilErr(t, err)
+	})
+
+	t.Run("restricted communities are read by anyone but commented in by members", func(t *testing.T) {
+		for _, r := range postReads(restrictedPost.Id) {
+			for _, c := range []servicesauth.TokenClaims{outsider, member} {
+				err := r.read(c)
+				test.NilErr(t, err)
+			}
+		}
+
+		_, err := s.CreateComment(ctx, outsider, restrictedPost.Id, forum.CommentValue{Body: "Hello"}, nil)
+		test.Assert(t, "Outsiders should not comment", errors.Is(err, NotMemberError))
+
+		_, err = s.CreatePost(ctx, outsider, restricted.Id, forum.PostValue{
+			Title: "Hello",
+			Body:  "Hello",
+		}, forum.PostLabels{}, nil)
+		test.Assert(t, "Outsiders should not post", errors.Is(err, NotMemberError))
+
+		_, err = s.CreateComment(ctx, member, restrictedPost.Id, forum.CommentValue{Body: "Hello"}, nil)
+		test.NilErr(t, err)
+	})
+}
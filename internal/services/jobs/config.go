@@ -0,0 +1,68 @@
+package servicesjobs
+
+import "time"
+
+// Config represents the configuration for the jobs service.
+type Config struct {
+	interval    time.Duration
+	concurrency int
+	timeout     time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// Option represents an option for the jobs service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the jobs service.
+func defaultConfig() Config {
+	return Config{
+		interval:    5 * time.Second,
+		concurrency: 2,
+		timeout:     2 * time.Minute,
+		maxAttempts: 5,
+		backoff:     30 * time.Second,
+		maxBackoff:  time.Hour,
+	}
+}
+
+// WithInterval sets the interval between checks for jobs once the queue is
+// empty.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.interval = interval
+	}
+}
+
+// WithConcurrency sets the number of jobs run at the same time.
+func WithConcurrency(concurrency int) Option {
+	return func(c *Config) {
+		c.concurrency = concurrency
+	}
+}
+
+// WithTimeout sets how long a single job may run. Jobs stay locked for twice
+// as long, after which they are assumed abandoned by a stopped worker and
+// claimed again.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts sets how many times a job is run before failing for good.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(c *Config) {
+		c.maxAttempts = maxAttempts
+	}
+}
+
+// WithBackoff sets the delay before a failed job is retried, which doubles
+// with each attempt up to the maximum backoff.
+func WithBackoff(backoff time.Duration, maxBackoff time.Duration) Option {
+	return func(c *Config) {
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
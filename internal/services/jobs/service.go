@@ -0,0 +1,248 @@
+package servicesjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	dbports "greddit/internal/ports/db"
+)
+
+// Handler runs the jobs of a kind.
+type Handler interface {
+	// Handle runs a job. Jobs failing with an error are retried with backoff,
+	// unless the error is permanent, see Permanent.
+	Handle(ctx context.Context, payload json.RawMessage) (err error)
+
+	// Abandon is called once a job has failed for good, after its last
+	// attempt or with a permanent error. Jobs which cannot be abandoned are
+	// retried, to be abandoned again.
+	Abandon(ctx context.Context, payload json.RawMessage, cause error) (err error)
+}
+
+// permanentError wraps an error which running a job again would not fix.
+type permanentError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps an error returned by a handler so that the job is abandoned
+// without being retried, as running it again would fail the same way.
+func Permanent(err error) error {
+	return permanentError{
+		err: err,
+	}
+}
+
+// Service is the jobs service, running the jobs of the queue with the handlers
+// of their kinds. Each job is run at least once, so handlers must be
+// idempotent.
+type Service struct {
+	logger   *slog.Logger
+	repo     dbports.JobsRepo
+	handlers map[string]Handler
+	kinds    []string
+	config   Config
+}
+
+// NewService creates a new Service running the jobs of the kinds in the map
+// with their handlers. Jobs of other kinds are left in the queue.
+func NewService(logger *slog.Logger, repo dbports.JobsRepo, handlers map[string]Handler, opts ...Option) Service {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	defaults := defaultConfig()
+	if config.interval <= 0 {
+		config.interval = defaults.interval
+	}
+	if config.concurrency <= 0 {
+		config.concurrency = defaults.concurrency
+	}
+	if config.timeout <= 0 {
+		config.timeout = defaults.timeout
+	}
+	if config.maxAttempts <= 0 {
+		config.maxAttempts = defaults.maxAttempts
+	}
+	if config.backoff <= 0 {
+		config.backoff = defaults.backoff
+	}
+	if config.maxBackoff < config.backoff {
+		config.maxBackoff = config.backoff
+	}
+
+	return Service{
+		logger:   logger,
+		repo:     repo,
+		handlers: handlers,
+		kinds:    slices.Sorted(maps.Keys(handlers)),
+		config:   config,
+	}
+}
+
+// Start runs the jobs of the queue with as many workers as the concurrency.
+// Each worker runs jobs until the queue is empty, and then checks it again at
+// every interval. It blocks until the context is cancelled and the jobs being
+// run have returned.
+func (s Service) Start(ctx context.Context) error {
+	s.logger.Info("Jobs worker started",
+		"kinds", s.kinds,
+		"concurrency", s.config.concurrency,
+		"interval", s.config.interval,
+	)
+	defer s.logger.Info("Jobs worker stopped")
+
+	wg := sync.WaitGroup{}
+	for range s.config.concurrency {
+		wg.Go(func() {
+			ticker := time.NewTicker(s.config.interval)
+			defer ticker.Stop()
+
+			for {
+				s.Run(ctx)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// Run runs due jobs one at a time until none is left, returning the number of
+// jobs run.
+func (s Service) Run(ctx context.Context) (count int) {
+	if len(s.kinds) == 0 {
+		return 0
+	}
+
+	for ctx.Err() == nil {
+		jobs, err := s.repo.ClaimJobs(ctx, s.kinds, 2*s.config.timeout, 1)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "jobs.service :: Error claiming jobs",
+					"error", err,
+				)
+			}
+			return count
+		} else if len(jobs) == 0 {
+			return count
+		}
+
+		s.run(ctx, jobs[0])
+		count++
+	}
+
+	return count
+}
+
+// run runs a claimed job, then completes, retries or fails it depending on
+// the outcome. Jobs interrupted by the context being cancelled are left
+// locked, to be claimed again once their lock expires.
+func (s Service) run(ctx context.Context, job dbports.Job) {
+	jobCtx, cancel := context.WithTimeout(ctx, s.config.timeout)
+	defer cancel()
+
+	handler := s.handlers[job.Kind]
+	err := handler.Handle(jobCtx, job.Payload)
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err == nil {
+		err = s.repo.CompleteJob(ctx, job.Id)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "jobs.service :: Error completing job",
+				"jobId", job.Id,
+				"kind", job.Kind,
+				"error", err,
+			)
+		}
+		return
+	}
+
+	var permanentErr permanentError
+	if errors.As(err, &permanentErr) || job.Attempts >= s.config.maxAttempts {
+		s.logger.WarnContext(ctx, "Job failed for good",
+			"jobId", job.Id,
+			"kind", job.Kind,
+			"attempts", job.Attempts,
+			"error", err,
+		)
+		s.fail(ctx, handler, job, err)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "Job failed, retrying",
+		"jobId", job.Id,
+		"kind", job.Kind,
+		"attempts", job.Attempts,
+		"error", err,
+	)
+	err = s.repo.RetryJob(ctx, job.Id, time.Now().Add(s.backoff(job.Attempts)), err.Error())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "jobs.service :: Error retrying job",
+			"jobId", job.Id,
+			"kind", job.Kind,
+			"error", err,
+		)
+	}
+}
+
+// fail abandons a job which failed for good and marks it as failed, or
+// retries it if it cannot be abandoned.
+func (s Service) fail(ctx context.Context, handler Handler, job dbports.Job, cause error) {
+	err := handler.Abandon(ctx, job.Payload, cause)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "jobs.service :: Error abandoning job",
+			"jobId", job.Id,
+			"kind", job.Kind,
+			"error", err,
+		)
+		err = s.repo.RetryJob(ctx, job.Id, time.Now().Add(s.backoff(job.Attempts)), cause.Error())
+	} else {
+		err = s.repo.FailJob(ctx, job.Id, cause.Error())
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "jobs.service :: Error failing job",
+			"jobId", job.Id,
+			"kind", job.Kind,
+			"error", err,
+		)
+	}
+}
+
+// backoff returns the delay before a job which failed the given number of
+// times is retried.
+func (s Service) backoff(attempts int) time.Duration {
+	backoff := s.config.backoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= s.config.maxBackoff {
+			return s.config.maxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,226 @@
+package servicesjobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
+)
+
+// fakeJob is a job in the queue of a fakeRepo.
+type fakeJob struct {
+	dbports.Job
+
+	runAt     time.Time
+	failed    bool
+	lastError string
+}
+
+// fakeRepo is an in-memory dbports.JobsRepo, which ignores locks since the
+// tests run a single worker.
+type fakeRepo struct {
+	mu   sync.Mutex
+	jobs []*fakeJob
+}
+
+func (r *fakeRepo) EnqueueJob(_ context.Context, kind string, payload json.RawMessage, runAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs = append(r.jobs, &fakeJob{
+		Job:   dbports.Job{Id: uuid.New(), Kind: kind, Payload: payload},
+		runAt: runAt,
+	})
+	return nil
+}
+
+func (r *fakeRepo) ClaimJobs(_ context.Context, kinds []string, _ time.Duration, limit int) ([]dbports.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := []dbports.Job{}
+	for _, job := range r.jobs {
+		if len(jobs) == limit {
+			break
+		}
+		for _, kind := range kinds {
+			if job.Kind == kind && !job.failed && !job.runAt.After(time.Now()) {
+				job.Attempts++
+				job.runAt = time.Now().Add(time.Hour)
+				jobs = append(jobs, job.Job)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeRepo) find(id uuid.UUID) (int, error) {
+	for i, job := range r.jobs {
+		if job.Id == id {
+			return i, nil
+		}
+	}
+	return 0, shared.NotFoundError{Entity: "job"}
+}
+
+func (r *fakeRepo) CompleteJob(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	r.jobs = append(r.jobs[:i], r.jobs[i+1:]...)
+	return nil
+}
+
+func (r *fakeRepo) RetryJob(_ context.Context, id uuid.UUID, runAt time.Time, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	r.jobs[i].runAt = runAt
+	r.jobs[i].lastError = lastError
+	return nil
+}
+
+func (r *fakeRepo) FailJob(_ context.Context, id uuid.UUID, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	r.jobs[i].failed = true
+	r.jobs[i].lastError = lastError
+	return nil
+}
+
+// fakeHandler fails with its error, counting the jobs handled and abandoned.
+type fakeHandler struct {
+	err       error
+	handled   int
+	abandoned int
+}
+
+func (h *fakeHandler) Handle(_ context.Context, _ json.RawMessage) error {
+	h.handled++
+	return h.err
+}
+
+func (h *fakeHandler) Abandon(_ context.Context, _ json.RawMessage, _ error) error {
+	h.abandoned++
+	return nil
+}
+
+func TestService_Run(t *testing.T) {
+	newService := func(handler *fakeHandler, opts ...Option) (Service, *fakeRepo) {
+		repo := &fakeRepo{}
+		ser := NewService(slog.New(slog.DiscardHandler), repo, map[string]Handler{"test": handler}, opts...)
+		return ser, repo
+	}
+
+	t.Run("completed jobs are removed", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &fakeHandler{}
+		ser, repo := newService(handler)
+		ctx := t.Context()
+
+		test.NilErr(t, repo.EnqueueJob(ctx, "test", json.RawMessage(`{}`), time.Now()))
+		test.NilErr(t, repo.EnqueueJob(ctx, "other", json.RawMessage(`{}`), time.Now()))
+		test.NilErr(t, repo.EnqueueJob(ctx, "test", json.RawMessage(`{}`), time.Now().Add(time.Hour)))
+
+		count := ser.Run(ctx)
+		test.AssertEqual(t, "Number of jobs run not as expected", 1, count)
+		test.AssertEqual(t, "Number of jobs handled not as expected", 1, handler.handled)
+		test.AssertEqual(t, "Number of jobs left not as expected", 2, len(repo.jobs))
+	})
+
+	t.Run("failed jobs are retried with backoff", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &fakeHandler{err: errors.New("transient")}
+		ser, repo := newService(handler, WithBackoff(time.Minute, time.Hour))
+		ctx := t.Context()
+
+		test.NilErr(t, repo.EnqueueJob(ctx, "test", json.RawMessage(`{}`), time.Now()))
+
+		count := ser.Run(ctx)
+		test.AssertEqual(t, "Number of jobs run not as expected", 1, count)
+		test.AssertEqual(t, "Job should not be abandoned", 0, handler.abandoned)
+
+		job := repo.jobs[0]
+		test.Assert(t, "Job should not be failed", !job.failed)
+		test.AssertEqual(t, "Last error not as expected", "transient", job.lastError)
+		test.Assert(t, "Job should be retried later", job.runAt.After(time.Now().Add(30*time.Second)))
+	})
+
+	t.Run("jobs are abandoned after the last attempt", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &fakeHandler{err: errors.New("transient")}
+		ser, repo := newService(handler, WithMaxAttempts(3))
+		ctx := t.Context()
+
+		test.NilErr(t, repo.EnqueueJob(ctx, "test", json.RawMessage(`{}`), time.Now()))
+
+		for range 3 {
+			repo.jobs[0].runAt = time.Now()
+			ser.Run(ctx)
+		}
+
+		test.AssertEqual(t, "Number of jobs handled not as expected", 3, handler.handled)
+		test.AssertEqual(t, "Job should be abandoned once", 1, handler.abandoned)
+		test.Assert(t, "Job should be failed", repo.jobs[0].failed)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &fakeHandler{err: Permanent(errors.New("corrupt"))}
+		ser, repo := newService(handler)
+		ctx := t.Context()
+
+		test.NilErr(t, repo.EnqueueJob(ctx, "test", json.RawMessage(`{}`), time.Now()))
+
+		ser.Run(ctx)
+		test.AssertEqual(t, "Job should be abandoned", 1, handler.abandoned)
+		test.Assert(t, "Job should be failed", repo.jobs[0].failed)
+		test.AssertEqual(t, "Last error not as expected", "corrupt", repo.jobs[0].lastError)
+	})
+}
+
+func TestService_backoff(t *testing.T) {
+	ser := NewService(slog.New(slog.DiscardHandler), &fakeRepo{}, nil, WithBackoff(time.Second, 10*time.Second))
+
+	data := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: time.Second},
+		{attempts: 2, expected: 2 * time.Second},
+		{attempts: 4, expected: 8 * time.Second},
+		{attempts: 5, expected: 10 * time.Second},
+		{attempts: 100, expected: 10 * time.Second},
+	}
+
+	for _, d := range data {
+		test.AssertEqual(t, "Backoff not as expected", d.expected, ser.backoff(d.attempts))
+	}
+}
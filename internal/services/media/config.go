@@ -0,0 +1,34 @@
+package servicesmedia
+
+// Config represents the configuration for the media service.
+type Config struct {
+	thumbnailSize int
+	maxPixels     int
+}
+
+// Option represents an option for the media service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the media service.
+func defaultConfig() Config {
+	return Config{
+		thumbnailSize: 320,
+		maxPixels:     50_000_000,
+	}
+}
+
+// WithThumbnailSize sets the size of the square thumbnails fit within.
+func WithThumbnailSize(thumbnailSize int) Option {
+	return func(c *Config) {
+		c.thumbnailSize = thumbnailSize
+	}
+}
+
+// WithMaxPixels sets the maximum number of pixels of the images processed.
+// Larger images are not decoded, as a small file can hold an image which
+// takes gigabytes once decoded.
+func WithMaxPixels(maxPixels int) Option {
+	return func(c *Config) {
+		c.maxPixels = maxPixels
+	}
+}
@@ -0,0 +1,158 @@
+package servicesmedia
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	// Decoders of the image formats attachments may have.
+	_ "image/gif"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	// ErrUnsupportedImage is returned when an image cannot be decoded.
+	ErrUnsupportedImage = errors.New("unsupported image")
+	// ErrImageTooLarge is returned when an image has more pixels than
+	// allowed, which could exhaust memory once decoded.
+	ErrImageTooLarge = errors.New("image too large")
+)
+
+// thumbnailJpegQuality is the quality of the thumbnails encoded as JPEG.
+const thumbnailJpegQuality = 85
+
+// processedImage is an image without its metadata, along with its thumbnail.
+type processedImage struct {
+	// content is the image without metadata.
+	content []byte
+	// width and height are the dimensions of the image as displayed, once
+	// oriented.
+	width  int
+	height int
+
+	thumbnail            []byte
+	thumbnailContentType string
+	thumbnailWidth       int
+	thumbnailHeight      int
+}
+
+// processImage strips the metadata of the image and generates its thumbnail,
+// fitting within a square of the given size. Thumbnails of opaque images are
+// encoded as JPEG, and those of images with transparency as PNG, upright
+// whatever the EXIF orientation of the image.
+func processImage(data []byte, thumbnailSize int, maxPixels int) (processed *processedImage, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(ErrUnsupportedImage, err)
+	} else if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupportedImage
+	} else if int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, ErrImageTooLarge
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	content, err := stripMetadata(data, format, orientation)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(ErrUnsupportedImage, err)
+	}
+
+	bounds := img.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), thumbnailSize)
+	thumbnail := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), img, bounds, draw.Src, nil)
+	thumbnail = orient(thumbnail, orientation)
+
+	var buf bytes.Buffer
+	contentType := "image/jpeg"
+	if thumbnail.Opaque() {
+		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: thumbnailJpegQuality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, thumbnail)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	processed = &processedImage{
+		content:              content,
+		width:                bounds.Dx(),
+		height:               bounds.Dy(),
+		thumbnail:            buf.Bytes(),
+		thumbnailContentType: contentType,
+		thumbnailWidth:       thumbnail.Bounds().Dx(),
+		thumbnailHeight:      thumbnail.Bounds().Dy(),
+	}
+	if orientation >= 5 {
+		processed.width, processed.height = processed.height, processed.width
+	}
+
+	return processed, nil
+}
+
+// fit returns the dimensions of an image scaled down to fit within a square
+// of the given size, keeping its aspect ratio. Images which already fit are
+// not scaled up.
+func fit(width int, height int, size int) (fitWidth int, fitHeight int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
+
+// orient returns the image transformed according to the EXIF orientation, so
+// that it is displayed upright without metadata.
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if orientation >= 5 {
+		dst = image.NewNRGBA(image.Rect(0, 0, h, w))
+	}
+
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored horizontally.
+				dx, dy = w-1-x, y
+			case 3: // Rotated 180°.
+				dx, dy = w-1-x, h-1-y
+			case 4: // Mirrored vertically.
+				dx, dy = x, h-1-y
+			case 5: // Transposed.
+				dx, dy = y, x
+			case 6: // Rotated 90° clockwise to be upright.
+				dx, dy = h-1-y, x
+			case 7: // Transversed.
+				dx, dy = h-1-y, w-1-x
+			case 8: // Rotated 90° counter-clockwise to be upright.
+				dx, dy = y, w-1-x
+			}
+			dst.SetNRGBA(dx, dy, img.NRGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
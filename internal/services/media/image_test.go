@@ -0,0 +1,273 @@
+package servicesmedia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"greddit/internal/test"
+)
+
+// newImage returns an image of the given size, opaque unless alpha is set,
+// whose top left pixel is red and the rest blue.
+func newImage(width int, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetNRGBA(x, y, color.NRGBA{B: 255, A: alpha})
+		}
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: alpha})
+	return img
+}
+
+// exifSegment returns an APP1 segment with little-endian EXIF metadata holding
+// the orientation and the make of the camera.
+func exifSegment(orientation uint16, make string) []byte {
+	order := binary.LittleEndian
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	tiff = order.AppendUint16(tiff, 2)
+	// The make is stored after the IFD.
+	tiff = order.AppendUint16(tiff, 0x010f)
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint32(tiff, uint32(len(make)+1))
+	tiff = order.AppendUint32(tiff, 8+2+2*12+4)
+	tiff = order.AppendUint16(tiff, exifOrientationTag)
+	tiff = order.AppendUint16(tiff, exifShortType)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = order.AppendUint32(tiff, 0)
+	tiff = append(tiff, make...)
+	tiff = append(tiff, 0)
+
+	payload := append([]byte{}, exifHeader...)
+	payload = append(payload, tiff...)
+	segment := []byte{0xff, jpegApp1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// newJpeg returns a JPEG image of the given size with the segments inserted
+// after its start of image marker.
+func newJpeg(t *testing.T, width int, height int, segments ...[]byte) []byte {
+	var buf bytes.Buffer
+	test.NilErr(t, jpeg.Encode(&buf, newImage(width, height, 255), nil))
+
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+// pngChunk returns a PNG chunk of the given type.
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// webpChunk returns a RIFF chunk of the given type, padded to an even size.
+func webpChunk(chunkType string, data []byte) []byte {
+	chunk := append([]byte(chunkType), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func TestStripMetadata(t *testing.T) {
+	t.Run("jpeg metadata is stripped but the orientation", func(t *testing.T) {
+		t.Parallel()
+
+		comment := append([]byte{0xff, jpegCom, 0, 9}, "secret!"...)
+		data := newJpeg(t, 8, 4, exifSegment(6, "Secret Camera"), comment)
+		test.AssertEqual(t, "Orientation not as expected", 6, jpegOrientation(data))
+
+		stripped, err := stripMetadata(data, "jpeg", 6)
+		test.NilErr(t, err)
+		test.Assert(t, "Make should be stripped", !bytes.Contains(stripped, []byte("Secret Camera")))
+		test.Assert(t, "Comment should be stripped", !bytes.Contains(stripped, []byte("secret!")))
+		test.AssertEqual(t, "Orientation should be kept", 6, jpegOrientation(stripped))
+
+		img, err := jpeg.Decode(bytes.NewReader(stripped))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Width not as expected", 8, img.Bounds().Dx())
+	})
+
+	t.Run("upright jpeg images have no exif", func(t *testing.T) {
+		t.Parallel()
+
+		data := newJpeg(t, 8, 4, exifSegment(1, "Secret Camera"))
+
+		stripped, err := stripMetadata(data, "jpeg", 1)
+		test.NilErr(t, err)
+		test.Assert(t, "Exif should be stripped", !bytes.Contains(stripped, exifHeader))
+	})
+
+	t.Run("png text chunks are stripped", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		test.NilErr(t, png.Encode(&buf, newImage(4, 4, 255)))
+		data := buf.Bytes()
+		// The chunks are inserted after the IHDR chunk.
+		ihdrEnd := len(pngSignature) + 8 + 13 + 4
+		withText := append([]byte{}, data[:ihdrEnd]...)
+		withText = append(withText, pngChunk("tEXt", []byte("Author\x00Secret Author"))...)
+		withText = append(withText, pngChunk("eXIf", []byte("MM\x00\x2aSecret"))...)
+		withText = append(withText, data[ihdrEnd:]...)
+
+		stripped, err := stripMetadata(withText, "png", 1)
+		test.NilErr(t, err)
+		test.Assert(t, "Text should be stripped", !bytes.Contains(stripped, []byte("Secret")))
+		test.AssertEqual(t, "Image should be unchanged", string(data), string(stripped))
+	})
+
+	t.Run("webp exif and xmp chunks are stripped", func(t *testing.T) {
+		t.Parallel()
+
+		vp8x := make([]byte, 10)
+		vp8x[0] = webpExifFlag | webpXmpFlag
+		body := []byte("WEBP")
+		body = append(body, webpChunk("VP8X", vp8x)...)
+		body = append(body, webpChunk("VP8L", []byte{1, 2, 3})...)
+		body = append(body, webpChunk("EXIF", []byte("Secret"))...)
+		body = append(body, webpChunk("XMP ", []byte("Secret"))...)
+		data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+		data = append(data, body...)
+
+		stripped, err := stripMetadata(data, "webp", 1)
+		test.NilErr(t, err)
+		test.Assert(t, "Metadata should be stripped", !bytes.Contains(stripped, []byte("Secret")))
+		test.AssertEqual(t, "RIFF size not as expected", uint32(len(stripped)-8),
+			binary.LittleEndian.Uint32(stripped[4:]))
+		test.AssertEqual(t, "VP8X flags should be cleared", byte(0), stripped[20])
+		test.Assert(t, "Image data should be kept", bytes.Contains(stripped, webpChunk("VP8L", []byte{1, 2, 3})))
+	})
+
+	t.Run("malformed images are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		data := newJpeg(t, 8, 4)
+		_, err := stripMetadata(data[:20], "jpeg", 1)
+		test.Assert(t, "Error should be ErrMalformedImage", errors.Is(err, ErrMalformedImage))
+
+		_, err = stripMetadata([]byte("\x89PNG\r\n\x1a\n\x00\x00"), "png", 1)
+		test.Assert(t, "Error should be ErrMalformedImage", errors.Is(err, ErrMalformedImage))
+	})
+}
+
+func TestProcessImage(t *testing.T) {
+	t.Run("opaque images get jpeg thumbnails", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		test.NilErr(t, png.Encode(&buf, newImage(100, 50, 255)))
+
+		processed, err := processImage(buf.Bytes(), 20, 1_000_000)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Width not as expected", 100, processed.width)
+		test.AssertEqual(t, "Height not as expected", 50, processed.height)
+		test.AssertEqual(t, "Thumbnail type not as expected", "image/jpeg", processed.thumbnailContentType)
+		test.AssertEqual(t, "Thumbnail width not as expected", 20, processed.thumbnailWidth)
+		test.AssertEqual(t, "Thumbnail height not as expected", 10, processed.thumbnailHeight)
+
+		thumbnail, err := jpeg.Decode(bytes.NewReader(processed.thumbnail))
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Decoded width not as expected", 20, thumbnail.Bounds().Dx())
+	})
+
+	t.Run("transparent images get png thumbnails", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		test.NilErr(t, png.Encode(&buf, newImage(10, 10, 128)))
+
+		processed, err := processImage(buf.Bytes(), 20, 1_000_000)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Thumbnail type not as expected", "image/png", processed.thumbnailContentType)
+		test.AssertEqual(t, "Small images should not be scaled up", 10, processed.thumbnailWidth)
+	})
+
+	t.Run("rotated jpeg images have upright thumbnails", func(t *testing.T) {
+		t.Parallel()
+
+		data := newJpeg(t, 80, 40, exifSegment(6, "Secret Camera"))
+
+		processed, err := processImage(data, 20, 1_000_000)
+		test.NilErr(t, err)
+		test.Assert(t, "Make should be stripped", !bytes.Contains(processed.content, []byte("Secret Camera")))
+		test.AssertEqual(t, "Width should be the displayed one", 40, processed.width)
+		test.AssertEqual(t, "Height should be the displayed one", 80, processed.height)
+		test.AssertEqual(t, "Thumbnail width not as expected", 10, processed.thumbnailWidth)
+		test.AssertEqual(t, "Thumbnail height not as expected", 20, processed.thumbnailHeight)
+	})
+
+	t.Run("oversized and undecodable images are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		test.NilErr(t, png.Encode(&buf, newImage(100, 100, 255)))
+
+		_, err := processImage(buf.Bytes(), 20, 100)
+		test.Assert(t, "Error should be ErrImageTooLarge", errors.Is(err, ErrImageTooLarge))
+
+		_, err = processImage([]byte("not an image"), 20, 100)
+		test.Assert(t, "Error should be ErrUnsupportedImage", errors.Is(err, ErrUnsupportedImage))
+	})
+}
+
+func TestOrient(t *testing.T) {
+	img := newImage(3, 2, 255)
+	red := color.NRGBA{R: 255, A: 255}
+
+	data := []struct {
+		orientation int
+		width       int
+		x, y        int
+	}{
+		{orientation: 1, width: 3, x: 0, y: 0},
+		{orientation: 2, width: 3, x: 2, y: 0},
+		{orientation: 3, width: 3, x: 2, y: 1},
+		{orientation: 4, width: 3, x: 0, y: 1},
+		{orientation: 5, width: 2, x: 0, y: 0},
+		{orientation: 6, width: 2, x: 1, y: 0},
+		{orientation: 7, width: 2, x: 1, y: 2},
+		{orientation: 8, width: 2, x: 0, y: 2},
+	}
+
+	for _, d := range data {
+		oriented := orient(img, d.orientation)
+		test.AssertEqual(t, "Width not as expected", d.width, oriented.Bounds().Dx())
+		test.AssertEqual(t, "Red pixel not as expected", red, oriented.NRGBAAt(d.x, d.y))
+	}
+}
+
+func TestFit(t *testing.T) {
+	data := []struct {
+		width, height                 int
+		expectedWidth, expectedHeight int
+	}{
+		{width: 100, height: 50, expectedWidth: 20, expectedHeight: 10},
+		{width: 50, height: 100, expectedWidth: 10, expectedHeight: 20},
+		{width: 10, height: 5, expectedWidth: 10, expectedHeight: 5},
+		{width: 1000, height: 1, expectedWidth: 20, expectedHeight: 1},
+	}
+
+	for _, d := range data {
+		w, h := fit(d.width, d.height, 20)
+		test.AssertEqual(t, "Width not as expected", d.expectedWidth, w)
+		test.AssertEqual(t, "Height not as expected", d.expectedHeight, h)
+	}
+}
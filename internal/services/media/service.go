@@ -0,0 +1,196 @@
+package servicesmedia
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+
+	blobports "greddit/internal/ports/blob"
+	dbports "greddit/internal/ports/db"
+	dbportsforum "greddit/internal/ports/db/forum"
+	servicesjobs "greddit/internal/services/jobs"
+)
+
+// JobKindProcessImage is the kind of the jobs processing image blobs.
+const JobKindProcessImage = "media.process_image"
+
+// processImagePayload is the payload of the jobs processing image blobs.
+type processImagePayload struct {
+	Sha256 string `json:"sha256"`
+}
+
+// EnqueueProcessImage enqueues the job processing an image blob. Must be
+// called within the transaction creating the blob, so that the job is only
+// run once the blob is committed.
+func EnqueueProcessImage(ctx context.Context, jobs dbports.JobsRepo, sha256 string) (err error) {
+	payload, err := json.Marshal(processImagePayload{
+		Sha256: sha256,
+	})
+	if err != nil {
+		return err
+	}
+
+	return jobs.EnqueueJob(ctx, JobKindProcessImage, payload, time.Now())
+}
+
+// Service is the media service, processing the images attached to posts and
+// comments in the background. Their metadata is stripped and their thumbnail
+// is generated, both stored in the blob store alongside the image, before the
+// image is marked as ready to be served.
+type Service struct {
+	logger *slog.Logger
+	txs    dbports.Transactional
+	repo   dbportsforum.AttachmentsRepo
+	blobs  blobports.BlobStore
+	config Config
+}
+
+var _ servicesjobs.Handler = Service{}
+
+// NewService creates a new Service.
+func NewService(logger *slog.Logger, txs dbports.Transactional, repo dbportsforum.AttachmentsRepo,
+	blobs blobports.BlobStore, opts ...Option,
+) Service {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	defaults := defaultConfig()
+	if config.thumbnailSize <= 0 {
+		config.thumbnailSize = defaults.thumbnailSize
+	}
+	if config.maxPixels <= 0 {
+		config.maxPixels = defaults.maxPixels
+	}
+
+	return Service{
+		logger: logger,
+		txs:    txs,
+		repo:   repo,
+		blobs:  blobs,
+		config: config,
+	}
+}
+
+// withTx runs the function within a transaction, committing if the function
+// succeeds.
+func (s Service) withTx(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	ctx, err = s.txs.CtxTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.txs.TxRollback(ctx)
+
+	err = f(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.txs.TxCommit(ctx)
+}
+
+// Handle processes an image blob, implementing servicesjobs.Handler. Images
+// which cannot be decoded fail permanently. Blobs which were deleted, or
+// already processed, are skipped.
+func (s Service) Handle(ctx context.Context, payload json.RawMessage) (err error) {
+	var p processImagePayload
+	err = json.Unmarshal(payload, &p)
+	if err != nil {
+		return servicesjobs.Permanent(err)
+	}
+
+	blob := forum.Blob{
+		Sha256: p.Sha256,
+	}
+	content, err := s.blobs.Open(ctx, blob.Key())
+	if errors.Is(err, shared.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(content, forum.AttachmentMaxSize+1))
+	content.Close()
+	if err != nil {
+		return err
+	}
+
+	// Images are processed outside of the transaction, which only holds the
+	// lock on the blob while its content is replaced.
+	processed, err := processImage(data, s.config.thumbnailSize, s.config.maxPixels)
+	if err != nil {
+		return servicesjobs.Permanent(err)
+	}
+
+	return s.withTx(ctx, func(ctx context.Context) (err error) {
+		locked, err := s.repo.LockBlob(ctx, blob.Sha256)
+		if errors.Is(err, shared.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		} else if locked.Status != forum.BlobStatusPending {
+			return nil
+		}
+		blob = *locked
+
+		err = s.blobs.Put(ctx, blob.ThumbnailKey(), bytes.NewReader(processed.thumbnail),
+			int64(len(processed.thumbnail)), processed.thumbnailContentType)
+		if err != nil {
+			return err
+		}
+
+		err = s.blobs.Put(ctx, blob.Key(), bytes.NewReader(processed.content), int64(len(processed.content)),
+			blob.ContentType)
+		if err != nil {
+			return err
+		}
+
+		blob.Status = forum.BlobStatusReady
+		blob.Size = int64(len(processed.content))
+		blob.Width = processed.width
+		blob.Height = processed.height
+		blob.Thumbnail = &forum.Thumbnail{
+			ContentType: processed.thumbnailContentType,
+			Width:       processed.thumbnailWidth,
+			Height:      processed.thumbnailHeight,
+		}
+		return s.repo.UpdateBlob(ctx, blob)
+	})
+}
+
+// Abandon marks an image blob which could not be processed as failed,
+// implementing servicesjobs.Handler. Failed images are never served, as their
+// metadata could not be stripped.
+func (s Service) Abandon(ctx context.Context, payload json.RawMessage, cause error) (err error) {
+	var p processImagePayload
+	err = json.Unmarshal(payload, &p)
+	if err != nil {
+		return nil
+	}
+
+	s.logger.InfoContext(ctx, "Failed to process image",
+		"sha256", p.Sha256,
+		"error", cause,
+	)
+
+	return s.withTx(ctx, func(ctx context.Context) (err error) {
+		blob, err := s.repo.LockBlob(ctx, p.Sha256)
+		if errors.Is(err, shared.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		} else if blob.Status != forum.BlobStatusPending {
+			return nil
+		}
+
+		blob.Status = forum.BlobStatusFailed
+		return s.repo.UpdateBlob(ctx, *blob)
+	})
+}
@@ -0,0 +1,307 @@
+package servicesmedia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrMalformedImage is returned when the container of an image cannot be
+	// parsed.
+	ErrMalformedImage = errors.New("malformed image")
+
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+const (
+	jpegSoi  = 0xd8
+	jpegSos  = 0xda
+	jpegApp0 = 0xe0
+	jpegApp1 = 0xe1
+	jpegApp2 = 0xe2
+	jpegAppE = 0xee
+	jpegAppF = 0xef
+	jpegCom  = 0xfe
+
+	// exifOrientationTag is the tag of the orientation in an EXIF IFD.
+	exifOrientationTag = 0x0112
+	// exifShortType is the type of 16-bit unsigned EXIF values.
+	exifShortType = 3
+)
+
+// exifHeader starts the APP1 segments of JPEG images holding EXIF metadata.
+var exifHeader = []byte("Exif\x00\x00")
+
+// stripMetadata returns the image without its metadata, such as EXIF, XMP and
+// text comments, which can reveal where and with which device a photo was
+// taken. Pixels are left untouched. The orientation of JPEG images is kept in
+// a minimal EXIF segment, so that they are still displayed upright. GIF
+// images are returned as is.
+func stripMetadata(data []byte, format string, orientation int) (stripped []byte, err error) {
+	switch format {
+	case "jpeg":
+		return stripJpeg(data, orientation)
+	case "png":
+		return stripPng(data)
+	case "webp":
+		return stripWebp(data)
+	default:
+		return data, nil
+	}
+}
+
+// jpegSegments calls the function with the marker and bytes of each segment of
+// the JPEG image before the image data, and returns the offset of the start of
+// scan segment, from which the image data follows.
+func jpegSegments(data []byte, f func(marker byte, segment []byte)) (sos int, err error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != jpegSoi {
+		return 0, ErrMalformedImage
+	}
+
+	i := 2
+	for i+1 < len(data) {
+		if data[i] != 0xff {
+			return 0, ErrMalformedImage
+		}
+		// Markers may be preceded by any number of fill bytes.
+		if data[i+1] == 0xff {
+			i++
+			continue
+		}
+
+		marker := data[i+1]
+		if marker == jpegSos {
+			return i, nil
+		} else if i+4 > len(data) {
+			return 0, ErrMalformedImage
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 0, ErrMalformedImage
+		}
+
+		f(marker, data[i:i+2+length])
+		i += 2 + length
+	}
+
+	return 0, ErrMalformedImage
+}
+
+// stripJpeg removes the application segments of the JPEG image other than
+// the JFIF header, the ICC profile and the Adobe color transform, as well as
+// comments.
+func stripJpeg(data []byte, orientation int) (stripped []byte, err error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, jpegSoi)
+
+	exif := orientationSegment(orientation)
+	sos, err := jpegSegments(data, func(marker byte, segment []byte) {
+		// The JFIF header must come first.
+		if exif != nil && marker != jpegApp0 {
+			out = append(out, exif...)
+			exif = nil
+		}
+
+		switch {
+		case marker == jpegApp0, marker == jpegApp2, marker == jpegAppE:
+			out = append(out, segment...)
+		case marker >= jpegApp0 && marker <= jpegAppF, marker == jpegCom:
+		default:
+			out = append(out, segment...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, exif...)
+
+	return append(out, data[sos:]...), nil
+}
+
+// jpegOrientation returns the EXIF orientation of the JPEG image, from 1 to
+// 8, or 1 if it has none.
+func jpegOrientation(data []byte) (orientation int) {
+	orientation = 1
+	_, _ = jpegSegments(data, func(marker byte, segment []byte) {
+		payload := segment[4:]
+		if marker == jpegApp1 && bytes.HasPrefix(payload, exifHeader) {
+			if o := exifOrientation(payload[len(exifHeader):]); o != 0 {
+				orientation = o
+			}
+		}
+	})
+
+	return orientation
+}
+
+// exifOrientation returns the orientation in the first IFD of the TIFF
+// structure of EXIF metadata, or 0 if it has none.
+func exifOrientation(tiff []byte) (orientation int) {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+
+	offset := uint64(order.Uint32(tiff[4:]))
+	if offset+2 > uint64(len(tiff)) {
+		return 0
+	}
+
+	count := uint64(order.Uint16(tiff[offset:]))
+	for i := range count {
+		entry := offset + 2 + 12*i
+		if entry+12 > uint64(len(tiff)) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:]) != exifShortType {
+			return 0
+		}
+		if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+			return o
+		}
+		return 0
+	}
+
+	return 0
+}
+
+// orientationSegment returns an APP1 segment with EXIF metadata holding only
+// the orientation, or nil if the orientation is the default one.
+func orientationSegment(orientation int) (segment []byte) {
+	if orientation <= 1 || orientation > 8 {
+		return nil
+	}
+
+	payload := append([]byte{}, exifHeader...)
+	// Big-endian TIFF header, with the first IFD right after it.
+	payload = append(payload, 'M', 'M', 0, 42, 0, 0, 0, 8)
+	// A single entry, a short holding the orientation.
+	payload = binary.BigEndian.AppendUint16(payload, 1)
+	payload = binary.BigEndian.AppendUint16(payload, exifOrientationTag)
+	payload = binary.BigEndian.AppendUint16(payload, exifShortType)
+	payload = binary.BigEndian.AppendUint32(payload, 1)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(orientation))
+	payload = append(payload, 0, 0)
+	// No next IFD.
+	payload = binary.BigEndian.AppendUint32(payload, 0)
+
+	segment = []byte{0xff, jpegApp1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// pngMetadataChunks are the types of the PNG chunks holding metadata.
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPng removes the chunks holding metadata from the PNG image.
+func stripPng(data []byte) (stripped []byte, err error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMalformedImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		length := uint64(binary.BigEndian.Uint32(data[i:]))
+		end := uint64(i) + 12 + length
+		if end > uint64(len(data)) {
+			return nil, ErrMalformedImage
+		}
+
+		chunkType := string(data[i+4 : i+8])
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		i = int(end)
+
+		if chunkType == "IEND" {
+			return out, nil
+		}
+	}
+
+	return nil, ErrMalformedImage
+}
+
+const (
+	// webpExifFlag and webpXmpFlag are the flags of the VP8X chunk telling
+	// whether a WebP image has EXIF and XMP metadata.
+	webpExifFlag = 0x08
+	webpXmpFlag  = 0x04
+)
+
+// stripWebp removes the EXIF and XMP chunks from the WebP image, and clears
+// the flags announcing them.
+func stripWebp(data []byte) (stripped []byte, err error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformedImage
+	}
+
+	size := uint64(binary.LittleEndian.Uint32(data[4:]))
+	if size+8 > uint64(len(data)) {
+		return nil, ErrMalformedImage
+	}
+	data = data[:size+8]
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	i := uint64(12)
+	for i < uint64(len(data)) {
+		if i+8 > uint64(len(data)) {
+			return nil, ErrMalformedImage
+		}
+
+		chunkSize := uint64(binary.LittleEndian.Uint32(data[i+4:]))
+		// Chunks are padded to an even size.
+		end := i + 8 + chunkSize + chunkSize%2
+		if end > uint64(len(data)) {
+			return nil, ErrMalformedImage
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if chunkSize > 0 {
+				out[start+8] &^= webpExifFlag | webpXmpFlag
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
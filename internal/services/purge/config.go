@@ -0,0 +1,43 @@
+package servicespurge
+
+import "time"
+
+// Config represents the configuration for the purge service.
+type Config struct {
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+}
+
+// Option represents an option for the purge service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the purge service.
+func defaultConfig() Config {
+	return Config{
+		retention: 30 * 24 * time.Hour,
+		interval:  time.Hour,
+		batchSize: 500,
+	}
+}
+
+// WithRetention sets how long soft-deleted rows are kept before being purged.
+func WithRetention(retention time.Duration) Option {
+	return func(c *Config) {
+		c.retention = retention
+	}
+}
+
+// WithInterval sets the interval between purges.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.interval = interval
+	}
+}
+
+// WithBatchSize sets the maximum number of rows deleted in each transaction.
+func WithBatchSize(batchSize int) Option {
+	return func(c *Config) {
+		c.batchSize = batchSize
+	}
+}
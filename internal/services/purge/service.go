@@ -0,0 +1,187 @@
+package servicespurge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	blobports "greddit/internal/ports/blob"
+	dbports "greddit/internal/ports/db"
+)
+
+// Service is the purge service, permanently deleting rows which have been soft
+// deleted for longer than the retention period, bans and suspensions which
+// have expired, and blobs which are no longer attached to anything.
+type Service struct {
+	logger *slog.Logger
+	txs    dbports.Transactional
+	repo   dbports.PurgeRepo
+	blobs  blobports.BlobStore
+	config Config
+}
+
+// NewService creates a new Service.
+func NewService(logger *slog.Logger, txs dbports.Transactional, repo dbports.PurgeRepo, blobs blobports.BlobStore,
+	opts ...Option,
+) Service {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	defaults := defaultConfig()
+	if config.interval <= 0 {
+		config.interval = defaults.interval
+	}
+	if config.batchSize <= 0 {
+		config.batchSize = defaults.batchSize
+	}
+
+	return Service{
+		logger: logger,
+		txs:    txs,
+		repo:   repo,
+		blobs:  blobs,
+		config: config,
+	}
+}
+
+// purgeStep purges a batch of one kind of row, deleted or expired before the
+// cutoff.
+type purgeStep struct {
+	name   string
+	before time.Time
+	purge  func(ctx context.Context, before time.Time, limit int) (count int64, err error)
+}
+
+// Start purges once immediately and then at every interval. It blocks until
+// the context is cancelled.
+func (s Service) Start(ctx context.Context) error {
+	s.logger.Info("Purge worker started",
+		"retention", s.config.retention,
+		"interval", s.config.interval,
+	)
+	defer s.logger.Info("Purge worker stopped")
+
+	ticker := time.NewTicker(s.config.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge permanently deletes the rows soft deleted before the retention period.
+// Comments are purged before posts, and posts before communities and users, so
+// that parents left without children are purged in the same run. The content
+// of purged users outlives them, see dbports.PurgeRepo.PurgeUsers. Expired
+// bans and suspensions are deleted regardless of the retention period, as they
+// are already ignored when read. Blobs are purged last, once the attachments
+// of the purged rows are gone.
+func (s Service) Purge(ctx context.Context) {
+	now := time.Now()
+	before := now.Add(-s.config.retention)
+
+	steps := []purgeStep{
+		{name: "comments", before: before, purge: s.repo.PurgeComments},
+		{name: "posts", before: before, purge: s.repo.PurgePosts},
+		{name: "communities", before: before, purge: s.repo.PurgeCommunities},
+		{name: "users", before: before, purge: s.repo.PurgeUsers},
+		{name: "bans", before: now, purge: s.repo.PurgeExpiredBans},
+		{name: "suspensions", before: now, purge: s.repo.PurgeExpiredSuspensions},
+		{name: "unused blobs", before: before, purge: s.repo.MarkUnusedBlobs},
+		{name: "blobs", before: now, purge: s.purgeBlobs},
+	}
+
+	for _, step := range steps {
+		total, err := s.purgeAll(ctx, step)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.ErrorContext(ctx, "purge.service :: Error purging rows",
+				"table", step.name,
+				"purged", total,
+				"error", err,
+			)
+			continue
+		}
+
+		if total > 0 {
+			s.logger.InfoContext(ctx, "Purged rows",
+				"table", step.name,
+				"purged", total,
+			)
+		}
+	}
+}
+
+// purgeBlobs purges a batch of blobs marked as purging, along with their
+// content and thumbnails in the blob store. Blobs are marked in an earlier
+// transaction, so that a rollback after their content is deleted leaves them
+// purging rather than pointing at missing content, to be purged again by the
+// next run, deleting missing content being a no-op. The rows stay locked
+// while the content is deleted, so that uploads of the same content wait to
+// store it again.
+func (s Service) purgeBlobs(ctx context.Context, _ time.Time, limit int) (count int64, err error) {
+	blobs, err := s.repo.LockPurgingBlobs(ctx, limit)
+	if err != nil || len(blobs) == 0 {
+		return 0, err
+	}
+
+	sha256s := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		if blob.IsImage() {
+			err = s.blobs.Delete(ctx, blob.ThumbnailKey())
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = s.blobs.Delete(ctx, blob.Key())
+		if err != nil {
+			return 0, err
+		}
+		sha256s = append(sha256s, blob.Sha256)
+	}
+
+	return s.repo.DeletePurgingBlobs(ctx, sha256s)
+}
+
+// purgeAll purges batches until a batch comes up short, with each batch in its
+// own transaction.
+func (s Service) purgeAll(ctx context.Context, step purgeStep) (total int64, err error) {
+	for {
+		count, err := s.purgeBatch(ctx, step)
+		if err != nil {
+			return total, err
+		}
+
+		total += count
+		if count < int64(s.config.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// purgeBatch purges a single batch within a transaction.
+func (s Service) purgeBatch(ctx context.Context, step purgeStep) (count int64, err error) {
+	ctx, err = s.txs.CtxTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer s.txs.TxRollback(ctx)
+
+	count, err = step.purge(ctx, step.before, s.config.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, s.txs.TxCommit(ctx)
+}
@@ -0,0 +1,233 @@
+package servicespurge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/test"
+)
+
+// fakeTxs is a dbports.Transactional which counts commits and rollbacks
+// without isolating anything.
+type fakeTxs struct {
+	commits   int
+	rollbacks int
+}
+
+func (t *fakeTxs) CtxTx(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (t *fakeTxs) TxRollback(_ context.Context) error {
+	t.rollbacks++
+	return nil
+}
+
+func (t *fakeTxs) TxCommit(_ context.Context) error {
+	t.commits++
+	return nil
+}
+
+// fakeRepo is an in-memory dbports.PurgeRepo, holding how many rows of each
+// kind are left to purge and the cutoffs each kind was purged with.
+type fakeRepo struct {
+	rows    map[string]int
+	calls   map[string]int
+	befores map[string]time.Time
+
+	// blobs holds the status of the blobs by their SHA-256.
+	blobs map[string]forum.BlobStatus
+	// contentTypes holds the content type of the blobs by their SHA-256.
+	contentTypes map[string]string
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		rows:         map[string]int{},
+		calls:        map[string]int{},
+		befores:      map[string]time.Time{},
+		blobs:        map[string]forum.BlobStatus{},
+		contentTypes: map[string]string{},
+	}
+}
+
+func (r *fakeRepo) purge(name string, before time.Time, limit int) (int64, error) {
+	r.calls[name]++
+	r.befores[name] = before
+
+	count := min(r.rows[name], limit)
+	r.rows[name] -= count
+	return int64(count), nil
+}
+
+func (r *fakeRepo) PurgeComments(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("comments", before, limit)
+}
+
+func (r *fakeRepo) PurgePosts(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("posts", before, limit)
+}
+
+func (r *fakeRepo) PurgeCommunities(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("communities", before, limit)
+}
+
+func (r *fakeRepo) PurgeUsers(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("users", before, limit)
+}
+
+func (r *fakeRepo) PurgeExpiredBans(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("bans", before, limit)
+}
+
+func (r *fakeRepo) PurgeExpiredSuspensions(_ context.Context, before time.Time, limit int) (int64, error) {
+	return r.purge("suspensions", before, limit)
+}
+
+func (r *fakeRepo) MarkUnusedBlobs(_ context.Context, before time.Time, limit int) (count int64, err error) {
+	r.befores["unused blobs"] = before
+
+	for sha256, status := range r.blobs {
+		if int(count) == limit {
+			break
+		}
+		if status == forum.BlobStatusReady {
+			r.blobs[sha256] = forum.BlobStatusPurging
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeRepo) LockPurgingBlobs(_ context.Context, limit int) ([]forum.Blob, error) {
+	blobs := []forum.Blob{}
+	for sha256, status := range r.blobs {
+		if len(blobs) == limit {
+			break
+		}
+		if status == forum.BlobStatusPurging {
+			blobs = append(blobs, forum.Blob{Sha256: sha256, ContentType: r.contentTypes[sha256], Status: status})
+		}
+	}
+	return blobs, nil
+}
+
+func (r *fakeRepo) DeletePurgingBlobs(_ context.Context, sha256s []string) (count int64, err error) {
+	for _, sha256 := range sha256s {
+		if r.blobs[sha256] == forum.BlobStatusPurging {
+			delete(r.blobs, sha256)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeBlobStore is an in-memory blobports.BlobStore, which fails to delete the
+// keys in fail once each.
+type fakeBlobStore struct {
+	keys map[string]bool
+	fail map[string]bool
+}
+
+func (s *fakeBlobStore) Put(_ context.Context, key string, _ io.Reader, _ int64, _ string) error {
+	s.keys[key] = true
+	return nil
+}
+
+func (s *fakeBlobStore) Open(_ context.Context, _ string) (io.ReadSeekCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeBlobStore) Delete(_ context.Context, key string) error {
+	if s.fail[key] {
+		delete(s.fail, key)
+		return errors.New("blob store unavailable")
+	}
+	delete(s.keys, key)
+	return nil
+}
+
+func TestService_Purge(t *testing.T) {
+	newService := func(repo *fakeRepo, txs *fakeTxs, blobs *fakeBlobStore) Service {
+		return NewService(slog.New(slog.DiscardHandler), txs, repo, blobs,
+			WithRetention(24*time.Hour),
+			WithBatchSize(2),
+		)
+	}
+
+	t.Run("batches are purged until one comes up short", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.rows["comments"] = 5
+		repo.rows["posts"] = 4
+		txs := &fakeTxs{}
+
+		newService(repo, txs, &fakeBlobStore{}).Purge(t.Context())
+
+		test.AssertEqual(t, "Expected all comments purged", 0, repo.rows["comments"])
+		test.AssertEqual(t, "Expected 3 batches of comments", 3, repo.calls["comments"])
+		test.AssertEqual(t, "Expected all posts purged", 0, repo.rows["posts"])
+		test.AssertEqual(t, "Expected 3 batches of posts", 3, repo.calls["posts"])
+		test.AssertEqual(t, "Expected 1 batch of users", 1, repo.calls["users"])
+		test.AssertEqual(t, "Expected every batch to be committed", txs.rollbacks, txs.commits)
+	})
+
+	t.Run("soft deletes are purged after the retention period", func(t *testing.T) {
+		repo := newFakeRepo()
+
+		start := time.Now()
+		newService(repo, &fakeTxs{}, &fakeBlobStore{}).Purge(t.Context())
+		end := time.Now()
+
+		inRange := func(before time.Time, from time.Time, to time.Time) bool {
+			return !before.Before(from) && !before.After(to)
+		}
+		for _, name := range []string{"comments", "posts", "communities", "users", "unused blobs"} {
+			test.Assert(t, "Expected "+name+" to be kept for the retention period",
+				inRange(repo.befores[name], start.Add(-24*time.Hour), end.Add(-24*time.Hour)))
+		}
+		for _, name := range []string{"bans", "suspensions"} {
+			test.Assert(t, "Expected "+name+" to be purged once expired",
+				inRange(repo.befores[name], start, end))
+		}
+	})
+
+	t.Run("unused blobs are purged with their content", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.blobs["image"] = forum.BlobStatusReady
+		repo.contentTypes["image"] = "image/png"
+		repo.blobs["text"] = forum.BlobStatusReady
+		repo.contentTypes["text"] = "text/plain"
+		blobs := &fakeBlobStore{
+			keys: map[string]bool{"image": true, "image.thumb": true, "text": true, "other": true},
+		}
+
+		newService(repo, &fakeTxs{}, blobs).Purge(t.Context())
+
+		test.AssertEqual(t, "Expected blobs to be purged", 0, len(repo.blobs))
+		test.AssertEqual(t, "Expected only other content to remain", map[string]bool{"other": true}, blobs.keys)
+	})
+
+	t.Run("blobs are purged again after failing", func(t *testing.T) {
+		repo := newFakeRepo()
+		repo.blobs["image"] = forum.BlobStatusReady
+		repo.contentTypes["image"] = "image/png"
+		blobs := &fakeBlobStore{
+			keys: map[string]bool{"image": true, "image.thumb": true},
+			fail: map[string]bool{"image": true},
+		}
+		ser := newService(repo, &fakeTxs{}, blobs)
+
+		ser.Purge(t.Context())
+		test.AssertEqual(t, "Expected blob to remain purging", forum.BlobStatusPurging, repo.blobs["image"])
+		test.Assert(t, "Expected thumbnail to be deleted", !blobs.keys["image.thumb"])
+
+		ser.Purge(t.Context())
+		test.AssertEqual(t, "Expected blob to be purged", 0, len(repo.blobs))
+		test.AssertEqual(t, "Expected content to be deleted", 0, len(blobs.keys))
+	})
+}
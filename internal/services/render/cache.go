@@ -0,0 +1,76 @@
+package servicesrender
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheKey is the key for a rendered body, the SHA-256 hash of its revision
+// ID and resolved links.
+type cacheKey = [32]byte
+
+// cacheEntry is a single rendered body in the cache.
+type cacheEntry struct {
+	key  cacheKey
+	html string
+}
+
+// cache is a size bounded, least recently used cache of rendered bodies. It is
+// safe for concurrent use.
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[cacheKey]*list.Element
+}
+
+// newCache creates a new cache holding at most size entries. A size of 0 or
+// less disables caching.
+func newCache(size int) *cache {
+	return &cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// get returns the rendered body for the key, if present.
+func (c *cache) get(key cacheKey) (html string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(e)
+
+	return e.Value.(cacheEntry).html, true
+}
+
+// put adds the rendered body to the cache, evicting the least recently used
+// entry if the cache is full.
+func (c *cache) put(key cacheKey, html string) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(cacheEntry{
+		key:  key,
+		html: html,
+	})
+
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(cacheEntry).key)
+	}
+}
@@ -0,0 +1,33 @@
+package servicesrender
+
+// Config represents the configuration for the render service.
+type Config struct {
+	cacheSize     int
+	postUrlPrefix string
+}
+
+// Option represents an option for the render service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the render service.
+func defaultConfig() Config {
+	return Config{
+		cacheSize:     1024,
+		postUrlPrefix: "/posts/",
+	}
+}
+
+// WithCacheSize sets the maximum number of rendered bodies kept in memory.
+func WithCacheSize(size int) Option {
+	return func(c *Config) {
+		c.cacheSize = size
+	}
+}
+
+// WithPostUrlPrefix sets the prefix of the URLs that resolved wiki-style links
+// point to. The ID of the target post is appended to the prefix.
+func WithPostUrlPrefix(prefix string) Option {
+	return func(c *Config) {
+		c.postUrlPrefix = prefix
+	}
+}
@@ -0,0 +1,131 @@
+package servicesrender
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"regexp"
+	"slices"
+
+	"greddit/internal/domains/forum"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// languageClassPattern matches the classes emitted on fenced code blocks, e.g.
+// "language-go".
+var languageClassPattern = regexp.MustCompile(`^language-[\w+#.-]+$`)
+
+// checkboxPattern matches the type of the inputs emitted for task lists.
+var checkboxPattern = regexp.MustCompile(`^checkbox$`)
+
+// wikiLinkClassPattern matches the classes emitted for wiki-style links.
+var wikiLinkClassPattern = regexp.MustCompile(`^wikilink( wikilink-dangling)?$`)
+
+// Service renders markdown bodies into sanitized HTML. Supports CommonMark
+// with the GFM extensions (tables, task lists, strikethrough and autolinks), as
+// well as wiki-style links between posts.
+type Service struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+	cache  *cache
+}
+
+// NewService creates a new Service.
+func NewService(opts ...Option) Service {
+	config := defaultConfig()
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(languageClassPattern).OnElements("code")
+	policy.AllowAttrs("type").Matching(checkboxPattern).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowAttrs("class").Matching(wikiLinkClassPattern).OnElements("a", "span")
+
+	return Service{
+		md: goldmark.New(
+			goldmark.WithExtensions(
+				extension.NewTable(
+					extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute),
+				),
+				extension.Strikethrough,
+				extension.Linkify,
+				extension.TaskList,
+				wikiLinkExtension{
+					postUrlPrefix: config.postUrlPrefix,
+				},
+			),
+		),
+		policy: policy,
+		cache:  newCache(config.cacheSize),
+	}
+}
+
+// Render converts the markdown body of a revision into sanitized HTML.
+// Wiki-style links are resolved using the given links, with any link not
+// resolved to a post rendered as dangling. Results are cached by the revision
+// ID and the resolved links, as links resolve without the revision changing,
+// so each revision of a post or comment is only rendered once. Bodies with a
+// nil revision ID are not cached.
+func (s Service) Render(revisionId forum.RevisionId, body string, links []forum.Link) (html string, err error) {
+	targets := make(map[string]forum.PostId, len(links))
+	for _, link := range links {
+		if link.TargetPostId != nil {
+			targets[link.Key()] = *link.TargetPostId
+		}
+	}
+
+	cached := revisionId != uuid.Nil
+	key := renderKey(revisionId, targets)
+
+	if cached {
+		html, ok := s.cache.get(key)
+		if ok {
+			return html, nil
+		}
+	}
+
+	pc := parser.NewContext()
+	pc.Set(wikiLinkTargetsKey, targets)
+
+	buf := bytes.Buffer{}
+	err = s.md.Convert([]byte(body), &buf, parser.WithContext(pc))
+	if err != nil {
+		return "", err
+	}
+
+	html = s.policy.Sanitize(buf.String())
+	if cached {
+		s.cache.put(key, html)
+	}
+
+	return html, nil
+}
+
+// renderKey returns the cache key for the revision with the resolved link
+// targets.
+func renderKey(revisionId forum.RevisionId, targets map[string]forum.PostId) cacheKey {
+	h := sha256.New()
+	h.Write(revisionId[:])
+
+	keys := make([]string, 0, len(targets))
+	for k := range targets {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		h.Write([]byte{0})
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(targets[k].String()))
+	}
+
+	return cacheKey(h.Sum(nil))
+}
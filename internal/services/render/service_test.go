@@ -0,0 +1,204 @@
+package servicesrender
+
+import (
+	"strings"
+	"testing"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/test"
+
+	"github.com/google/uuid"
+)
+
+func TestService_Render(t *testing.T) {
+	t.Run("commonmark and gfm", func(t *testing.T) {
+		t.Parallel()
+
+		data := []struct {
+			name     string
+			body     string
+			contains []string
+		}{
+			{
+				name:     "emphasis",
+				body:     "some *emphasised* and **strong** text",
+				contains: []string{"<em>emphasised</em>", "<strong>strong</strong>"},
+			},
+			{
+				name: "table",
+				body: "| a | b |\n|:--|--:|\n| 1 | 2 |\n",
+				contains: []string{
+					"<table>", `<th align="left">a</th>`, `<td align="right">2</td>`,
+				},
+			},
+			{
+				name: "task list",
+				body: "- [x] done\n- [ ] todo\n",
+				contains: []string{
+					`<input checked="" disabled="" type="checkbox"> done`,
+					`<input disabled="" type="checkbox"> todo`,
+				},
+			},
+			{
+				name:     "fenced code with language",
+				body:     "```go\nfmt.Println(1)\n```\n",
+				contains: []string{`<pre><code class="language-go">fmt.Println(1)`},
+			},
+			{
+				name:     "strikethrough",
+				body:     "~~gone~~",
+				contains: []string{"<del>gone</del>"},
+			},
+			{
+				name:     "autolink",
+				body:     "see https://example.com",
+				contains: []string{`<a href="https://example.com" rel="nofollow">https://example.com</a>`},
+			},
+		}
+
+		s := NewService()
+		for _, d := range data {
+			html, err := s.Render(uuid.Nil, d.body, nil)
+			test.NilErr(t, err)
+			for _, c := range d.contains {
+				test.Assert(t, d.name+": expected html to contain "+c, strings.Contains(html, c))
+			}
+		}
+	})
+
+	t.Run("sanitization", func(t *testing.T) {
+		t.Parallel()
+
+		data := []struct {
+			name     string
+			body     string
+			excludes []string
+		}{
+			{
+				name:     "script tag",
+				body:     "<script>alert(1)</script>",
+				excludes: []string{"<script", "alert(1)"},
+			},
+			{
+				name:     "javascript link",
+				body:     "[click](javascript:alert(1))",
+				excludes: []string{"javascript:"},
+			},
+			{
+				name:     "event handler",
+				body:     `<b onclick="alert(1)">bold</b>`,
+				excludes: []string{"onclick"},
+			},
+			{
+				name:     "arbitrary class",
+				body:     "```x\" onmouseover=\"alert(1)\ncode\n```\n",
+				excludes: []string{"onmouseover"},
+			},
+			{
+				name:     "non checkbox input",
+				body:     `<input type="text" value="x">`,
+				excludes: []string{`type="text"`},
+			},
+		}
+
+		s := NewService()
+		for _, d := range data {
+			html, err := s.Render(uuid.Nil, d.body, nil)
+			test.NilErr(t, err)
+			for _, e := range d.excludes {
+				test.Assert(t, d.name+": expected html to not contain "+e, !strings.Contains(html, e))
+			}
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewService(WithCacheSize(1))
+		revisionId := uuid.New()
+
+		first, err := s.Render(revisionId, "first", nil)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected 1 cached entry", 1, s.cache.order.Len())
+
+		again, err := s.Render(revisionId, "first", nil)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected same html", first, again)
+		test.AssertEqual(t, "Expected 1 cached entry", 1, s.cache.order.Len())
+
+		_, err = s.Render(uuid.New(), "second", nil)
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Expected oldest entry to be evicted", 1, s.cache.order.Len())
+		_, ok := s.cache.get([32]byte{})
+		test.Assert(t, "Expected no entry for unknown key", !ok)
+	})
+
+	t.Run("bodies without a revision are not cached", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewService()
+
+		first, err := s.Render(uuid.Nil, "first", nil)
+		test.NilErr(t, err)
+		second, err := s.Render(uuid.Nil, "second", nil)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected each body to be rendered", first != second)
+		test.AssertEqual(t, "Expected no cached entries", 0, s.cache.order.Len())
+	})
+
+	t.Run("wiki links", func(t *testing.T) {
+		t.Parallel()
+
+		target := uuid.New()
+		links := []forum.Link{
+			{
+				WikiLink:     forum.WikiLink{Community: "golang", Title: "Resolved Post"},
+				TargetPostId: &target,
+			},
+			{
+				WikiLink: forum.WikiLink{Title: "Missing Post"},
+			},
+		}
+
+		s := NewService()
+		html, err := s.Render(uuid.Nil, "See [[golang/resolved post]], [[Missing Post]] and `[[Code]]`", links)
+		test.NilErr(t, err)
+
+		expected := `<a class="wikilink" href="/posts/` + target.String() + `" rel="nofollow">golang/resolved post</a>`
+		test.Assert(t, "Expected resolved link, got "+html, strings.Contains(html, expected))
+		expected = `<span class="wikilink wikilink-dangling">Missing Post</span>`
+		test.Assert(t, "Expected dangling link, got "+html, strings.Contains(html, expected))
+		test.Assert(t, "Expected link in code to be left as is", strings.Contains(html, "<code>[[Code]]</code>"))
+	})
+
+	t.Run("wiki link label is escaped", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewService()
+		html, err := s.Render(uuid.Nil, `[[<img src=x onerror="alert(1)">]]`, nil)
+		test.NilErr(t, err)
+		test.Assert(t, "Expected label to be escaped, got "+html, !strings.Contains(html, "<img"))
+	})
+
+	t.Run("cache keyed by resolved links", func(t *testing.T) {
+		t.Parallel()
+
+		s := NewService()
+		revisionId := uuid.New()
+		body := "[[Later Post]]"
+
+		dangling, err := s.Render(revisionId, body, nil)
+		test.NilErr(t, err)
+
+		target := uuid.New()
+		resolved, err := s.Render(revisionId, body, []forum.Link{
+			{
+				WikiLink:     forum.WikiLink{Title: "Later Post"},
+				TargetPostId: &target,
+			},
+		})
+		test.NilErr(t, err)
+
+		test.Assert(t, "Expected html to change once the link resolves", dangling != resolved)
+	})
+}
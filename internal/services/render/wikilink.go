@@ -0,0 +1,144 @@
+package servicesrender
+
+import (
+	"bytes"
+
+	"greddit/internal/domains/forum"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// wikiLinkParserPriority places the parser before the standard link
+	// parser, which also triggers on '['.
+	wikiLinkParserPriority = 199
+
+	wikiLinkRendererPriority = 500
+)
+
+// kindWikiLink is the node kind of a wiki-style link.
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLinkTargetsKey is the parser context key holding the resolved targets,
+// mapping forum.WikiLink.Key to the ID of the target post.
+var wikiLinkTargetsKey = parser.NewContextKey()
+
+// wikiLinkNode is a wiki-style link within a body. The target is nil if the
+// link is dangling.
+type wikiLinkNode struct {
+	ast.BaseInline
+
+	link   forum.WikiLink
+	target *forum.PostId
+}
+
+// Kind implements ast.Node.
+func (n *wikiLinkNode) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+// Dump implements ast.Node.
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Link": n.link.String(),
+	}, nil)
+}
+
+// wikiLinkParser parses [[Post Title]] and [[community/Post Title]] links.
+type wikiLinkParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (p wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+// Parse implements parser.InlineParser.
+func (p wikiLinkParser) Parse(_ ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if len(line) < 2 || line[1] != '[' {
+		return nil
+	}
+
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := line[2 : 2+end]
+	if bytes.ContainsAny(inner, "[]") {
+		return nil
+	}
+
+	link, ok := forum.NewWikiLink(string(inner))
+	if !ok {
+		return nil
+	}
+	block.Advance(end + 4)
+
+	node := &wikiLinkNode{
+		link: link,
+	}
+	targets, ok := pc.Get(wikiLinkTargetsKey).(map[string]forum.PostId)
+	if ok {
+		if id, ok := targets[link.Key()]; ok {
+			node.target = &id
+		}
+	}
+
+	return node
+}
+
+// wikiLinkRenderer renders resolved links as anchors to the target post, and
+// dangling links as marked spans.
+type wikiLinkRenderer struct {
+	postUrlPrefix string
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, r.render)
+}
+
+// render writes the HTML for a wiki-style link.
+func (r wikiLinkRenderer) render(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*wikiLinkNode)
+	label := util.EscapeHTML([]byte(n.link.String()))
+
+	if n.target == nil {
+		_, _ = w.WriteString(`<span class="wikilink wikilink-dangling">`)
+		_, _ = w.Write(label)
+		_, _ = w.WriteString(`</span>`)
+		return ast.WalkSkipChildren, nil
+	}
+
+	_, _ = w.WriteString(`<a class="wikilink" href="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(r.postUrlPrefix + n.target.String())))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(label)
+	_, _ = w.WriteString(`</a>`)
+
+	return ast.WalkSkipChildren, nil
+}
+
+// wikiLinkExtension adds wiki-style links to goldmark.
+type wikiLinkExtension struct {
+	postUrlPrefix string
+}
+
+// Extend implements goldmark.Extender.
+func (e wikiLinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(
+		util.Prioritized(wikiLinkParser{}, wikiLinkParserPriority),
+	))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(wikiLinkRenderer{postUrlPrefix: e.postUrlPrefix}, wikiLinkRendererPriority),
+	))
+}
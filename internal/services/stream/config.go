@@ -0,0 +1,80 @@
+package servicesstream
+
+import "time"
+
+// Config represents the configuration for the stream service.
+type Config struct {
+	retention     time.Duration
+	pruneInterval time.Duration
+	retryDelay    time.Duration
+	bufferSize    int
+	replayLimit   int
+
+	presenceInterval time.Duration
+}
+
+// Option represents an option for the stream service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the stream service.
+func defaultConfig() Config {
+	return Config{
+		retention:     24 * time.Hour,
+		pruneInterval: time.Hour,
+		retryDelay:    5 * time.Second,
+		bufferSize:    64,
+		replayLimit:   1000,
+
+		presenceInterval: 20 * time.Second,
+	}
+}
+
+// WithRetention sets how long events are kept for reconnecting clients to
+// replay.
+func WithRetention(retention time.Duration) Option {
+	return func(c *Config) {
+		c.retention = retention
+	}
+}
+
+// WithPruneInterval sets the interval between deletions of events older than
+// the retention period.
+func WithPruneInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.pruneInterval = interval
+	}
+}
+
+// WithRetryDelay sets how long to wait before listening for events again
+// after the connection to the database fails.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.retryDelay = delay
+	}
+}
+
+// WithBufferSize sets how many events may be queued for a client before the
+// client is disconnected for being too slow.
+func WithBufferSize(size int) Option {
+	return func(c *Config) {
+		c.bufferSize = size
+	}
+}
+
+// WithReplayLimit sets the maximum number of missed events replayed to a
+// reconnecting client.
+func WithReplayLimit(limit int) Option {
+	return func(c *Config) {
+		c.replayLimit = limit
+	}
+}
+
+// WithPresenceInterval sets the interval at which the viewers of posts are
+// recounted and the sessions of connected clients are kept alive. Sessions
+// not seen for three intervals, such as those of a crashed instance, no
+// longer count as viewers.
+func WithPresenceInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.presenceInterval = interval
+	}
+}
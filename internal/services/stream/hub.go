@@ -0,0 +1,265 @@
+package servicesstream
+
+import (
+	"sync"
+
+	"greddit/internal/domains/auth"
+	"greddit/internal/domains/forum"
+	"greddit/internal/util/set"
+
+	dbports "greddit/internal/ports/db"
+
+	"github.com/google/uuid"
+)
+
+// Stream is the live events matching the filter of a client. The events are
+// closed when the stream is closed, when the service stops, when the client
+// falls behind or when the access of its user to a community is revoked,
+// after which the client is expected to reconnect with the ID of the last
+// event it received.
+type Stream struct {
+	hub    *hub
+	filter forum.EventFilter
+	events chan forum.Event
+
+	// pending is set while the missed events of the client are replayed,
+	// during which live events are held in the backlog.
+	pending    bool
+	backlog    []forum.Event
+	overflowed bool
+
+	// userId is the user of the stream, whose streams are closed when their
+	// access to a community is revoked.
+	userId auth.UserId
+
+	// sessionId identifies the viewer of the posts watched by a thread
+	// stream, which may only watch the posts visible to the viewer.
+	sessionId  uuid.UUID
+	visibility dbports.ReadOption
+}
+
+// Events returns the events of the stream, in the order they were
+// published.
+func (st *Stream) Events() <-chan forum.Event {
+	return st.events
+}
+
+// Close closes the stream. Closing a stream more than once has no effect.
+func (st *Stream) Close() {
+	st.hub.remove(st)
+}
+
+// hub fans out the published events to the matching streams.
+type hub struct {
+	mu         sync.Mutex
+	streams    map[*Stream]struct{}
+	bufferSize int
+	closed     bool
+
+	// viewers is the last number of viewers dispatched for each watched
+	// post.
+	viewers map[forum.PostId]int
+}
+
+// newHub creates a new hub.
+func newHub(bufferSize int) *hub {
+	return &hub{
+		streams:    make(map[*Stream]struct{}),
+		bufferSize: bufferSize,
+		viewers:    make(map[forum.PostId]int),
+	}
+}
+
+// add adds a pending stream, holding its live events until it is activated.
+func (h *hub) add(st *Stream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st.pending = true
+	h.streams[st] = struct{}{}
+}
+
+// activate queues the replayed events of the stream followed by the live
+// events held since it was added, skipping those already replayed, and sends
+// further events to the stream directly.
+func (h *hub) activate(st *Stream, replayed []forum.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st.events = make(chan forum.Event, len(replayed)+len(st.backlog)+h.bufferSize)
+	ids := set.New[forum.EventId]()
+	for _, event := range replayed {
+		st.events <- event
+		ids.Add(event.Id)
+	}
+	for _, event := range st.backlog {
+		if !ids.Contains(event.Id) {
+			st.events <- event
+		}
+	}
+
+	_, ok := h.streams[st]
+	if h.closed || !ok || st.overflowed {
+		delete(h.streams, st)
+		close(st.events)
+		return
+	}
+	st.pending = false
+	st.backlog = nil
+}
+
+// remove removes the stream, closing its events.
+func (h *hub) remove(st *Stream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(st)
+}
+
+// removeLocked removes the stream while the lock is held. Pending streams
+// have their events closed once activated.
+func (h *hub) removeLocked(st *Stream) {
+	if _, ok := h.streams[st]; !ok {
+		return
+	}
+
+	delete(h.streams, st)
+	if !st.pending {
+		close(st.events)
+	}
+	for _, postId := range st.filter.PostIds.Keys() {
+		h.forgetLocked(postId)
+	}
+}
+
+// watch adds the post to the filter of the stream, returning false if the
+// stream has been removed.
+func (h *hub) watch(st *Stream, postId forum.PostId) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.streams[st]; !ok {
+		return false
+	}
+
+	st.filter.PostIds.Add(postId)
+	return true
+}
+
+// unwatch removes the post from the filter of the stream, returning whether
+// the stream was watching it.
+func (h *hub) unwatch(st *Stream, postId forum.PostId) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.streams[st]; !ok || !st.filter.PostIds.Remove(postId) {
+		return false
+	}
+
+	h.forgetLocked(postId)
+	return true
+}
+
+// watched returns the sessions of the thread streams and the posts they
+// watch.
+func (h *hub) watched() (sessionIds []uuid.UUID, postIds []forum.PostId) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	posts := set.New[forum.PostId]()
+	for st := range h.streams {
+		if st.sessionId == uuid.Nil {
+			continue
+		}
+
+		sessionIds = append(sessionIds, st.sessionId)
+		posts.Add(st.filter.PostIds.Keys()...)
+	}
+
+	return sessionIds, posts.Keys()
+}
+
+// isWatched returns whether a thread stream watches the post.
+func (h *hub) isWatched(postId forum.PostId) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.isWatchedLocked(postId)
+}
+
+// isWatchedLocked returns whether a thread stream watches the post while the
+// lock is held.
+func (h *hub) isWatchedLocked(postId forum.PostId) bool {
+	for st := range h.streams {
+		if st.sessionId != uuid.Nil && st.filter.PostIds.Contains(postId) {
+			return true
+		}
+	}
+	return false
+}
+
+// setViewers records the number of viewers of the post, returning whether
+// it changed since last recorded. Posts no longer watched are not recorded.
+func (h *hub) setViewers(postId forum.PostId, viewers int) (changed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.isWatchedLocked(postId) {
+		return false
+	}
+
+	last, ok := h.viewers[postId]
+	h.viewers[postId] = viewers
+	return !ok || last != viewers
+}
+
+// forgetLocked forgets the number of viewers of the post once no thread
+// stream watches it, while the lock is held.
+func (h *hub) forgetLocked(postId forum.PostId) {
+	if !h.isWatchedLocked(postId) {
+		delete(h.viewers, postId)
+	}
+}
+
+// dispatch sends the event to every matching stream, removing the streams
+// whose buffers are full and those of the users whose access it revokes.
+func (h *hub) dispatch(event forum.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for st := range h.streams {
+		if event.Revokes(st.userId) {
+			h.removeLocked(st)
+			continue
+		}
+		if !st.filter.Matches(event) {
+			continue
+		}
+
+		if st.pending {
+			if len(st.backlog) < h.bufferSize {
+				st.backlog = append(st.backlog, event)
+			} else {
+				st.overflowed = true
+			}
+			continue
+		}
+
+		select {
+		case st.events <- event:
+		default:
+			h.removeLocked(st)
+		}
+	}
+}
+
+// close removes every stream, and any stream added afterwards.
+func (h *hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for st := range h.streams {
+		h.removeLocked(st)
+	}
+}
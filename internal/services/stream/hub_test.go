@@ -0,0 +1,190 @@
+package servicesstream
+
+import (
+	"testing"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/test"
+	"greddit/internal/util/set"
+
+	"github.com/google/uuid"
+)
+
+func TestHub(t *testing.T) {
+	userId := uuid.New()
+	notification := func(id forum.EventId) forum.Event {
+		return forum.Event{
+			Id: id,
+			EventValue: forum.EventValue{
+				Kind:        forum.EventKindNotification,
+				RecipientId: &userId,
+			},
+		}
+	}
+
+	newStream := func(h *hub) *Stream {
+		st := &Stream{
+			hub: h,
+			filter: forum.EventFilter{
+				RecipientId: &userId,
+			},
+			userId: userId,
+		}
+		h.add(st)
+		return st
+	}
+
+	drain := func(st *Stream) (ids []forum.EventId, closed bool) {
+		for {
+			select {
+			case event, ok := <-st.Events():
+				if !ok {
+					return ids, true
+				}
+				ids = append(ids, event.Id)
+			default:
+				return ids, false
+			}
+		}
+	}
+
+	t.Run("replay is merged with live events", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(8)
+		st := newStream(h)
+
+		h.dispatch(notification(3))
+		h.dispatch(notification(4))
+		h.activate(st, []forum.Event{notification(2), notification(3)})
+		h.dispatch(notification(5))
+		h.dispatch(forum.Event{Id: 6, EventValue: forum.EventValue{Kind: forum.EventKindPostCreated}})
+
+		ids, closed := drain(st)
+		test.AssertEqual(t, "Unexpected events", []forum.EventId{2, 3, 4, 5}, ids)
+		test.Assert(t, "Expected stream to be open", !closed)
+
+		st.Close()
+		st.Close()
+		_, closed = drain(st)
+		test.Assert(t, "Expected stream to be closed", closed)
+	})
+
+	t.Run("slow streams are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(2)
+		st := newStream(h)
+		h.activate(st, nil)
+
+		for id := range forum.EventId(3) {
+			h.dispatch(notification(id + 1))
+		}
+
+		ids, closed := drain(st)
+		test.AssertEqual(t, "Unexpected events", []forum.EventId{1, 2}, ids)
+		test.Assert(t, "Expected stream to be closed", closed)
+	})
+
+	t.Run("overflowing backlog closes the stream", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(1)
+		st := newStream(h)
+		h.dispatch(notification(1))
+		h.dispatch(notification(2))
+		h.activate(st, nil)
+
+		ids, closed := drain(st)
+		test.AssertEqual(t, "Unexpected events", []forum.EventId{1}, ids)
+		test.Assert(t, "Expected stream to be closed", closed)
+	})
+
+	t.Run("closing the hub closes all streams", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(1)
+		active := newStream(h)
+		h.activate(active, nil)
+		pending := newStream(h)
+
+		h.close()
+		h.activate(pending, nil)
+
+		_, closed := drain(active)
+		test.Assert(t, "Expected active stream to be closed", closed)
+		_, closed = drain(pending)
+		test.Assert(t, "Expected pending stream to be closed", closed)
+	})
+
+	t.Run("revoked access closes the streams of the user", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(8)
+		st := newStream(h)
+		h.activate(st, nil)
+		other := &Stream{
+			hub:    h,
+			userId: uuid.New(),
+		}
+		h.add(other)
+		h.activate(other, nil)
+
+		revoked, err := forum.NewAccessRevokedEvent(forum.AccessRevocation{
+			CommunityId: uuid.New(),
+			UserId:      &userId,
+		})
+		test.NilErr(t, err)
+		h.dispatch(notification(1))
+		h.dispatch(forum.Event{Id: 2, EventValue: revoked})
+
+		ids, closed := drain(st)
+		test.AssertEqual(t, "Unexpected events", []forum.EventId{1}, ids)
+		test.Assert(t, "Expected stream to be closed", closed)
+		_, closed = drain(other)
+		test.Assert(t, "Expected stream of other user to be open", !closed)
+
+		private, err := forum.NewAccessRevokedEvent(forum.AccessRevocation{
+			CommunityId: uuid.New(),
+		})
+		test.NilErr(t, err)
+		h.dispatch(forum.Event{Id: 3, EventValue: private})
+
+		_, closed = drain(other)
+		test.Assert(t, "Expected every stream to be closed", closed)
+	})
+
+	t.Run("viewers are tracked while posts are watched", func(t *testing.T) {
+		t.Parallel()
+
+		h := newHub(8)
+		postId := uuid.New()
+		st := &Stream{
+			hub: h,
+			filter: forum.EventFilter{
+				PostIds: set.New[forum.PostId](),
+			},
+			sessionId: uuid.New(),
+			userId:    userId,
+		}
+		h.add(st)
+		h.activate(st, nil)
+
+		test.Assert(t, "Expected unwatched post to be ignored", !h.setViewers(postId, 1))
+		test.Assert(t, "Expected post to be watched", h.watch(st, postId))
+		test.Assert(t, "Expected new viewers to change", h.setViewers(postId, 1))
+		test.Assert(t, "Expected same viewers not to change", !h.setViewers(postId, 1))
+
+		sessionIds, postIds := h.watched()
+		test.AssertEqual(t, "Unexpected sessions", []uuid.UUID{st.sessionId}, sessionIds)
+		test.AssertEqual(t, "Unexpected posts", []forum.PostId{postId}, postIds)
+
+		test.Assert(t, "Expected post to be unwatched", h.unwatch(st, postId))
+		test.Assert(t, "Expected post not to be unwatched twice", !h.unwatch(st, postId))
+		test.Assert(t, "Expected post to be watched again", h.watch(st, postId))
+		test.Assert(t, "Expected viewers to be forgotten", h.setViewers(postId, 1))
+
+		st.Close()
+		test.Assert(t, "Expected closed stream not to watch", !h.watch(st, postId))
+	})
+}
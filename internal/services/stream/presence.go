@@ -0,0 +1,194 @@
+package servicesstream
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/util/set"
+
+	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+
+	"github.com/google/uuid"
+)
+
+// ErrStreamClosed is returned when a closed stream is asked to watch a post.
+var ErrStreamClosed = errors.New("stream closed")
+
+// leaveTimeout bounds how long leaving the watched posts of a closed thread
+// stream may take.
+const leaveTimeout = 5 * time.Second
+
+// OpenThreads opens a stream for the user in the claims, which initially
+// watches no post. Watching a post streams the changes to its comments and
+// the number of users viewing it, counting the user as a viewer until the
+// post is unwatched or the stream is closed with CloseThreads.
+func (s Service) OpenThreads(claims servicesauth.TokenClaims) *Stream {
+	stream := &Stream{
+		hub: s.hub,
+		filter: forum.EventFilter{
+			PostIds: set.New[forum.PostId](),
+		},
+		sessionId:  uuid.New(),
+		userId:     claims.UserId,
+		visibility: servicesforum.VisibleTo(claims),
+	}
+
+	s.hub.add(stream)
+	s.hub.activate(stream, nil)
+
+	return stream
+}
+
+// Watch makes the thread stream watch the post, returning the number of
+// users viewing it, including the user of the stream. Posts in communities
+// not visible to the user are not found.
+func (s Service) Watch(ctx context.Context, stream *Stream, postId forum.PostId) (viewers int, err error) {
+	_, err = s.posts.GetPostById(ctx, postId, stream.visibility)
+	if err != nil {
+		return 0, err
+	}
+
+	if !s.hub.watch(stream, postId) {
+		return 0, ErrStreamClosed
+	}
+
+	err = s.presence.Join(ctx, stream.sessionId, postId, stream.userId)
+	if err != nil {
+		s.hub.unwatch(stream, postId)
+		s.logger.ErrorContext(ctx, "stream.service :: Error joining viewers of post",
+			"postId", postId,
+			"error", err,
+		)
+		return 0, err
+	}
+
+	counts, err := s.recount(ctx, []forum.PostId{postId})
+	if err != nil {
+		return 0, err
+	}
+
+	return counts[postId], nil
+}
+
+// Unwatch stops the thread stream from watching the post. Unwatching a post
+// which is not watched has no effect.
+func (s Service) Unwatch(ctx context.Context, stream *Stream, postId forum.PostId) (err error) {
+	if !s.hub.unwatch(stream, postId) {
+		return nil
+	}
+
+	err = s.presence.Leave(ctx, stream.sessionId, postId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "stream.service :: Error leaving viewers of post",
+			"postId", postId,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// CloseThreads closes the thread stream, no longer counting its user as a
+// viewer of the watched posts. Leaving the posts is not cancelled with the
+// context, as the client has usually disconnected by then.
+func (s Service) CloseThreads(ctx context.Context, stream *Stream) {
+	stream.Close()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaveTimeout)
+	defer cancel()
+
+	err := s.presence.LeaveAll(ctx, stream.sessionId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "stream.service :: Error leaving viewers of posts",
+			"error", err,
+		)
+	}
+}
+
+// keepPresence keeps the sessions of the thread streams alive, recounts the
+// viewers of the watched posts and deletes the sessions of other instances
+// which stopped without leaving, at every presence interval, until the
+// context is cancelled.
+func (s Service) keepPresence(ctx context.Context) {
+	ticker := time.NewTicker(s.config.presenceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sessionIds, postIds := s.hub.watched()
+		if len(sessionIds) > 0 {
+			err := s.presence.Touch(ctx, sessionIds)
+			if err != nil && ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "stream.service :: Error keeping sessions alive",
+					"error", err,
+				)
+			}
+		}
+
+		_, err := s.presence.DeleteStale(ctx, s.staleBefore())
+		if err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "stream.service :: Error deleting stale sessions",
+				"error", err,
+			)
+		}
+
+		_, _ = s.recount(ctx, postIds)
+	}
+}
+
+// recount counts the viewers of the posts, dispatching the presence of those
+// whose number of viewers changed to the streams watching them.
+func (s Service) recount(ctx context.Context, postIds []forum.PostId) (counts map[forum.PostId]int, err error) {
+	if len(postIds) == 0 {
+		return map[forum.PostId]int{}, nil
+	}
+
+	counts, err = s.presence.CountViewers(ctx, postIds, s.staleBefore())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "stream.service :: Error counting viewers of posts",
+				"error", err,
+			)
+		}
+		return nil, err
+	}
+
+	for _, postId := range postIds {
+		viewers := counts[postId]
+		if !s.hub.setViewers(postId, viewers) {
+			continue
+		}
+
+		value, err := forum.NewPresenceEvent(forum.Presence{
+			PostId:  postId,
+			Viewers: viewers,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "stream.service :: Error creating presence event",
+				"error", err,
+			)
+			continue
+		}
+		s.hub.dispatch(forum.Event{
+			CreatedAt:  time.Now(),
+			EventValue: value,
+		})
+	}
+
+	return counts, nil
+}
+
+// staleBefore returns the cutoff before which sessions no longer count as
+// viewers.
+func (s Service) staleBefore() time.Time {
+	return time.Now().Add(-3 * s.config.presenceInterval)
+}
@@ -0,0 +1,272 @@
+package servicesstream
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/util/set"
+
+	dbportsforum "greddit/internal/ports/db/forum"
+	servicesauth "greddit/internal/services/auth"
+	servicesforum "greddit/internal/services/forum"
+)
+
+// Service is the stream service, fanning out the events published by every
+// server instance to the clients connected to this one.
+type Service struct {
+	logger *slog.Logger
+	config Config
+	hub    *hub
+
+	events        dbportsforum.EventsRepo
+	subscriptions dbportsforum.SubscriptionsRepo
+	posts         dbportsforum.PostsRepo
+	presence      dbportsforum.PresenceRepo
+}
+
+// Repos contains the repositories used by the stream service.
+type Repos struct {
+	Events        dbportsforum.EventsRepo
+	Subscriptions dbportsforum.SubscriptionsRepo
+	Posts         dbportsforum.PostsRepo
+	Presence      dbportsforum.PresenceRepo
+}
+
+// NewService creates a new Service.
+func NewService(logger *slog.Logger, repos Repos, opts ...Option) Service {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	defaults := defaultConfig()
+	if config.pruneInterval <= 0 {
+		config.pruneInterval = defaults.pruneInterval
+	}
+	if config.retryDelay <= 0 {
+		config.retryDelay = defaults.retryDelay
+	}
+	if config.bufferSize <= 0 {
+		config.bufferSize = defaults.bufferSize
+	}
+	if config.replayLimit <= 0 {
+		config.replayLimit = defaults.replayLimit
+	}
+	if config.presenceInterval <= 0 {
+		config.presenceInterval = defaults.presenceInterval
+	}
+
+	return Service{
+		logger: logger,
+		config: config,
+		hub:    newHub(config.bufferSize),
+
+		events:        repos.Events,
+		subscriptions: repos.Subscriptions,
+		posts:         repos.Posts,
+		presence:      repos.Presence,
+	}
+}
+
+// Start listens for published events and changes to the viewers of posts,
+// dispatching them to the connected streams, and periodically deletes events
+// older than the retention period. It blocks until the context is cancelled,
+// closing every stream.
+func (s Service) Start(ctx context.Context) error {
+	s.logger.Info("Stream hub started",
+		"retention", s.config.retention,
+	)
+	defer s.logger.Info("Stream hub stopped")
+	defer s.hub.close()
+
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	wg.Go(func() {
+		s.prune(ctx)
+	})
+	wg.Go(func() {
+		s.keepPresence(ctx)
+	})
+	wg.Go(func() {
+		s.retry(ctx, "presence", func(reconnected bool) error {
+			return s.presence.Listen(ctx, func() {
+				// Changes made while reconnecting were not announced.
+				if reconnected {
+					_, postIds := s.hub.watched()
+					s.recount(ctx, postIds)
+				}
+			}, func(postId forum.PostId) {
+				if s.hub.isWatched(postId) {
+					s.recount(ctx, []forum.PostId{postId})
+				}
+			})
+		})
+	})
+
+	var lastId forum.EventId
+	dispatch := func(event forum.Event) {
+		lastId = max(lastId, event.Id)
+		s.hub.dispatch(event)
+	}
+
+	s.retry(ctx, "events", func(reconnected bool) error {
+		return s.events.Listen(ctx, func() {
+			// Events published while reconnecting were not announced.
+			if reconnected {
+				s.catchUp(ctx, lastId, dispatch)
+			}
+		}, func(id forum.EventId) {
+			event, err := s.events.GetEventById(ctx, id)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "stream.service :: Error getting event",
+					"eventId", id,
+					"error", err,
+				)
+				return
+			}
+			dispatch(*event)
+		})
+	})
+
+	return nil
+}
+
+// retry calls the listen function until the context is cancelled, waiting
+// for the retry delay after every failure. The function is told whether it
+// is reconnecting after a failure.
+func (s Service) retry(ctx context.Context, name string, listen func(reconnected bool) error) {
+	for reconnected := false; ; reconnected = true {
+		err := listen(reconnected)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.logger.ErrorContext(ctx, "stream.service :: Error listening, retrying",
+			"listener", name,
+			"retryDelay", s.config.retryDelay,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.config.retryDelay):
+		}
+	}
+}
+
+// catchUp dispatches the events published after the given ID.
+func (s Service) catchUp(ctx context.Context, afterId forum.EventId, dispatch func(event forum.Event)) {
+	for {
+		events, err := s.events.GetEventsAfter(ctx, afterId, s.config.replayLimit)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "stream.service :: Error catching up on events",
+				"afterId", afterId,
+				"error", err,
+			)
+			return
+		}
+
+		for _, event := range events {
+			dispatch(event)
+			afterId = event.Id
+		}
+		if len(events) < s.config.replayLimit {
+			return
+		}
+	}
+}
+
+// prune deletes the events older than the retention period at every prune
+// interval, until the context is cancelled.
+func (s Service) prune(ctx context.Context) {
+	ticker := time.NewTicker(s.config.pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := s.events.DeleteEventsBefore(ctx, time.Now().Add(-s.config.retention))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.ErrorContext(ctx, "stream.service :: Error deleting old events",
+				"error", err,
+			)
+			continue
+		}
+
+		if count > 0 {
+			s.logger.InfoContext(ctx, "Deleted old events",
+				"deleted", count,
+			)
+		}
+	}
+}
+
+// Connect opens a stream of the new posts in the communities the user in the
+// claims is subscribed to and can see, the notifications of the user and, if a
+// post ID is given, the changes to the comments on the post. Given the ID of
+// the last event the client received, the matching events published since are
+// replayed first, up to the replay limit.
+func (s Service) Connect(ctx context.Context, claims servicesauth.TokenClaims, postId *forum.PostId,
+	lastEventId *forum.EventId,
+) (stream *Stream, err error) {
+	if postId != nil {
+		_, err = s.posts.GetPostById(ctx, *postId, servicesforum.VisibleTo(claims))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	communityIds, err := s.subscriptions.GetSubscribedCommunityIds(ctx, claims.UserId, servicesforum.VisibleTo(claims))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "stream.service :: Error getting subscribed communities",
+			"error", err,
+		)
+		return nil, err
+	}
+
+	postIds := set.New[forum.PostId]()
+	if postId != nil {
+		postIds.Add(*postId)
+	}
+
+	stream = &Stream{
+		hub: s.hub,
+		filter: forum.EventFilter{
+			RecipientId:  &claims.UserId,
+			CommunityIds: set.New[forum.CommunityId](set.WithSlice(communityIds)),
+			PostIds:      postIds,
+		},
+		userId: claims.UserId,
+	}
+
+	// The stream is added before replaying, so that no event is missed
+	// between the replay and the live events.
+	s.hub.add(stream)
+
+	var replayed []forum.Event
+	if lastEventId != nil {
+		replayed, err = s.events.GetMatchingEventsAfter(ctx, *lastEventId, stream.filter, s.config.replayLimit)
+		if err != nil {
+			s.hub.remove(stream)
+			s.logger.ErrorContext(ctx, "stream.service :: Error replaying events",
+				"lastEventId", *lastEventId,
+				"error", err,
+			)
+			return nil, err
+		}
+	}
+
+	s.hub.activate(stream, replayed)
+
+	return stream, nil
+}
@@ -0,0 +1,76 @@
+package servicesunfurl
+
+import "time"
+
+// Config represents the configuration for the unfurl service.
+type Config struct {
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+	maxSize   int64
+	ttl       time.Duration
+	userAgent string
+
+	// allowPrivateAddrs lets pages be fetched from private and loopback
+	// addresses, for tests against local servers.
+	allowPrivateAddrs bool
+}
+
+// Option represents an option for the unfurl service.
+type Option func(*Config)
+
+// defaultConfig returns the default configuration for the unfurl service.
+func defaultConfig() Config {
+	return Config{
+		interval:  10 * time.Second,
+		batchSize: 20,
+		timeout:   5 * time.Second,
+		maxSize:   1024 * 1024,
+		ttl:       24 * time.Hour,
+		userAgent: "greddit-unfurl/1.0",
+	}
+}
+
+// WithInterval sets the interval between checks for previews to fetch.
+func WithInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.interval = interval
+	}
+}
+
+// WithBatchSize sets the maximum number of previews fetched in each batch.
+func WithBatchSize(batchSize int) Option {
+	return func(c *Config) {
+		c.batchSize = batchSize
+	}
+}
+
+// WithTimeout sets how long fetching a single page may take, redirects
+// included.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxSize sets the maximum number of bytes read from a page. The metadata
+// of a page is expected in its head, so the rest is not read.
+func WithMaxSize(maxSize int64) Option {
+	return func(c *Config) {
+		c.maxSize = maxSize
+	}
+}
+
+// WithTtl sets how long a fetched preview is used before being fetched again.
+func WithTtl(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.ttl = ttl
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent when fetching pages.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) {
+		c.userAgent = userAgent
+	}
+}
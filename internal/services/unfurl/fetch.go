@@ -0,0 +1,240 @@
+package servicesunfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	"golang.org/x/net/html"
+)
+
+// maxRedirects is the maximum number of redirects followed when fetching a
+// page.
+const maxRedirects = 5
+
+var (
+	// ErrForbiddenAddress is returned when a page resolves to an address which
+	// may not be fetched, such as a loopback or private address.
+	ErrForbiddenAddress = errors.New("unfurl: forbidden address")
+	// ErrUnsupportedContent is returned when a page is neither HTML nor an
+	// image.
+	ErrUnsupportedContent = errors.New("unfurl: unsupported content type")
+)
+
+// forbiddenPrefixes are the ranges outside those reported by netip.Addr
+// methods which may not be fetched: this network, shared address space, IETF
+// protocol assignments, benchmarking, reserved, and NAT64 which may embed any
+// IPv4 address.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// isPublicAddr returns whether the address is a public unicast address.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// newClient creates the HTTP client fetching pages. Addresses are checked
+// when connecting rather than when resolving, so that neither redirects nor
+// DNS answers changing between lookups can reach a forbidden address, and
+// proxies from the environment are ignored as they would hide the address.
+func newClient(config Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: config.timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			if config.allowPrivateAddrs {
+				return nil
+			}
+
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: config.timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+			TLSHandshakeTimeout:   config.timeout,
+			ResponseHeaderTimeout: config.timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("unfurl: stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// fetch fetches the page at the URL and returns its preview. The preview of an
+// image is the image itself.
+func (s Service) fetch(ctx context.Context, rawUrl string) (preview forum.LinkPreview, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return forum.LinkPreview{}, err
+	}
+	req.Header.Set("User-Agent", s.config.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,image/*;q=0.8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return forum.LinkPreview{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return forum.LinkPreview{}, fmt.Errorf("unfurl: unexpected status %d", resp.StatusCode)
+	}
+
+	// The final URL is the one after redirects, which relative image URLs are
+	// resolved against.
+	finalUrl := resp.Request.URL
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+	case strings.HasPrefix(mediaType, "image/"):
+		return forum.NewLinkPreview(rawUrl, "", "", finalUrl.String(), time.Now()), nil
+	default:
+		return forum.LinkPreview{}, fmt.Errorf("%w: %q", ErrUnsupportedContent, mediaType)
+	}
+
+	meta := parseMetadata(io.LimitReader(resp.Body, s.config.maxSize))
+
+	imageUrl := ""
+	if meta.image != "" {
+		if u, err := finalUrl.Parse(meta.image); err == nil {
+			imageUrl = u.String()
+		}
+	}
+
+	return forum.NewLinkPreview(rawUrl, meta.title, meta.description, imageUrl, time.Now()), nil
+}
+
+// metadata is the metadata of a page, from its OpenGraph tags when present
+// and from its title and description otherwise.
+type metadata struct {
+	title       string
+	description string
+	image       string
+}
+
+// parseMetadata reads the metadata in the head of the HTML page, stopping at
+// the body.
+func parseMetadata(r io.Reader) metadata {
+	var (
+		meta     metadata
+		ogMeta   metadata
+		inTitle  bool
+		tokenize = html.NewTokenizer(r)
+	)
+
+	for {
+		tokenType := tokenize.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return meta.or(ogMeta)
+		case html.TextToken:
+			if inTitle && meta.title == "" {
+				meta.title = string(tokenize.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenize.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta.or(ogMeta)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenize.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = tokenType == html.StartTagToken
+			case "body":
+				return meta.or(ogMeta)
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+
+				attrs := map[string]string{}
+				for {
+					key, value, more := tokenize.TagAttr()
+					attrs[string(key)] = string(value)
+					if !more {
+						break
+					}
+				}
+
+				property := attrs["property"]
+				if property == "" {
+					property = attrs["name"]
+				}
+				content := attrs["content"]
+
+				switch strings.ToLower(property) {
+				case "og:title":
+					ogMeta.title = content
+				case "og:description":
+					ogMeta.description = content
+				case "og:image", "og:image:url":
+					if ogMeta.image == "" {
+						ogMeta.image = content
+					}
+				case "description":
+					meta.description = content
+				}
+			}
+		}
+	}
+}
+
+// or returns the metadata with the fields of other taking precedence when
+// set.
+func (m metadata) or(other metadata) metadata {
+	if other.title != "" {
+		m.title = other.title
+	}
+	if other.description != "" {
+		m.description = other.description
+	}
+	if other.image != "" {
+		m.image = other.image
+	}
+	return m
+}
@@ -0,0 +1,128 @@
+package servicesunfurl
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"greddit/internal/domains/forum"
+
+	dbportsforum "greddit/internal/ports/db/forum"
+)
+
+// Service is the unfurl service, fetching the title, description and image of
+// the pages link posts point to. Pages are only fetched from public addresses,
+// within a timeout and up to a maximum size, and previews are fetched again
+// once they are older than the TTL and requested again.
+type Service struct {
+	logger *slog.Logger
+	repo   dbportsforum.LinkPreviewsRepo
+	client *http.Client
+	config Config
+}
+
+// NewService creates a new Service.
+func NewService(logger *slog.Logger, repo dbportsforum.LinkPreviewsRepo, opts ...Option) Service {
+	config := defaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	defaults := defaultConfig()
+	if config.interval <= 0 {
+		config.interval = defaults.interval
+	}
+	if config.batchSize <= 0 {
+		config.batchSize = defaults.batchSize
+	}
+	if config.timeout <= 0 {
+		config.timeout = defaults.timeout
+	}
+	if config.maxSize <= 0 {
+		config.maxSize = defaults.maxSize
+	}
+
+	return Service{
+		logger: logger,
+		repo:   repo,
+		client: newClient(config),
+		config: config,
+	}
+}
+
+// Start fetches the due previews once immediately and then at every interval.
+// It blocks until the context is cancelled.
+func (s Service) Start(ctx context.Context) error {
+	s.logger.Info("Unfurl worker started",
+		"interval", s.config.interval,
+		"ttl", s.config.ttl,
+	)
+	defer s.logger.Info("Unfurl worker stopped")
+
+	ticker := time.NewTicker(s.config.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Unfurl(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unfurl fetches the due previews in batches until a batch comes up short.
+// Pages which cannot be fetched leave a failed preview, which is only tried
+// again once stale.
+func (s Service) Unfurl(ctx context.Context) {
+	for {
+		urls, err := s.repo.GetDueLinkPreviewUrls(ctx, time.Now().Add(-s.config.ttl), s.config.batchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.ErrorContext(ctx, "unfurl.service :: Error getting due link previews",
+				"error", err,
+			)
+			return
+		}
+
+		for _, url := range urls {
+			err = s.unfurl(ctx, url)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.logger.ErrorContext(ctx, "unfurl.service :: Error saving link preview",
+					"url", url,
+					"error", err,
+				)
+				return
+			}
+		}
+
+		if len(urls) < s.config.batchSize {
+			return
+		}
+	}
+}
+
+// unfurl fetches and saves the preview of a single URL.
+func (s Service) unfurl(ctx context.Context, url string) (err error) {
+	preview, err := s.fetch(ctx, url)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.logger.InfoContext(ctx, "Failed to fetch link preview",
+			"url", url,
+			"error", err,
+		)
+		preview = forum.NewFailedLinkPreview(url, time.Now())
+	}
+
+	return s.repo.SaveLinkPreview(ctx, preview)
+}
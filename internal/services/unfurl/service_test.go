@@ -0,0 +1,291 @@
+package servicesunfurl
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"greddit/internal/domains/forum"
+	"greddit/internal/domains/shared"
+	"greddit/internal/test"
+)
+
+// allowPrivateAddrs lets the service fetch from the local test servers.
+func allowPrivateAddrs() Option {
+	return func(c *Config) {
+		c.allowPrivateAddrs = true
+	}
+}
+
+// fakeRepo is an in-memory dbportsforum.LinkPreviewsRepo, where every preview
+// not fetched yet is due.
+type fakeRepo struct {
+	mu       sync.Mutex
+	previews map[string]forum.LinkPreview
+}
+
+func newFakeRepo(urls ...string) *fakeRepo {
+	r := &fakeRepo{previews: map[string]forum.LinkPreview{}}
+	for _, url := range urls {
+		r.previews[url] = forum.LinkPreview{Url: url, Status: forum.LinkPreviewStatusPending}
+	}
+	return r
+}
+
+func (r *fakeRepo) RequestLinkPreview(_ context.Context, url string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.previews[url]; !ok {
+		r.previews[url] = forum.LinkPreview{Url: url, Status: forum.LinkPreviewStatusPending}
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetLinkPreviewsByUrls(_ context.Context, urls []string) ([]forum.LinkPreview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previews := []forum.LinkPreview{}
+	for _, url := range urls {
+		if preview, ok := r.previews[url]; ok {
+			previews = append(previews, preview)
+		}
+	}
+	return previews, nil
+}
+
+func (r *fakeRepo) GetDueLinkPreviewUrls(_ context.Context, _ time.Time, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	urls := []string{}
+	for url, preview := range r.previews {
+		if preview.FetchedAt == nil && len(urls) < limit {
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
+
+func (r *fakeRepo) SaveLinkPreview(_ context.Context, preview forum.LinkPreview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.previews[preview.Url]; !ok {
+		return shared.NotFoundError{Entity: "link preview"}
+	}
+	r.previews[preview.Url] = preview
+	return nil
+}
+
+func newTestService(repo *fakeRepo, opts ...Option) Service {
+	opts = append([]Option{allowPrivateAddrs()}, opts...)
+	return NewService(slog.New(slog.DiscardHandler), repo, opts...)
+}
+
+func TestService_fetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/opengraph", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!DOCTYPE html><html><head>
+<title>Plain title</title>
+<meta name="description" content="Plain description">
+<meta property="og:title" content="OpenGraph title">
+<meta property="og:description" content="OpenGraph description">
+<meta property="og:image" content="/images/cover.png">
+</head><body><p>Body</p></body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>
+  Plain
+  title </title><meta name="description" content="Plain description"></head>
+<body><meta property="og:title" content="Not in head"></body></html>`))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/opengraph", http.StatusFound)
+	})
+	mux.HandleFunc("/articles/opengraph", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<head><meta property="og:image" content="cover.png"></head>`))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"title": "JSON"}`))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<head><title>Large</title><!--" + strings.Repeat("x", 64*1024) + "-->"))
+		_, _ = w.Write([]byte(`<meta name="description" content="After the limit"></head>`))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	// The server is closed once the parallel subtests are done.
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	ser := newTestService(newFakeRepo(),
+		WithMaxSize(32*1024),
+		WithTimeout(200*time.Millisecond),
+	)
+
+	t.Run("opengraph tags take precedence", func(t *testing.T) {
+		t.Parallel()
+
+		preview, err := ser.fetch(t.Context(), server.URL+"/opengraph")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Status not as expected", forum.LinkPreviewStatusReady, preview.Status)
+		test.AssertEqual(t, "URL not as expected", server.URL+"/opengraph", preview.Url)
+		test.AssertEqual(t, "Title not as expected", "OpenGraph title", preview.Title)
+		test.AssertEqual(t, "Description not as expected", "OpenGraph description", preview.Description)
+		test.AssertEqual(t, "Image URL not as expected", server.URL+"/images/cover.png", preview.ImageUrl)
+	})
+
+	t.Run("falls back to the title and description", func(t *testing.T) {
+		t.Parallel()
+
+		preview, err := ser.fetch(t.Context(), server.URL+"/plain")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Title not as expected", "Plain title", preview.Title)
+		test.AssertEqual(t, "Description not as expected", "Plain description", preview.Description)
+		test.AssertEqual(t, "Image URL not as expected", "", preview.ImageUrl)
+	})
+
+	t.Run("relative images resolve against the final url", func(t *testing.T) {
+		t.Parallel()
+
+		preview, err := ser.fetch(t.Context(), server.URL+"/redirect")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "URL should be the requested URL", server.URL+"/redirect", preview.Url)
+		test.AssertEqual(t, "Image URL not as expected", server.URL+"/articles/cover.png", preview.ImageUrl)
+	})
+
+	t.Run("images preview themselves", func(t *testing.T) {
+		t.Parallel()
+
+		preview, err := ser.fetch(t.Context(), server.URL+"/image.png")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Image URL not as expected", server.URL+"/image.png", preview.ImageUrl)
+	})
+
+	t.Run("reads at most the max size", func(t *testing.T) {
+		t.Parallel()
+
+		preview, err := ser.fetch(t.Context(), server.URL+"/large")
+		test.NilErr(t, err)
+		test.AssertEqual(t, "Title not as expected", "Large", preview.Title)
+		test.AssertEqual(t, "Description past the limit should not be read", "", preview.Description)
+	})
+
+	t.Run("unsupported content", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ser.fetch(t.Context(), server.URL+"/data.json")
+		test.Assert(t, "Expected unsupported content error", errors.Is(err, ErrUnsupportedContent))
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		data := []struct {
+			name string
+			path string
+		}{
+			{name: "not found", path: "/missing"},
+			{name: "redirect loop", path: "/loop"},
+			{name: "timeout", path: "/slow"},
+		}
+
+		for _, d := range data {
+			t.Run(d.name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := ser.fetch(t.Context(), server.URL+d.path)
+				test.Assert(t, "Expected error", err != nil)
+			})
+		}
+	})
+
+	t.Run("private addresses are forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		ser := NewService(slog.New(slog.DiscardHandler), newFakeRepo())
+
+		_, err := ser.fetch(t.Context(), server.URL+"/opengraph")
+		test.Assert(t, "Expected forbidden address error", errors.Is(err, ErrForbiddenAddress))
+	})
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	data := []struct {
+		addr   string
+		public bool
+	}{
+		{addr: "93.184.216.34", public: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", public: true},
+		{addr: "127.0.0.1", public: false},
+		{addr: "10.1.2.3", public: false},
+		{addr: "172.16.0.1", public: false},
+		{addr: "192.168.1.1", public: false},
+		{addr: "169.254.169.254", public: false},
+		{addr: "100.64.0.1", public: false},
+		{addr: "0.0.0.0", public: false},
+		{addr: "::1", public: false},
+		{addr: "fd00::1", public: false},
+		{addr: "fe80::1", public: false},
+		{addr: "::ffff:127.0.0.1", public: false},
+		{addr: "64:ff9b::a00:1", public: false},
+	}
+
+	for _, d := range data {
+		t.Run(d.addr, func(t *testing.T) {
+			t.Parallel()
+
+			test.AssertEqual(t, "Publicness not as expected", d.public, isPublicAddr(netip.MustParseAddr(d.addr)))
+		})
+	}
+}
+
+func TestService_Unfurl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<head><title>Home</title></head>`))
+	}))
+	defer server.Close()
+
+	ok, missing := server.URL+"/", server.URL+"/missing"
+	repo := newFakeRepo(ok, missing)
+	ser := newTestService(repo, WithBatchSize(1))
+
+	ser.Unfurl(t.Context())
+
+	previews, err := repo.GetLinkPreviewsByUrls(t.Context(), []string{ok, missing})
+	test.NilErr(t, err)
+	test.AssertEqual(t, "Number of previews not as expected", 2, len(previews))
+	test.AssertEqual(t, "Fetched preview not as expected", forum.LinkPreviewStatusReady, previews[0].Status)
+	test.AssertEqual(t, "Title not as expected", "Home", previews[0].Title)
+	test.AssertEqual(t, "Missing page should fail", forum.LinkPreviewStatusFailed, previews[1].Status)
+	test.Assert(t, "Failed preview should be marked fetched", previews[1].FetchedAt != nil)
+}